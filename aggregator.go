@@ -0,0 +1,334 @@
+package lifecycle
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Aggregator computes rolling statistics over the events a Producer emits
+// Statistics cover a sliding window, tracked as a series of time buckets so old
+// observations expire without rescanning every sample
+//
+// Operations group an event family (the first two segments of the event type,
+// e.g. "api.request" or "db.query") by endpoint. Only outcome events count toward
+// an operation; start events such as api.request.received and db.query.started do not
+//
+// It is safe for concurrent use
+type Aggregator struct {
+	mu         sync.Mutex
+	window     time.Duration
+	bucketSize time.Duration
+	buckets    []*aggregateBucket        // Oldest first
+	endpoints  map[string]pendingRequest // correlation ID -> endpoint from api.request.received
+	now        func() time.Time
+}
+
+// aggregateBucket holds the observations for one slice of the window
+type aggregateBucket struct {
+	start       time.Time
+	eventCounts map[string]int
+	operations  map[operationKey]*operationAccumulator
+}
+
+// operationKey identifies an operation and endpoint
+type operationKey struct {
+	operation string
+	endpoint  string
+}
+
+// operationAccumulator collects raw observations for an operation in one bucket
+type operationAccumulator struct {
+	count     int
+	errors    int
+	durations []float64 // Milliseconds
+}
+
+// pendingRequest remembers the endpoint of a request until its outcome is observed
+type pendingRequest struct {
+	endpoint string
+	seen     time.Time
+}
+
+// Aggregates is a point-in-time view of an Aggregator's window
+type Aggregates struct {
+	Window      time.Duration    `json:"-"`
+	EventCounts map[string]int   `json:"event_counts"` // Events per event type
+	Operations  []OperationStats `json:"operations"`   // Busiest first
+}
+
+// OperationStats summarizes the outcomes of one operation/endpoint within the window
+type OperationStats struct {
+	Operation     string  `json:"operation"`          // Event family (e.g., "api.request")
+	Endpoint      string  `json:"endpoint,omitempty"` // "METHOD path" for requests, otherwise the API identifier
+	Count         int     `json:"count"`
+	Errors        int     `json:"errors"`
+	ErrorRate     float64 `json:"error_rate"`
+	RatePerSecond float64 `json:"rate_per_second"`
+	P50Ms         float64 `json:"p50_ms"`
+	P95Ms         float64 `json:"p95_ms"`
+	P99Ms         float64 `json:"p99_ms"`
+}
+
+// aggregatorBuckets is the number of buckets the window is divided into
+const aggregatorBuckets = 12
+
+// NewAggregator creates an aggregator with the given sliding window (default: 1 minute)
+func NewAggregator(window time.Duration) *Aggregator {
+	if window <= 0 {
+		window = time.Minute
+	}
+	return &Aggregator{
+		window:     window,
+		bucketSize: window / aggregatorBuckets,
+		endpoints:  make(map[string]pendingRequest),
+		now:        time.Now,
+	}
+}
+
+// WithAggregator enables in-process aggregation of emitted events
+// Statistics are available from Producer.Aggregates
+func WithAggregator(aggregator *Aggregator) ProducerOption {
+	return func(p *Producer) {
+		p.aggregator = aggregator
+	}
+}
+
+// Aggregates returns the producer's rolling statistics, or nil if aggregation is not enabled
+func (p *Producer) Aggregates() *Aggregates {
+	if p.aggregator == nil {
+		return nil
+	}
+	return p.aggregator.Snapshot()
+}
+
+// Observe records an event and its duration
+// The library's own lifecycle.* events are ignored so stats never count themselves
+func (a *Aggregator) Observe(event Event, duration time.Duration) {
+	eventType := event.GetEventType()
+	if strings.HasPrefix(eventType, "lifecycle.") {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	bucket := a.currentBucket(now)
+	bucket.eventCounts[eventType]++
+
+	if received, ok := event.(*RequestReceivedEvent); ok {
+		if id := received.GetCorrelationID(); id != "" {
+			a.endpoints[id] = pendingRequest{endpoint: received.Method + " " + requestRoute(received), seen: now}
+		}
+		return
+	}
+	if !isOutcomeEvent(eventType) {
+		return
+	}
+
+	key := operationKey{operation: eventFamily(eventType), endpoint: event.GetAPI()}
+	if id := event.GetCorrelationID(); id != "" && key.operation == "api.request" {
+		if pending, ok := a.endpoints[id]; ok {
+			key.endpoint = pending.endpoint
+			delete(a.endpoints, id)
+		}
+	}
+
+	acc, ok := bucket.operations[key]
+	if !ok {
+		acc = &operationAccumulator{}
+		bucket.operations[key] = acc
+	}
+	acc.count++
+	if isErrorEvent(eventType) {
+		acc.errors++
+	}
+	if duration > 0 {
+		acc.durations = append(acc.durations, float64(duration)/float64(time.Millisecond))
+	}
+}
+
+// Snapshot computes statistics over the current window
+func (a *Aggregator) Snapshot() *Aggregates {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.expire(a.now())
+
+	result := &Aggregates{
+		Window:      a.window,
+		EventCounts: make(map[string]int),
+	}
+	merged := make(map[operationKey]*operationAccumulator)
+	for _, bucket := range a.buckets {
+		for eventType, count := range bucket.eventCounts {
+			result.EventCounts[eventType] += count
+		}
+		for key, acc := range bucket.operations {
+			total, ok := merged[key]
+			if !ok {
+				total = &operationAccumulator{}
+				merged[key] = total
+			}
+			total.count += acc.count
+			total.errors += acc.errors
+			total.durations = append(total.durations, acc.durations...)
+		}
+	}
+
+	for key, acc := range merged {
+		sort.Float64s(acc.durations)
+		stats := OperationStats{
+			Operation:     key.operation,
+			Endpoint:      key.endpoint,
+			Count:         acc.count,
+			Errors:        acc.errors,
+			RatePerSecond: float64(acc.count) / a.window.Seconds(),
+			P50Ms:         Percentile(acc.durations, 50),
+			P95Ms:         Percentile(acc.durations, 95),
+			P99Ms:         Percentile(acc.durations, 99),
+		}
+		if acc.count > 0 {
+			stats.ErrorRate = float64(acc.errors) / float64(acc.count)
+		}
+		result.Operations = append(result.Operations, stats)
+	}
+	sort.Slice(result.Operations, func(i, j int) bool {
+		oi, oj := result.Operations[i], result.Operations[j]
+		if oi.Count != oj.Count {
+			return oi.Count > oj.Count
+		}
+		if oi.Operation != oj.Operation {
+			return oi.Operation < oj.Operation
+		}
+		return oi.Endpoint < oj.Endpoint
+	})
+
+	return result
+}
+
+// Operation returns the stats for an operation and endpoint, if any were observed
+func (a *Aggregates) Operation(operation, endpoint string) (OperationStats, bool) {
+	for _, stats := range a.Operations {
+		if stats.Operation == operation && stats.Endpoint == endpoint {
+			return stats, true
+		}
+	}
+	return OperationStats{}, false
+}
+
+// currentBucket returns the bucket for now, starting a new one if needed
+// Must be called with a.mu held
+func (a *Aggregator) currentBucket(now time.Time) *aggregateBucket {
+	a.expire(now)
+
+	start := now.Truncate(a.bucketSize)
+	if n := len(a.buckets); n > 0 && a.buckets[n-1].start.Equal(start) {
+		return a.buckets[n-1]
+	}
+
+	bucket := &aggregateBucket{
+		start:       start,
+		eventCounts: make(map[string]int),
+		operations:  make(map[operationKey]*operationAccumulator),
+	}
+	a.buckets = append(a.buckets, bucket)
+	return bucket
+}
+
+// expire drops buckets and pending requests that have left the window
+// Must be called with a.mu held
+func (a *Aggregator) expire(now time.Time) {
+	cutoff := now.Add(-a.window)
+
+	drop := 0
+	for drop < len(a.buckets) && !a.buckets[drop].start.Add(a.bucketSize).After(cutoff) {
+		drop++
+	}
+	a.buckets = a.buckets[drop:]
+
+	for id, pending := range a.endpoints {
+		if pending.seen.Before(cutoff) {
+			delete(a.endpoints, id)
+		}
+	}
+}
+
+// eventFamily returns the first two segments of an event type (e.g., "api.request")
+func eventFamily(eventType string) string {
+	parts := splitEventType(eventType)
+	if len(parts) >= 2 {
+		return parts[0] + "." + parts[1]
+	}
+	return eventType
+}
+
+// isOutcomeEvent reports whether an event type marks the end of an operation
+// Retries are intermediate steps, not outcomes
+func isOutcomeEvent(eventType string) bool {
+	return !strings.HasSuffix(eventType, ".started") && !strings.HasSuffix(eventType, ".received") &&
+		!strings.HasSuffix(eventType, ".retried")
+}
+
+// isErrorEvent reports whether an event type represents a failed outcome
+func isErrorEvent(eventType string) bool {
+	return strings.HasPrefix(eventType, "error.") ||
+		contains(eventType, "errored", "failed", "crashed", "rolled_back", "timed_out", "unavailable")
+}
+
+// StatsEvent represents a lifecycle.stats event carrying periodic aggregates
+type StatsEvent struct {
+	Base          *BaseEvent       `json:"base"`
+	WindowSeconds float64          `json:"window_seconds"`
+	EventCounts   map[string]int   `json:"event_counts"`
+	Operations    []OperationStats `json:"operations,omitempty"`
+}
+
+func (e *StatsEvent) GetEventType() string     { return e.Base.GetEventType() }
+func (e *StatsEvent) GetTimestamp() time.Time  { return e.Base.GetTimestamp() }
+func (e *StatsEvent) GetService() string       { return e.Base.GetService() }
+func (e *StatsEvent) GetAPI() string           { return e.Base.GetAPI() }
+func (e *StatsEvent) GetHost() string          { return e.Base.GetHost() }
+func (e *StatsEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *StatsEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *StatsEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *StatsEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
+
+// EmitStats emits a lifecycle.stats event with the current aggregates
+func (p *Producer) EmitStats(ctx context.Context) error {
+	aggregates := p.Aggregates()
+	if aggregates == nil {
+		return nil
+	}
+
+	event := &StatsEvent{
+		Base:          p.createBaseEvent("lifecycle.stats", "", nil),
+		WindowSeconds: aggregates.Window.Seconds(),
+		EventCounts:   aggregates.EventCounts,
+		Operations:    aggregates.Operations,
+	}
+	return p.emitEvent(ctx, event, 0)
+}
+
+// RunStatsReporter emits a lifecycle.stats event every interval until ctx is done
+// It is typically started in its own goroutine
+func (p *Producer) RunStatsReporter(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = p.EmitStats(ctx)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+)
+
+// Anomaly describes an unusual condition detected in aggregated metrics
+type Anomaly struct {
+	Detector  string  `json:"detector"`           // Name of the detector that fired
+	Operation string  `json:"operation"`          // Event family (e.g., "api.request")
+	Endpoint  string  `json:"endpoint,omitempty"` // Endpoint within the operation
+	Metric    string  `json:"metric"`             // Metric that was evaluated (e.g., "error_rate")
+	Value     float64 `json:"value"`              // Observed value
+	Threshold float64 `json:"threshold"`          // Value the observation was compared against
+	Message   string  `json:"message"`
+}
+
+// AnomalyDetector inspects the current aggregates and returns any anomalies found
+// Detectors may keep state between calls (e.g., a baseline); the Producer never
+// calls a detector concurrently with itself
+type AnomalyDetector func(aggregates *Aggregates) []Anomaly
+
+// Metric names accepted by the built-in detectors
+const (
+	MetricErrorRate     = "error_rate"
+	MetricRatePerSecond = "rate_per_second"
+	MetricP50           = "p50_ms"
+	MetricP95           = "p95_ms"
+	MetricP99           = "p99_ms"
+)
+
+// operationMetric returns the named metric from stats
+func operationMetric(stats OperationStats, metric string) (float64, bool) {
+	switch metric {
+	case MetricErrorRate:
+		return stats.ErrorRate, true
+	case MetricRatePerSecond:
+		return stats.RatePerSecond, true
+	case MetricP50:
+		return stats.P50Ms, true
+	case MetricP95:
+		return stats.P95Ms, true
+	case MetricP99:
+		return stats.P99Ms, true
+	}
+	return 0, false
+}
+
+// ThresholdDetector fires when a metric exceeds a fixed threshold
+// Operations with fewer than minCount outcomes in the window are ignored
+func ThresholdDetector(metric string, threshold float64, minCount int) AnomalyDetector {
+	name := "threshold:" + metric
+	return func(aggregates *Aggregates) []Anomaly {
+		var anomalies []Anomaly
+		for _, stats := range aggregates.Operations {
+			value, ok := operationMetric(stats, metric)
+			if !ok || stats.Count < minCount || value <= threshold {
+				continue
+			}
+			anomalies = append(anomalies, Anomaly{
+				Detector:  name,
+				Operation: stats.Operation,
+				Endpoint:  stats.Endpoint,
+				Metric:    metric,
+				Value:     value,
+				Threshold: threshold,
+				Message:   fmt.Sprintf("%s %.4g exceeds threshold %.4g over %s", metric, value, threshold, aggregates.Window),
+			})
+		}
+		return anomalies
+	}
+}
+
+// ErrorRateDetector fires when an operation's error rate exceeds maxRate (e.g., 0.05 for 5%)
+func ErrorRateDetector(maxRate float64, minCount int) AnomalyDetector {
+	return ThresholdDetector(MetricErrorRate, maxRate, minCount)
+}
+
+// LatencyIncreaseDetector fires when an operation's p99 latency rises above factor times
+// its baseline (e.g., factor 2 for "p99 latency doubled")
+// The baseline is an exponentially weighted moving average of previous observations
+// and is only updated when no anomaly fires, so a sustained regression keeps firing
+func LatencyIncreaseDetector(factor float64, minCount int) AnomalyDetector {
+	const smoothing = 0.2
+	baselines := make(map[operationKey]float64)
+
+	return func(aggregates *Aggregates) []Anomaly {
+		var anomalies []Anomaly
+		for _, stats := range aggregates.Operations {
+			if stats.Count < minCount || stats.P99Ms <= 0 {
+				continue
+			}
+			key := operationKey{operation: stats.Operation, endpoint: stats.Endpoint}
+			baseline, ok := baselines[key]
+			if !ok {
+				baselines[key] = stats.P99Ms
+				continue
+			}
+
+			threshold := baseline * factor
+			if stats.P99Ms > threshold {
+				anomalies = append(anomalies, Anomaly{
+					Detector:  "latency_increase",
+					Operation: stats.Operation,
+					Endpoint:  stats.Endpoint,
+					Metric:    MetricP99,
+					Value:     stats.P99Ms,
+					Threshold: threshold,
+					Message:   fmt.Sprintf("p99 latency %.1fms is %.1fx the %.1fms baseline", stats.P99Ms, stats.P99Ms/baseline, baseline),
+				})
+				continue
+			}
+			baselines[key] = baseline + smoothing*(stats.P99Ms-baseline)
+		}
+		return anomalies
+	}
+}
+
+// StdDevDetector fires when a metric is more than k standard deviations above the mean
+// of its last history observations
+// At least history/2 previous observations are required before the detector fires
+func StdDevDetector(metric string, k float64, history int) AnomalyDetector {
+	if history < 2 {
+		history = 2
+	}
+	series := make(map[operationKey][]float64)
+
+	return func(aggregates *Aggregates) []Anomaly {
+		var anomalies []Anomaly
+		for _, stats := range aggregates.Operations {
+			value, ok := operationMetric(stats, metric)
+			if !ok {
+				continue
+			}
+			key := operationKey{operation: stats.Operation, endpoint: stats.Endpoint}
+			previous := series[key]
+
+			if len(previous) >= history/2 {
+				mean, stddev := meanStdDev(previous)
+				threshold := mean + k*stddev
+				if stddev > 0 && value > threshold {
+					anomalies = append(anomalies, Anomaly{
+						Detector:  "stddev:" + metric,
+						Operation: stats.Operation,
+						Endpoint:  stats.Endpoint,
+						Metric:    metric,
+						Value:     value,
+						Threshold: threshold,
+						Message:   fmt.Sprintf("%s %.4g is %.1f standard deviations above the mean %.4g", metric, value, (value-mean)/stddev, mean),
+					})
+				}
+			}
+
+			previous = append(previous, value)
+			if len(previous) > history {
+				previous = previous[len(previous)-history:]
+			}
+			series[key] = previous
+		}
+		return anomalies
+	}
+}
+
+// meanStdDev returns the mean and population standard deviation of values
+func meanStdDev(values []float64) (mean, stddev float64) {
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	for _, v := range values {
+		stddev += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(stddev / float64(len(values)))
+}
+
+// WithAnomalyDetectors sets detectors evaluated against the aggregator by CheckAnomalies
+// Requires WithAggregator
+func WithAnomalyDetectors(detectors ...AnomalyDetector) ProducerOption {
+	return func(p *Producer) {
+		p.anomalyDetectors = append(p.anomalyDetectors, detectors...)
+	}
+}
+
+// AnomalyEvent represents a lifecycle.anomaly event
+type AnomalyEvent struct {
+	Base *BaseEvent `json:"base"`
+	Anomaly
+}
+
+func (e *AnomalyEvent) GetEventType() string     { return e.Base.GetEventType() }
+func (e *AnomalyEvent) GetTimestamp() time.Time  { return e.Base.GetTimestamp() }
+func (e *AnomalyEvent) GetService() string       { return e.Base.GetService() }
+func (e *AnomalyEvent) GetAPI() string           { return e.Base.GetAPI() }
+func (e *AnomalyEvent) GetHost() string          { return e.Base.GetHost() }
+func (e *AnomalyEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *AnomalyEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *AnomalyEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *AnomalyEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
+
+// CheckAnomalies runs the configured detectors against the current aggregates and
+// emits a lifecycle.anomaly event for each anomaly found
+func (p *Producer) CheckAnomalies(ctx context.Context) ([]Anomaly, error) {
+	aggregates := p.Aggregates()
+	if aggregates == nil || len(p.anomalyDetectors) == 0 {
+		return nil, nil
+	}
+
+	p.anomalyMu.Lock()
+	var anomalies []Anomaly
+	for _, detect := range p.anomalyDetectors {
+		anomalies = append(anomalies, detect(aggregates)...)
+	}
+	p.anomalyMu.Unlock()
+
+	p.profileOnAnomalies(anomalies)
+
+	for _, anomaly := range anomalies {
+		event := &AnomalyEvent{
+			Base:    p.createBaseEvent("lifecycle.anomaly", "", nil),
+			Anomaly: anomaly,
+		}
+		if err := p.emitEvent(ctx, event, 0); err != nil {
+			return anomalies, err
+		}
+	}
+	return anomalies, nil
+}
+
+// RunAnomalyMonitor calls CheckAnomalies every interval until ctx is done
+// It is typically started in its own goroutine
+func (p *Producer) RunAnomalyMonitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, _ = p.CheckAnomalies(ctx)
+		}
+	}
+}
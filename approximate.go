@@ -0,0 +1,87 @@
+package lifecycle
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+)
+
+// approximates reports whether numbers in the annotated field are reported approximately
+// instead of redacted
+func (a FieldAnnotations) approximates() bool {
+	return a.Noise > 0 || a.Bucket > 0
+}
+
+// approximateNumber returns value with the annotation's noise added, then bucketed, or
+// false if value is not a number
+// Integers stay integers: noisy integers are rounded, and integer buckets are inclusive
+// ("30-39"); other buckets include their lower bound only ("1.5-2")
+func approximateNumber(value interface{}, annotations FieldAnnotations) (interface{}, bool) {
+	n, isInt, ok := toFloat(value)
+	if !ok {
+		return nil, false
+	}
+
+	if annotations.Noise > 0 {
+		n += (rand.Float64()*2 - 1) * annotations.Noise
+		if isInt {
+			n = math.Round(n)
+		}
+	}
+
+	if width := annotations.Bucket; width > 0 {
+		lo := math.Floor(n/width) * width
+		if isInt && width == math.Trunc(width) {
+			return fmt.Sprintf("%d-%d", int64(lo), int64(lo+width-1)), true
+		}
+		return formatFloat(lo) + "-" + formatFloat(lo+width), true
+	}
+	if isInt {
+		return int64(n), true
+	}
+	return n, true
+}
+
+// toFloat converts a number to float64, reporting whether it is an integer
+func toFloat(value interface{}) (n float64, isInt, ok bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true, true
+	case int8:
+		return float64(v), true, true
+	case int16:
+		return float64(v), true, true
+	case int32:
+		return float64(v), true, true
+	case int64:
+		return float64(v), true, true
+	case uint:
+		return float64(v), true, true
+	case uint8:
+		return float64(v), true, true
+	case uint16:
+		return float64(v), true, true
+	case uint32:
+		return float64(v), true, true
+	case uint64:
+		return float64(v), true, true
+	case float32:
+		return float64(v), false, true
+	case float64:
+		return v, false, true
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return float64(i), true, true
+		}
+		f, err := v.Float64()
+		return f, false, err == nil
+	}
+	return 0, false, false
+}
+
+// formatFloat formats n in the shortest form that represents it exactly
+func formatFloat(n float64) string {
+	return strconv.FormatFloat(n, 'g', -1, 64)
+}
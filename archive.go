@@ -0,0 +1,264 @@
+package lifecycle
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Archive defaults
+const (
+	DefaultArchivePrefix   = "{service}/{date}/{hour}"
+	DefaultArchiveWindow   = time.Hour
+	DefaultArchiveMaxBytes = 64 << 20
+)
+
+// ObjectStore stores archived objects, such as an S3 or GCS bucket (see S3Store and
+// GCSStore)
+type ObjectStore interface {
+	PutObject(ctx context.Context, key string, body []byte, contentType string) error
+}
+
+// ArchiveSink writes events as NDJSON objects, gzipped by default, to an object store, one
+// object per prefix and time window, for retention independent of the logging platform
+// Object keys are the prefix with its placeholders filled in, then the window's start and a
+// random suffix, so several processes can archive to the same prefix:
+//
+//	orders/2024-05-01/13/20240501T130000Z-4f2a9c1e.ndjson.gz
+//
+// Objects are uploaded when their window ends, when they reach the size limit, and on
+// Flush and Close. An object whose upload fails is kept and retried at the next upload, so
+// events are not lost while the store is unavailable
+type ArchiveSink struct {
+	store    ObjectStore
+	prefix   string
+	window   time.Duration
+	compress bool
+	maxBytes int
+	timeout  time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	objects map[string]*archiveObject // By key prefix and window start
+
+	uploadMu sync.Mutex // Keeps uploads of an object's parts in order
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// archiveObject is an object being written
+type archiveObject struct {
+	key    string // Without the random suffix and extension
+	start  time.Time
+	buf    bytes.Buffer
+	gz     *gzip.Writer // Nil when not compressing
+	events int
+}
+
+// ArchiveOption configures an ArchiveSink
+type ArchiveOption func(*ArchiveSink)
+
+// WithArchivePrefix sets the object key prefix (default: DefaultArchivePrefix)
+// The placeholders {service}, {host}, {date} (2006-01-02), and {hour} (15) are filled in
+// from the event and the window's start, in UTC
+func WithArchivePrefix(prefix string) ArchiveOption {
+	return func(s *ArchiveSink) {
+		s.prefix = strings.Trim(prefix, "/")
+	}
+}
+
+// WithArchiveWindow sets the time window each object covers (default: DefaultArchiveWindow)
+func WithArchiveWindow(window time.Duration) ArchiveOption {
+	return func(s *ArchiveSink) {
+		if window > 0 {
+			s.window = window
+		}
+	}
+}
+
+// WithArchiveCompression sets whether objects are gzipped (default: true)
+func WithArchiveCompression(compress bool) ArchiveOption {
+	return func(s *ArchiveSink) {
+		s.compress = compress
+	}
+}
+
+// WithArchiveMaxBytes sets the size at which an object is uploaded before its window ends
+// (default: DefaultArchiveMaxBytes)
+func WithArchiveMaxBytes(maxBytes int) ArchiveOption {
+	return func(s *ArchiveSink) {
+		if maxBytes > 0 {
+			s.maxBytes = maxBytes
+		}
+	}
+}
+
+// NewArchiveSink creates a sink archiving events to store
+// Close it (or the producer) to upload the last objects
+func NewArchiveSink(store ObjectStore, opts ...ArchiveOption) *ArchiveSink {
+	s := &ArchiveSink{
+		store:    store,
+		prefix:   DefaultArchivePrefix,
+		window:   DefaultArchiveWindow,
+		compress: true,
+		maxBytes: DefaultArchiveMaxBytes,
+		timeout:  DefaultFlushTimeout,
+		now:      time.Now,
+		objects:  make(map[string]*archiveObject),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	go s.run()
+	return s
+}
+
+// WriteEvent appends event to the object for its prefix and the current window
+func (s *ArchiveSink) WriteEvent(event Event) error {
+	data, err := NewJSONEncoder().Encode(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	start := s.now().UTC().Truncate(s.window)
+	key := s.objectKey(event, start)
+
+	s.mu.Lock()
+	object, ok := s.objects[key]
+	if !ok {
+		object = &archiveObject{key: key, start: start}
+		if s.compress {
+			object.gz = gzip.NewWriter(&object.buf)
+		}
+		s.objects[key] = object
+	}
+	var w io.Writer = &object.buf
+	if object.gz != nil {
+		w = object.gz
+	}
+	_, _ = w.Write(data)
+	_, _ = w.Write([]byte{'\n'})
+	object.events++
+	full := object.buf.Len() >= s.maxBytes
+	if full {
+		delete(s.objects, key)
+	}
+	s.mu.Unlock()
+
+	if full {
+		return s.upload([]*archiveObject{object})
+	}
+	return nil
+}
+
+// Flush uploads every object, including those whose window has not ended
+func (s *ArchiveSink) Flush() error {
+	return s.uploadWhere(func(*archiveObject) bool { return true })
+}
+
+// Close stops rolling objects by window and uploads the remaining objects
+func (s *ArchiveSink) Close() error {
+	s.closeOnce.Do(func() { close(s.stop) })
+	<-s.done
+	return s.Flush()
+}
+
+// run uploads objects as their windows end, until Close
+func (s *ArchiveSink) run() {
+	defer close(s.done)
+	for {
+		next := s.now().UTC().Truncate(s.window).Add(s.window)
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-s.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+			_ = s.uploadWhere(func(object *archiveObject) bool {
+				return !object.start.Add(s.window).After(s.now())
+			})
+		}
+	}
+}
+
+// uploadWhere uploads the objects for which ready returns true
+func (s *ArchiveSink) uploadWhere(ready func(*archiveObject) bool) error {
+	s.mu.Lock()
+	var objects []*archiveObject
+	for key, object := range s.objects {
+		if ready(object) {
+			objects = append(objects, object)
+			delete(s.objects, key)
+		}
+	}
+	s.mu.Unlock()
+	return s.upload(objects)
+}
+
+// upload puts objects in the store, keeping those that fail for the next upload
+func (s *ArchiveSink) upload(objects []*archiveObject) error {
+	s.uploadMu.Lock()
+	defer s.uploadMu.Unlock()
+
+	var errs []error
+	for _, object := range objects {
+		if object.gz != nil {
+			if err := object.gz.Close(); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			object.gz = nil
+		}
+
+		suffix := make([]byte, 4)
+		_, _ = rand.Read(suffix)
+		key := fmt.Sprintf("%s/%s-%s.ndjson", object.key, object.start.Format("20060102T150405Z"), hex.EncodeToString(suffix))
+		contentType := "application/x-ndjson"
+		if s.compress {
+			key += ".gz"
+			contentType = "application/gzip"
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		err := s.store.PutObject(ctx, strings.TrimPrefix(key, "/"), object.buf.Bytes(), contentType)
+		cancel()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to archive %d events to %s: %w", object.events, key, err))
+			s.retain(object)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// retain keeps an object whose upload failed for the next upload, apart from any newer
+// object for the same key
+func (s *ArchiveSink) retain(object *archiveObject) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := object.key + "@failed@" + object.start.Format(time.RFC3339Nano)
+	if _, ok := s.objects[id]; !ok {
+		s.objects[id] = object
+	}
+}
+
+// objectKey fills in the prefix's placeholders for event in the window starting at start
+func (s *ArchiveSink) objectKey(event Event, start time.Time) string {
+	return strings.NewReplacer(
+		"{service}", event.GetService(),
+		"{host}", event.GetHost(),
+		"{date}", start.Format("2006-01-02"),
+		"{hour}", start.Format("15"),
+	).Replace(s.prefix)
+}
@@ -0,0 +1,204 @@
+package lifecycle
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// AsyncAPIVersion is the AsyncAPI specification version of AsyncAPIDocument
+const AsyncAPIVersion = "3.0.0"
+
+// DefaultAsyncAPIChannel is the address events are published on when AsyncAPIOptions
+// sets none, e.g., a Kafka topic
+const DefaultAsyncAPIChannel = "lifecycle"
+
+// AsyncAPIOptions configures AsyncAPIDocument
+type AsyncAPIOptions struct {
+	Title       string                    // Default: "Lifecycle Events"
+	Version     string                    // Version of the stream's API (default: "1.0.0")
+	Description string                    // Default: a description of the event layout
+	Servers     map[string]AsyncAPIServer // Brokers the stream is published on, by name
+	Channel     string                    // Address of the stream (default: DefaultAsyncAPIChannel)
+
+	// ChannelFor returns the address of an event type's channel, for streams split by
+	// event type or family (default: Channel for every type)
+	ChannelFor func(eventType string) string
+
+	// EventTypes are the event types to include, as exact types or path.Match patterns
+	// (default: every built-in and registered type)
+	EventTypes []string
+}
+
+// AsyncAPIServer is a broker in an AsyncAPI document
+type AsyncAPIServer struct {
+	Host        string `json:"host"`     // e.g., "kafka.internal:9092"
+	Protocol    string `json:"protocol"` // e.g., "kafka", "amqp", or "http"
+	Description string `json:"description,omitempty"`
+}
+
+// AsyncAPIDocument describes the event stream as an AsyncAPI 3.0 document, so other teams
+// can generate consumers: a channel per address with a message per event type, whose
+// payload is the type's JSON Schema (see SchemaFor), correlation ID, and the W3C trace
+// context headers that Kafka and HTTP sinks add
+func AsyncAPIDocument(opts AsyncAPIOptions) (map[string]interface{}, error) {
+	if opts.Title == "" {
+		opts.Title = "Lifecycle Events"
+	}
+	if opts.Version == "" {
+		opts.Version = "1.0.0"
+	}
+	if opts.Description == "" {
+		opts.Description = fmt.Sprintf("Lifecycle events are JSON objects with common fields under `base` "+
+			"and event-specific fields at the top level (schema version %d). Consumers should ignore unknown fields.",
+			CurrentSchemaVersion)
+	}
+	if opts.Channel == "" {
+		opts.Channel = DefaultAsyncAPIChannel
+	}
+	channelFor := opts.ChannelFor
+	if channelFor == nil {
+		channelFor = func(string) string { return opts.Channel }
+	}
+
+	schemas := make(map[string]interface{})
+	messages := make(map[string]interface{})
+	channels := make(map[string]interface{})
+	operations := make(map[string]interface{})
+	channelMessages := make(map[string]map[string]interface{})
+	addresses := make(map[string]string)
+
+	for _, info := range EventTypes() {
+		if !matchesAny(opts.EventTypes, info.EventType) {
+			continue
+		}
+		schema, err := SchemaFor(info.EventType)
+		if err != nil {
+			return nil, err
+		}
+		delete(schema, "$schema") // AsyncAPI schemas are JSON Schema draft 7 supersets
+		schemas[info.EventType] = schema
+
+		message := map[string]interface{}{
+			"name":        info.EventType,
+			"title":       info.EventType,
+			"contentType": "application/json",
+			"payload":     map[string]interface{}{"$ref": "#/components/schemas/" + info.EventType},
+			"headers":     map[string]interface{}{"$ref": "#/components/schemas/TraceHeaders"},
+			"correlationId": map[string]interface{}{
+				"$ref": "#/components/correlationIds/correlationId",
+			},
+		}
+		if info.Description != "" {
+			message["summary"] = info.Description
+		}
+		messages[info.EventType] = message
+
+		address := channelFor(info.EventType)
+		key := asyncAPIKey(address)
+		addresses[key] = address
+		if channelMessages[key] == nil {
+			channelMessages[key] = make(map[string]interface{})
+		}
+		channelMessages[key][info.EventType] = map[string]interface{}{"$ref": "#/components/messages/" + info.EventType}
+	}
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("no event types match %v", opts.EventTypes)
+	}
+
+	for key, refs := range channelMessages {
+		channels[key] = map[string]interface{}{
+			"address":  addresses[key],
+			"messages": refs,
+		}
+		names := make([]string, 0, len(refs))
+		for name := range refs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		operationMessages := make([]interface{}, len(names))
+		for i, name := range names {
+			operationMessages[i] = map[string]interface{}{"$ref": "#/channels/" + key + "/messages/" + name}
+		}
+		operations["consume_"+key] = map[string]interface{}{
+			"action":   "receive",
+			"summary":  "Consume events from " + addresses[key],
+			"channel":  map[string]interface{}{"$ref": "#/channels/" + key},
+			"messages": operationMessages,
+		}
+	}
+
+	schemas["TraceHeaders"] = map[string]interface{}{
+		"type":        "object",
+		"description": "W3C trace context of the batch the event was published in",
+		"properties": map[string]interface{}{
+			"traceparent": map[string]interface{}{"type": "string"},
+			"tracestate":  map[string]interface{}{"type": "string"},
+		},
+	}
+
+	doc := map[string]interface{}{
+		"asyncapi": AsyncAPIVersion,
+		"info": map[string]interface{}{
+			"title":       opts.Title,
+			"version":     opts.Version,
+			"description": opts.Description,
+		},
+		"defaultContentType": "application/json",
+		"channels":           channels,
+		"operations":         operations,
+		"components": map[string]interface{}{
+			"schemas":  schemas,
+			"messages": messages,
+			"correlationIds": map[string]interface{}{
+				"correlationId": map[string]interface{}{
+					"description": "Shared by the events of one request; Kafka messages are also keyed by it",
+					"location":    "$message.payload#/base/correlation_id",
+				},
+			},
+		},
+	}
+	if len(opts.Servers) > 0 {
+		doc["servers"] = opts.Servers
+	}
+	return doc, nil
+}
+
+// WriteAsyncAPI writes AsyncAPIDocument as indented JSON, which AsyncAPI tools accept
+// alongside YAML
+func WriteAsyncAPI(w io.Writer, opts AsyncAPIOptions) error {
+	doc, err := AsyncAPIDocument(opts)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// matchesAny reports whether value matches one of patterns, or patterns is empty
+func matchesAny(patterns []string, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if matchPattern(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// asyncAPIKey turns a channel address into a component key, which may only contain
+// letters, digits, '.', '-', and '_'
+func asyncAPIKey(address string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, address)
+}
@@ -0,0 +1,264 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// BackpressurePolicy decides what an asynchronous sink does when its buffer is full
+type BackpressurePolicy string
+
+const (
+	// BackpressureBlock makes the emitting goroutine wait for space
+	BackpressureBlock BackpressurePolicy = "block"
+	// BackpressureDropOldest discards the oldest buffered event to make room
+	BackpressureDropOldest BackpressurePolicy = "drop_oldest"
+	// BackpressureDropNewest discards the incoming event
+	BackpressureDropNewest BackpressurePolicy = "drop_newest"
+	// BackpressureSample keeps warnings and errors but only a fraction of other events
+	// once the buffer is half full, and discards incoming events when it is full
+	BackpressureSample BackpressurePolicy = "sample"
+)
+
+// backpressureSampleRate is the fraction of info and debug events kept by
+// BackpressureSample while the buffer is under pressure
+const backpressureSampleRate = 0.1
+
+// dropReportInterval limits how often lifecycle.events.dropped is emitted per sink
+const dropReportInterval = time.Second
+
+// EventsDroppedEvent represents a lifecycle.events.dropped event, emitted when an
+// asynchronous sink discards events under backpressure
+// The report is written to the other sinks only: the sink that dropped the events would
+// likely drop it too, and each report it buffered could be followed by another
+type EventsDroppedEvent struct {
+	Base       *BaseEvent         `json:"base"`
+	Sink       string             `json:"sink"`
+	Policy     BackpressurePolicy `json:"policy"`
+	Dropped    int64              `json:"dropped"` // Events dropped since the last report
+	BufferSize int                `json:"buffer_size"`
+
+	source *asyncSink // The sink that dropped the events
+}
+
+func (e *EventsDroppedEvent) GetEventType() string     { return e.Base.GetEventType() }
+func (e *EventsDroppedEvent) GetTimestamp() time.Time  { return e.Base.GetTimestamp() }
+func (e *EventsDroppedEvent) GetService() string       { return e.Base.GetService() }
+func (e *EventsDroppedEvent) GetAPI() string           { return e.Base.GetAPI() }
+func (e *EventsDroppedEvent) GetHost() string          { return e.Base.GetHost() }
+func (e *EventsDroppedEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *EventsDroppedEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *EventsDroppedEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *EventsDroppedEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
+
+// SinkAsync writes to the sink from a background goroutine through a buffer of
+// bufferSize events, so a slow sink does not slow down emitters; policy decides what
+// happens when the buffer is full
+// Drops are counted in the lifecycle.events.dropped OTel counter and reported, at most
+// once a second, as lifecycle.events.dropped events written to the other sinks
+func SinkAsync(bufferSize int, policy BackpressurePolicy) SinkOption {
+	return func(e *sinkEntry) {
+		e.bufferSize = max(bufferSize, 1)
+		e.policy = policy
+	}
+}
+
+// SinkName names the sink in drop reports (default: its Go type)
+func SinkName(name string) SinkOption {
+	return func(e *sinkEntry) {
+		e.name = name
+	}
+}
+
+// asyncSink buffers events for a sink and writes them from a worker goroutine
+type asyncSink struct {
+	producer *Producer
+	sink     Sink
+	name     string
+	policy   BackpressurePolicy
+	size     int
+
+	mu         sync.Mutex
+	notEmpty   *sync.Cond
+	notFull    *sync.Cond
+	queue      []Event
+	dropped    int64 // Since the last report
+	lastReport time.Time
+	reporting  bool          // A drop report is being emitted
+	closing    bool          // Set by close: no new events, the worker drains the buffer
+	stopped    bool          // Set once close gives up: the worker abandons the buffer
+	flushed    int64         // Events written since closing started
+	done       chan struct{} // Closed when the worker exits
+}
+
+// newAsyncSink starts a worker writing to sink
+func newAsyncSink(p *Producer, sink Sink, name string, size int, policy BackpressurePolicy) *asyncSink {
+	s := &asyncSink{
+		producer: p,
+		sink:     sink,
+		name:     name,
+		policy:   policy,
+		size:     size,
+		queue:    make([]Event, 0, size),
+		done:     make(chan struct{}),
+	}
+	s.notEmpty = sync.NewCond(&s.mu)
+	s.notFull = sync.NewCond(&s.mu)
+	go s.run()
+	return s
+}
+
+// WriteEvent buffers event according to the backpressure policy
+func (s *asyncSink) WriteEvent(event Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closing {
+		s.dropped++
+		return ErrProducerClosed
+	}
+	if s.policy == BackpressureSample && len(s.queue) >= s.size/2 &&
+		EventLevel(event) < slog.LevelWarn && samplingFraction(event.GetCorrelationID()) >= backpressureSampleRate {
+		s.dropped++
+		return nil
+	}
+
+	for len(s.queue) >= s.size {
+		switch s.policy {
+		case BackpressureBlock:
+			s.notFull.Wait()
+			if s.closing {
+				s.dropped++
+				return ErrProducerClosed
+			}
+			continue
+		case BackpressureDropOldest:
+			s.queue = append(s.queue[:0], s.queue[1:]...)
+			s.dropped++
+		default:
+			s.dropped++
+			return nil
+		}
+	}
+
+	s.queue = append(s.queue, event)
+	s.notEmpty.Signal()
+	return nil
+}
+
+// run writes buffered events until the sink is closed
+func (s *asyncSink) run() {
+	defer close(s.done)
+	for {
+		s.mu.Lock()
+		for len(s.queue) == 0 && !s.closing {
+			s.notEmpty.Wait()
+		}
+		if s.stopped || len(s.queue) == 0 {
+			s.mu.Unlock()
+			return
+		}
+		event := s.queue[0]
+		s.queue = append(s.queue[:0], s.queue[1:]...)
+		s.notFull.Signal()
+
+		dropped := s.dropped
+		// Once closing, drops are left for close to report; while a report is emitted,
+		// drops are counted for the next one
+		report := !s.closing && !s.reporting && dropped > 0 && (len(s.queue) == 0 || time.Since(s.lastReport) >= dropReportInterval)
+		if report {
+			s.dropped = 0
+			s.lastReport = time.Now()
+			s.reporting = true
+		}
+		s.mu.Unlock()
+
+		err := s.sink.WriteEvent(event)
+		if err != nil {
+			s.producer.logger.Warn("lifecycle: sink write failed", "sink", s.name, "error", err)
+		}
+		if report {
+			// Reported from another goroutine, as the report may wait on other sinks'
+			// buffers, whose workers may in turn be reporting drops to this one
+			go s.report(dropped)
+		}
+
+		s.mu.Lock()
+		if s.closing && err == nil {
+			s.flushed++
+		}
+		s.mu.Unlock()
+	}
+}
+
+// close stops accepting events and waits until the buffer is written or ctx is done
+// It returns how many buffered events were written while closing and how many were
+// dropped, including any abandoned in the buffer
+func (s *asyncSink) close(ctx context.Context) (flushed, dropped int64) {
+	s.mu.Lock()
+	s.closing = true
+	s.notEmpty.Broadcast()
+	s.notFull.Broadcast()
+	s.mu.Unlock()
+
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopped = true
+	flushed = s.flushed
+	dropped = s.dropped + int64(len(s.queue))
+	s.queue = nil
+	s.dropped = 0
+	return flushed, dropped
+}
+
+// report emits a drop report for this sink
+func (s *asyncSink) report(dropped int64) {
+	s.producer.emitEventsDropped(s, dropped)
+
+	s.mu.Lock()
+	s.reporting = false
+	s.mu.Unlock()
+}
+
+// exited reports whether the worker has stopped writing to the sink
+func (s *asyncSink) exited() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// emitEventsDropped records and emits a drop report for a sink
+func (p *Producer) emitEventsDropped(sink *asyncSink, dropped int64) {
+	ctx := context.Background()
+	if p.otel != nil {
+		p.otel.RecordDropped(ctx, sink.name, string(sink.policy), dropped)
+	}
+	event := &EventsDroppedEvent{
+		Base:       p.createBaseEvent("lifecycle.events.dropped", "", nil),
+		Sink:       sink.name,
+		Policy:     sink.policy,
+		Dropped:    dropped,
+		BufferSize: sink.size,
+		source:     sink,
+	}
+	_ = p.emitEvent(ctx, event, 0)
+}
+
+// defaultSinkName names a sink by its Go type
+func defaultSinkName(sink Sink) string {
+	return fmt.Sprintf("%T", sink)
+}
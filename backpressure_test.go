@@ -0,0 +1,68 @@
+package lifecycle
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// typesSink records the types of the events written to it, after release is closed
+type typesSink struct {
+	release chan struct{}
+	mu      sync.Mutex
+	types   []string
+}
+
+func (s *typesSink) WriteEvent(event Event) error {
+	if s.release != nil {
+		<-s.release
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.types = append(s.types, event.GetEventType())
+	return nil
+}
+
+func (s *typesSink) count(eventType string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, t := range s.types {
+		if t == eventType {
+			n++
+		}
+	}
+	return n
+}
+
+// TestDropReportOtherSinks checks that a drop report is written to the other sinks but
+// not to the sink that dropped the events
+func TestDropReportOtherSinks(t *testing.T) {
+	slow := &typesSink{release: make(chan struct{})}
+	other := &typesSink{}
+	p := NewProducer("drops", "localhost",
+		WithSink(slow, SinkAsync(1, BackpressureDropNewest)),
+		WithSink(other))
+
+	for i := 0; i < 10; i++ {
+		if err := p.EmitServiceStarted(context.Background(), "1.0.0", int32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(slow.release)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for other.count("lifecycle.events.dropped") == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("no lifecycle.events.dropped on the other sink")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := slow.count("lifecycle.events.dropped"); n != 0 {
+		t.Errorf("the dropping sink got %d drop reports, want 0", n)
+	}
+}
@@ -0,0 +1,317 @@
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Batch defaults
+const (
+	DefaultBatchSize     = 100
+	DefaultFlushInterval = time.Second
+	DefaultFlushTimeout  = 10 * time.Second
+)
+
+// BatchWriter sends a batch of events to a remote system
+// ctx carries the batch's lifecycle.sink.flush span and the flush timeout; writers pass
+// the span on with InjectTraceContext so downstream processors continue the trace
+type BatchWriter interface {
+	WriteBatch(ctx context.Context, events []Event) error
+}
+
+// BatchSink collects events and hands them to a BatchWriter in batches, when a batch is
+// full, every flush interval, and on Flush and Close
+// Each flush runs in a lifecycle.sink.flush span (a root span, since the events come from
+// many requests), so the pipeline itself can be traced
+// A full batch is written on the emitting goroutine; combine it with SinkAsync to keep
+// slow writers off the request path
+type BatchSink struct {
+	writer   BatchWriter
+	name     string
+	size     int
+	interval time.Duration
+	timeout  time.Duration
+
+	mu    sync.Mutex
+	batch []Event
+
+	flushMu sync.Mutex // Keeps batches in order
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// BatchOption configures a BatchSink
+type BatchOption func(*BatchSink)
+
+// WithBatchSize sets how many events are written together (default: DefaultBatchSize)
+func WithBatchSize(size int) BatchOption {
+	return func(s *BatchSink) {
+		s.size = max(size, 1)
+	}
+}
+
+// WithFlushInterval sets how often a partial batch is written (default:
+// DefaultFlushInterval); 0 disables periodic flushing
+func WithFlushInterval(interval time.Duration) BatchOption {
+	return func(s *BatchSink) {
+		s.interval = interval
+	}
+}
+
+// WithFlushTimeout bounds one WriteBatch call (default: DefaultFlushTimeout)
+func WithFlushTimeout(timeout time.Duration) BatchOption {
+	return func(s *BatchSink) {
+		s.timeout = timeout
+	}
+}
+
+// WithBatchName names the sink in flush spans (default: the writer's Go type)
+func WithBatchName(name string) BatchOption {
+	return func(s *BatchSink) {
+		s.name = name
+	}
+}
+
+// NewBatchSink creates a sink that writes batches of events with writer
+// Close it (or the producer) to write the last batch and stop periodic flushing
+func NewBatchSink(writer BatchWriter, opts ...BatchOption) *BatchSink {
+	s := &BatchSink{
+		writer:   writer,
+		name:     fmt.Sprintf("%T", writer),
+		size:     DefaultBatchSize,
+		interval: DefaultFlushInterval,
+		timeout:  DefaultFlushTimeout,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.interval > 0 {
+		go s.run()
+	} else {
+		close(s.done)
+	}
+	return s
+}
+
+// WriteEvent adds event to the current batch, writing the batch once it is full
+func (s *BatchSink) WriteEvent(event Event) error {
+	s.mu.Lock()
+	s.batch = append(s.batch, event)
+	full := len(s.batch) >= s.size
+	s.mu.Unlock()
+
+	if full {
+		return s.Flush()
+	}
+	return nil
+}
+
+// Flush writes the current batch, if any
+func (s *BatchSink) Flush() error {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	s.mu.Lock()
+	batch := s.batch
+	s.batch = nil
+	s.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+	return s.write(batch)
+}
+
+// Close stops periodic flushing, writes the last batch, and closes the writer if it is
+// an io.Closer
+func (s *BatchSink) Close() error {
+	s.closeOnce.Do(func() { close(s.stop) })
+	<-s.done
+
+	errs := []error{s.Flush()}
+	if c, ok := s.writer.(io.Closer); ok {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// run flushes every interval until Close
+func (s *BatchSink) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			_ = s.Flush()
+		}
+	}
+}
+
+// write hands batch to the writer inside a flush span
+func (s *BatchSink) write(batch []Event) error {
+	ctx, span := otel.Tracer("lifecycle").Start(context.Background(), "lifecycle.sink.flush",
+		trace.WithNewRoot(),
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("lifecycle.sink.name", s.name),
+			semconv.MessagingBatchMessageCount(len(batch)),
+		))
+	defer span.End()
+
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	if err := s.writer.WriteBatch(ctx, batch); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to write batch of %d events to %s: %w", len(batch), s.name, err)
+	}
+	return nil
+}
+
+// InjectTraceContext writes the span context in ctx to carrier as W3C traceparent and
+// tracestate, regardless of the globally configured propagator, so the events' consumers
+// can continue the pipeline's trace
+func InjectTraceContext(ctx context.Context, carrier propagation.TextMapCarrier) {
+	propagation.TraceContext{}.Inject(ctx, carrier)
+}
+
+// HTTPWriter posts batches of events as NDJSON
+type HTTPWriter struct {
+	URL     string
+	Client  *http.Client // Default: http.DefaultClient
+	Header  http.Header  // Added to every request (e.g., Authorization)
+	Encoder Encoder      // Default: NewJSONEncoder()
+}
+
+// NewHTTPSink creates a batch sink posting events to url as NDJSON, with the flush span
+// in the traceparent header
+func NewHTTPSink(url string, opts ...BatchOption) *BatchSink {
+	return NewBatchSink(&HTTPWriter{URL: url}, append([]BatchOption{WithBatchName("http")}, opts...)...)
+}
+
+// WriteBatch posts events in one request; any status other than 2xx is an error
+func (w *HTTPWriter) WriteBatch(ctx context.Context, events []Event) error {
+	encoder := w.Encoder
+	if encoder == nil {
+		encoder = NewJSONEncoder()
+	}
+	var body bytes.Buffer
+	for _, event := range events {
+		data, err := encoder.Encode(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event: %w", err)
+		}
+		body.Write(data)
+		body.WriteByte('\n')
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, &body)
+	if err != nil {
+		return err
+	}
+	for key, values := range w.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	InjectTraceContext(ctx, propagation.HeaderCarrier(req.Header))
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// doWithRetry sends the request built by newRequest, retrying with policy's backoff while
+// the server answers 429 Too Many Requests or 503 Service Unavailable, and returns the final
+// response's status and body
+// A Retry-After header in seconds replaces the backoff delay, capped at policy.MaxDelay
+func doWithRetry(ctx context.Context, client *http.Client, policy RetryPolicy,
+	newRequest func() (*http.Request, error)) (int, []byte, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	policy = policy.withDefaults()
+
+	for attempt := 1; ; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return 0, nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return 0, nil, err
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return 0, nil, err
+		}
+		if !throttled(resp.StatusCode) || attempt >= policy.MaxAttempts {
+			return resp.StatusCode, body, nil
+		}
+		if err := sleepContext(ctx, retryDelay(policy, attempt, resp.Header)); err != nil {
+			return 0, nil, err
+		}
+	}
+}
+
+// throttled reports whether an HTTP status asks the client to back off and retry
+func throttled(status int) bool {
+	return status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable
+}
+
+// retryDelay returns the wait before retry number attempt, honoring a Retry-After header
+func retryDelay(policy RetryPolicy, attempt int, header http.Header) time.Duration {
+	if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil && seconds >= 0 {
+		return min(time.Duration(seconds)*time.Second, policy.MaxDelay)
+	}
+	return policy.delay(attempt)
+}
+
+// sleepContext waits for d, returning early with ctx's error once ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,366 @@
+package lifecycle
+
+import (
+	"context"
+	"time"
+)
+
+// Builders are an alternative to the positional Emit methods for events with many
+// parameters, where it is easy to swap arguments of the same type:
+//
+//	err := producer.NewRequestHandled().
+//		Correlation(id).
+//		Actor(actor).
+//		Status(200).
+//		Duration(elapsed).
+//		Emit(ctx)
+//
+// Build returns the event without emitting it. Builders are not safe for concurrent use
+
+// builderBase holds the fields every builder sets on the base event
+type builderBase struct {
+	producer      *Producer
+	correlationID string
+	api           string
+	metadata      map[string]interface{}
+	timestamp     time.Time      // Default: when the base event is created
+	retention     RetentionClass // Default: from WithRetention
+	err           error          // First invalid entry, returned by emit
+}
+
+// createBase creates the base event, inferring the API from resource when none was set
+func (b *builderBase) createBase(eventType string, resource *Resource) *BaseEvent {
+	api := b.api
+	if api == "" && resource != nil {
+		api = resource.Type
+	}
+	base := b.producer.createBaseEvent(eventType, b.correlationID, b.metadata, api)
+	if !b.timestamp.IsZero() {
+		base.Timestamp = b.timestamp
+	}
+	if b.retention != "" {
+		base.Retention = b.retention
+	}
+	return base
+}
+
+// setMetadata adds a metadata entry to the builder, recording an error for reserved keys
+func (b *builderBase) setMetadata(key string, value interface{}) {
+	if err := ValidateMetadataKey(key); err != nil {
+		if b.err == nil {
+			b.err = err
+		}
+		return
+	}
+	if b.metadata == nil {
+		b.metadata = make(map[string]interface{})
+	}
+	b.metadata[key] = value
+}
+
+// emit emits a built event, taking the correlation ID from ctx when none was set
+func (b *builderBase) emit(ctx context.Context, base *BaseEvent, event Event, duration time.Duration) error {
+	if b.err != nil {
+		return b.err
+	}
+	if base.CorrelationID == "" {
+		base.CorrelationID = extractCorrelationID(ctx)
+	}
+	return b.producer.emitEvent(ctx, event, duration)
+}
+
+// RequestHandledBuilder builds an api.request.handled event
+type RequestHandledBuilder struct {
+	builderBase
+	event RequestHandledEvent
+}
+
+// NewRequestHandled starts building an api.request.handled event
+func (p *Producer) NewRequestHandled() *RequestHandledBuilder {
+	return &RequestHandledBuilder{
+		builderBase: builderBase{producer: p},
+		event:       RequestHandledEvent{Status: StatusSuccess},
+	}
+}
+
+// Correlation sets the correlation ID (default: from the context passed to Emit)
+func (b *RequestHandledBuilder) Correlation(id string) *RequestHandledBuilder {
+	b.correlationID = id
+	return b
+}
+
+// API sets the API identifier (default: the resource type, then the producer's API)
+func (b *RequestHandledBuilder) API(api string) *RequestHandledBuilder {
+	b.api = api
+	return b
+}
+
+// Metadata adds a metadata entry
+func (b *RequestHandledBuilder) Metadata(key string, value interface{}) *RequestHandledBuilder {
+	b.setMetadata(key, value)
+	return b
+}
+
+// Actor sets who made the request
+func (b *RequestHandledBuilder) Actor(actor *Actor) *RequestHandledBuilder {
+	b.event.Actor = actor
+	return b
+}
+
+// Resource sets the resource the request acted on
+func (b *RequestHandledBuilder) Resource(resource *Resource) *RequestHandledBuilder {
+	b.event.Resource = resource
+	return b
+}
+
+// Status sets the HTTP status code
+func (b *RequestHandledBuilder) Status(statusCode int32) *RequestHandledBuilder {
+	b.event.StatusCode = statusCode
+	return b
+}
+
+// Duration sets how long the request took
+func (b *RequestHandledBuilder) Duration(d time.Duration) *RequestHandledBuilder {
+	b.event.DurationMs = d.Milliseconds()
+	return b
+}
+
+// ResponseSize sets the response body size in bytes
+func (b *RequestHandledBuilder) ResponseSize(bytes int64) *RequestHandledBuilder {
+	b.event.ResponseSizeBytes = bytes
+	return b
+}
+
+// Build returns the event without emitting it
+func (b *RequestHandledBuilder) Build() *RequestHandledEvent {
+	return b.build(b.createBase("api.request.handled", b.event.Resource))
+}
+
+// Emit builds and emits the event
+func (b *RequestHandledBuilder) Emit(ctx context.Context) error {
+	base := b.createBase("api.request.handled", b.event.Resource)
+	return b.emit(ctx, base, b.build(base), time.Duration(b.event.DurationMs)*time.Millisecond)
+}
+
+// build returns the event with the given base
+func (b *RequestHandledBuilder) build(base *BaseEvent) *RequestHandledEvent {
+	event := b.event
+	event.Base = base
+	return &event
+}
+
+// RequestErroredBuilder builds an api.request.errored event
+type RequestErroredBuilder struct {
+	builderBase
+	event RequestErroredEvent
+}
+
+// NewRequestErrored starts building an api.request.errored event
+func (p *Producer) NewRequestErrored() *RequestErroredBuilder {
+	return &RequestErroredBuilder{
+		builderBase: builderBase{producer: p},
+		event:       RequestErroredEvent{Status: StatusError},
+	}
+}
+
+// Correlation sets the correlation ID (default: from the context passed to Emit)
+func (b *RequestErroredBuilder) Correlation(id string) *RequestErroredBuilder {
+	b.correlationID = id
+	return b
+}
+
+// API sets the API identifier (default: the producer's API)
+func (b *RequestErroredBuilder) API(api string) *RequestErroredBuilder {
+	b.api = api
+	return b
+}
+
+// Metadata adds a metadata entry
+func (b *RequestErroredBuilder) Metadata(key string, value interface{}) *RequestErroredBuilder {
+	b.setMetadata(key, value)
+	return b
+}
+
+// Error sets the error message from err
+func (b *RequestErroredBuilder) Error(err error) *RequestErroredBuilder {
+	if err != nil {
+		b.event.ErrorMessage = err.Error()
+	}
+	return b
+}
+
+// Message sets the error message
+func (b *RequestErroredBuilder) Message(message string) *RequestErroredBuilder {
+	b.event.ErrorMessage = message
+	return b
+}
+
+// Code sets the application error code
+func (b *RequestErroredBuilder) Code(code string) *RequestErroredBuilder {
+	b.event.ErrorCode = code
+	return b
+}
+
+// Status sets the HTTP status code
+func (b *RequestErroredBuilder) Status(statusCode int32) *RequestErroredBuilder {
+	b.event.StatusCode = statusCode
+	return b
+}
+
+// Duration sets how long the request took
+func (b *RequestErroredBuilder) Duration(d time.Duration) *RequestErroredBuilder {
+	b.event.DurationMs = d.Milliseconds()
+	return b
+}
+
+// Build returns the event without emitting it
+func (b *RequestErroredBuilder) Build() *RequestErroredEvent {
+	return b.build(b.createBase("api.request.errored", nil))
+}
+
+// Emit builds and emits the event
+func (b *RequestErroredBuilder) Emit(ctx context.Context) error {
+	base := b.createBase("api.request.errored", nil)
+	return b.emit(ctx, base, b.build(base), time.Duration(b.event.DurationMs)*time.Millisecond)
+}
+
+// build returns the event with the given base
+func (b *RequestErroredBuilder) build(base *BaseEvent) *RequestErroredEvent {
+	event := b.event
+	event.Base = base
+	return &event
+}
+
+// ResourceBuilder builds a resource.created, resource.updated, or resource.deleted event
+// Data is redacted using the schema annotations, as with the Emit methods
+type ResourceBuilder struct {
+	builderBase
+	eventType     string
+	actor         *Actor
+	resource      *Resource
+	data          map[string]interface{} // Created: resource data; updated: new data; deleted: final data
+	previousData  map[string]interface{}
+	updatedFields []string
+	softDelete    bool
+	annotations   map[string]FieldAnnotations
+}
+
+// NewResourceCreated starts building a resource.created event
+func (p *Producer) NewResourceCreated() *ResourceBuilder {
+	return &ResourceBuilder{builderBase: builderBase{producer: p}, eventType: "resource.created"}
+}
+
+// NewResourceUpdated starts building a resource.updated event
+func (p *Producer) NewResourceUpdated() *ResourceBuilder {
+	return &ResourceBuilder{builderBase: builderBase{producer: p}, eventType: "resource.updated"}
+}
+
+// NewResourceDeleted starts building a resource.deleted event
+func (p *Producer) NewResourceDeleted() *ResourceBuilder {
+	return &ResourceBuilder{builderBase: builderBase{producer: p}, eventType: "resource.deleted"}
+}
+
+// Correlation sets the correlation ID (default: from the context passed to Emit)
+func (b *ResourceBuilder) Correlation(id string) *ResourceBuilder {
+	b.correlationID = id
+	return b
+}
+
+// API sets the API identifier (default: the resource type, then the producer's API)
+func (b *ResourceBuilder) API(api string) *ResourceBuilder {
+	b.api = api
+	return b
+}
+
+// Metadata adds a metadata entry
+func (b *ResourceBuilder) Metadata(key string, value interface{}) *ResourceBuilder {
+	b.setMetadata(key, value)
+	return b
+}
+
+// Actor sets who acted on the resource
+func (b *ResourceBuilder) Actor(actor *Actor) *ResourceBuilder {
+	b.actor = actor
+	return b
+}
+
+// Resource sets the resource type and ID
+func (b *ResourceBuilder) Resource(resourceType, id string) *ResourceBuilder {
+	b.resource = &Resource{Type: resourceType, ID: id}
+	return b
+}
+
+// Data sets the resource's data: its initial state when created, its new state when
+// updated, or its final state when deleted
+func (b *ResourceBuilder) Data(data map[string]interface{}) *ResourceBuilder {
+	b.data = data
+	return b
+}
+
+// Previous sets the resource's state before an update
+func (b *ResourceBuilder) Previous(data map[string]interface{}) *ResourceBuilder {
+	b.previousData = data
+	return b
+}
+
+// UpdatedFields sets the names of the fields an update changed
+func (b *ResourceBuilder) UpdatedFields(fields ...string) *ResourceBuilder {
+	b.updatedFields = fields
+	return b
+}
+
+// SoftDelete marks a deletion as soft
+func (b *ResourceBuilder) SoftDelete() *ResourceBuilder {
+	b.softDelete = true
+	return b
+}
+
+// Annotations sets the schema annotations used to redact the data
+func (b *ResourceBuilder) Annotations(annotations map[string]FieldAnnotations) *ResourceBuilder {
+	b.annotations = annotations
+	return b
+}
+
+// Build returns the event without emitting it: a *ResourceCreatedEvent,
+// *ResourceUpdatedEvent, or *ResourceDeletedEvent
+func (b *ResourceBuilder) Build() Event {
+	return b.build(b.createBase(b.eventType, b.resource))
+}
+
+// Emit builds and emits the event
+func (b *ResourceBuilder) Emit(ctx context.Context) error {
+	base := b.createBase(b.eventType, b.resource)
+	return b.emit(ctx, base, b.build(base), 0)
+}
+
+// build returns the event with the given base
+func (b *ResourceBuilder) build(base *BaseEvent) Event {
+	data := b.producer.redactData(b.eventType, b.data, b.annotations)
+
+	switch b.eventType {
+	case "resource.updated":
+		return &ResourceUpdatedEvent{
+			Base:          base,
+			Actor:         b.actor,
+			Resource:      b.resource,
+			PreviousData:  b.producer.redactData(b.eventType, b.previousData, b.annotations),
+			NewData:       data,
+			UpdatedFields: b.updatedFields,
+		}
+	case "resource.deleted":
+		return &ResourceDeletedEvent{
+			Base:       base,
+			Actor:      b.actor,
+			Resource:   b.resource,
+			SoftDelete: b.softDelete,
+			FinalData:  data,
+		}
+	default:
+		return &ResourceCreatedEvent{
+			Base:         base,
+			Actor:        b.actor,
+			Resource:     b.resource,
+			ResourceData: data,
+		}
+	}
+}
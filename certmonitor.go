@@ -0,0 +1,288 @@
+package lifecycle
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"time"
+)
+
+// DefaultCertExpiryThreshold is how far ahead of expiry certificates are reported
+const DefaultCertExpiryThreshold = 30 * 24 * time.Hour
+
+// TLSCertExpiringEvent represents a tls.cert.expiring event
+type TLSCertExpiringEvent struct {
+	Base          *BaseEvent `json:"base"`
+	Source        string     `json:"source"` // Endpoint (host:port) or certificate file
+	Subject       string     `json:"subject"`
+	Issuer        string     `json:"issuer,omitempty"`
+	SerialNumber  string     `json:"serial_number,omitempty"`
+	NotAfter      time.Time  `json:"not_after"`
+	DaysRemaining int        `json:"days_remaining"` // Negative once expired
+	Status        Status     `json:"status"`         // Error once expired, otherwise warning
+}
+
+func (e *TLSCertExpiringEvent) GetEventType() string     { return e.Base.GetEventType() }
+func (e *TLSCertExpiringEvent) GetTimestamp() time.Time  { return e.Base.GetTimestamp() }
+func (e *TLSCertExpiringEvent) GetService() string       { return e.Base.GetService() }
+func (e *TLSCertExpiringEvent) GetAPI() string           { return e.Base.GetAPI() }
+func (e *TLSCertExpiringEvent) GetHost() string          { return e.Base.GetHost() }
+func (e *TLSCertExpiringEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *TLSCertExpiringEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *TLSCertExpiringEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *TLSCertExpiringEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
+
+// DNSResolutionFailedEvent represents a dns.resolution.failed event
+type DNSResolutionFailedEvent struct {
+	Base         *BaseEvent `json:"base"`
+	Hostname     string     `json:"hostname"`
+	Status       Status     `json:"status"`
+	ErrorMessage string     `json:"error_message"`
+	DurationMs   int64      `json:"duration_ms"`
+}
+
+func (e *DNSResolutionFailedEvent) GetEventType() string     { return e.Base.GetEventType() }
+func (e *DNSResolutionFailedEvent) GetTimestamp() time.Time  { return e.Base.GetTimestamp() }
+func (e *DNSResolutionFailedEvent) GetService() string       { return e.Base.GetService() }
+func (e *DNSResolutionFailedEvent) GetAPI() string           { return e.Base.GetAPI() }
+func (e *DNSResolutionFailedEvent) GetHost() string          { return e.Base.GetHost() }
+func (e *DNSResolutionFailedEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *DNSResolutionFailedEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *DNSResolutionFailedEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *DNSResolutionFailedEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
+
+// CertMonitor periodically checks TLS endpoints and certificate files, emitting
+// tls.cert.expiring for certificates within the expiry threshold and
+// dns.resolution.failed for hostnames that do not resolve, so certificate rotation alerts
+// flow through the same pipeline as other events
+type CertMonitor struct {
+	producer  *Producer
+	endpoints []string
+	files     []string
+	hostnames []string
+	threshold time.Duration
+	timeout   time.Duration
+	resolver  *net.Resolver
+}
+
+// CertMonitorOption configures a CertMonitor
+type CertMonitorOption func(*CertMonitor)
+
+// WithTLSEndpoints adds endpoints (host:port) whose served certificate chains are checked
+// Their hostnames are also checked for DNS resolution
+func WithTLSEndpoints(addrs ...string) CertMonitorOption {
+	return func(m *CertMonitor) {
+		m.endpoints = append(m.endpoints, addrs...)
+	}
+}
+
+// WithCertFiles adds PEM certificate files to check, such as mounted Kubernetes secrets
+func WithCertFiles(paths ...string) CertMonitorOption {
+	return func(m *CertMonitor) {
+		m.files = append(m.files, paths...)
+	}
+}
+
+// WithDNSNames adds hostnames that are only checked for DNS resolution
+func WithDNSNames(hostnames ...string) CertMonitorOption {
+	return func(m *CertMonitor) {
+		m.hostnames = append(m.hostnames, hostnames...)
+	}
+}
+
+// WithExpiryThreshold sets how far ahead of expiry certificates are reported
+// (default: DefaultCertExpiryThreshold)
+func WithExpiryThreshold(threshold time.Duration) CertMonitorOption {
+	return func(m *CertMonitor) {
+		m.threshold = threshold
+	}
+}
+
+// WithResolver sets the resolver used for DNS checks (default: net.DefaultResolver)
+func WithResolver(resolver *net.Resolver) CertMonitorOption {
+	return func(m *CertMonitor) {
+		m.resolver = resolver
+	}
+}
+
+// NewCertMonitor creates a certificate and DNS monitor that emits through producer
+func NewCertMonitor(producer *Producer, opts ...CertMonitorOption) *CertMonitor {
+	m := &CertMonitor{
+		producer:  producer,
+		threshold: DefaultCertExpiryThreshold,
+		timeout:   DefaultProbeTimeout,
+		resolver:  net.DefaultResolver,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Check runs every configured check once
+// It returns the errors that prevented a check from completing; expiring certificates
+// are reported as events, not errors
+func (m *CertMonitor) Check(ctx context.Context) error {
+	var errs []error
+
+	for _, hostname := range m.hostnames {
+		if err := m.checkDNS(ctx, hostname); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, addr := range m.endpoints {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid endpoint %q: %w", addr, err))
+			continue
+		}
+		if net.ParseIP(host) == nil {
+			if err := m.checkDNS(ctx, host); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+		if err := m.checkEndpoint(ctx, addr, host); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, path := range m.files {
+		if err := m.checkFile(ctx, path); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Run checks every interval until ctx is done
+// It is typically started in its own goroutine
+func (m *CertMonitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		_ = m.Check(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkDNS resolves hostname and emits dns.resolution.failed if it does not resolve
+func (m *CertMonitor) checkDNS(ctx context.Context, hostname string) error {
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	start := time.Now()
+	_, err := m.resolver.LookupHost(ctx, hostname)
+	if err == nil {
+		return nil
+	}
+
+	event := &DNSResolutionFailedEvent{
+		Base:         m.producer.createBaseEvent("dns.resolution.failed", "", nil),
+		Hostname:     hostname,
+		Status:       StatusError,
+		ErrorMessage: err.Error(),
+		DurationMs:   time.Since(start).Milliseconds(),
+	}
+	_ = m.producer.emitEvent(ctx, event, time.Since(start))
+	return fmt.Errorf("failed to resolve %s: %w", hostname, err)
+}
+
+// checkEndpoint connects to addr and checks the certificate chain it serves
+func (m *CertMonitor) checkEndpoint(ctx context.Context, addr, serverName string) error {
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	dialer := &tls.Dialer{Config: &tls.Config{
+		ServerName: serverName,
+		// The chain is only inspected, never trusted, and must be readable even once
+		// it has expired
+		InsecureSkipVerify: true,
+	}}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	defer conn.Close()
+
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	return m.checkCerts(ctx, addr, certs)
+}
+
+// checkFile reads the PEM certificates in path and checks them
+func (m *CertMonitor) checkFile(ctx context.Context, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read certificate file: %w", err)
+	}
+
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("failed to parse certificate in %s: %w", path, err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return fmt.Errorf("no certificates found in %s", path)
+	}
+	return m.checkCerts(ctx, path, certs)
+}
+
+// checkCerts emits tls.cert.expiring for each certificate within the threshold
+func (m *CertMonitor) checkCerts(ctx context.Context, source string, certs []*x509.Certificate) error {
+	now := time.Now()
+	for _, cert := range certs {
+		remaining := cert.NotAfter.Sub(now)
+		if remaining > m.threshold {
+			continue
+		}
+
+		event := &TLSCertExpiringEvent{
+			Base:          m.producer.createBaseEvent("tls.cert.expiring", "", nil),
+			Source:        source,
+			Subject:       cert.Subject.String(),
+			Issuer:        cert.Issuer.String(),
+			SerialNumber:  cert.SerialNumber.String(),
+			NotAfter:      cert.NotAfter,
+			DaysRemaining: int(remaining.Hours() / 24),
+			Status:        StatusWarning,
+		}
+		if remaining <= 0 {
+			event.Status = StatusError
+		}
+		if err := m.producer.emitEvent(ctx, event, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
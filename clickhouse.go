@@ -0,0 +1,178 @@
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// DefaultClickHouseTable is the table ClickHouseWriter inserts into by default
+const DefaultClickHouseTable = "lifecycle_events"
+
+// ClickHouseWriter inserts batches of events into a ClickHouse table over the HTTP
+// interface, as typed columns: the base fields, duration_ms, status_code, metadata and the
+// event-specific fields as JSON strings (see ClickHouseTableDDL)
+// Each batch is sent column by column in the JSONColumns format (ClickHouse 22.7 or later),
+// so a column's values are written together. Requests answered with 429 or 503 are
+// retried with backoff
+type ClickHouseWriter struct {
+	URL      string       // HTTP interface URL (e.g., "http://clickhouse.internal:8123")
+	Client   *http.Client // Default: http.DefaultClient
+	Header   http.Header  // Added to every request (e.g., X-ClickHouse-User and X-ClickHouse-Key)
+	Database string       // Default: the user's default database
+	Table    string       // Default: DefaultClickHouseTable
+	Retry    RetryPolicy  // Backoff for throttled requests (zero: DefaultRetryPolicy)
+}
+
+// NewClickHouseSink creates a batch sink inserting events into table through the HTTP
+// interface at url; create the table with ClickHouseWriter.CreateTable or ClickHouseTableDDL
+// High-volume telemetry inserts best in large batches, e.g., WithBatchSize(10000)
+func NewClickHouseSink(url, table string, opts ...BatchOption) *BatchSink {
+	return NewBatchSink(&ClickHouseWriter{URL: url, Table: table}, append([]BatchOption{WithBatchName("clickhouse")}, opts...)...)
+}
+
+// clickHouseColumns is a batch of events split into columns, in table order
+type clickHouseColumns struct {
+	EventID       []string `json:"event_id"`
+	EventType     []string `json:"event_type"`
+	Service       []string `json:"service"`
+	API           []string `json:"api"`
+	Host          []string `json:"host"`
+	CorrelationID []string `json:"correlation_id"`
+	Level         []string `json:"level"`
+	Timestamp     []string `json:"ts"`
+	DurationMs    []int64  `json:"duration_ms"`
+	StatusCode    []int64  `json:"status_code"`
+	Metadata      []string `json:"metadata"` // JSON object
+	Fields        []string `json:"fields"`   // JSON object of the event-specific fields
+}
+
+// clickHouseTimeFormat is how DateTime64(3) values are written
+const clickHouseTimeFormat = "2006-01-02 15:04:05.000"
+
+// WriteBatch inserts events in one request
+func (w *ClickHouseWriter) WriteBatch(ctx context.Context, events []Event) error {
+	var columns clickHouseColumns
+	for _, event := range events {
+		fields, metadata, err := splitEventJSON(event)
+		if err != nil {
+			return err
+		}
+		var eventID string
+		if base := eventBase(event); base != nil {
+			eventID = base.EventID
+		}
+		columns.EventID = append(columns.EventID, eventID)
+		columns.EventType = append(columns.EventType, event.GetEventType())
+		columns.Service = append(columns.Service, event.GetService())
+		columns.API = append(columns.API, event.GetAPI())
+		columns.Host = append(columns.Host, event.GetHost())
+		columns.CorrelationID = append(columns.CorrelationID, event.GetCorrelationID())
+		columns.Level = append(columns.Level, EventLevel(event).String())
+		columns.Timestamp = append(columns.Timestamp, event.GetTimestamp().UTC().Format(clickHouseTimeFormat))
+		columns.DurationMs = append(columns.DurationMs, eventIntField(event, "DurationMs"))
+		columns.StatusCode = append(columns.StatusCode, eventIntField(event, "StatusCode"))
+		columns.Metadata = append(columns.Metadata, metadata)
+		columns.Fields = append(columns.Fields, fields)
+	}
+	body, err := json.Marshal(columns)
+	if err != nil {
+		return fmt.Errorf("failed to marshal batch: %w", err)
+	}
+	return w.exec(ctx, "INSERT INTO "+w.table()+" FORMAT JSONColumns", body)
+}
+
+// splitEventJSON returns an event's specific fields and its metadata as JSON objects
+func splitEventJSON(event Event) (fields, metadata string, err error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to marshal event: %w", err)
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return "", "", fmt.Errorf("failed to marshal event: %w", err)
+	}
+	delete(object, "base")
+
+	encoded, err := json.Marshal(object)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to marshal event: %w", err)
+	}
+	metadata = "{}"
+	if md := event.GetMetadata(); len(md) > 0 {
+		data, err := json.Marshal(md)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to marshal metadata: %w", err)
+		}
+		metadata = string(data)
+	}
+	return string(encoded), metadata, nil
+}
+
+// CreateTable creates the writer's table, if it does not exist, with ClickHouseTableDDL
+func (w *ClickHouseWriter) CreateTable(ctx context.Context) error {
+	return w.exec(ctx, ClickHouseTableDDL(w.table()), nil)
+}
+
+// ClickHouseTableDDL returns the CREATE TABLE statement for a table ClickHouseWriter
+// inserts into: a MergeTree partitioned by month and ordered by service, event type, and
+// time, with low-cardinality strings for the repetitive columns
+func ClickHouseTableDDL(table string) string {
+	return `CREATE TABLE IF NOT EXISTS ` + table + ` (
+    event_id String,
+    event_type LowCardinality(String),
+    service LowCardinality(String),
+    api LowCardinality(String),
+    host LowCardinality(String),
+    correlation_id String,
+    level LowCardinality(String),
+    ts DateTime64(3, 'UTC'),
+    duration_ms Int64,
+    status_code UInt16,
+    metadata String,
+    fields String
+) ENGINE = MergeTree
+PARTITION BY toYYYYMM(ts)
+ORDER BY (service, event_type, ts)`
+}
+
+// table returns the qualified table name
+func (w *ClickHouseWriter) table() string {
+	table := w.Table
+	if table == "" {
+		table = DefaultClickHouseTable
+	}
+	if w.Database != "" {
+		table = w.Database + "." + table
+	}
+	return table
+}
+
+// exec runs query with body as its data
+func (w *ClickHouseWriter) exec(ctx context.Context, query string, body []byte) error {
+	endpoint := strings.TrimSuffix(w.URL, "/") + "/?query=" + url.QueryEscape(query)
+	status, resp, err := doWithRetry(ctx, w.Client, w.Retry, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		for key, values := range w.Header {
+			req.Header[key] = values
+		}
+		InjectTraceContext(ctx, propagation.HeaderCarrier(req.Header))
+		return req, nil
+	})
+	if err != nil {
+		return err
+	}
+	if status < 200 || status > 299 {
+		return fmt.Errorf("unexpected status %d: %s", status, truncateBody(resp))
+	}
+	return nil
+}
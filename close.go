@@ -0,0 +1,202 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+)
+
+// ErrProducerClosed is returned when emitting through a closed producer
+var ErrProducerClosed = errors.New("lifecycle: producer is closed")
+
+// ProducerClosedEvent represents a lifecycle.producer.closed event, the last event a
+// producer writes
+type ProducerClosedEvent struct {
+	Base       *BaseEvent `json:"base"`
+	Status     Status     `json:"status"`  // Warning if events were dropped
+	Flushed    int64      `json:"flushed"` // Buffered events written while closing
+	Dropped    int64      `json:"dropped"` // Buffered events abandoned or rejected while closing
+	DurationMs int64      `json:"duration_ms"`
+}
+
+func (e *ProducerClosedEvent) GetEventType() string     { return e.Base.GetEventType() }
+func (e *ProducerClosedEvent) GetTimestamp() time.Time  { return e.Base.GetTimestamp() }
+func (e *ProducerClosedEvent) GetService() string       { return e.Base.GetService() }
+func (e *ProducerClosedEvent) GetAPI() string           { return e.Base.GetAPI() }
+func (e *ProducerClosedEvent) GetHost() string          { return e.Base.GetHost() }
+func (e *ProducerClosedEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *ProducerClosedEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *ProducerClosedEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *ProducerClosedEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
+
+// flusher is implemented by sinks and outputs that buffer writes
+type flusher interface {
+	Flush() error
+}
+
+// Close stops the producer accepting events and flushes everything it has buffered before
+// ctx is done: the tail sampler's groups, queued Sentry reports, asynchronous sink buffers,
+// and finally the sinks themselves, which are flushed (Flush() error) and closed (io.Closer)
+// once writes in progress to them finish
+// It writes a lifecycle.producer.closed event, signed like any other, reporting how many
+// buffered events were flushed and dropped, and returns ctx.Err() if buffers had to be
+// abandoned or sinks were still being written to
+// Emitting after Close returns ErrProducerClosed
+//
+// On shutdown, call it last, after DrainTracker.Drain and EmitServiceShutdown:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+//	defer cancel()
+//	_ = drain.Drain(ctx)
+//	_ = producer.EmitServiceShutdown(ctx, "signal", 0)
+//	_ = producer.Close(ctx)
+func (p *Producer) Close(ctx context.Context) error {
+	if p.closed.Swap(true) {
+		return ErrProducerClosed
+	}
+	start := time.Now()
+
+	if p.tailSampler != nil {
+		p.tailSampler.Flush()
+	}
+	var sentryErr error
+	if p.sentry != nil {
+		sentryErr = p.sentry.close(ctx)
+	}
+
+	// Once loaded under the lock, ReplaceSinks cannot swap the sinks any more
+	p.sinksMu.Lock()
+	set := p.sinks.Load()
+	p.sinksMu.Unlock()
+	sinks := p.currentSinks()
+
+	var flushed, dropped int64
+	for _, entry := range sinks {
+		if entry.async == nil {
+			continue
+		}
+		f, d := entry.async.close(ctx)
+		flushed += f
+		dropped += d
+		if d > 0 && p.otel != nil {
+			p.otel.RecordDropped(context.WithoutCancel(ctx), entry.name, string(entry.policy), d)
+		}
+	}
+
+	event := &ProducerClosedEvent{
+		Base:       p.createBaseEvent("lifecycle.producer.closed", "", nil),
+		Status:     StatusSuccess,
+		Flushed:    flushed,
+		Dropped:    dropped,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if dropped > 0 {
+		event.Status = StatusWarning
+	}
+
+	errs := []error{sentryErr}
+	if dropped > 0 && ctx.Err() != nil {
+		errs = append(errs, fmt.Errorf("dropped %d buffered events: %w", dropped, ctx.Err()))
+	}
+	if err := p.drainWrites(ctx, set); err != nil {
+		// Closing sinks still being written to would fail those writes, so they are left open
+		errs = append(errs, fmt.Errorf("failed to close sinks: writes in progress: %w", err))
+		return errors.Join(errs...)
+	}
+
+	// Sinks cannot be interrupted, so a wedged one is abandoned at the deadline
+	done := make(chan error, 1)
+	go func() {
+		done <- p.closeSinks(event, sinks)
+	}()
+	select {
+	case err := <-done:
+		errs = append(errs, err)
+	case <-ctx.Done():
+		errs = append(errs, fmt.Errorf("failed to close sinks: %w", ctx.Err()))
+	}
+	return errors.Join(errs...)
+}
+
+// drainWrites waits until no writes are in progress, or ctx is done: background writes
+// hold the producer's write slots, which are taken for good, and writes to the sinks are
+// registered with them, which are retired so no new writes start
+func (p *Producer) drainWrites(ctx context.Context, sinks *sinkSet) error {
+	for i := 0; i < cap(p.writeSlots); i++ {
+		select {
+		case p.writeSlots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if sinks == nil {
+		return nil
+	}
+	return sinks.retire(ctx)
+}
+
+// closeSinks writes the final event directly to the sinks (or default output), then
+// flushes and closes them
+// Sinks whose buffers were abandoned are skipped, since their workers may still be
+// writing to them
+func (p *Producer) closeSinks(final Event, sinks []*sinkEntry) error {
+	var errs []error
+	if len(sinks) == 0 {
+		if err := p.writeFinal(final, nil); err != nil {
+			errs = append(errs, err)
+		}
+		p.outputMu.Lock()
+		if f, ok := p.output.(flusher); ok {
+			if err := f.Flush(); err != nil {
+				errs = append(errs, fmt.Errorf("failed to flush output: %w", err))
+			}
+		}
+		p.outputMu.Unlock()
+		return errors.Join(errs...)
+	}
+
+	open := make([]*sinkEntry, 0, len(sinks))
+	for _, entry := range sinks {
+		if entry.async == nil || entry.async.exited() {
+			open = append(open, entry)
+		}
+	}
+	if err := p.writeFinal(final, open); err != nil {
+		errs = append(errs, err)
+	}
+	for _, entry := range open {
+		if f, ok := entry.sink.(flusher); ok {
+			if err := f.Flush(); err != nil {
+				errs = append(errs, fmt.Errorf("failed to flush sink %s: %w", entry.name, err))
+			}
+		}
+		if c, ok := entry.sink.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("failed to close sink %s: %w", entry.name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// writeFinal signs the final event and writes it directly to the sinks, which are no
+// longer written to otherwise, or to the default output when sinks is nil
+func (p *Producer) writeFinal(final Event, sinks []*sinkEntry) error {
+	if p.chain != nil {
+		p.chain.mu.Lock()
+		defer p.chain.mu.Unlock()
+		if err := p.chain.sign(final); err != nil {
+			return err
+		}
+	}
+	if sinks == nil {
+		return p.writeOutput(final)
+	}
+	return p.writeSinks(sinks, final, true)
+}
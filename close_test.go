@@ -0,0 +1,113 @@
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestCloseSigned checks that the final lifecycle.producer.closed event is signed and
+// chained like the events before it, on the default output and on sinks
+func TestCloseSigned(t *testing.T) {
+	key := []byte("close")
+	var output, sink, closedOnly bytes.Buffer
+	for name, opts := range map[string][]ProducerOption{
+		"output": {WithOutput(&output)},
+		"sinks": {
+			WithSink(NewWriterSink(&sink, nil)),
+			WithSink(NewWriterSink(&closedOnly, nil), SinkFilter(func(event Event) bool {
+				return event.GetEventType() == "lifecycle.producer.closed"
+			})),
+		},
+	} {
+		p := NewProducer("close", "localhost", append(opts, WithEventSigning(NewHMACSigner(key)))...)
+		for i := 0; i < 3; i++ {
+			if err := p.EmitServiceStarted(context.Background(), "1.0.0", int32(i)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := p.Close(context.Background()); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+
+	for name, tt := range map[string]struct {
+		buf  *bytes.Buffer
+		want int
+	}{"output": {&output, 4}, "sink": {&sink, 4}, "filtered sink": {&closedOnly, 1}} {
+		n, err := VerifyEvents(bytes.NewReader(tt.buf.Bytes()), NewHMACVerifier(key))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if n != tt.want {
+			t.Errorf("%s: verified %d events, want %d", name, n, tt.want)
+		}
+		if !strings.Contains(tt.buf.String(), "lifecycle.producer.closed") {
+			t.Errorf("%s: no lifecycle.producer.closed event", name)
+		}
+	}
+}
+
+// closeCheckSink records writes that arrive after it was closed
+type closeCheckSink struct {
+	mu          sync.Mutex
+	closed      bool
+	afterClosed int
+}
+
+func (s *closeCheckSink) WriteEvent(Event) error {
+	time.Sleep(time.Millisecond)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		s.afterClosed++
+	}
+	return nil
+}
+
+func (s *closeCheckSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+// TestCloseWaitsForWrites checks that Close closes sinks only after writes in progress,
+// including background writes of emitters with a deadline, have finished
+func TestCloseWaitsForWrites(t *testing.T) {
+	sink := &closeCheckSink{}
+	p := NewProducer("close", "localhost", WithSink(sink))
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			ctx := context.Background()
+			if g%2 == 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, time.Minute)
+				defer cancel()
+			}
+			for p.EmitServiceStarted(ctx, "1.0.0", int32(g)) == nil {
+			}
+		}(g)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if !sink.closed {
+		t.Fatal("sink was not closed")
+	}
+	if sink.afterClosed > 0 {
+		t.Errorf("%d events were written after the sink was closed", sink.afterClosed)
+	}
+}
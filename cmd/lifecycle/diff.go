@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/SCKelemen/lifecycle"
+)
+
+// errRegressions makes "lifecycle diff -fail" exit non-zero
+var errRegressions = errors.New("regressions found")
+
+// runDiff implements "lifecycle diff [flags] before.ndjson after.ndjson"
+func runDiff(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	output := fs.String("o", "-", "output file (- for stdout)")
+	latency := fs.Float64("latency-threshold", lifecycle.DefaultLatencyRegression, "relative p95/p99 increase reported as a regression")
+	errorRate := fs.Float64("error-threshold", lifecycle.DefaultErrorRateRegression, "absolute error rate increase reported as a regression")
+	minRequests := fs.Int("min-requests", lifecycle.DefaultDiffMinRequests, "requests an endpoint needs in both runs to be judged")
+	fail := fs.Bool("fail", false, "exit with status 1 when there are regressions, e.g., in CI")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: lifecycle diff [flags] <before.ndjson> <after.ndjson>")
+		fmt.Fprintln(fs.Output(), "Compares event types, error rates, and latency percentiles of two runs.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return fmt.Errorf("expected two input files, got %d", fs.NArg())
+	}
+
+	var reports [2]*lifecycle.Report
+	for i, name := range fs.Args() {
+		in, err := openInput(name)
+		if err != nil {
+			return err
+		}
+		reports[i], err = lifecycle.BuildReport(in, lifecycle.ReportOptions{})
+		in.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	diff := lifecycle.DiffReports(reports[0], reports[1], lifecycle.DiffOptions{
+		LatencyThreshold:   *latency,
+		ErrorRateThreshold: *errorRate,
+		MinRequests:        *minRequests,
+	})
+
+	out, err := createOutput(*output)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	if err := diff.WriteMarkdown(out); err != nil {
+		return err
+	}
+	if *fail && len(diff.Regressions) > 0 {
+		return errRegressions
+	}
+	return nil
+}
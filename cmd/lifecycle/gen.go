@@ -0,0 +1,320 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/SCKelemen/lifecycle"
+)
+
+// runGen implements "lifecycle gen [flags] <types dir>"
+func runGen(args []string) error {
+	fs := flag.NewFlagSet("gen", flag.ContinueOnError)
+	output := fs.String("o", "-", "output Go file (- for stdout)")
+	pkg := fs.String("package", "events", "package name of the generated file")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: lifecycle gen [flags] <types dir>")
+		fmt.Fprintln(fs.Output(), "Generates typed Emit helpers, color registration, and field annotation tables")
+		fmt.Fprintln(fs.Output(), "from the API generator's type and event definitions.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected a types directory, got %d arguments", fs.NArg())
+	}
+
+	defs, err := lifecycle.LoadTypeDefinitions(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if len(defs) == 0 {
+		return fmt.Errorf("no type or event definitions in %s", fs.Arg(0))
+	}
+
+	source, err := generateTypes(*pkg, defs)
+	if err != nil {
+		return err
+	}
+	out, err := createOutput(*output)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	_, err = out.Write(source)
+	return err
+}
+
+// generateTypes returns the formatted Go source for defs
+func generateTypes(pkg string, defs []lifecycle.TypeDefinition) ([]byte, error) {
+	var events []lifecycle.TypeDefinition
+	for _, def := range defs {
+		if def.IsEvent() {
+			events = append(events, def)
+		}
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintln(&b, `// Code generated by "lifecycle gen"; DO NOT EDIT.`)
+	fmt.Fprintln(&b)
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	fmt.Fprintln(&b, "import (")
+	if len(events) > 0 {
+		fmt.Fprintln(&b, `"context"`)
+		fmt.Fprintln(&b, `"time"`)
+	}
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, `"github.com/SCKelemen/lifecycle"`)
+	fmt.Fprintln(&b, ")")
+
+	for _, def := range events {
+		genEvent(&b, def)
+	}
+	if len(events) > 0 {
+		fmt.Fprintln(&b, "\nfunc init() {")
+		for _, def := range events {
+			fmt.Fprintf(&b, "lifecycle.RegisterEventType[%s](%q, %q)\n", eventStructName(def), def.Spec.Type, def.Spec.Description)
+		}
+		fmt.Fprintln(&b, "}")
+	}
+
+	genColors(&b, defs)
+	genAnnotations(&b, defs)
+
+	source, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+	return source, nil
+}
+
+// genEvent writes an event's struct, Event methods, and Emit helper
+func genEvent(b *bytes.Buffer, def lifecycle.TypeDefinition) {
+	name := eventStructName(def)
+	annotations := def.FieldAnnotations()
+
+	fmt.Fprintf(b, "\n// %s is an event of type %s", name, def.Spec.Type)
+	if def.Spec.Description != "" {
+		fmt.Fprintf(b, ": %s", def.Spec.Description)
+	}
+	fmt.Fprintf(b, "\ntype %s struct {\n", name)
+	fmt.Fprintln(b, "Base *lifecycle.BaseEvent `json:\"base\"`")
+	var redacted []string
+	for _, field := range def.Spec.Fields {
+		jsonTag := field.Name
+		if !field.Required {
+			jsonTag += ",omitempty"
+		}
+		tags := fmt.Sprintf("json:%q", jsonTag)
+		if a, ok := annotations[field.Name]; ok {
+			if tag := annotationTag(a); tag != "" {
+				tags += fmt.Sprintf(" lifecycle:%q", tag)
+			}
+			if lifecycle.ShouldRedact(a) && goType(field.Type, field.Items) == "string" {
+				redacted = append(redacted, goName(field.Name))
+			}
+		}
+		if field.Description != "" {
+			tags += fmt.Sprintf(" doc:%q", field.Description)
+		}
+		fmt.Fprintf(b, "%s %s `%s`\n", goName(field.Name), goType(field.Type, field.Items), tags)
+	}
+	fmt.Fprintln(b, "}")
+
+	fmt.Fprintf(b, `
+func (e *%[1]s) GetEventType() string { return e.Base.GetEventType() }
+func (e *%[1]s) GetTimestamp() time.Time { return e.Base.GetTimestamp() }
+func (e *%[1]s) GetService() string { return e.Base.GetService() }
+func (e *%[1]s) GetAPI() string { return e.Base.GetAPI() }
+func (e *%[1]s) GetHost() string { return e.Base.GetHost() }
+func (e *%[1]s) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+
+func (e *%[1]s) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *%[1]s) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
+`, name)
+
+	if len(redacted) > 0 {
+		fmt.Fprintf(b, "\nfunc (e *%s) RedactPII(detector *lifecycle.PIIDetector, redactor *lifecycle.Redactor) {\n", name)
+		for _, field := range redacted {
+			fmt.Fprintf(b, "e.%[1]s = redactor.RedactString(e.%[1]s)\n", field)
+		}
+		fmt.Fprintln(b, "}")
+	}
+
+	fmt.Fprintf(b, `
+// Emit%[1]s emits an event of type %[2]s
+func Emit%[1]s(ctx context.Context, p *lifecycle.Producer, event *%[3]s, opts ...lifecycle.EmitOption) error {
+	base, err := p.NewBaseEvent(ctx, %[2]q, opts...)
+	if err != nil {
+		return err
+	}
+	event.Base = base
+	return p.Emit(ctx, event)
+}
+`, typeName(def), def.Spec.Type, name)
+}
+
+// genColors writes RegisterColors for the definitions' color annotations
+func genColors(b *bytes.Buffer, defs []lifecycle.TypeDefinition) {
+	colors := lifecycle.LoadColorsFromTypeDefinitions(defs)
+	fmt.Fprintln(b, "\n// RegisterColors registers the colors of the generated APIs and events")
+	fmt.Fprintln(b, "func RegisterColors(r *lifecycle.ColorRegistry) {")
+	for _, api := range sortedKeys(colors.APIs) {
+		fmt.Fprintf(b, "r.RegisterAPIColor(%q, %q)\n", api, colors.APIs[api])
+	}
+	for _, event := range sortedKeys(colors.Events) {
+		fmt.Fprintf(b, "r.RegisterEventColor(%q, %q)\n", event, colors.Events[event])
+	}
+	fmt.Fprintln(b, "}")
+}
+
+// genAnnotations writes a field annotation table per definition with flagged fields
+func genAnnotations(b *bytes.Buffer, defs []lifecycle.TypeDefinition) {
+	for _, def := range defs {
+		annotations := def.FieldAnnotations()
+		if len(annotations) == 0 {
+			continue
+		}
+		name := typeName(def) + "Annotations"
+		fmt.Fprintf(b, "\n// %s are the field annotations of %s", name, def.Spec.Type)
+		if !def.IsEvent() {
+			fmt.Fprint(b, ", e.g., for ResourceCreatedOptions.Annotations")
+		}
+		fmt.Fprintf(b, "\nvar %s = map[string]lifecycle.FieldAnnotations{\n", name)
+		for _, field := range sortedKeys(annotations) {
+			fmt.Fprintf(b, "%q: {%s},\n", field, annotationLiteral(annotations[field]))
+		}
+		fmt.Fprintln(b, "}")
+	}
+}
+
+// annotationTag returns the lifecycle struct tag of a field's annotations
+func annotationTag(a lifecycle.FieldAnnotations) string {
+	var flags []string
+	for _, flag := range []struct {
+		name string
+		set  bool
+	}{
+		{"pii", a.PII}, {"encrypted", a.Encrypted}, {"redactable", a.Redactable},
+		{"sensitive", a.Sensitive}, {"immutable", a.Immutable}, {"anonymize_ip", a.AnonymizeIP},
+	} {
+		if flag.set {
+			flags = append(flags, flag.name)
+		}
+	}
+	if a.Noise != 0 {
+		flags = append(flags, "noise="+strconv.FormatFloat(a.Noise, 'g', -1, 64))
+	}
+	if a.Bucket != 0 {
+		flags = append(flags, "bucket="+strconv.FormatFloat(a.Bucket, 'g', -1, 64))
+	}
+	return strings.Join(flags, ",")
+}
+
+// annotationLiteral returns the fields of a FieldAnnotations composite literal
+func annotationLiteral(a lifecycle.FieldAnnotations) string {
+	var fields []string
+	for _, flag := range []struct {
+		name string
+		set  bool
+	}{
+		{"PII", a.PII}, {"Encrypted", a.Encrypted}, {"Redactable", a.Redactable},
+		{"Sensitive", a.Sensitive}, {"Immutable", a.Immutable}, {"AnonymizeIP", a.AnonymizeIP},
+	} {
+		if flag.set {
+			fields = append(fields, flag.name+": true")
+		}
+	}
+	if a.Noise != 0 {
+		fields = append(fields, "Noise: "+strconv.FormatFloat(a.Noise, 'g', -1, 64))
+	}
+	if a.Bucket != 0 {
+		fields = append(fields, "Bucket: "+strconv.FormatFloat(a.Bucket, 'g', -1, 64))
+	}
+	return strings.Join(fields, ", ")
+}
+
+// goType returns the Go type of a definition field type
+func goType(typ, items string) string {
+	switch strings.ToLower(typ) {
+	case "string":
+		return "string"
+	case "integer", "int", "int64":
+		return "int64"
+	case "int32":
+		return "int32"
+	case "number", "float", "double":
+		return "float64"
+	case "boolean", "bool":
+		return "bool"
+	case "timestamp", "time", "datetime":
+		return "time.Time"
+	case "object", "map":
+		return "map[string]interface{}"
+	case "array", "list":
+		if items == "" {
+			return "[]interface{}"
+		}
+		return "[]" + goType(items, "")
+	}
+	return "interface{}"
+}
+
+// typeName returns the Go name of a definition's type: the last segment of its type
+// name, e.g., "OrderPlaced" for "examples.OrderPlaced"
+func typeName(def lifecycle.TypeDefinition) string {
+	name := def.Spec.Type
+	if i := strings.LastIndexAny(name, "./"); i >= 0 {
+		name = name[i+1:]
+	}
+	return goName(name)
+}
+
+// eventStructName returns the Go name of an event definition's struct
+func eventStructName(def lifecycle.TypeDefinition) string {
+	return typeName(def) + "Event"
+}
+
+// goInitialisms are name parts written in upper case, as golint expects
+var goInitialisms = map[string]bool{
+	"api": true, "http": true, "id": true, "ip": true, "json": true, "sql": true, "uri": true, "url": true, "uuid": true,
+}
+
+// goName returns an exported Go identifier for a snake_case, kebab-case, or camelCase name
+func goName(name string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if goInitialisms[strings.ToLower(part)] {
+			b.WriteString(strings.ToUpper(part))
+			continue
+		}
+		runes := []rune(part)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	if b.Len() == 0 || unicode.IsDigit([]rune(b.String())[0]) {
+		return "X" + b.String()
+	}
+	return b.String()
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
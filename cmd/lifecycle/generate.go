@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/SCKelemen/lifecycle"
+	"github.com/SCKelemen/lifecycle/lifecycletest"
+)
+
+// runGenerate implements "lifecycle generate [flags]"
+func runGenerate(args []string) error {
+	fs := flag.NewFlagSet("generate", flag.ContinueOnError)
+	output := fs.String("o", "-", "output file (- for stdout)")
+	service := fs.String("service", "demo-service", "service name")
+	hosts := fs.Int("hosts", 1, "number of hosts to spread requests across")
+	requests := fs.Int("requests", 1000, "number of requests")
+	rate := fs.Float64("rate", 50, "mean requests per second")
+	errorRatio := fs.Float64("error-ratio", 0.02, "fraction of requests that fail")
+	queries := fs.Int("queries", 3, "maximum queries per request")
+	queryErrorRatio := fs.Float64("query-error-ratio", 0.01, "fraction of queries that fail")
+	latency := fs.Duration("latency", 20*time.Millisecond, "median request time outside queries")
+	latencyP99 := fs.Duration("latency-p99", 250*time.Millisecond, "99th percentile request time outside queries")
+	queryLatency := fs.Duration("query-latency", 3*time.Millisecond, "median query latency")
+	queryLatencyP99 := fs.Duration("query-latency-p99", 80*time.Millisecond, "99th percentile query latency")
+	start := fs.String("start", "", "RFC 3339 time of the first request (default: now)")
+	seed := fs.Int64("seed", 0, "random seed for a reproducible stream (default: random)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: lifecycle generate [flags]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %q", fs.Args())
+	}
+
+	// Zero means "default" in a Profile, and negative means "none"
+	for _, v := range []*float64{errorRatio, queryErrorRatio} {
+		if *v == 0 {
+			*v = -1
+		}
+	}
+	if *queries == 0 {
+		*queries = -1
+	}
+
+	profile := lifecycletest.Profile{
+		Service:           *service,
+		Hosts:             *hosts,
+		Requests:          *requests,
+		Rate:              *rate,
+		Seed:              *seed,
+		ErrorRatio:        *errorRatio,
+		QueriesPerRequest: *queries,
+		QueryErrorRatio:   *queryErrorRatio,
+		RequestLatency:    lifecycletest.Latency{Median: *latency, P99: *latencyP99},
+		QueryLatency:      lifecycletest.Latency{Median: *queryLatency, P99: *queryLatencyP99},
+	}
+	if *start != "" {
+		t, err := time.Parse(time.RFC3339Nano, *start)
+		if err != nil {
+			return fmt.Errorf("invalid start %q: %w", *start, err)
+		}
+		profile.Start = t
+	}
+
+	out, err := createOutput(*output)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	w := bufio.NewWriter(out)
+	encoder := lifecycle.NewJSONEncoder()
+	for _, event := range lifecycletest.Generate(profile) {
+		data, err := encoder.Encode(event)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(append(data, '\n')); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,100 @@
+// Command lifecycle provides developer tooling for lifecycle event streams
+//
+// Usage:
+//
+//	lifecycle <command> [flags] [args]
+//
+// Run "lifecycle help" for the list of commands
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// command is a lifecycle CLI subcommand
+type command struct {
+	summary string                    // One-line description shown in help
+	run     func(args []string) error // Parses args and runs the command
+}
+
+// commands maps subcommand names to their implementations
+var commands = map[string]command{
+	"diff":     {summary: "compare two NDJSON event streams and report regressions", run: runDiff},
+	"gen":      {summary: "generate typed Emit helpers, colors, and annotations from API type definitions", run: runGen},
+	"generate": {summary: "generate a synthetic NDJSON event stream", run: runGenerate},
+	"redact":   {summary: "redact PII from JSON documents or an NDJSON stream", run: runRedact},
+	"replay":   {summary: "replay a recorded NDJSON event stream into a sink", run: runReplay},
+	"report":   {summary: "summarize an NDJSON event stream as Markdown or HTML", run: runReport},
+	"schema":   {summary: "document event types (schema docs) or the stream as AsyncAPI (schema asyncapi)", run: runSchema},
+	"stats":    {summary: "render per-endpoint latency histograms and heatmaps from an NDJSON stream", run: runStats},
+	"tail":     {summary: "follow event streams from several files or sockets, merged by timestamp", run: runTail},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+
+	name := os.Args[1]
+	if name == "help" || name == "-h" || name == "--help" {
+		usage(os.Stdout)
+		return
+	}
+
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "lifecycle: unknown command %q\n\n", name)
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+
+	if err := cmd.run(os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "lifecycle %s: %v\n", name, err)
+		os.Exit(1)
+	}
+}
+
+// usage prints the list of available commands
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: lifecycle <command> [flags] [args]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-10s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Run "lifecycle <command> -h" for command flags.`)
+}
+
+// openInput opens the named file for reading, or stdin for "-" or an empty name
+func openInput(name string) (io.ReadCloser, error) {
+	if name == "" || name == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(name)
+}
+
+// createOutput creates the named file for writing, or stdout for "-" or an empty name
+func createOutput(name string) (io.WriteCloser, error) {
+	if name == "" || name == "-" {
+		return nopWriteCloser{os.Stdout}, nil
+	}
+	return os.Create(name)
+}
+
+// nopWriteCloser wraps a writer with a no-op Close
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/SCKelemen/lifecycle"
+)
+
+// runRedact implements "lifecycle redact [flags] payload.json"
+func runRedact(args []string) error {
+	fs := flag.NewFlagSet("redact", flag.ContinueOnError)
+	output := fs.String("o", "-", "output file (- for stdout)")
+	mask := fs.String("mask", "[REDACTED]", "string that replaces redacted values")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: lifecycle redact [flags] <payload.json|events.ndjson|->")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return fmt.Errorf("expected a single input file, got %d", fs.NArg())
+	}
+
+	in, err := openInput(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := createOutput(*output)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	redactor := lifecycle.NewRedactor().WithRedactionString(*mask)
+	return redactor.RedactJSON(in, out, lifecycle.NewPIIDetector())
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SCKelemen/lifecycle"
+	"github.com/SCKelemen/lifecycle/lifecyclekafka"
+	"github.com/segmentio/kafka-go"
+)
+
+// runReplay implements "lifecycle replay [flags] run.ndjson"
+func runReplay(args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	rate := fs.String("rate", "", "fixed rate such as 500/s or 100/m, ignoring recorded timing")
+	speed := fs.Float64("speed", 1, "scale recorded timing (2 = twice as fast, 0 = as fast as possible)")
+	target := fs.String("target", "-", "where to send events: - (stdout), a file, http(s)://url, or kafka://broker[,broker]/topic")
+	retime := fs.Bool("retime", false, "rewrite event timestamps to the time they are replayed")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: lifecycle replay [flags] <events.ndjson|->")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return fmt.Errorf("expected a single input file, got %d", fs.NArg())
+	}
+
+	var interval time.Duration
+	if *rate != "" {
+		var err error
+		if interval, err = parseRate(*rate); err != nil {
+			return err
+		}
+	} else if *speed < 0 {
+		return fmt.Errorf("invalid speed %v", *speed)
+	}
+
+	in, err := openInput(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	sink, err := openSink(*target)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	r := &replayer{sink: sink, interval: interval, speed: *speed, retime: *retime}
+	replayErr := r.replay(ctx, in)
+	if c, ok := sink.(io.Closer); ok {
+		if err := c.Close(); err != nil && replayErr == nil {
+			replayErr = err
+		}
+	}
+
+	elapsed := time.Since(r.start)
+	fmt.Fprintf(os.Stderr, "replayed %d events in %s (%.1f/s)\n",
+		r.count, elapsed.Round(time.Millisecond), float64(r.count)/max(elapsed.Seconds(), 1e-9))
+	if ctx.Err() != nil {
+		return nil
+	}
+	return replayErr
+}
+
+// replayer paces recorded events into a sink
+type replayer struct {
+	sink     lifecycle.Sink
+	interval time.Duration // Fixed spacing between events; 0 follows recorded timing
+	speed    float64       // Divides recorded gaps; 0 disables pacing
+	retime   bool
+
+	start time.Time
+	first time.Time // Timestamp of the first recorded event
+	count int
+}
+
+// replay sends every event read from r, waiting until each one is due
+func (r *replayer) replay(ctx context.Context, in io.Reader) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	r.start = time.Now()
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		record, err := lifecycle.DecodeRecord(data)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+
+		if err := r.wait(ctx, record.Timestamp); err != nil {
+			return err
+		}
+
+		event := &replayedEvent{record: record, raw: append([]byte(nil), data...)}
+		if r.retime {
+			if err := event.setTimestamp(time.Now()); err != nil {
+				return fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+		if err := r.sink.WriteEvent(event); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		r.count++
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read events: %w", err)
+	}
+	return nil
+}
+
+// wait sleeps until the next event is due: at a fixed interval after the previous one, or
+// at its recorded offset from the first event scaled by speed
+func (r *replayer) wait(ctx context.Context, ts time.Time) error {
+	var due time.Time
+	switch {
+	case r.interval > 0:
+		due = r.start.Add(time.Duration(r.count) * r.interval)
+	case r.speed == 0 || ts.IsZero():
+		return ctx.Err()
+	default:
+		if r.first.IsZero() {
+			r.first = ts
+		}
+		due = r.start.Add(time.Duration(float64(ts.Sub(r.first)) / r.speed))
+	}
+
+	delay := time.Until(due)
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// parseRate parses "500/s", "100/m", "10/h", or a bare number of events per second into
+// the interval between events
+func parseRate(rate string) (time.Duration, error) {
+	count, unit, found := strings.Cut(rate, "/")
+	per := time.Second
+	if found {
+		switch unit {
+		case "s":
+		case "m":
+			per = time.Minute
+		case "h":
+			per = time.Hour
+		default:
+			return 0, fmt.Errorf("invalid rate %q (want e.g. 500/s, 100/m, or 10/h)", rate)
+		}
+	}
+	n, err := strconv.ParseFloat(count, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid rate %q (want e.g. 500/s, 100/m, or 10/h)", rate)
+	}
+	return time.Duration(float64(per) / n), nil
+}
+
+// openSink creates the sink named by target
+func openSink(target string) (lifecycle.Sink, error) {
+	switch {
+	case target == "" || target == "-":
+		return lifecycle.NewWriterSink(os.Stdout, nil), nil
+	case strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://"):
+		return lifecycle.NewHTTPSink(target), nil
+	case strings.HasPrefix(target, "kafka://"):
+		u, err := url.Parse(target)
+		if err != nil {
+			return nil, err
+		}
+		topic := strings.TrimPrefix(u.Path, "/")
+		if u.Host == "" || topic == "" {
+			return nil, fmt.Errorf("invalid kafka target %q (want kafka://broker[,broker]/topic)", target)
+		}
+		return lifecyclekafka.NewSink(&kafka.Writer{
+			Addr:     kafka.TCP(strings.Split(u.Host, ",")...),
+			Topic:    topic,
+			Balancer: &kafka.Hash{},
+		}), nil
+	default:
+		file, err := os.Create(target)
+		if err != nil {
+			return nil, err
+		}
+		return &fileSink{Sink: lifecycle.NewWriterSink(file, nil), file: file}, nil
+	}
+}
+
+// fileSink is a writer sink that closes its file
+type fileSink struct {
+	lifecycle.Sink
+	file *os.File
+}
+
+func (s *fileSink) Close() error { return s.file.Close() }
+
+// replayedEvent is a recorded event, written back exactly as it was read
+type replayedEvent struct {
+	record *lifecycle.Record
+	raw    []byte
+}
+
+func (e *replayedEvent) GetEventType() string                { return e.record.EventType }
+func (e *replayedEvent) GetTimestamp() time.Time             { return e.record.Timestamp }
+func (e *replayedEvent) GetService() string                  { return e.record.Service }
+func (e *replayedEvent) GetAPI() string                      { return e.record.API }
+func (e *replayedEvent) GetHost() string                     { return e.record.Host }
+func (e *replayedEvent) GetCorrelationID() string            { return e.record.CorrelationID }
+func (e *replayedEvent) GetMetadata() map[string]interface{} { return e.record.Metadata }
+func (e *replayedEvent) MarshalJSON() ([]byte, error)        { return e.raw, nil }
+
+// SetMetadata does nothing: recorded events are replayed unchanged
+func (e *replayedEvent) SetMetadata(key string, value interface{}) {}
+
+// setTimestamp rewrites the event's timestamp, in the nested or flat form it was read in
+func (e *replayedEvent) setTimestamp(ts time.Time) error {
+	decoder := json.NewDecoder(bytes.NewReader(e.raw))
+	decoder.UseNumber()
+	var raw map[string]interface{}
+	if err := decoder.Decode(&raw); err != nil {
+		return fmt.Errorf("failed to decode event: %w", err)
+	}
+	base := raw
+	if nested, ok := raw["base"].(map[string]interface{}); ok {
+		base = nested
+	}
+	base["timestamp"] = ts.Format(time.RFC3339Nano)
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	e.raw = data
+	e.record.Timestamp = ts
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/SCKelemen/lifecycle"
+)
+
+// runReport implements "lifecycle report [flags] run.ndjson"
+func runReport(args []string) error {
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	format := fs.String("format", "markdown", "output format: markdown or html")
+	output := fs.String("o", "-", "output file (- for stdout)")
+	top := fs.Int("top", 10, "number of slowest queries to list")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: lifecycle report [flags] <events.ndjson|->")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return fmt.Errorf("expected a single input file, got %d", fs.NArg())
+	}
+
+	in, err := openInput(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	report, err := lifecycle.BuildReport(in, lifecycle.ReportOptions{SlowQueryLimit: *top})
+	if err != nil {
+		return err
+	}
+
+	out, err := createOutput(*output)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	switch *format {
+	case "markdown", "md":
+		return report.WriteMarkdown(out)
+	case "html":
+		return report.WriteHTML(out)
+	default:
+		return fmt.Errorf("unknown format %q (want markdown or html)", *format)
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/SCKelemen/lifecycle"
+)
+
+// runSchema implements "lifecycle schema <subcommand> [flags]"
+func runSchema(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "docs":
+			return runSchemaDocs(args[1:])
+		case "asyncapi":
+			return runSchemaAsyncAPI(args[1:])
+		}
+	}
+	return fmt.Errorf(`expected a subcommand: "lifecycle schema docs" or "lifecycle schema asyncapi"`)
+}
+
+// runSchemaDocs implements "lifecycle schema docs [flags]"
+func runSchemaDocs(args []string) error {
+	fs := flag.NewFlagSet("schema docs", flag.ContinueOnError)
+	format := fs.String("format", "markdown", "output format: markdown, json, or jsonschema (a JSON Schema per event type)")
+	output := fs.String("o", "-", "output file (- for stdout)")
+	eventType := fs.String("type", "", `only document event types matching this pattern, e.g., "db.*"`)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: lifecycle schema docs [flags]")
+		fmt.Fprintln(fs.Output(), "Documents the fields of every built-in event type. Custom event types are")
+		fmt.Fprintln(fs.Output(), "included by lifecycle.WriteEventTypesMarkdown in programs that register them.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	types := lifecycle.EventTypes()
+	if *eventType != "" {
+		var matched []lifecycle.EventTypeInfo
+		for _, info := range types {
+			if ok, _ := path.Match(*eventType, info.EventType); ok {
+				matched = append(matched, info)
+			}
+		}
+		if len(matched) == 0 {
+			return fmt.Errorf("no event types match %q", *eventType)
+		}
+		types = matched
+	}
+
+	out, err := createOutput(*output)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	switch *format {
+	case "markdown", "md":
+		return lifecycle.WriteEventTypesMarkdown(out, types)
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]interface{}{
+			"base_fields": lifecycle.BaseEventFields,
+			"event_types": types,
+		})
+	case "jsonschema":
+		schemas := make(map[string]interface{}, len(types))
+		for _, info := range types {
+			schema, err := lifecycle.SchemaFor(info.EventType)
+			if err != nil {
+				return err
+			}
+			schemas[info.EventType] = schema
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(schemas)
+	default:
+		return fmt.Errorf("unknown format %q (want markdown, json, or jsonschema)", *format)
+	}
+}
+
+// runSchemaAsyncAPI implements "lifecycle schema asyncapi [flags]"
+func runSchemaAsyncAPI(args []string) error {
+	fs := flag.NewFlagSet("schema asyncapi", flag.ContinueOnError)
+	output := fs.String("o", "-", "output file (- for stdout)")
+	title := fs.String("title", "", "document title (default: Lifecycle Events)")
+	version := fs.String("version", "", "version of the stream's API (default: 1.0.0)")
+	channel := fs.String("channel", lifecycle.DefaultAsyncAPIChannel, "address events are published on, e.g., a Kafka topic")
+	perFamily := fs.Bool("channel-per-family", false, `publish each event family on its own channel, e.g., "lifecycle.db"`)
+	var servers, eventTypes stringList
+	fs.Var(&servers, "server", "broker as name=protocol://host, e.g., prod=kafka://kafka.internal:9092 (repeatable)")
+	fs.Var(&eventTypes, "type", `only describe event types matching this pattern, e.g., "api.*" (repeatable)`)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: lifecycle schema asyncapi [flags]")
+		fmt.Fprintln(fs.Output(), "Writes an AsyncAPI 3.0 document describing the event stream, for consumer codegen.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	opts := lifecycle.AsyncAPIOptions{
+		Title:      *title,
+		Version:    *version,
+		Channel:    *channel,
+		EventTypes: eventTypes,
+	}
+	if *perFamily {
+		opts.ChannelFor = func(eventType string) string {
+			family, _, _ := strings.Cut(eventType, ".")
+			return *channel + "." + family
+		}
+	}
+	for _, server := range servers {
+		name, url, ok := strings.Cut(server, "=")
+		protocol, host, hasProtocol := strings.Cut(url, "://")
+		if !ok || !hasProtocol || name == "" || host == "" {
+			return fmt.Errorf("invalid -server %q (want name=protocol://host)", server)
+		}
+		if opts.Servers == nil {
+			opts.Servers = make(map[string]lifecycle.AsyncAPIServer)
+		}
+		opts.Servers[name] = lifecycle.AsyncAPIServer{Host: host, Protocol: protocol}
+	}
+
+	out, err := createOutput(*output)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	return lifecycle.WriteAsyncAPI(out, opts)
+}
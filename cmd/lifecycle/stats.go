@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/SCKelemen/lifecycle"
+)
+
+// runStats implements "lifecycle stats [flags] run.ndjson"
+func runStats(args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	output := fs.String("o", "-", "output file (- for stdout)")
+	columns := fs.Int("columns", lifecycle.DefaultHeatmapColumns, "number of time intervals in the heatmap")
+	top := fs.Int("top", 5, "number of busiest endpoints to show (0 for all)")
+	endpoint := fs.String("endpoint", "", "only show endpoints containing this text")
+	filter := fs.String("filter", "", `only count requests whose completion event matches this expression, e.g., 'status_code>=500'`)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: lifecycle stats [flags] <events.ndjson|->")
+		fmt.Fprintln(fs.Output(), "Renders per-endpoint latency percentiles, histograms, and heatmaps over time.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return fmt.Errorf("expected a single input file, got %d", fs.NArg())
+	}
+
+	in, err := openInput(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	opts := lifecycle.LatencyStatsOptions{Columns: *columns}
+	if *filter != "" {
+		f, err := lifecycle.ParseFilter(*filter)
+		if err != nil {
+			return err
+		}
+		opts.Filter = f.Match
+	}
+	stats, err := lifecycle.BuildLatencyStats(in, opts)
+	if err != nil {
+		return err
+	}
+	if *endpoint != "" {
+		var matched []lifecycle.EndpointLatency
+		for _, e := range stats.Endpoints {
+			if strings.Contains(e.Endpoint, *endpoint) {
+				matched = append(matched, e)
+			}
+		}
+		stats.Endpoints = matched
+	}
+	if *top > 0 && len(stats.Endpoints) > *top {
+		stats.Endpoints = stats.Endpoints[:*top]
+	}
+
+	out, err := createOutput(*output)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	return stats.WriteText(out)
+}
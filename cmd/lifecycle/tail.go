@@ -0,0 +1,367 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"container/heap"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/SCKelemen/lifecycle"
+)
+
+// tailPollInterval is how often followed files are checked for new lines
+const tailPollInterval = 200 * time.Millisecond
+
+// runTail implements "lifecycle tail [flags] source..."
+func runTail(args []string) error {
+	fs := flag.NewFlagSet("tail", flag.ContinueOnError)
+	all := fs.Bool("all", false, "print the events already in files before following them")
+	follow := fs.Bool("follow", true, "keep following sources (false: read files to the end and exit)")
+	reorder := fs.Duration("reorder", 250*time.Millisecond, "how long to hold events so sources merge in timestamp order")
+	jsonOutput := fs.Bool("json", false, "print NDJSON instead of styled output")
+	filterExpr := fs.String("filter", "", `only print events matching this expression, e.g., 'status_code>=500 && api=="examples.User"'`)
+	var containers, pods stringList
+	fs.Var(&containers, "docker", "follow the logs of a docker container (repeatable)")
+	fs.Var(&pods, "kubectl", "follow the logs of a Kubernetes pod, or kubectl resource such as deploy/orders (repeatable)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: lifecycle tail [flags] [<file|-|unix:/path/to.sock>...]")
+		fmt.Fprintln(fs.Output(), "Follows several event streams at once, merged by timestamp and colored by service.")
+		fmt.Fprintln(fs.Output(), "unix: sources listen for lifecycle.NewSocketSink writers. Lines that are not")
+		fmt.Fprintln(fs.Output(), "lifecycle events, such as other log output of containers, are skipped.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	names := fs.Args()
+	if len(names) == 0 && len(containers) == 0 && len(pods) == 0 {
+		names = []string{"-"}
+	}
+	var sources []tailSource
+	for _, name := range names {
+		source, err := newTailSource(name, *all || !*follow, *follow)
+		if err != nil {
+			return err
+		}
+		sources = append(sources, source)
+	}
+	for _, container := range containers {
+		args := []string{"logs"}
+		if *follow {
+			args = append(args, "--follow")
+			if !*all {
+				args = append(args, "--tail", "0")
+			}
+		}
+		// "--" keeps a name starting with "-" from being read as a flag
+		sources = append(sources, newCommandSource("docker:"+container, "docker", append(args, "--", container)...))
+	}
+	for _, pod := range pods {
+		args := []string{"logs"}
+		if *follow {
+			args = append(args, "--follow")
+			if !*all {
+				args = append(args, "--tail=0")
+			}
+		}
+		sources = append(sources, newCommandSource("kubectl:"+pod, "kubectl", append(args, "--", pod)...))
+	}
+
+	var filter *lifecycle.Filter
+	if *filterExpr != "" {
+		var err error
+		if filter, err = lifecycle.ParseFilter(*filterExpr); err != nil {
+			return err
+		}
+	}
+
+	var sink lifecycle.Sink
+	if *jsonOutput {
+		sink = lifecycle.NewWriterSink(os.Stdout, nil)
+	} else {
+		sink = lifecycle.NewStyledOutput(os.Stdout,
+			lifecycle.WithStyledColorRegistry(lifecycle.NewColorRegistry(lifecycle.WithAutoColors())))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return tailSources(ctx, sources, sink, *reorder, filter)
+}
+
+// tailSource is a stream of events to follow
+type tailSource struct {
+	name string
+	run  func(ctx context.Context, emit func(*lifecycle.Record)) error
+}
+
+// newTailSource creates the source named by name: a file, "-" for stdin, or unix:path
+func newTailSource(name string, fromStart, follow bool) (tailSource, error) {
+	switch {
+	case name == "-":
+		return tailSource{name: "stdin", run: func(ctx context.Context, emit func(*lifecycle.Record)) error {
+			return scanEvents(ctx, os.Stdin, emit)
+		}}, nil
+	case strings.HasPrefix(name, "unix:"):
+		if !follow {
+			return tailSource{}, fmt.Errorf("%s: sockets can only be followed", name)
+		}
+		path := strings.TrimPrefix(name, "unix:")
+		return tailSource{name: name, run: func(ctx context.Context, emit func(*lifecycle.Record)) error {
+			return listenEvents(ctx, path, emit)
+		}}, nil
+	default:
+		file, err := os.Open(name)
+		if err != nil {
+			return tailSource{}, err
+		}
+		return tailSource{name: name, run: func(ctx context.Context, emit func(*lifecycle.Record)) error {
+			defer file.Close()
+			return followFile(ctx, file, fromStart, follow, emit)
+		}}, nil
+	}
+}
+
+// newCommandSource creates a source reading the output of a log command, such as
+// "docker logs", from both stdout and stderr
+func newCommandSource(name, command string, args ...string) tailSource {
+	return tailSource{name: name, run: func(ctx context.Context, emit func(*lifecycle.Record)) error {
+		r, w := io.Pipe()
+		cmd := exec.CommandContext(ctx, command, args...)
+		cmd.Stdout = w
+		cmd.Stderr = w
+		if err := cmd.Start(); err != nil {
+			return err
+		}
+		go func() {
+			w.CloseWithError(cmd.Wait())
+		}()
+		err := scanEvents(ctx, r, emit)
+		r.Close()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return fmt.Errorf("%s exited: %v", command, exitErr)
+		}
+		return err
+	}}
+}
+
+// stringList is a flag that may be repeated
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// tailSources merges the sources' events matching filter (nil for all) into sink until
+// they all end or ctx is done
+func tailSources(ctx context.Context, sources []tailSource, sink lifecycle.Sink, reorder time.Duration, filter *lifecycle.Filter) error {
+	records := make(chan *lifecycle.Record, 1024)
+	var failed atomic.Int32
+	var wg sync.WaitGroup
+	for _, source := range sources {
+		wg.Add(1)
+		go func(source tailSource) {
+			defer wg.Done()
+			emit := func(record *lifecycle.Record) {
+				select {
+				case records <- record:
+				case <-ctx.Done():
+				}
+			}
+			if err := source.run(ctx, emit); err != nil && ctx.Err() == nil {
+				fmt.Fprintf(os.Stderr, "lifecycle tail: %s: %v\n", source.name, err)
+				failed.Add(1)
+			}
+		}(source)
+	}
+	go func() {
+		wg.Wait()
+		close(records)
+	}()
+
+	merger := &tailMerger{sink: sink, reorder: reorder, filter: filter}
+	ticker := time.NewTicker(max(reorder/4, 10*time.Millisecond))
+	defer ticker.Stop()
+	for {
+		select {
+		case record, ok := <-records:
+			if !ok {
+				if err := merger.release(time.Time{}); err != nil {
+					return err
+				}
+				if int(failed.Load()) == len(sources) {
+					return errors.New("no source could be read")
+				}
+				return nil
+			}
+			merger.add(record)
+		case now := <-ticker.C:
+			if err := merger.release(now); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return merger.release(time.Time{})
+		}
+	}
+}
+
+// tailMerger holds events for the reorder window and writes them in timestamp order
+type tailMerger struct {
+	sink    lifecycle.Sink
+	reorder time.Duration
+	filter  *lifecycle.Filter
+	pending tailHeap
+	seq     int
+}
+
+// add holds record until its reorder window passes, unless the filter drops it
+func (m *tailMerger) add(record *lifecycle.Record) {
+	if m.filter != nil && !m.filter.Match(record) {
+		return
+	}
+	m.seq++
+	heap.Push(&m.pending, tailEvent{record: record, arrived: time.Now(), seq: m.seq})
+}
+
+// release writes, in timestamp order, the events held for the reorder window as of now,
+// or every event for a zero now
+func (m *tailMerger) release(now time.Time) error {
+	for m.pending.Len() > 0 {
+		next := m.pending[0]
+		if !now.IsZero() && now.Sub(next.arrived) < m.reorder {
+			return nil
+		}
+		heap.Pop(&m.pending)
+		if err := m.sink.WriteEvent(lifecycle.NewRecordEvent(next.record)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tailEvent is an event waiting in the merger
+type tailEvent struct {
+	record  *lifecycle.Record
+	arrived time.Time
+	seq     int // Keeps events with equal timestamps in arrival order
+}
+
+// tailHeap orders waiting events by timestamp
+type tailHeap []tailEvent
+
+func (h tailHeap) Len() int { return len(h) }
+func (h tailHeap) Less(i, j int) bool {
+	if !h[i].record.Timestamp.Equal(h[j].record.Timestamp) {
+		return h[i].record.Timestamp.Before(h[j].record.Timestamp)
+	}
+	return h[i].seq < h[j].seq
+}
+func (h tailHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *tailHeap) Push(x interface{}) { *h = append(*h, x.(tailEvent)) }
+func (h *tailHeap) Pop() interface{} {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return last
+}
+
+// followFile reads events from file, then, when following, polls it for new lines,
+// starting over if it is truncated
+func followFile(ctx context.Context, file *os.File, fromStart, follow bool, emit func(*lifecycle.Record)) error {
+	if !fromStart {
+		if _, err := file.Seek(0, io.SeekEnd); err != nil {
+			return err
+		}
+	}
+	reader := bufio.NewReader(file)
+	var partial []byte
+	for {
+		line, err := reader.ReadBytes('\n')
+		partial = append(partial, line...)
+		if err == nil {
+			emitLine(partial, emit)
+			partial = partial[:0]
+			continue
+		}
+		if err != io.EOF {
+			return err
+		}
+		if !follow {
+			emitLine(partial, emit)
+			return nil
+		}
+
+		if info, err := file.Stat(); err == nil {
+			if offset, err := file.Seek(0, io.SeekCurrent); err == nil && info.Size() < offset {
+				if _, err := file.Seek(0, io.SeekStart); err != nil {
+					return err
+				}
+				reader.Reset(file)
+				partial = partial[:0]
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(tailPollInterval):
+		}
+	}
+}
+
+// scanEvents reads events from r until it ends or ctx is done
+func scanEvents(ctx context.Context, r io.Reader, emit func(*lifecycle.Record)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			return nil
+		}
+		emitLine(scanner.Bytes(), emit)
+	}
+	return scanner.Err()
+}
+
+// listenEvents receives events from socket sinks at path until ctx is done
+func listenEvents(ctx context.Context, path string, emit func(*lifecycle.Record)) error {
+	listener, err := lifecycle.ListenSocket(path)
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+	defer os.Remove(path)
+	return listener.Serve(func(record *lifecycle.Record) error {
+		emit(record)
+		return nil
+	})
+}
+
+// emitLine emits the event on line, skipping lines that are not lifecycle events
+// The event may follow a prefix, such as the "service  | " added by docker compose
+func emitLine(line []byte, emit func(*lifecycle.Record)) {
+	start := bytes.IndexByte(line, '{')
+	if start < 0 {
+		return
+	}
+	record, err := lifecycle.DecodeRecord(bytes.TrimSpace(line[start:]))
+	if err != nil || record.EventType == "" {
+		return // Not an event, or JSON logged by something else
+	}
+	emit(record)
+}
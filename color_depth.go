@@ -0,0 +1,254 @@
+package lifecycle
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// ColorDepth is the number of colors styled output renders with
+// Hex colors are downsampled to the 256-color or 16-color palette, so output stays legible
+// on terminals (or SSH and tmux sessions) without true color
+type ColorDepth int
+
+const (
+	// ColorDepthAuto uses the depth the terminal reports, or LIFECYCLE_COLOR_DEPTH if set
+	ColorDepthAuto ColorDepth = iota
+	// ColorDepthTrueColor renders hex colors as they are
+	ColorDepthTrueColor
+	// ColorDepth256 downsamples to the xterm 256-color palette
+	ColorDepth256
+	// ColorDepth16 downsamples to the 16 basic ANSI colors, by hue
+	ColorDepth16
+)
+
+// String returns the name of the color depth, as accepted by ParseColorDepth
+func (d ColorDepth) String() string {
+	switch d {
+	case ColorDepthTrueColor:
+		return "truecolor"
+	case ColorDepth256:
+		return "256"
+	case ColorDepth16:
+		return "16"
+	default:
+		return "auto"
+	}
+}
+
+// ParseColorDepth parses "auto", "truecolor" (or "24bit"), "256", or "16"
+func ParseColorDepth(s string) (ColorDepth, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "auto":
+		return ColorDepthAuto, nil
+	case "truecolor", "24bit":
+		return ColorDepthTrueColor, nil
+	case "256":
+		return ColorDepth256, nil
+	case "16":
+		return ColorDepth16, nil
+	}
+	return ColorDepthAuto, fmt.Errorf("invalid color depth %q (want auto, truecolor, 256, or 16)", s)
+}
+
+// WithColorDepth sets the color depth of styled output (default: ColorDepthAuto)
+// It only applies when colors are enabled (see WithColorMode)
+func WithColorDepth(depth ColorDepth) StyledOutputOption {
+	return func(s *StyledOutput) {
+		s.colorDepth = depth
+	}
+}
+
+// applyColorDepth returns the profile to render with for the detected profile and depth
+func applyColorDepth(profile termenv.Profile, depth ColorDepth) termenv.Profile {
+	if profile == termenv.Ascii {
+		return profile
+	}
+	if depth == ColorDepthAuto {
+		// An invalid value is ignored rather than failing output
+		depth, _ = ParseColorDepth(os.Getenv("LIFECYCLE_COLOR_DEPTH"))
+	}
+	switch depth {
+	case ColorDepthTrueColor:
+		return termenv.TrueColor
+	case ColorDepth256:
+		return termenv.ANSI256
+	case ColorDepth16:
+		return termenv.ANSI
+	default:
+		return profile
+	}
+}
+
+// colorFallback is an explicit downsampled color
+type colorFallback struct {
+	ansi256 int
+	ansi16  int
+}
+
+var (
+	colorFallbacksMu sync.RWMutex
+	colorFallbacks   = make(map[string]colorFallback) // Upper-case hex color -> fallback
+)
+
+// RegisterColorFallback sets the 256-color and 16-color palette indexes used for a hex
+// color (e.g., "#F97316") instead of the computed nearest colors
+func RegisterColorFallback(color string, ansi256, ansi16 int) {
+	colorFallbacksMu.Lock()
+	defer colorFallbacksMu.Unlock()
+	colorFallbacks[strings.ToUpper(color)] = colorFallback{ansi256: ansi256, ansi16: ansi16}
+}
+
+// terminalColor returns color as a lipgloss color for the output's profile
+func (s *StyledOutput) terminalColor(color string) lipgloss.Color {
+	return lipgloss.Color(downsampleColor(color, s.renderer.ColorProfile()))
+}
+
+// downsampleColor converts a hex color to a palette index for 256-color and 16-color
+// profiles; other colors and profiles are returned unchanged
+func downsampleColor(color string, profile termenv.Profile) string {
+	if profile != termenv.ANSI256 && profile != termenv.ANSI {
+		return color
+	}
+	r, g, b, ok := parseHexColor(color)
+	if !ok {
+		return color
+	}
+
+	colorFallbacksMu.RLock()
+	fallback, explicit := colorFallbacks[strings.ToUpper(color)]
+	colorFallbacksMu.RUnlock()
+
+	switch {
+	case explicit && profile == termenv.ANSI256:
+		return strconv.Itoa(fallback.ansi256)
+	case explicit:
+		return strconv.Itoa(fallback.ansi16)
+	case profile == termenv.ANSI256:
+		return strconv.Itoa(nearestANSI256(r, g, b))
+	default:
+		return strconv.Itoa(nearestANSI16(r, g, b))
+	}
+}
+
+// parseHexColor parses "#RRGGBB" or "#RGB"
+func parseHexColor(color string) (r, g, b int, ok bool) {
+	hex, found := strings.CutPrefix(color, "#")
+	if !found {
+		return 0, 0, 0, false
+	}
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return 0, 0, 0, false
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	return int(v >> 16), int(v >> 8 & 0xFF), int(v & 0xFF), true
+}
+
+// cubeLevels are the channel values of the 6x6x6 color cube at indexes 16-231
+var cubeLevels = [6]int{0, 0x5F, 0x87, 0xAF, 0xD7, 0xFF}
+
+// nearestANSI256 returns the closest color cube or grayscale ramp index to an RGB color
+func nearestANSI256(r, g, b int) int {
+	nearestLevel := func(v int) int {
+		best := 0
+		for i, level := range cubeLevels {
+			if abs(v-level) < abs(v-cubeLevels[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+	ri, gi, bi := nearestLevel(r), nearestLevel(g), nearestLevel(b)
+	cube := 16 + 36*ri + 6*gi + bi
+	cubeDist := colorDistance(r, g, b, cubeLevels[ri], cubeLevels[gi], cubeLevels[bi])
+
+	// The grayscale ramp (232-255) runs from 8 to 238 in steps of 10
+	grayStep := (r+g+b)/3 - 8
+	grayStep = max(0, min(23, (grayStep+5)/10))
+	gray := 8 + 10*grayStep
+	if colorDistance(r, g, b, gray, gray, gray) < cubeDist {
+		return 232 + grayStep
+	}
+	return cube
+}
+
+// colorDistance returns a perceptually weighted squared distance between two RGB colors
+func colorDistance(r1, g1, b1, r2, g2, b2 int) int {
+	dr, dg, db := r1-r2, g1-g2, b1-b2
+	return 2*dr*dr + 4*dg*dg + 3*db*db
+}
+
+// nearestANSI16 maps an RGB color to a basic ANSI color by hue, so distinct hues stay
+// distinct whatever palette the terminal assigns to its 16 colors
+func nearestANSI16(r, g, b int) int {
+	rf, gf, bf := float64(r)/255, float64(g)/255, float64(b)/255
+	hi, lo := math.Max(rf, math.Max(gf, bf)), math.Min(rf, math.Min(gf, bf))
+	lightness := (hi + lo) / 2
+
+	// Grays: black, bright black, white, or bright white by lightness
+	if hi-lo < 0.15 {
+		switch {
+		case lightness < 0.2:
+			return 0
+		case lightness < 0.55:
+			return 8
+		case lightness < 0.85:
+			return 7
+		default:
+			return 15
+		}
+	}
+
+	var hue float64
+	switch hi {
+	case rf:
+		hue = math.Mod((gf-bf)/(hi-lo), 6)
+	case gf:
+		hue = (bf-rf)/(hi-lo) + 2
+	default:
+		hue = (rf-gf)/(hi-lo) + 4
+	}
+	hue = math.Mod(hue*60+360, 360)
+
+	var base int
+	switch {
+	case hue < 20 || hue >= 340:
+		base = 1 // Red
+	case hue < 45:
+		return 3 // Orange and amber render closest as dark yellow
+	case hue < 70:
+		base = 3 // Yellow
+	case hue < 160:
+		base = 2 // Green
+	case hue < 200:
+		base = 6 // Cyan
+	case hue < 255:
+		base = 4 // Blue
+	default:
+		base = 5 // Magenta
+	}
+	if lightness >= 0.5 {
+		return base + 8
+	}
+	return base
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,121 @@
+package lifecycle
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Definitions returns the registry's current colors, icons, and status codes, including
+// the defaults, as ColorDefinitions
+func (r *ColorRegistry) Definitions() *ColorDefinitions {
+	snap := r.Snapshot()
+	return &ColorDefinitions{
+		APIs:        snap.apiColors,
+		Events:      snap.eventColors,
+		Services:    snap.serviceColors,
+		Icons:       snap.eventIcons,
+		Statuses:    snap.statusColors,
+		StatusCodes: snap.statusCodes,
+		AutoPalette: snap.autoPalette,
+	}
+}
+
+// MarshalJSON encodes the registry's definitions, with keys sorted so the output can be
+// version-controlled
+func (r *ColorRegistry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.Definitions())
+}
+
+// UnmarshalJSON registers the decoded definitions, overwriting existing registrations for
+// the same keys
+func (r *ColorRegistry) UnmarshalJSON(data []byte) error {
+	var defs ColorDefinitions
+	if err := json.Unmarshal(data, &defs); err != nil {
+		return err
+	}
+	r.initDefaults()
+	r.RegisterBulk(&defs)
+	return nil
+}
+
+// MarshalYAML encodes the registry's definitions
+func (r *ColorRegistry) MarshalYAML() (interface{}, error) {
+	return r.Definitions(), nil
+}
+
+// UnmarshalYAML registers the decoded definitions, overwriting existing registrations for
+// the same keys
+func (r *ColorRegistry) UnmarshalYAML(value *yaml.Node) error {
+	var defs ColorDefinitions
+	if err := value.Decode(&defs); err != nil {
+		return err
+	}
+	r.initDefaults()
+	r.RegisterBulk(&defs)
+	return nil
+}
+
+// initDefaults gives a zero ColorRegistry the defaults NewColorRegistry would
+func (r *ColorRegistry) initDefaults() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.serviceColors != nil {
+		return
+	}
+	r.serviceColors = make(map[string]string)
+	r.apiColors = make(map[string]string)
+	r.eventColors = make(map[string]string)
+	r.statusColors = defaultStatusColors()
+	r.eventIcons = defaultEventIcons()
+	r.statusCodes = defaultStatusCodes()
+}
+
+// LoadColorRegistry creates a color registry from a JSON or YAML file written by
+// ColorRegistry.Save, so services and the CLI can share color assignments
+// Files ending in .yaml or .yml are read as YAML, others as JSON. Definitions in the file
+// are registered over the defaults and opts
+func LoadColorRegistry(path string, opts ...ColorRegistryOption) (*ColorRegistry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := NewColorRegistry(opts...)
+	if isYAMLFile(path) {
+		err = yaml.Unmarshal(data, r)
+	} else {
+		err = json.Unmarshal(data, r)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse color registry %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// Save writes the registry's definitions to path as YAML for .yaml and .yml files, and as
+// indented JSON otherwise
+func (r *ColorRegistry) Save(path string) error {
+	var data []byte
+	var err error
+	if isYAMLFile(path) {
+		data, err = yaml.Marshal(r)
+	} else {
+		data, err = json.MarshalIndent(r, "", "  ")
+		data = append(data, '\n')
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// isYAMLFile reports whether path has a YAML extension
+func isYAMLFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
@@ -1,5 +1,7 @@
 package lifecycle
 
+import "reflect"
+
 // ColorLoader provides utilities to load colors from API generator type definitions
 // This allows services to automatically use colors from their type/event annotations
 
@@ -17,45 +19,132 @@ package lifecycle
 //	    registry.RegisterEventColor(event, color)
 //	}
 type ColorDefinitions struct {
-	APIs     map[string]string // API type -> color (e.g., "examples.User" -> "#3B82F6")
-	Events   map[string]string // Event type -> color (e.g., "examples.OrderCreated" -> "#10B981")
-	Services map[string]string // Service name -> color (optional, can be set via config)
+	APIs        map[string]string `json:"apis,omitempty" yaml:"apis,omitempty"`                 // API type -> color (e.g., "examples.User" -> "#3B82F6")
+	Events      map[string]string `json:"events,omitempty" yaml:"events,omitempty"`             // Event type -> color (e.g., "examples.OrderCreated" -> "#10B981")
+	Services    map[string]string `json:"services,omitempty" yaml:"services,omitempty"`         // Service name -> color (optional, can be set via config)
+	Icons       map[string]string `json:"icons,omitempty" yaml:"icons,omitempty"`               // Event type or pattern -> icon (optional, e.g., "db.*" -> "🗄️")
+	Statuses    map[string]string `json:"statuses,omitempty" yaml:"statuses,omitempty"`         // Status -> color (optional, e.g., "error" -> "#FF0000")
+	StatusCodes map[string]string `json:"status_codes,omitempty" yaml:"status_codes,omitempty"` // HTTP status code or class -> status (optional, e.g., "4xx" -> "warning")
+	AutoPalette []string          `json:"auto_palette,omitempty" yaml:"auto_palette,omitempty"` // Palette for auto-assigned colors (optional, see WithAutoColors)
 }
 
-// LoadColorsFromTypeDefinitions extracts colors from type definitions
-// This function would be implemented by integrating with the API generator's schema loader
-// For now, this is a placeholder that shows the expected interface
-//
-// In practice, this would:
-// 1. Load type files using the API generator's schema loader
-// 2. Extract color annotations from TypeSpec.Annotations
-// 3. Map type names to colors
-// 4. Return a ColorDefinitions struct
+// LoadColorsFromTypeDefinitions extracts the colors of type definitions: those of API
+// resources (Kind "Type") go to APIs and those of events (Kind "Event") to Events, keyed by
+// Spec.Type. typeFiles is a slice of TypeDefinition values (see LoadTypeDefinitions) or of
+// the API generator's type files, or pointers to either; a color annotation takes
+// precedence over the deprecated Spec.Color field
 func LoadColorsFromTypeDefinitions(typeFiles interface{}) *ColorDefinitions {
-	// This is a placeholder - actual implementation would:
-	// 1. Iterate through typeFiles
-	// 2. Extract color from annotations: typeFile.Spec.Annotations (look for color annotation)
-	// 3. Map type name to color: typeFile.Spec.Type -> color
-	// 4. Determine if it's an API (Kind: "Type") or Event (Kind: "Event")
-
-	return &ColorDefinitions{
+	defs := &ColorDefinitions{
 		APIs:     make(map[string]string),
 		Events:   make(map[string]string),
 		Services: make(map[string]string),
 	}
+
+	files := reflect.ValueOf(typeFiles)
+	if files.Kind() != reflect.Slice && files.Kind() != reflect.Array {
+		return defs
+	}
+	for i := 0; i < files.Len(); i++ {
+		file := indirectValue(files.Index(i))
+		if file.Kind() != reflect.Struct {
+			continue
+		}
+		spec := indirectValue(file.FieldByName("Spec"))
+		if spec.Kind() != reflect.Struct {
+			continue
+		}
+		name := structString(spec, "Type")
+		if name == "" {
+			continue
+		}
+
+		color := ""
+		if annotations := spec.FieldByName("Annotations"); annotations.IsValid() {
+			color = ExtractColorFromAnnotations(annotations.Interface())
+		}
+		if color == "" {
+			color = structString(spec, "Color") // Deprecated field
+		}
+		if color == "" {
+			continue
+		}
+
+		if structString(file, "Kind") == "Event" {
+			defs.Events[name] = color
+		} else {
+			defs.APIs[name] = color
+		}
+	}
+	return defs
 }
 
-// ExtractColorFromAnnotations extracts color value from annotations
-// This matches the logic from the API generator's CLI
+// ExtractColorFromAnnotations returns the first color in a slice of annotations, as the
+// API generator's CLI does. Annotations are structs with a Color field or maps with a
+// "color" key, whose value is "#RRGGBB" or a map with a "value" or "color" key
 func ExtractColorFromAnnotations(annotations interface{}) string {
-	// This would need to match the annotation structure from the API generator
-	// For now, this is a placeholder
-
-	// Expected structure:
-	// annotations: []Annotation
-	// Annotation.Color can be:
-	//   - string: "#RRGGBB"
-	//   - map[string]interface{}: {"value": "#RRGGBB"} or {"color": "#RRGGBB"}
+	list := reflect.ValueOf(annotations)
+	if list.Kind() != reflect.Slice && list.Kind() != reflect.Array {
+		return ""
+	}
+	for i := 0; i < list.Len(); i++ {
+		annotation := indirectValue(list.Index(i))
+		var color reflect.Value
+		switch annotation.Kind() {
+		case reflect.Struct:
+			color = annotation.FieldByName("Color")
+		case reflect.Map:
+			if annotation.Type().Key().Kind() == reflect.String {
+				color = annotation.MapIndex(reflect.ValueOf("color").Convert(annotation.Type().Key()))
+			}
+		}
+		if !color.IsValid() || !color.CanInterface() {
+			continue
+		}
+		if value := colorValue(color.Interface()); value != "" {
+			return value
+		}
+	}
+	return ""
+}
 
+// colorValue returns a color annotation's value: a string, or a map with a "value" or
+// "color" key
+func colorValue(color interface{}) string {
+	switch v := color.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		if value, ok := v["value"].(string); ok {
+			return value
+		}
+		if value, ok := v["color"].(string); ok {
+			return value
+		}
+	case map[string]string:
+		if value, ok := v["value"]; ok {
+			return value
+		}
+		return v["color"]
+	}
 	return ""
 }
+
+// indirectValue follows pointers and interfaces to the value they hold
+func indirectValue(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// structString returns the named string field of struct v, or ""
+func structString(v reflect.Value, name string) string {
+	field := v.FieldByName(name)
+	if !field.IsValid() || field.Kind() != reflect.String {
+		return ""
+	}
+	return field.String()
+}
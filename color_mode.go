@@ -0,0 +1,105 @@
+package lifecycle
+
+import (
+	"io"
+	"os"
+
+	"github.com/mattn/go-isatty"
+	"github.com/muesli/termenv"
+)
+
+// ColorMode controls whether styled output emits ANSI colors
+type ColorMode int
+
+const (
+	// ColorModeAuto colors output only when writing to a terminal, honoring NO_COLOR,
+	// CLICOLOR_FORCE, and FORCE_COLOR
+	ColorModeAuto ColorMode = iota
+	// ColorModeAlways colors output even when redirected to a file or pipe
+	ColorModeAlways
+	// ColorModeNever always writes plain text
+	ColorModeNever
+)
+
+// String returns the name of the color mode
+func (m ColorMode) String() string {
+	switch m {
+	case ColorModeAlways:
+		return "always"
+	case ColorModeNever:
+		return "never"
+	default:
+		return "auto"
+	}
+}
+
+// resolveColorProfile determines the color profile to use for w under the given mode
+//
+// In auto mode the rules follow https://no-color.org, https://bixense.com/clicolors, and
+// https://force-color.org: a non-empty NO_COLOR disables colors, CLICOLOR_FORCE or
+// FORCE_COLOR (other than "0" or "false") forces them, and otherwise colors are used only
+// when w is a terminal. FORCE_COLOR levels 1, 2, and 3 select 16 colors, 256 colors, and
+// true color
+func resolveColorProfile(w io.Writer, mode ColorMode) termenv.Profile {
+	switch mode {
+	case ColorModeNever:
+		return termenv.Ascii
+	case ColorModeAlways:
+		return forcedColorProfile(w)
+	}
+
+	if os.Getenv("NO_COLOR") != "" {
+		return termenv.Ascii
+	}
+	if force := os.Getenv("CLICOLOR_FORCE"); force != "" && force != "0" {
+		return forcedColorProfile(w)
+	}
+	switch force := os.Getenv("FORCE_COLOR"); force {
+	case "", "0", "false":
+	case "1":
+		return termenv.ANSI
+	case "2":
+		return termenv.ANSI256
+	case "3":
+		return termenv.TrueColor
+	default:
+		return forcedColorProfile(w)
+	}
+	if !isTerminal(w) {
+		return termenv.Ascii
+	}
+	return termenv.NewOutput(w).ColorProfile()
+}
+
+// forcedColorProfile returns the terminal's profile when w is a terminal, or TrueColor otherwise
+func forcedColorProfile(w io.Writer) termenv.Profile {
+	if isTerminal(w) {
+		if profile := termenv.NewOutput(w).ColorProfile(); profile != termenv.Ascii {
+			return profile
+		}
+	}
+	return termenv.TrueColor
+}
+
+// enableTerminalColors prepares the terminal behind w for ANSI sequences, returning Ascii
+// when it cannot display them
+// Windows consoles need virtual terminal processing, which is enabled for the rest of the
+// process; legacy consoles that refuse it get plain text rather than raw escape codes
+func enableTerminalColors(w io.Writer, profile termenv.Profile) termenv.Profile {
+	if profile == termenv.Ascii || !isTerminal(w) {
+		return profile
+	}
+	if _, err := termenv.EnableVirtualTerminalProcessing(termenv.NewOutput(w)); err != nil {
+		return termenv.Ascii
+	}
+	return profile
+}
+
+// isTerminal reports whether w is a terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(interface{ Fd() uintptr })
+	if !ok {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
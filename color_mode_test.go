@@ -0,0 +1,98 @@
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/muesli/termenv"
+)
+
+func TestResolveColorProfile(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		mode     ColorMode
+		noColor  string
+		clicolor string
+		force    string
+		want     termenv.Profile
+	}{
+		{name: "auto, not a terminal", want: termenv.Ascii},
+		{name: "NO_COLOR", noColor: "1", want: termenv.Ascii},
+		{name: "NO_COLOR beats FORCE_COLOR", noColor: "1", force: "3", want: termenv.Ascii},
+		{name: "CLICOLOR_FORCE", clicolor: "1", want: termenv.TrueColor},
+		{name: "CLICOLOR_FORCE=0", clicolor: "0", want: termenv.Ascii},
+		{name: "FORCE_COLOR", force: "true", want: termenv.TrueColor},
+		{name: "FORCE_COLOR=0", force: "0", want: termenv.Ascii},
+		{name: "FORCE_COLOR=false", force: "false", want: termenv.Ascii},
+		{name: "FORCE_COLOR=1", force: "1", want: termenv.ANSI},
+		{name: "FORCE_COLOR=2", force: "2", want: termenv.ANSI256},
+		{name: "FORCE_COLOR=3", force: "3", want: termenv.TrueColor},
+		{name: "always", mode: ColorModeAlways, want: termenv.TrueColor},
+		{name: "always ignores NO_COLOR", mode: ColorModeAlways, noColor: "1", want: termenv.TrueColor},
+		{name: "never ignores FORCE_COLOR", mode: ColorModeNever, force: "3", want: termenv.Ascii},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tt.noColor)
+			t.Setenv("CLICOLOR_FORCE", tt.clicolor)
+			t.Setenv("FORCE_COLOR", tt.force)
+			if got := resolveColorProfile(&bytes.Buffer{}, tt.mode); got != tt.want {
+				t.Errorf("resolveColorProfile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestColorProfileNotTerminal checks that files that are not terminals, such as pipes,
+// get plain text in auto mode and keep their profile when colors are enabled
+func TestColorProfileNotTerminal(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("CLICOLOR_FORCE", "")
+	t.Setenv("FORCE_COLOR", "")
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	if isTerminal(w) {
+		t.Fatal("isTerminal(pipe) = true")
+	}
+	if got := resolveColorProfile(w, ColorModeAuto); got != termenv.Ascii {
+		t.Errorf("resolveColorProfile(pipe) = %v, want Ascii", got)
+	}
+	if got := enableTerminalColors(w, termenv.TrueColor); got != termenv.TrueColor {
+		t.Errorf("enableTerminalColors(pipe) = %v, want TrueColor", got)
+	}
+}
+
+// TestStyledOutputColorMode checks that styled output binds colors to its own writer
+func TestStyledOutputColorMode(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("CLICOLOR_FORCE", "")
+	t.Setenv("FORCE_COLOR", "")
+
+	for _, tt := range []struct {
+		mode       ColorMode
+		wantEscape bool
+	}{
+		{ColorModeAuto, false},
+		{ColorModeAlways, true},
+		{ColorModeNever, false},
+	} {
+		t.Run(tt.mode.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			p := NewProducer("color", "localhost", WithSink(NewStyledOutput(&buf, WithColorMode(tt.mode))))
+			if err := p.EmitServiceStarted(context.Background(), "1.0.0", 1); err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Contains(buf.String(), "\x1b["); got != tt.wantEscape {
+				t.Errorf("output has ANSI escapes: %v, want %v\n%q", got, tt.wantEscape, buf.String())
+			}
+		})
+	}
+}
@@ -1,26 +1,89 @@
 package lifecycle
 
 import (
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+
 	"github.com/charmbracelet/lipgloss"
 )
 
 // ColorRegistry manages color mappings for services, APIs, events, and statuses
 // Colors come from type/event annotations in the API generator
+// It is safe for concurrent use; colors may be registered while events are rendered
 type ColorRegistry struct {
-	serviceColors map[string]string // service name -> color
-	apiColors     map[string]string // API type (e.g., "examples.User") -> color
-	eventColors   map[string]string // event type (e.g., "examples.OrderCreated") -> color
-	statusColors  map[string]string // status -> color (e.g., "success" -> green, "error" -> red)
+	mu            sync.RWMutex
+	serviceColors map[string]string             // service name -> color
+	apiColors     map[string]string             // API type (e.g., "examples.User") -> color
+	eventColors   map[string]string             // event type (e.g., "examples.OrderCreated") -> color
+	statusColors  map[string]string             // status -> color (e.g., "success" -> green, "error" -> red)
+	eventIcons    map[string]string             // event type or pattern (e.g., "db.*") -> icon
+	statusCodes   map[string]string             // HTTP status code or class (e.g., "404", "4xx") -> status
+	autoPalette   []string                      // Palette for auto-assigned colors (nil = disabled)
+	snapshot      atomic.Pointer[ColorSnapshot] // Cached snapshot, cleared on registration
+}
+
+// ColorRegistryOption configures the ColorRegistry
+type ColorRegistryOption func(*ColorRegistry)
+
+// DefaultAutoColorPalette is a curated set of visually distinct colors used for auto-assignment
+// Reds are deliberately excluded so auto-assigned colors are not mistaken for errors
+var DefaultAutoColorPalette = []string{
+	"#3B82F6", // Blue
+	"#10B981", // Emerald
+	"#F59E0B", // Amber
+	"#8B5CF6", // Violet
+	"#EC4899", // Pink
+	"#14B8A6", // Teal
+	"#F97316", // Orange
+	"#6366F1", // Indigo
+	"#84CC16", // Lime
+	"#06B6D4", // Cyan
+	"#A855F7", // Purple
+	"#EAB308", // Yellow
+}
+
+// WithAutoColors assigns stable colors to services, APIs, and events that have no registered color
+// The color is chosen by hashing the name into the palette, so the same name always gets
+// the same color across processes. If no palette is given, DefaultAutoColorPalette is used
+func WithAutoColors(palette ...string) ColorRegistryOption {
+	return func(r *ColorRegistry) {
+		if len(palette) == 0 {
+			palette = DefaultAutoColorPalette
+		}
+		r.autoPalette = append([]string(nil), palette...)
+	}
+}
+
+// ColorSnapshot is an immutable point-in-time copy of a ColorRegistry
+// Lookups are lock-free, making it suitable for read-mostly hot paths
+// A nil snapshot returns no colors
+type ColorSnapshot struct {
+	serviceColors map[string]string
+	apiColors     map[string]string
+	eventColors   map[string]string
+	statusColors  map[string]string
+	eventIcons    map[string]string
+	statusCodes   map[string]string
+	autoPalette   []string
 }
 
 // NewColorRegistry creates a new color registry
-func NewColorRegistry() *ColorRegistry {
-	return &ColorRegistry{
+func NewColorRegistry(opts ...ColorRegistryOption) *ColorRegistry {
+	r := &ColorRegistry{
 		serviceColors: make(map[string]string),
 		apiColors:     make(map[string]string),
 		eventColors:   make(map[string]string),
 		statusColors:  defaultStatusColors(),
+		eventIcons:    defaultEventIcons(),
+		statusCodes:   defaultStatusCodes(),
+	}
+
+	for _, opt := range opts {
+		opt(r)
 	}
+
+	return r
 }
 
 // defaultStatusColors returns default colors for common statuses
@@ -43,41 +106,61 @@ func defaultStatusColors() map[string]string {
 
 // RegisterServiceColor registers a color for a service
 func (r *ColorRegistry) RegisterServiceColor(service, color string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.serviceColors[service] = color
+	r.snapshot.Store(nil)
 }
 
 // RegisterAPIColor registers a color for an API type
 func (r *ColorRegistry) RegisterAPIColor(api, color string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.apiColors[api] = color
+	r.snapshot.Store(nil)
 }
 
 // RegisterEventColor registers a color for an event type
 func (r *ColorRegistry) RegisterEventColor(eventType, color string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.eventColors[eventType] = color
+	r.snapshot.Store(nil)
 }
 
 // RegisterStatusColor registers a color for a status
 func (r *ColorRegistry) RegisterStatusColor(status, color string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.statusColors[status] = color
+	r.snapshot.Store(nil)
 }
 
 // GetServiceColor returns the color for a service, or empty string if not found
 func (r *ColorRegistry) GetServiceColor(service string) string {
-	return r.serviceColors[service]
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return lookupColor(r.serviceColors, service, r.autoPalette)
 }
 
 // GetAPIColor returns the color for an API, or empty string if not found
 func (r *ColorRegistry) GetAPIColor(api string) string {
-	return r.apiColors[api]
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return lookupColor(r.apiColors, api, r.autoPalette)
 }
 
 // GetEventColor returns the color for an event type, or empty string if not found
 func (r *ColorRegistry) GetEventColor(eventType string) string {
-	return r.eventColors[eventType]
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return lookupColor(r.eventColors, eventType, r.autoPalette)
 }
 
 // GetStatusColor returns the color for a status, or default if not found
 func (r *ColorRegistry) GetStatusColor(status string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	if color, ok := r.statusColors[status]; ok {
 		return color
 	}
@@ -85,6 +168,128 @@ func (r *ColorRegistry) GetStatusColor(status string) string {
 	return "#808080"
 }
 
+// RegisterBulk registers all colors from a set of color definitions
+// Existing registrations for the same keys are overwritten
+func (r *ColorRegistry) RegisterBulk(defs *ColorDefinitions) {
+	if defs == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for service, color := range defs.Services {
+		r.serviceColors[service] = color
+	}
+	for api, color := range defs.APIs {
+		r.apiColors[api] = color
+	}
+	for eventType, color := range defs.Events {
+		r.eventColors[eventType] = color
+	}
+	for eventType, icon := range defs.Icons {
+		r.eventIcons[eventType] = icon
+	}
+	for status, color := range defs.Statuses {
+		r.statusColors[status] = color
+	}
+	for code, status := range defs.StatusCodes {
+		r.statusCodes[code] = status
+	}
+	if len(defs.AutoPalette) > 0 {
+		r.autoPalette = append([]string(nil), defs.AutoPalette...)
+	}
+	r.snapshot.Store(nil)
+}
+
+// Snapshot returns an immutable copy of the registry's current colors
+// The snapshot is cached until the next registration, so repeated calls are cheap
+func (r *ColorRegistry) Snapshot() *ColorSnapshot {
+	if snap := r.snapshot.Load(); snap != nil {
+		return snap
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	snap := &ColorSnapshot{
+		serviceColors: copyColors(r.serviceColors),
+		apiColors:     copyColors(r.apiColors),
+		eventColors:   copyColors(r.eventColors),
+		statusColors:  copyColors(r.statusColors),
+		eventIcons:    copyColors(r.eventIcons),
+		statusCodes:   copyColors(r.statusCodes),
+		autoPalette:   r.autoPalette,
+	}
+	r.snapshot.Store(snap)
+	return snap
+}
+
+// copyColors returns a shallow copy of a color map
+func copyColors(colors map[string]string) map[string]string {
+	copied := make(map[string]string, len(colors))
+	for key, color := range colors {
+		copied[key] = color
+	}
+	return copied
+}
+
+// lookupColor returns the registered color for name, falling back to an auto-assigned
+// palette color when auto colors are enabled
+func lookupColor(colors map[string]string, name string, palette []string) string {
+	if color, ok := colors[name]; ok {
+		return color
+	}
+	if len(palette) == 0 || name == "" {
+		return ""
+	}
+	return AutoColor(name, palette)
+}
+
+// AutoColor deterministically picks a color for name from the palette
+func AutoColor(name string, palette []string) string {
+	if len(palette) == 0 {
+		return ""
+	}
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return palette[h.Sum32()%uint32(len(palette))]
+}
+
+// GetServiceColor returns the color for a service, or empty string if not found
+func (s *ColorSnapshot) GetServiceColor(service string) string {
+	if s == nil {
+		return ""
+	}
+	return lookupColor(s.serviceColors, service, s.autoPalette)
+}
+
+// GetAPIColor returns the color for an API, or empty string if not found
+func (s *ColorSnapshot) GetAPIColor(api string) string {
+	if s == nil {
+		return ""
+	}
+	return lookupColor(s.apiColors, api, s.autoPalette)
+}
+
+// GetEventColor returns the color for an event type, or empty string if not found
+func (s *ColorSnapshot) GetEventColor(eventType string) string {
+	if s == nil {
+		return ""
+	}
+	return lookupColor(s.eventColors, eventType, s.autoPalette)
+}
+
+// GetStatusColor returns the color for a status, or default if not found
+func (s *ColorSnapshot) GetStatusColor(status string) string {
+	if s == nil {
+		return ""
+	}
+	if color, ok := s.statusColors[status]; ok {
+		return color
+	}
+	// Default to gray for unknown statuses
+	return "#808080"
+}
+
 // GetColorStyle returns a lipgloss style with the given color
 // Handles hex colors (#RRGGBB) and named colors
 func GetColorStyle(color string) lipgloss.Style {
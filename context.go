@@ -0,0 +1,67 @@
+package lifecycle
+
+import "context"
+
+// contextKey is the type of context keys defined by this package
+type contextKey int
+
+const (
+	correlationIDKey contextKey = iota
+	actorKey
+	tenantKey
+	requestPathKey
+	routeKey
+	metadataKey
+)
+
+// ContextWithCorrelationID returns a context carrying the correlation ID for events
+// emitted with it
+func ContextWithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return context.WithValue(ctx, correlationIDKey, correlationID)
+}
+
+// ContextWithActor returns a context carrying the actor for events emitted with it
+func ContextWithActor(ctx context.Context, actor *Actor) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+// ContextWithTenant returns a context carrying the tenant for events emitted with it
+func ContextWithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey, tenant)
+}
+
+// ContextWithRequestPath returns a context carrying the HTTP request path, so events
+// without a path field (such as api.request.handled) can be matched by sampling rules
+func ContextWithRequestPath(ctx context.Context, path string) context.Context {
+	return context.WithValue(ctx, requestPathKey, path)
+}
+
+// CorrelationIDFromContext returns the correlation ID carried by ctx, if any
+// The untyped "correlation_id" key is also accepted for compatibility
+func CorrelationIDFromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(correlationIDKey).(string); ok {
+		return id
+	}
+	if id, ok := ctx.Value("correlation_id").(string); ok {
+		return id
+	}
+	return ""
+}
+
+// ActorFromContext returns the actor carried by ctx, or nil
+func ActorFromContext(ctx context.Context) *Actor {
+	actor, _ := ctx.Value(actorKey).(*Actor)
+	return actor
+}
+
+// TenantFromContext returns the tenant carried by ctx, if any
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey).(string)
+	return tenant
+}
+
+// RequestPathFromContext returns the HTTP request path carried by ctx, if any
+func RequestPathFromContext(ctx context.Context) string {
+	path, _ := ctx.Value(requestPathKey).(string)
+	return path
+}
@@ -0,0 +1,158 @@
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// DefaultDatadogURL is the logs intake of the US1 Datadog site
+const DefaultDatadogURL = "https://http-intake.logs.datadoghq.com"
+
+// DatadogWriter sends batches of events to the Datadog logs intake API (v2)
+// Each event becomes a log with ddsource, ddtags, service, and hostname set from the event,
+// a status from its level, and the event's fields as attributes. Trace and span IDs in the
+// metadata (see TraceIDHook) are converted to dd.trace_id and dd.span_id, so logs link to
+// APM traces. Requests answered with 429 or 503 are retried with backoff
+// The intake accepts up to 1000 logs per request, so keep the batch size at or below that
+type DatadogWriter struct {
+	URL    string       // Intake URL for the Datadog site (default: DefaultDatadogURL)
+	APIKey string       // Default: DD_API_KEY
+	Client *http.Client // Default: http.DefaultClient
+	Header http.Header  // Added to every request
+	Source string       // ddsource (default: "lifecycle")
+	Tags   []string     // Added to every log's ddtags (e.g., "env:prod")
+	Retry  RetryPolicy  // Backoff for throttled requests (zero: DefaultRetryPolicy)
+}
+
+// NewDatadogSink creates a batch sink sending events to Datadog with apiKey
+// For sites other than US1, configure a DatadogWriter with the site's intake URL
+func NewDatadogSink(apiKey string, opts ...BatchOption) *BatchSink {
+	return NewBatchSink(&DatadogWriter{APIKey: apiKey}, append([]BatchOption{WithBatchName("datadog")}, opts...)...)
+}
+
+// WriteBatch sends events in one request
+func (w *DatadogWriter) WriteBatch(ctx context.Context, events []Event) error {
+	logs := make([]map[string]interface{}, 0, len(events))
+	for _, event := range events {
+		entry, err := w.log(event)
+		if err != nil {
+			return err
+		}
+		logs = append(logs, entry)
+	}
+	body, err := json.Marshal(logs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal batch: %w", err)
+	}
+
+	endpoint := strings.TrimSuffix(firstNonEmpty(w.URL, DefaultDatadogURL), "/") + "/api/v2/logs"
+	apiKey := firstNonEmpty(w.APIKey, os.Getenv("DD_API_KEY"))
+	status, resp, err := doWithRetry(ctx, w.Client, w.Retry, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		for key, values := range w.Header {
+			req.Header[key] = values
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("DD-API-KEY", apiKey)
+		InjectTraceContext(ctx, propagation.HeaderCarrier(req.Header))
+		return req, nil
+	})
+	if err != nil {
+		return err
+	}
+	if status < 200 || status > 299 {
+		return fmt.Errorf("unexpected status %d: %s", status, truncateBody(resp))
+	}
+	return nil
+}
+
+// log converts event to a Datadog log
+func (w *DatadogWriter) log(event Event) (map[string]interface{}, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event: %w", err)
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	eventType := event.GetEventType()
+	tags := append([]string{
+		"event_type:" + eventType,
+		"event_family:" + eventFamily(eventType),
+	}, w.Tags...)
+	if api := event.GetAPI(); api != "" {
+		tags = append(tags, "api:"+api)
+	}
+
+	message := eventType
+	if logEvent, ok := event.(*LogMessageEvent); ok && logEvent.Message != "" {
+		message = logEvent.Message
+	}
+
+	entry["ddsource"] = firstNonEmpty(w.Source, "lifecycle")
+	entry["ddtags"] = strings.Join(tags, ",")
+	entry["service"] = event.GetService()
+	entry["hostname"] = event.GetHost()
+	entry["message"] = message
+	entry["status"] = datadogStatus(EventLevel(event))
+	entry["timestamp"] = event.GetTimestamp().UnixMilli()
+	if id := event.GetCorrelationID(); id != "" {
+		entry["correlation_id"] = id
+	}
+
+	metadata := event.GetMetadata()
+	if traceID, ok := datadogID(metadata["trace_id"], 16); ok {
+		entry["dd.trace_id"] = traceID
+	}
+	if spanID, ok := datadogID(metadata["span_id"], 8); ok {
+		entry["dd.span_id"] = spanID
+	}
+	return entry, nil
+}
+
+// datadogStatus returns the log status for a level
+func datadogStatus(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return "error"
+	case level >= slog.LevelWarn:
+		return "warning"
+	case level >= slog.LevelInfo:
+		return "info"
+	default:
+		return "debug"
+	}
+}
+
+// datadogID converts a hex OpenTelemetry ID of size bytes to the decimal form Datadog uses,
+// the lower 64 bits of the ID
+func datadogID(value interface{}, size int) (string, bool) {
+	id, ok := value.(string)
+	if !ok || len(id) != size*2 {
+		return "", false
+	}
+	raw, err := hex.DecodeString(id)
+	if err != nil {
+		return "", false
+	}
+	var n uint64
+	for _, b := range raw[size-8:] {
+		n = n<<8 | uint64(b)
+	}
+	return strconv.FormatUint(n, 10), true
+}
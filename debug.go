@@ -0,0 +1,213 @@
+package lifecycle
+
+import (
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultDebugEvents is the number of recent events DebugHandler shows by default
+const defaultDebugEvents = 500
+
+// eventRing keeps snapshots of the most recent events
+// Events are encoded when added: the producer goes on to sign the event it emitted, so
+// the ring must not share it with DebugHandler
+type eventRing struct {
+	mu     sync.Mutex
+	events []debugEntry // Event holds the unredacted encoding
+	next   int          // Index the next event is written to
+	full   bool         // Whether the ring has wrapped
+}
+
+// newEventRing creates a ring holding size events
+func newEventRing(size int) *eventRing {
+	return &eventRing{events: make([]debugEntry, max(size, 1))}
+}
+
+// add records a snapshot of event, replacing the oldest once the ring is full
+func (r *eventRing) add(event Event) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	entry := debugEntry{
+		Timestamp:     event.GetTimestamp(),
+		EventType:     event.GetEventType(),
+		CorrelationID: event.GetCorrelationID(),
+		Event:         data,
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events[r.next] = entry
+	r.next = (r.next + 1) % len(r.events)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// recent returns the recorded events, newest first
+func (r *eventRing) recent() []debugEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := r.next
+	if r.full {
+		n = len(r.events)
+	}
+	events := make([]debugEntry, 0, n)
+	for i := 1; i <= n; i++ {
+		events = append(events, r.events[(r.next-i+len(r.events))%len(r.events)])
+	}
+	return events
+}
+
+// WithDebugEvents keeps the last size events for DebugHandler from the start, rather than
+// from the first DebugHandler call, and sets how many are kept (default: 500)
+func WithDebugEvents(size int) ProducerOption {
+	return func(p *Producer) {
+		p.debugEvents.Store(newEventRing(size))
+	}
+}
+
+// DebugHandler serves the producer's recent events, newest first, as an HTML page or, for
+// "?format=json" or an Accept header of application/json, as JSON: a /debug/pprof for
+// events, to mount at a path such as /debug/lifecycle
+// Events are kept from the first call (or from the start with WithDebugEvents). They can
+// be filtered with the query parameters type (an event type or path.Match pattern, e.g.,
+// "db.*"), correlation_id, and limit. PII is always redacted, whatever the producer's own
+// redaction settings
+func (p *Producer) DebugHandler() http.Handler {
+	p.debugEvents.CompareAndSwap(nil, newEventRing(defaultDebugEvents))
+	ring := p.debugEvents.Load()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		filter := debugFilter{
+			EventType:     query.Get("type"),
+			CorrelationID: query.Get("correlation_id"),
+		}
+		if limit := query.Get("limit"); limit != "" {
+			n, err := strconv.Atoi(limit)
+			if err != nil || n < 0 {
+				http.Error(w, "invalid limit", http.StatusBadRequest)
+				return
+			}
+			filter.Limit = n
+		}
+
+		var entries []debugEntry
+		for _, entry := range ring.recent() {
+			if filter.Limit > 0 && len(entries) >= filter.Limit {
+				break
+			}
+			if !filter.matches(entry) {
+				continue
+			}
+			data, err := p.debugJSON(entry.Event)
+			if err != nil {
+				continue
+			}
+			entry.Event = data
+			entries = append(entries, entry)
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		if query.Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json") {
+			events := make([]json.RawMessage, len(entries))
+			for i, entry := range entries {
+				events[i] = entry.Event
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"events": events})
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = debugHTMLTemplate.Execute(w, debugPage{Filter: filter, Entries: entries})
+	})
+}
+
+// debugJSON returns an encoded event with PII redacted
+func (p *Producer) debugJSON(data json.RawMessage) (json.RawMessage, error) {
+	var redacted bytes.Buffer
+	if err := p.redactor.RedactJSON(bytes.NewReader(data), &redacted, p.piiDetector); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSpace(redacted.Bytes()), nil
+}
+
+// debugFilter selects the events DebugHandler shows
+type debugFilter struct {
+	EventType     string
+	CorrelationID string
+	Limit         int // 0: all
+}
+
+// matches reports whether an event passes the filter
+func (f debugFilter) matches(entry debugEntry) bool {
+	if f.CorrelationID != "" && entry.CorrelationID != f.CorrelationID {
+		return false
+	}
+	return f.EventType == "" || matchPattern(f.EventType, entry.EventType)
+}
+
+// debugEntry is one event on the debug page
+type debugEntry struct {
+	Timestamp     time.Time
+	EventType     string
+	CorrelationID string
+	Event         json.RawMessage // Redacted on the page
+}
+
+// debugPage is the data rendered by debugHTMLTemplate
+type debugPage struct {
+	Filter  debugFilter
+	Entries []debugEntry
+}
+
+// debugHTMLTemplate renders recent events as a standalone HTML page
+var debugHTMLTemplate = template.Must(template.New("debug").Funcs(template.FuncMap{
+	"rfc3339": func(t time.Time) string { return t.Format(time.RFC3339Nano) },
+	"indent": func(data json.RawMessage) string {
+		var b bytes.Buffer
+		if err := json.Indent(&b, data, "", "  "); err != nil {
+			return string(data)
+		}
+		return b.String()
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Lifecycle Events</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; margin: 2rem; color: #1f2937; }
+form { margin-bottom: 1.5rem; }
+input { margin-right: 0.75rem; }
+details { border-bottom: 1px solid #e5e7eb; padding: 0.35rem 0; }
+summary { cursor: pointer; font-variant-numeric: tabular-nums; }
+pre { background: #f3f4f6; padding: 0.75rem; overflow-x: auto; }
+code { font-size: 0.9em; }
+</style>
+</head>
+<body>
+<h1>Lifecycle Events</h1>
+<form method="get">
+<label>Type <input name="type" value="{{.Filter.EventType}}" placeholder="db.*"></label>
+<label>Correlation ID <input name="correlation_id" value="{{.Filter.CorrelationID}}"></label>
+<label>Limit <input name="limit" type="number" min="0" value="{{if .Filter.Limit}}{{.Filter.Limit}}{{end}}"></label>
+<button type="submit">Filter</button> <a href="?">Clear</a> &middot; <a href="?type={{.Filter.EventType}}&amp;correlation_id={{.Filter.CorrelationID}}&amp;format=json">JSON</a>
+</form>
+<p>{{len .Entries}} events, newest first</p>
+{{range .Entries}}<details>
+<summary>{{rfc3339 .Timestamp}} <code>{{.EventType}}</code>{{if .CorrelationID}} &middot; <a href="?correlation_id={{.CorrelationID}}">{{.CorrelationID}}</a>{{end}}</summary>
+<pre><code>{{indent .Event}}</code></pre>
+</details>
+{{else}}<p>No events recorded.</p>{{end}}
+</body>
+</html>
+`))
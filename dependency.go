@@ -0,0 +1,204 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultProbeTimeout is the timeout for dependency probes that do not set their own
+const DefaultProbeTimeout = 5 * time.Second
+
+// DependencyProbe checks connectivity to one dependency
+type DependencyProbe struct {
+	Name    string        // Dependency name (e.g., "postgres", "user-service")
+	Kind    string        // Optional: dependency kind (e.g., "database", "cache", "http")
+	Target  string        // Optional: address or URL, for diagnostics
+	Timeout time.Duration // Default: DefaultProbeTimeout
+	Check   func(ctx context.Context) error
+}
+
+// PingProbe creates a probe from a ping function, such as (*sql.DB).PingContext or a
+// Redis client's Ping
+func PingProbe(name, kind string, ping func(ctx context.Context) error) DependencyProbe {
+	return DependencyProbe{Name: name, Kind: kind, Check: ping}
+}
+
+// TCPProbe creates a probe that succeeds if a TCP connection to addr can be opened
+func TCPProbe(name, addr string) DependencyProbe {
+	return DependencyProbe{
+		Name:   name,
+		Kind:   "tcp",
+		Target: addr,
+		Check: func(ctx context.Context) error {
+			var d net.Dialer
+			conn, err := d.DialContext(ctx, "tcp", addr)
+			if err != nil {
+				return err
+			}
+			return conn.Close()
+		},
+	}
+}
+
+// HTTPProbe creates a probe that succeeds if a GET of url returns a non-5xx response
+func HTTPProbe(name, url string) DependencyProbe {
+	return DependencyProbe{
+		Name:   name,
+		Kind:   "http",
+		Target: url,
+		Check: func(ctx context.Context) error {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+			if err != nil {
+				return err
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return err
+			}
+			resp.Body.Close()
+			if resp.StatusCode >= 500 {
+				return fmt.Errorf("unexpected status %s", resp.Status)
+			}
+			return nil
+		},
+	}
+}
+
+// DependencyAvailableEvent represents a dependency.available event
+type DependencyAvailableEvent struct {
+	Base      *BaseEvent `json:"base"`
+	Name      string     `json:"name"`
+	Kind      string     `json:"kind,omitempty"`
+	Target    string     `json:"target,omitempty"`
+	Status    Status     `json:"status"`
+	LatencyMs int64      `json:"latency_ms"`
+}
+
+func (e *DependencyAvailableEvent) GetEventType() string     { return e.Base.GetEventType() }
+func (e *DependencyAvailableEvent) GetTimestamp() time.Time  { return e.Base.GetTimestamp() }
+func (e *DependencyAvailableEvent) GetService() string       { return e.Base.GetService() }
+func (e *DependencyAvailableEvent) GetAPI() string           { return e.Base.GetAPI() }
+func (e *DependencyAvailableEvent) GetHost() string          { return e.Base.GetHost() }
+func (e *DependencyAvailableEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *DependencyAvailableEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *DependencyAvailableEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *DependencyAvailableEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
+
+// DependencyUnavailableEvent represents a dependency.unavailable event
+type DependencyUnavailableEvent struct {
+	Base         *BaseEvent `json:"base"`
+	Name         string     `json:"name"`
+	Kind         string     `json:"kind,omitempty"`
+	Target       string     `json:"target,omitempty"`
+	Status       Status     `json:"status"`
+	ErrorMessage string     `json:"error_message"`
+	LatencyMs    int64      `json:"latency_ms"`
+}
+
+func (e *DependencyUnavailableEvent) GetEventType() string     { return e.Base.GetEventType() }
+func (e *DependencyUnavailableEvent) GetTimestamp() time.Time  { return e.Base.GetTimestamp() }
+func (e *DependencyUnavailableEvent) GetService() string       { return e.Base.GetService() }
+func (e *DependencyUnavailableEvent) GetAPI() string           { return e.Base.GetAPI() }
+func (e *DependencyUnavailableEvent) GetHost() string          { return e.Base.GetHost() }
+func (e *DependencyUnavailableEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *DependencyUnavailableEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *DependencyUnavailableEvent) GetMetadata() map[string]interface{} {
+	return e.Base.GetMetadata()
+}
+func (e *DependencyUnavailableEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
+
+// probeResult is the outcome of one dependency probe
+type probeResult struct {
+	err     error
+	latency time.Duration
+}
+
+// ProbeDependencies checks all probes concurrently, each with its own timeout, and emits
+// a dependency.available or dependency.unavailable event per probe, in the order given
+// It returns an error naming every unavailable dependency, so it is typically called at
+// startup before EmitServiceHealthy:
+//
+//	if err := producer.ProbeDependencies(ctx, probes...); err == nil {
+//		producer.EmitServiceHealthy(ctx, names)
+//	}
+func (p *Producer) ProbeDependencies(ctx context.Context, probes ...DependencyProbe) error {
+	results := runProbes(ctx, probes)
+
+	var errs []error
+	for i, probe := range probes {
+		result := results[i]
+		var event Event
+		if result.err == nil {
+			event = &DependencyAvailableEvent{
+				Base:      p.createBaseEvent("dependency.available", extractCorrelationID(ctx), nil),
+				Name:      probe.Name,
+				Kind:      probe.Kind,
+				Target:    probe.Target,
+				Status:    StatusSuccess,
+				LatencyMs: result.latency.Milliseconds(),
+			}
+		} else {
+			errs = append(errs, fmt.Errorf("dependency %s unavailable: %w", probe.Name, result.err))
+			event = &DependencyUnavailableEvent{
+				Base:         p.createBaseEvent("dependency.unavailable", extractCorrelationID(ctx), nil),
+				Name:         probe.Name,
+				Kind:         probe.Kind,
+				Target:       probe.Target,
+				Status:       StatusError,
+				ErrorMessage: result.err.Error(),
+				LatencyMs:    result.latency.Milliseconds(),
+			}
+		}
+		if err := p.emitEvent(ctx, event, result.latency); err != nil {
+			return err
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runProbes runs probes concurrently and returns their results in the same order
+func runProbes(ctx context.Context, probes []DependencyProbe) []probeResult {
+	results := make([]probeResult, len(probes))
+
+	var wg sync.WaitGroup
+	for i, probe := range probes {
+		wg.Add(1)
+		go func(i int, probe DependencyProbe) {
+			defer wg.Done()
+			results[i] = runProbe(ctx, probe)
+		}(i, probe)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// runProbe runs a single probe with its timeout
+func runProbe(ctx context.Context, probe DependencyProbe) probeResult {
+	if probe.Check == nil {
+		return probeResult{err: errors.New("probe has no check")}
+	}
+
+	timeout := probe.Timeout
+	if timeout <= 0 {
+		timeout = DefaultProbeTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := probe.Check(ctx)
+	return probeResult{err: err, latency: time.Since(start)}
+}
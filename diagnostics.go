@@ -0,0 +1,280 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// goroutineDumpChunkSize is the largest stack text carried by a single diagnostics.goroutines event
+// Larger dumps are split on goroutine boundaries across several events
+const goroutineDumpChunkSize = 64 * 1024
+
+// GoroutineDumpEvent represents a diagnostics.goroutines event
+// A dump too large for one event is split into chunks that share a DumpID
+type GoroutineDumpEvent struct {
+	Base       *BaseEvent `json:"base"`
+	DumpID     string     `json:"dump_id"`
+	Reason     string     `json:"reason"`
+	Goroutines int        `json:"goroutines"`
+	Chunk      int        `json:"chunk"`  // 1-based index of this chunk
+	Chunks     int        `json:"chunks"` // Total chunks in the dump
+	Stacks     string     `json:"stacks"`
+}
+
+func (e *GoroutineDumpEvent) GetEventType() string     { return e.Base.GetEventType() }
+func (e *GoroutineDumpEvent) GetTimestamp() time.Time  { return e.Base.GetTimestamp() }
+func (e *GoroutineDumpEvent) GetService() string       { return e.Base.GetService() }
+func (e *GoroutineDumpEvent) GetAPI() string           { return e.Base.GetAPI() }
+func (e *GoroutineDumpEvent) GetHost() string          { return e.Base.GetHost() }
+func (e *GoroutineDumpEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *GoroutineDumpEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *GoroutineDumpEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *GoroutineDumpEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
+
+// StyledFields keeps the stacks out of terminal output, which only summarizes the dump
+func (e *GoroutineDumpEvent) StyledFields() []interface{} {
+	return []interface{}{
+		"reason", e.Reason,
+		"goroutines", e.Goroutines,
+		"chunk", fmt.Sprintf("%d/%d", e.Chunk, e.Chunks),
+		"bytes", len(e.Stacks),
+	}
+}
+
+// EmitGoroutineDump captures the stacks of all goroutines and emits them as one or more
+// diagnostics.goroutines events
+// Useful when responding to SIGQUIT or investigating a suspected deadlock
+func (p *Producer) EmitGoroutineDump(ctx context.Context, reason string) error {
+	stacks := allGoroutineStacks()
+	chunks := chunkGoroutineStacks(stacks, goroutineDumpChunkSize)
+	dumpID := fmt.Sprintf("%x", time.Now().UnixNano())
+	goroutines := runtime.NumGoroutine()
+
+	for i, chunk := range chunks {
+		event := &GoroutineDumpEvent{
+			Base:       p.createBaseEvent("diagnostics.goroutines", extractCorrelationID(ctx), nil),
+			DumpID:     dumpID,
+			Reason:     reason,
+			Goroutines: goroutines,
+			Chunk:      i + 1,
+			Chunks:     len(chunks),
+			Stacks:     chunk,
+		}
+		if err := p.emitEvent(ctx, event, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// allGoroutineStacks returns the formatted stacks of all goroutines
+func allGoroutineStacks() string {
+	buf := make([]byte, 64*1024)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return string(buf[:n])
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+// chunkGoroutineStacks splits a dump into chunks of at most size bytes, breaking between
+// goroutines; a single goroutine larger than size is split mid-stack
+func chunkGoroutineStacks(stacks string, size int) []string {
+	var chunks []string
+	var current strings.Builder
+
+	for _, goroutine := range strings.SplitAfter(stacks, "\n\n") {
+		if current.Len() > 0 && current.Len()+len(goroutine) > size {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+		for len(goroutine) > size {
+			chunks = append(chunks, goroutine[:size])
+			goroutine = goroutine[size:]
+		}
+		current.WriteString(goroutine)
+	}
+	if current.Len() > 0 || len(chunks) == 0 {
+		chunks = append(chunks, current.String())
+	}
+	return chunks
+}
+
+// MemoryStatsEvent represents a diagnostics.memory event
+type MemoryStatsEvent struct {
+	Base            *BaseEvent `json:"base"`
+	Reason          string     `json:"reason"`
+	HeapAllocBytes  uint64     `json:"heap_alloc_bytes"`
+	HeapInuseBytes  uint64     `json:"heap_inuse_bytes"`
+	HeapObjects     uint64     `json:"heap_objects"`
+	StackInuseBytes uint64     `json:"stack_inuse_bytes"`
+	SysBytes        uint64     `json:"sys_bytes"`
+	TotalAllocBytes uint64     `json:"total_alloc_bytes"`
+	NumGC           uint32     `json:"num_gc"`
+	LastGC          *time.Time `json:"last_gc,omitempty"`
+	PauseTotalMs    float64    `json:"pause_total_ms"`
+	Goroutines      int        `json:"goroutines"`
+}
+
+func (e *MemoryStatsEvent) GetEventType() string     { return e.Base.GetEventType() }
+func (e *MemoryStatsEvent) GetTimestamp() time.Time  { return e.Base.GetTimestamp() }
+func (e *MemoryStatsEvent) GetService() string       { return e.Base.GetService() }
+func (e *MemoryStatsEvent) GetAPI() string           { return e.Base.GetAPI() }
+func (e *MemoryStatsEvent) GetHost() string          { return e.Base.GetHost() }
+func (e *MemoryStatsEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *MemoryStatsEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *MemoryStatsEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *MemoryStatsEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
+
+// EmitMemoryStats emits a diagnostics.memory event with the runtime's memory statistics
+func (p *Producer) EmitMemoryStats(ctx context.Context, reason string) error {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	event := &MemoryStatsEvent{
+		Base:            p.createBaseEvent("diagnostics.memory", extractCorrelationID(ctx), nil),
+		Reason:          reason,
+		HeapAllocBytes:  m.HeapAlloc,
+		HeapInuseBytes:  m.HeapInuse,
+		HeapObjects:     m.HeapObjects,
+		StackInuseBytes: m.StackInuse,
+		SysBytes:        m.Sys,
+		TotalAllocBytes: m.TotalAlloc,
+		NumGC:           m.NumGC,
+		PauseTotalMs:    float64(m.PauseTotalNs) / float64(time.Millisecond),
+		Goroutines:      runtime.NumGoroutine(),
+	}
+	if m.LastGC > 0 {
+		lastGC := time.Unix(0, int64(m.LastGC))
+		event.LastGC = &lastGC
+	}
+	return p.emitEvent(ctx, event, 0)
+}
+
+// ConfigSnapshotEvent represents a diagnostics.config event
+// Config holds the application's own settings (see WithConfigSnapshot) and is redacted
+// like other event data
+type ConfigSnapshotEvent struct {
+	Base       *BaseEvent             `json:"base"`
+	Reason     string                 `json:"reason"`
+	GoVersion  string                 `json:"go_version"`
+	Version    string                 `json:"version,omitempty"` // Main module version from build info
+	GOMAXPROCS int                    `json:"gomaxprocs"`
+	NumCPU     int                    `json:"num_cpu"`
+	LogLevel   string                 `json:"log_level"`
+	Producer   map[string]interface{} `json:"producer"`
+	Config     map[string]interface{} `json:"config,omitempty"`
+}
+
+func (e *ConfigSnapshotEvent) GetEventType() string     { return e.Base.GetEventType() }
+func (e *ConfigSnapshotEvent) GetTimestamp() time.Time  { return e.Base.GetTimestamp() }
+func (e *ConfigSnapshotEvent) GetService() string       { return e.Base.GetService() }
+func (e *ConfigSnapshotEvent) GetAPI() string           { return e.Base.GetAPI() }
+func (e *ConfigSnapshotEvent) GetHost() string          { return e.Base.GetHost() }
+func (e *ConfigSnapshotEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *ConfigSnapshotEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *ConfigSnapshotEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *ConfigSnapshotEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
+
+func (e *ConfigSnapshotEvent) RedactPII(detector *PIIDetector, redactor *Redactor) {
+	if e.Config != nil {
+		e.Config = redactor.RedactMap(e.Config, detector)
+	}
+}
+
+// WithConfigSnapshot sets a function returning the application's configuration, included
+// in diagnostics.config events
+func WithConfigSnapshot(snapshot func() map[string]interface{}) ProducerOption {
+	return func(p *Producer) {
+		p.configSnapshot = snapshot
+	}
+}
+
+// EmitConfigSnapshot emits a diagnostics.config event describing the running process,
+// the producer's settings, and the application's configuration
+func (p *Producer) EmitConfigSnapshot(ctx context.Context, reason string) error {
+	event := &ConfigSnapshotEvent{
+		Base:       p.createBaseEvent("diagnostics.config", extractCorrelationID(ctx), nil),
+		Reason:     reason,
+		GoVersion:  runtime.Version(),
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		NumCPU:     runtime.NumCPU(),
+		LogLevel:   p.LogLevel().String(),
+		Producer: map[string]interface{}{
+			"service":           p.service,
+			"api":               p.api,
+			"host":              p.host,
+			"styled_output":     p.styled != nil,
+			"otel":              p.otel != nil,
+			"aggregator":        p.aggregator != nil,
+			"anomaly_detectors": len(p.anomalyDetectors),
+			"log_classifiers":   len(p.logClassifiers),
+			"max_stack_frames":  p.maxStackFrames,
+		},
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		event.Version = info.Main.Version
+	}
+	if p.configSnapshot != nil {
+		event.Config = p.configSnapshot()
+	}
+	return p.emitEvent(ctx, event, 0)
+}
+
+// RunSignalDiagnostics listens for diagnostic signals until ctx is done
+// On SIGUSR1 it emits a goroutine dump, memory statistics, and a config snapshot
+// On SIGUSR2 it toggles the producer's log level between debug and its previous level
+// and emits a config snapshot recording the change
+// It is opt-in and typically started in its own goroutine; on platforms without these
+// signals it simply waits for ctx
+func (p *Producer) RunSignalDiagnostics(ctx context.Context) {
+	if dumpSignal == nil || levelSignal == nil {
+		<-ctx.Done()
+		return
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, dumpSignal, levelSignal)
+	defer signal.Stop(signals)
+
+	previous := p.LogLevel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-signals:
+			reason := sig.String()
+			if sig == levelSignal {
+				if p.LogLevel() == slog.LevelDebug {
+					p.SetLogLevel(previous)
+				} else {
+					previous = p.LogLevel()
+					p.SetLogLevel(slog.LevelDebug)
+				}
+				_ = p.EmitConfigSnapshot(ctx, reason+": log level set to "+p.LogLevel().String())
+				continue
+			}
+			_ = p.EmitGoroutineDump(ctx, reason)
+			_ = p.EmitMemoryStats(ctx, reason)
+			_ = p.EmitConfigSnapshot(ctx, reason)
+		}
+	}
+}
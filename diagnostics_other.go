@@ -0,0 +1,11 @@
+//go:build !unix
+
+package lifecycle
+
+import "os"
+
+// Signals handled by RunSignalDiagnostics; this platform has no user-defined signals
+var (
+	dumpSignal  os.Signal
+	levelSignal os.Signal
+)
@@ -0,0 +1,14 @@
+//go:build unix
+
+package lifecycle
+
+import (
+	"os"
+	"syscall"
+)
+
+// Signals handled by RunSignalDiagnostics
+var (
+	dumpSignal  os.Signal = syscall.SIGUSR1
+	levelSignal os.Signal = syscall.SIGUSR2
+)
@@ -0,0 +1,185 @@
+package lifecycle
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// drainProgressInterval is how often service.draining is re-emitted while requests remain
+const drainProgressInterval = time.Second
+
+// DrainTracker counts in-flight requests so shutdown can wait for them and report progress
+// Requests are counted by its Middleware, or manually with Track
+// It is safe for concurrent use
+type DrainTracker struct {
+	producer *Producer
+
+	mu       sync.Mutex
+	inFlight int
+	draining bool
+	idle     chan struct{} // Closed when in-flight requests reach zero during a drain
+}
+
+// NewDrainTracker creates a drain tracker that emits through producer
+func NewDrainTracker(producer *Producer) *DrainTracker {
+	return &DrainTracker{producer: producer}
+}
+
+// Track records the start of a request and returns a function that records its end
+func (d *DrainTracker) Track() (done func()) {
+	d.mu.Lock()
+	d.inFlight++
+	d.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			d.mu.Lock()
+			defer d.mu.Unlock()
+			d.inFlight--
+			if d.inFlight == 0 && d.idle != nil {
+				close(d.idle)
+				d.idle = nil
+			}
+		})
+	}
+}
+
+// InFlight returns the number of requests currently in flight
+func (d *DrainTracker) InFlight() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.inFlight
+}
+
+// Draining reports whether Drain has been called
+func (d *DrainTracker) Draining() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.draining
+}
+
+// Middleware counts requests handled by next
+// Once draining has started, new requests are rejected with 503 and "Connection: close"
+// so clients and load balancers retry elsewhere
+func (d *DrainTracker) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if d.Draining() {
+			w.Header().Set("Connection", "close")
+			http.Error(w, "service is shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		done := d.Track()
+		defer done()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ServiceDrainingEvent represents a service.draining event
+type ServiceDrainingEvent struct {
+	Base      *BaseEvent `json:"base"`
+	InFlight  int        `json:"in_flight"`  // Requests still running
+	ElapsedMs int64      `json:"elapsed_ms"` // Time since the drain started
+}
+
+func (e *ServiceDrainingEvent) GetEventType() string     { return e.Base.GetEventType() }
+func (e *ServiceDrainingEvent) GetTimestamp() time.Time  { return e.Base.GetTimestamp() }
+func (e *ServiceDrainingEvent) GetService() string       { return e.Base.GetService() }
+func (e *ServiceDrainingEvent) GetAPI() string           { return e.Base.GetAPI() }
+func (e *ServiceDrainingEvent) GetHost() string          { return e.Base.GetHost() }
+func (e *ServiceDrainingEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *ServiceDrainingEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *ServiceDrainingEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *ServiceDrainingEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
+
+// ServiceDrainedEvent represents a service.drained event
+type ServiceDrainedEvent struct {
+	Base       *BaseEvent `json:"base"`
+	Status     Status     `json:"status"`    // error if the drain timed out
+	Abandoned  int        `json:"abandoned"` // Requests still running when the drain ended
+	DurationMs int64      `json:"duration_ms"`
+}
+
+func (e *ServiceDrainedEvent) GetEventType() string     { return e.Base.GetEventType() }
+func (e *ServiceDrainedEvent) GetTimestamp() time.Time  { return e.Base.GetTimestamp() }
+func (e *ServiceDrainedEvent) GetService() string       { return e.Base.GetService() }
+func (e *ServiceDrainedEvent) GetAPI() string           { return e.Base.GetAPI() }
+func (e *ServiceDrainedEvent) GetHost() string          { return e.Base.GetHost() }
+func (e *ServiceDrainedEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *ServiceDrainedEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *ServiceDrainedEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *ServiceDrainedEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
+
+// Drain stops new requests from being admitted by the middleware and waits for in-flight
+// requests to finish or ctx to be done
+// It emits service.draining with the remaining count when it starts and every second
+// while requests remain, then service.drained with the total drain duration
+// It returns ctx.Err() if requests were abandoned
+func (d *DrainTracker) Drain(ctx context.Context) error {
+	start := time.Now()
+
+	d.mu.Lock()
+	d.draining = true
+	var idle chan struct{}
+	if d.inFlight > 0 {
+		if d.idle == nil {
+			d.idle = make(chan struct{})
+		}
+		idle = d.idle
+	}
+	d.mu.Unlock()
+
+	_ = d.emitDraining(ctx, start)
+
+	var err error
+	if idle != nil {
+		ticker := time.NewTicker(drainProgressInterval)
+		defer ticker.Stop()
+	wait:
+		for {
+			select {
+			case <-idle:
+				break wait
+			case <-ctx.Done():
+				err = ctx.Err()
+				break wait
+			case <-ticker.C:
+				_ = d.emitDraining(ctx, start)
+			}
+		}
+	}
+
+	event := &ServiceDrainedEvent{
+		Base:       d.producer.createBaseEvent("service.drained", "", nil),
+		Status:     StatusSuccess,
+		Abandoned:  d.InFlight(),
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		event.Status = StatusError
+	}
+	// ctx may already be done, so emit with a context that is not
+	if emitErr := d.producer.emitEvent(context.WithoutCancel(ctx), event, time.Since(start)); err == nil {
+		err = emitErr
+	}
+	return err
+}
+
+// emitDraining emits a service.draining event with the current in-flight count
+func (d *DrainTracker) emitDraining(ctx context.Context, start time.Time) error {
+	event := &ServiceDrainingEvent{
+		Base:      d.producer.createBaseEvent("service.draining", "", nil),
+		InFlight:  d.InFlight(),
+		ElapsedMs: time.Since(start).Milliseconds(),
+	}
+	return d.producer.emitEvent(ctx, event, 0)
+}
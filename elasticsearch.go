@@ -0,0 +1,234 @@
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// DefaultElasticsearchIndexPrefix prefixes the indices ElasticsearchWriter writes to
+const DefaultElasticsearchIndexPrefix = "lifecycle"
+
+// ElasticsearchWriter indexes batches of events with the Elasticsearch or OpenSearch _bulk
+// API, into one index per event family and day (e.g., "lifecycle-db.query-2024.05.01")
+// Events the cluster rejects with 429 Too Many Requests, and whole requests answered with
+// 429 or 503, are retried with backoff; other rejected events are reported in the error
+type ElasticsearchWriter struct {
+	URL         string       // Cluster URL (e.g., "https://es.internal:9200")
+	Client      *http.Client // Default: http.DefaultClient
+	Header      http.Header  // Added to every request (e.g., Authorization)
+	IndexPrefix string       // Default: DefaultElasticsearchIndexPrefix
+	Retry       RetryPolicy  // Backoff for throttled requests and events (zero: DefaultRetryPolicy)
+
+	// Index overrides the index of an event (default: prefix-family-YYYY.MM.DD)
+	Index func(event Event) string
+}
+
+// NewElasticsearchSink creates a batch sink indexing events in the cluster at url
+// Install the mapping template once with ElasticsearchWriter.PutIndexTemplate
+func NewElasticsearchSink(url string, opts ...BatchOption) *BatchSink {
+	return NewBatchSink(&ElasticsearchWriter{URL: url}, append([]BatchOption{WithBatchName("elasticsearch")}, opts...)...)
+}
+
+// WriteBatch indexes events in one _bulk request, retrying throttled events
+func (w *ElasticsearchWriter) WriteBatch(ctx context.Context, events []Event) error {
+	type document struct {
+		index string
+		data  []byte
+	}
+	pending := make([]document, 0, len(events))
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event: %w", err)
+		}
+		pending = append(pending, document{index: w.index(event), data: data})
+	}
+
+	policy := w.Retry.withDefaults()
+	for attempt := 1; ; attempt++ {
+		var body bytes.Buffer
+		for _, doc := range pending {
+			fmt.Fprintf(&body, `{"create":{"_index":%q}}`+"\n", doc.index)
+			body.Write(doc.data)
+			body.WriteByte('\n')
+		}
+
+		status, resp, err := doWithRetry(ctx, w.Client, policy, func() (*http.Request, error) {
+			return w.newRequest(ctx, http.MethodPost, "/_bulk", body.Bytes(), "application/x-ndjson")
+		})
+		if err != nil {
+			return err
+		}
+		if status < 200 || status > 299 {
+			return fmt.Errorf("unexpected status %d: %s", status, truncateBody(resp))
+		}
+
+		var result bulkResponse
+		if err := json.Unmarshal(resp, &result); err != nil {
+			return fmt.Errorf("failed to decode bulk response: %w", err)
+		}
+		if !result.Errors {
+			return nil
+		}
+
+		// Keep only the events throttled individually, and fail on any other rejection
+		var throttledDocs []document
+		var rejected int
+		var firstError string
+		for i, item := range result.Items {
+			if i >= len(pending) {
+				break
+			}
+			for _, op := range item {
+				switch {
+				case op.Status >= 200 && op.Status <= 299:
+				case op.Status == http.StatusTooManyRequests:
+					throttledDocs = append(throttledDocs, pending[i])
+				default:
+					rejected++
+					if firstError == "" {
+						firstError = op.Error.Type + ": " + op.Error.Reason
+					}
+				}
+			}
+		}
+		if rejected > 0 {
+			return fmt.Errorf("%d events rejected (first: %s)", rejected+len(throttledDocs), firstError)
+		}
+		if len(throttledDocs) == 0 {
+			return nil
+		}
+		if attempt >= policy.MaxAttempts {
+			return fmt.Errorf("%d events still throttled after %d attempts", len(throttledDocs), attempt)
+		}
+		if err := sleepContext(ctx, policy.delay(attempt)); err != nil {
+			return err
+		}
+		pending = throttledDocs
+	}
+}
+
+// bulkResponse is the part of a _bulk response used to find failed items
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int `json:"status"`
+		Error  struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+// index returns the index for event
+func (w *ElasticsearchWriter) index(event Event) string {
+	if w.Index != nil {
+		return w.Index(event)
+	}
+	return fmt.Sprintf("%s-%s-%s", w.indexPrefix(), strings.ToLower(eventFamily(event.GetEventType())),
+		event.GetTimestamp().UTC().Format("2006.01.02"))
+}
+
+// indexPrefix returns the configured or default index prefix
+func (w *ElasticsearchWriter) indexPrefix() string {
+	if w.IndexPrefix == "" {
+		return DefaultElasticsearchIndexPrefix
+	}
+	return w.IndexPrefix
+}
+
+// PutIndexTemplate installs ElasticsearchIndexTemplate for the writer's indices as the
+// composable index template named after the index prefix
+func (w *ElasticsearchWriter) PutIndexTemplate(ctx context.Context) error {
+	prefix := w.indexPrefix()
+	template := ElasticsearchIndexTemplate(prefix)
+	status, resp, err := doWithRetry(ctx, w.Client, w.Retry, func() (*http.Request, error) {
+		return w.newRequest(ctx, http.MethodPut, "/_index_template/"+prefix, template, "application/json")
+	})
+	if err != nil {
+		return err
+	}
+	if status < 200 || status > 299 {
+		return fmt.Errorf("failed to put index template: status %d: %s", status, truncateBody(resp))
+	}
+	return nil
+}
+
+// newRequest builds a request to the cluster with the configured headers and the flush
+// span's trace context
+func (w *ElasticsearchWriter) newRequest(ctx context.Context, method, path string, body []byte, contentType string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(w.URL, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range w.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", contentType)
+	InjectTraceContext(ctx, propagation.HeaderCarrier(req.Header))
+	return req, nil
+}
+
+// ElasticsearchIndexTemplate returns a composable index template for indices starting
+// with prefix: base fields, durations, and status codes get typed mappings, free text
+// such as queries and error messages is full-text searchable, and other strings are
+// keywords
+func ElasticsearchIndexTemplate(prefix string) []byte {
+	template := map[string]interface{}{
+		"index_patterns": []string{prefix + "-*"},
+		"priority":       100,
+		"template": map[string]interface{}{
+			"mappings": map[string]interface{}{
+				"dynamic_templates": []interface{}{
+					map[string]interface{}{"durations": map[string]interface{}{
+						"match": "*_ms", "mapping": map[string]interface{}{"type": "long"},
+					}},
+					map[string]interface{}{"bytes": map[string]interface{}{
+						"match": "*_bytes", "mapping": map[string]interface{}{"type": "long"},
+					}},
+					map[string]interface{}{"strings": map[string]interface{}{
+						"match_mapping_type": "string",
+						"mapping":            map[string]interface{}{"type": "keyword", "ignore_above": 1024},
+					}},
+				},
+				"properties": map[string]interface{}{
+					"base": map[string]interface{}{"properties": map[string]interface{}{
+						"event_id":       map[string]interface{}{"type": "keyword"},
+						"event_type":     map[string]interface{}{"type": "keyword"},
+						"schema_version": map[string]interface{}{"type": "integer"},
+						"timestamp":      map[string]interface{}{"type": "date"},
+						"service":        map[string]interface{}{"type": "keyword"},
+						"api":            map[string]interface{}{"type": "keyword"},
+						"host":           map[string]interface{}{"type": "keyword"},
+						"correlation_id": map[string]interface{}{"type": "keyword"},
+						"level":          map[string]interface{}{"type": "keyword"},
+						"retention":      map[string]interface{}{"type": "keyword"},
+						"metadata":       map[string]interface{}{"type": "object"},
+					}},
+					"status_code":   map[string]interface{}{"type": "integer"},
+					"query":         map[string]interface{}{"type": "text"},
+					"message":       map[string]interface{}{"type": "text"},
+					"error_message": map[string]interface{}{"type": "text"},
+					"stack_trace":   map[string]interface{}{"type": "text", "index": false},
+				},
+			},
+		},
+	}
+	data, _ := json.Marshal(template)
+	return data
+}
+
+// truncateBody shortens a response body for an error message
+func truncateBody(body []byte) string {
+	const limit = 512
+	if len(body) > limit {
+		return string(body[:limit]) + "..."
+	}
+	return string(body)
+}
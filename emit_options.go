@@ -0,0 +1,183 @@
+package lifecycle
+
+import (
+	"context"
+	"time"
+)
+
+// EmitOption sets an optional piece of an event emitted with an EmitXWithOptions method
+type EmitOption func(*builderBase)
+
+// WithEventAPI sets the event's API identifier, replacing the deprecated trailing api
+// parameter of the positional Emit methods
+func WithEventAPI(api string) EmitOption {
+	return func(b *builderBase) {
+		b.api = api
+	}
+}
+
+// WithEventMetadata adds a metadata entry to the event
+func WithEventMetadata(key string, value interface{}) EmitOption {
+	return func(b *builderBase) {
+		b.setMetadata(key, value)
+	}
+}
+
+// WithEventTimestamp sets when the event happened, for events emitted after the fact
+// (e.g., by adapters that only learn of a query once it has run)
+func WithEventTimestamp(t time.Time) EmitOption {
+	return func(b *builderBase) {
+		b.timestamp = t
+	}
+}
+
+// WithEventRetention sets the event's retention class, overriding WithRetention
+func WithEventRetention(class RetentionClass) EmitOption {
+	return func(b *builderBase) {
+		b.retention = class
+	}
+}
+
+// applyEmitOptions applies opts to a builder
+func (b *builderBase) applyEmitOptions(opts []EmitOption) {
+	for _, opt := range opts {
+		opt(b)
+	}
+}
+
+// RequestReceivedOptions describes an api.request.received event
+type RequestReceivedOptions struct {
+	CorrelationID string // Default: from ctx
+	Method        string
+	Path          string
+	Route         string // Route template (default: from ctx, WithRoutes, or TemplatePath)
+}
+
+// EmitRequestReceivedWithOptions emits an api.request.received event
+func (p *Producer) EmitRequestReceivedWithOptions(ctx context.Context, opts RequestReceivedOptions, emitOpts ...EmitOption) error {
+	b := builderBase{producer: p, correlationID: opts.CorrelationID}
+	b.applyEmitOptions(emitOpts)
+
+	base := b.createBase("api.request.received", nil)
+	event := &RequestReceivedEvent{
+		Base:   base,
+		Method: opts.Method,
+		Path:   opts.Path,
+		Route:  p.route(ctx, opts.Path, opts.Route),
+	}
+	p.describeClient(ctx, event)
+	return b.emit(ctx, base, event, 0)
+}
+
+// RequestHandledOptions describes an api.request.handled event
+type RequestHandledOptions struct {
+	CorrelationID     string // Default: from ctx
+	Actor             *Actor
+	Resource          *Resource // Its type is the default API identifier
+	StatusCode        int32
+	Duration          time.Duration
+	ResponseSizeBytes int64
+}
+
+// EmitRequestHandledWithOptions emits an api.request.handled event
+func (p *Producer) EmitRequestHandledWithOptions(ctx context.Context, opts RequestHandledOptions, emitOpts ...EmitOption) error {
+	b := p.NewRequestHandled().
+		Correlation(opts.CorrelationID).
+		Actor(opts.Actor).
+		Resource(opts.Resource).
+		Status(opts.StatusCode).
+		Duration(opts.Duration).
+		ResponseSize(opts.ResponseSizeBytes)
+	b.applyEmitOptions(emitOpts)
+	return b.Emit(ctx)
+}
+
+// RequestErroredOptions describes an api.request.errored event
+type RequestErroredOptions struct {
+	CorrelationID string // Default: from ctx
+	ErrorMessage  string
+	ErrorCode     string
+	StatusCode    int32
+	Duration      time.Duration
+}
+
+// EmitRequestErroredWithOptions emits an api.request.errored event
+func (p *Producer) EmitRequestErroredWithOptions(ctx context.Context, opts RequestErroredOptions, emitOpts ...EmitOption) error {
+	b := p.NewRequestErrored().
+		Correlation(opts.CorrelationID).
+		Message(opts.ErrorMessage).
+		Code(opts.ErrorCode).
+		Status(opts.StatusCode).
+		Duration(opts.Duration)
+	b.applyEmitOptions(emitOpts)
+	return b.Emit(ctx)
+}
+
+// ResourceCreatedOptions describes a resource.created event
+type ResourceCreatedOptions struct {
+	CorrelationID string // Default: from ctx
+	Actor         *Actor
+	Resource      *Resource // Its type is the default API identifier
+	Data          map[string]interface{}
+	Annotations   map[string]FieldAnnotations // Schema annotations used to redact Data
+}
+
+// EmitResourceCreatedWithOptions emits a resource.created event
+func (p *Producer) EmitResourceCreatedWithOptions(ctx context.Context, opts ResourceCreatedOptions, emitOpts ...EmitOption) error {
+	b := p.NewResourceCreated().
+		Correlation(opts.CorrelationID).
+		Actor(opts.Actor).
+		Data(opts.Data).
+		Annotations(opts.Annotations)
+	b.resource = opts.Resource
+	b.applyEmitOptions(emitOpts)
+	return b.Emit(ctx)
+}
+
+// ResourceUpdatedOptions describes a resource.updated event
+type ResourceUpdatedOptions struct {
+	CorrelationID string // Default: from ctx
+	Actor         *Actor
+	Resource      *Resource // Its type is the default API identifier
+	PreviousData  map[string]interface{}
+	NewData       map[string]interface{}
+	UpdatedFields []string
+	Annotations   map[string]FieldAnnotations // Schema annotations used to redact the data
+}
+
+// EmitResourceUpdatedWithOptions emits a resource.updated event
+func (p *Producer) EmitResourceUpdatedWithOptions(ctx context.Context, opts ResourceUpdatedOptions, emitOpts ...EmitOption) error {
+	b := p.NewResourceUpdated().
+		Correlation(opts.CorrelationID).
+		Actor(opts.Actor).
+		Previous(opts.PreviousData).
+		Data(opts.NewData).
+		UpdatedFields(opts.UpdatedFields...).
+		Annotations(opts.Annotations)
+	b.resource = opts.Resource
+	b.applyEmitOptions(emitOpts)
+	return b.Emit(ctx)
+}
+
+// ResourceDeletedOptions describes a resource.deleted event
+type ResourceDeletedOptions struct {
+	CorrelationID string // Default: from ctx
+	Actor         *Actor
+	Resource      *Resource // Its type is the default API identifier
+	SoftDelete    bool
+	FinalData     map[string]interface{}
+	Annotations   map[string]FieldAnnotations // Schema annotations used to redact FinalData
+}
+
+// EmitResourceDeletedWithOptions emits a resource.deleted event
+func (p *Producer) EmitResourceDeletedWithOptions(ctx context.Context, opts ResourceDeletedOptions, emitOpts ...EmitOption) error {
+	b := p.NewResourceDeleted().
+		Correlation(opts.CorrelationID).
+		Actor(opts.Actor).
+		Data(opts.FinalData).
+		Annotations(opts.Annotations)
+	b.resource = opts.Resource
+	b.softDelete = opts.SoftDelete
+	b.applyEmitOptions(emitOpts)
+	return b.Emit(ctx)
+}
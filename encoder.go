@@ -0,0 +1,241 @@
+package lifecycle
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Encoder serializes an event for output
+// Encoded events are written one per line, so encodings must not contain newlines
+type Encoder interface {
+	Encode(event Event) ([]byte, error)
+}
+
+// WithEncoder sets how events are serialized for the producer's output (default: NewJSONEncoder)
+func WithEncoder(encoder Encoder) ProducerOption {
+	return func(p *Producer) {
+		p.encoder = encoder
+	}
+}
+
+// WithCanonicalJSON makes the producer's JSON output deterministic (see NewCanonicalJSONEncoder)
+func WithCanonicalJSON() ProducerOption {
+	return WithEncoder(NewCanonicalJSONEncoder())
+}
+
+// WithLogfmt makes the producer write logfmt lines instead of JSON (see NewLogfmtEncoder)
+func WithLogfmt() ProducerOption {
+	return WithEncoder(NewLogfmtEncoder())
+}
+
+// jsonEncoder encodes events with encoding/json
+type jsonEncoder struct{}
+
+// NewJSONEncoder returns the default encoder, which writes the event struct with
+// encoding/json: fields in declaration order, base fields nested under "base"
+func NewJSONEncoder() Encoder {
+	return jsonEncoder{}
+}
+
+func (jsonEncoder) Encode(event Event) ([]byte, error) {
+	return json.Marshal(event)
+}
+
+// canonicalJSONEncoder encodes events deterministically
+type canonicalJSONEncoder struct{}
+
+// NewCanonicalJSONEncoder returns an encoder whose output is byte-for-byte stable for the
+// same event, suitable for golden files, diffs, and hashing:
+//   - object keys at every level, including "base" and metadata, are sorted lexicographically
+//   - the base timestamp is written in UTC with RFC 3339 nanosecond precision
+//   - numbers keep their original text, and <, >, and & are not escaped
+func NewCanonicalJSONEncoder() Encoder {
+	return canonicalJSONEncoder{}
+}
+
+func (canonicalJSONEncoder) Encode(event Event) ([]byte, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	return canonicalizeJSON(data)
+}
+
+// canonicalizeJSON re-encodes a JSON object with sorted keys and a UTC base timestamp
+func canonicalizeJSON(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return canonicalizeValue(value)
+}
+
+// canonicalizeValue encodes a decoded JSON value with sorted keys and a UTC base timestamp
+func canonicalizeValue(value interface{}) ([]byte, error) {
+	if object, ok := value.(map[string]interface{}); ok {
+		if base, ok := object["base"].(map[string]interface{}); ok {
+			if ts, ok := base["timestamp"].(string); ok {
+				if t, err := time.Parse(time.RFC3339Nano, ts); err == nil {
+					base["timestamp"] = t.UTC().Format(time.RFC3339Nano)
+				}
+			}
+		}
+	}
+
+	// encoding/json writes map keys in sorted order
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// logfmtEncoder encodes events as logfmt key=value lines
+type logfmtEncoder struct{}
+
+// NewLogfmtEncoder returns an encoder writing events as logfmt lines, for pipelines that
+// expect key=value pairs:
+//
+//	event_type=api.request.handled schema_version=1 timestamp=2024-05-01T12:00:00Z service=api status=success duration_ms=12 actor.user_id=u1
+//
+// Keys are the JSON encoder's field names, flattened: base fields and metadata entries
+// come first at the top level, nested objects are joined with dots, and arrays are written
+// as JSON. Values are quoted when empty or when they contain spaces, '=', quotes, or
+// control characters
+func NewLogfmtEncoder() Encoder {
+	return logfmtEncoder{}
+}
+
+func (logfmtEncoder) Encode(event Event) ([]byte, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if tok, err := decoder.Token(); err != nil || tok != json.Delim('{') {
+		return nil, fmt.Errorf("logfmt: event does not encode to a JSON object")
+	}
+
+	var buf bytes.Buffer
+	if err := writeLogfmtObject(&buf, decoder, "", true); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeLogfmtObject writes the pairs of the JSON object being decoded, after its opening
+// brace, with keys prefixed by prefix; at the top level, base and metadata are lifted
+func writeLogfmtObject(buf *bytes.Buffer, decoder *json.Decoder, prefix string, top bool) error {
+	for decoder.More() {
+		tok, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		key := tok.(string)
+
+		if top && (key == "base" || key == "metadata") {
+			if tok, err := decoder.Token(); err != nil {
+				return err
+			} else if tok == json.Delim('{') {
+				if err := writeLogfmtObject(buf, decoder, "", key == "base"); err != nil {
+					return err
+				}
+				continue
+			} else if tok == nil {
+				continue
+			}
+			return fmt.Errorf("logfmt: %s is not an object", key)
+		}
+
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return err
+		}
+		if len(value) > 0 && value[0] == '{' {
+			nested := json.NewDecoder(bytes.NewReader(value))
+			nested.UseNumber()
+			if _, err := nested.Token(); err != nil {
+				return err
+			}
+			if err := writeLogfmtObject(buf, nested, prefix+key+".", false); err != nil {
+				return err
+			}
+			continue
+		}
+		writeLogfmtPair(buf, prefix+key, value)
+	}
+	_, err := decoder.Token() // Closing brace
+	return err
+}
+
+// writeLogfmtPair writes key=value for a JSON value
+func writeLogfmtPair(buf *bytes.Buffer, key string, value json.RawMessage) {
+	if buf.Len() > 0 {
+		buf.WriteByte(' ')
+	}
+	writeLogfmtKey(buf, key)
+	buf.WriteByte('=')
+
+	text := string(value)
+	if len(value) > 0 && value[0] == '"' {
+		if err := json.Unmarshal(value, &text); err != nil {
+			text = string(value)
+		}
+	} else if len(value) > 0 && value[0] == '[' {
+		var compact bytes.Buffer
+		if json.Compact(&compact, value) == nil {
+			text = compact.String()
+		}
+	}
+	writeLogfmtValue(buf, text)
+}
+
+// writeLogfmtKey writes a key, replacing characters logfmt keys cannot contain with '_'
+func writeLogfmtKey(buf *bytes.Buffer, key string) {
+	for _, r := range key {
+		if r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError || !unicode.IsPrint(r) {
+			r = '_'
+		}
+		buf.WriteRune(r)
+	}
+}
+
+// writeLogfmtValue writes a value, quoted and escaped when needed
+func writeLogfmtValue(buf *bytes.Buffer, value string) {
+	if value != "" && !strings.ContainsFunc(value, func(r rune) bool {
+		return r <= ' ' || r == '=' || r == '"' || r == '\\' || r == utf8.RuneError || !unicode.IsPrint(r)
+	}) {
+		buf.WriteString(value)
+		return
+	}
+
+	buf.WriteByte('"')
+	for _, r := range value {
+		switch {
+		case r == '"' || r == '\\':
+			buf.WriteByte('\\')
+			buf.WriteRune(r)
+		case r == '\n':
+			buf.WriteString(`\n`)
+		case r == '\r':
+			buf.WriteString(`\r`)
+		case r == '\t':
+			buf.WriteString(`\t`)
+		case r < ' ' || r == 0x7f:
+			fmt.Fprintf(buf, `\u%04x`, r)
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	buf.WriteByte('"')
+}
@@ -0,0 +1,400 @@
+package lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventTypeInfo describes the contract of an event type for downstream consumers: the
+// fields it carries besides the base fields every event has (see BaseEventFields)
+type EventTypeInfo struct {
+	EventType   string           `json:"event_type"`
+	Description string           `json:"description,omitempty"`
+	GoType      string           `json:"go_type"`          // Name of the event struct, e.g., "lifecycle.RequestHandledEvent"
+	Custom      bool             `json:"custom,omitempty"` // Registered with RegisterEventType
+	Fields      []EventFieldInfo `json:"fields"`
+}
+
+// EventFieldInfo describes one field of an event, as it appears in the event's JSON
+type EventFieldInfo struct {
+	Name        string            `json:"name"`
+	Type        string            `json:"type"`               // string, integer, number, boolean, timestamp (RFC 3339 string), object, array, or any
+	Items       string            `json:"items,omitempty"`    // Element type of an array
+	Required    bool              `json:"required,omitempty"` // Always present (no omitempty)
+	Nullable    bool              `json:"nullable,omitempty"` // May be null (pointers, slices, and maps)
+	Description string            `json:"description,omitempty"`
+	Annotations *FieldAnnotations `json:"annotations,omitempty"` // From the field's lifecycle struct tag
+	Fields      []EventFieldInfo  `json:"fields,omitempty"`      // Fields of an object, or of an array's objects
+}
+
+// builtinEventTypes are the event types emitted by this library
+var builtinEventTypes = []struct {
+	eventType   string
+	event       interface{}
+	description string
+}{
+	{"service.started", ServiceStartedEvent{}, "The service process started"},
+	{"service.healthy", ServiceHealthyEvent{}, "The service passed its startup health checks"},
+	{"service.shutdown", ServiceShutdownEvent{}, "The service is shutting down"},
+	{"service.crashed", ServiceCrashedEvent{}, "The service crashed, with the panic's stack trace"},
+	{"service.ready", ServiceProbeEvent{}, "A readiness probe started passing"},
+	{"service.not_ready", ServiceProbeEvent{}, "A readiness probe started failing"},
+	{"service.live", ServiceProbeEvent{}, "A liveness probe started passing"},
+	{"service.not_live", ServiceProbeEvent{}, "A liveness probe started failing"},
+	{"service.draining", ServiceDrainingEvent{}, "The service stopped accepting work and is finishing requests in flight"},
+	{"service.drained", ServiceDrainedEvent{}, "The service finished draining"},
+	{"api.request.received", RequestReceivedEvent{}, "An API request arrived"},
+	{"api.request.handled", RequestHandledEvent{}, "An API request completed"},
+	{"api.request.errored", RequestErroredEvent{}, "An API request failed"},
+	{"api.request.retried", RequestRetriedEvent{}, "An API request is being retried"},
+	{"api.call.completed", APICallEvent{}, "An outbound HTTP call completed"},
+	{"api.call.failed", APICallEvent{}, "An outbound HTTP call failed without a response"},
+	{"db.query.started", QueryStartedEvent{}, "A database query started"},
+	{"db.query.completed", QueryCompletedEvent{}, "A database query completed"},
+	{"db.query.errored", QueryErroredEvent{}, "A database query failed"},
+	{"db.query.slow", QuerySlowEvent{}, "A database query exceeded the slow query threshold"},
+	{"db.transaction.started", TransactionStartedEvent{}, "A database transaction started"},
+	{"db.transaction.committed", TransactionCommittedEvent{}, "A database transaction committed"},
+	{"db.transaction.rolled_back", TransactionRolledBackEvent{}, "A database transaction rolled back"},
+	{"redis.command.started", RedisCommandStartedEvent{}, "A Redis command started"},
+	{"redis.command.completed", RedisCommandCompletedEvent{}, "A Redis command completed"},
+	{"redis.command.errored", RedisCommandErroredEvent{}, "A Redis command failed"},
+	{"messaging.published", MessagePublishedEvent{}, "A message was published"},
+	{"messaging.publish_failed", MessagePublishedEvent{}, "A message could not be published"},
+	{"messaging.consumed", MessageConsumedEvent{}, "A message was consumed"},
+	{"messaging.consume_failed", MessageConsumedEvent{}, "Consuming a message failed"},
+	{"messaging.ack", MessageSettledEvent{}, "A message was acknowledged"},
+	{"messaging.nack", MessageSettledEvent{}, "A message was rejected"},
+	{"resource.created", ResourceCreatedEvent{}, "A resource was created"},
+	{"resource.updated", ResourceUpdatedEvent{}, "A resource was updated"},
+	{"resource.deleted", ResourceDeletedEvent{}, "A resource was deleted"},
+	{"error.occurred", ErrorOccurredEvent{}, "An error was reported or a panic recovered"},
+	{"log.message", LogMessageEvent{}, "A log message, from the slog handler or log bridges"},
+	{"dependency.available", DependencyAvailableEvent{}, "A dependency became reachable"},
+	{"dependency.unavailable", DependencyUnavailableEvent{}, "A dependency became unreachable"},
+	{"operation.retried", OperationRetriedEvent{}, "An operation is being retried"},
+	{"operation.retry_succeeded", RetryOutcomeEvent{}, "An operation succeeded after retries"},
+	{"operation.retry_failed", RetryOutcomeEvent{}, "An operation failed after its last retry"},
+	{"operation.timed_out", OperationTimedOutEvent{}, "An operation exceeded its deadline"},
+	{"leadership.acquired", LeadershipEvent{}, "This instance became leader"},
+	{"leadership.renewed", LeadershipEvent{}, "This instance renewed its leadership"},
+	{"leadership.lost", LeadershipEvent{}, "This instance lost leadership"},
+	{"tls.cert.expiring", TLSCertExpiringEvent{}, "A TLS certificate is close to expiry"},
+	{"dns.resolution.failed", DNSResolutionFailedEvent{}, "A host name could not be resolved"},
+	{"diagnostics.goroutines", GoroutineDumpEvent{}, "A dump of the process's goroutines"},
+	{"diagnostics.memory", MemoryStatsEvent{}, "A snapshot of memory statistics"},
+	{"diagnostics.config", ConfigSnapshotEvent{}, "A snapshot of the service's configuration"},
+	{"diagnostics.profile", ProfileEvent{}, "A CPU or heap profile was captured"},
+	{"lifecycle.stats", StatsEvent{}, "Aggregated event statistics for an interval"},
+	{"lifecycle.anomaly", AnomalyEvent{}, "An event rate or latency deviated from its baseline"},
+	{"lifecycle.events.dropped", EventsDroppedEvent{}, "A sink dropped events under backpressure"},
+	{"lifecycle.producer.closed", ProducerClosedEvent{}, "The producer was closed"},
+}
+
+// BaseEventFields describes the base fields every event carries under "base"
+var BaseEventFields = []EventFieldInfo{
+	{Name: "event_id", Type: "string", Description: "Unique event ID (with WithEventIDs)"},
+	{Name: "event_type", Type: "string", Required: true, Description: "Dot-separated event type, e.g., api.request.handled"},
+	{Name: "schema_version", Type: "integer", Required: true, Description: "Shape of the event (see CurrentSchemaVersion)"},
+	{Name: "timestamp", Type: "timestamp", Required: true, Description: "When the event happened"},
+	{Name: "service", Type: "string", Required: true, Description: "Service that emitted the event"},
+	{Name: "api", Type: "string", Description: "API identifier, e.g., examples.User"},
+	{Name: "host", Type: "string", Required: true, Description: "Host or pod identifier"},
+	{Name: "correlation_id", Type: "string", Description: "ID shared by the events of one request"},
+	{Name: "retention", Type: "string", Description: "Retention class"},
+	{Name: "level", Type: "string", Description: "Severity (with WithEventLevels), e.g., INFO"},
+	{Name: "metadata", Type: "object", Description: "Global and per-event metadata"},
+	{Name: "resource_attributes", Type: "object", Description: "OpenTelemetry resource attributes"},
+	{Name: "prev_hash", Type: "string", Description: "Hash of the previous event (with WithEventSigning)"},
+	{Name: "signature", Type: "string", Description: "Event signature (with WithEventSigning)"},
+}
+
+// eventTypeRegistry holds the built-in and registered event types
+var eventTypeRegistry = newEventTypeRegistry()
+
+type eventTypes struct {
+	mu    sync.RWMutex
+	types map[string]EventTypeInfo
+}
+
+func newEventTypeRegistry() *eventTypes {
+	r := &eventTypes{types: make(map[string]EventTypeInfo)}
+	for _, builtin := range builtinEventTypes {
+		r.types[builtin.eventType] = describeEventType(builtin.eventType, builtin.description, reflect.TypeOf(builtin.event))
+	}
+	return r
+}
+
+// RegisterEventType adds a custom event type, so it is documented alongside the built-in
+// ones; T is the event struct, whose fields other than Base are described by their json
+// tags, lifecycle tags (see AnnotationsFor), and an optional doc tag:
+//
+//	type OrderPlacedEvent struct {
+//		Base    *lifecycle.BaseEvent `json:"base"`
+//		OrderID string               `json:"order_id" doc:"ID of the new order"`
+//		Email   string               `json:"email" lifecycle:"pii"`
+//	}
+//
+//	lifecycle.RegisterEventType[OrderPlacedEvent]("order.placed", "A customer placed an order")
+//
+// Registering a type again replaces it
+func RegisterEventType[T any](eventType, description string) {
+	info := describeEventType(eventType, description, reflect.TypeOf((*T)(nil)).Elem())
+	info.Custom = true
+	eventTypeRegistry.mu.Lock()
+	eventTypeRegistry.types[eventType] = info
+	eventTypeRegistry.mu.Unlock()
+}
+
+// NewBaseEvent creates the base of a custom event the way bases of built-in events are
+// created, taking the correlation ID from ctx; set it as the event's Base and pass the
+// event to Emit:
+//
+//	base, err := producer.NewBaseEvent(ctx, "order.placed")
+//	if err != nil {
+//		return err
+//	}
+//	return producer.Emit(ctx, &OrderPlacedEvent{Base: base, OrderID: order.ID})
+func (p *Producer) NewBaseEvent(ctx context.Context, eventType string, opts ...EmitOption) (*BaseEvent, error) {
+	b := builderBase{producer: p}
+	b.applyEmitOptions(opts)
+	if b.err != nil {
+		return nil, b.err
+	}
+	base := b.createBase(eventType, nil)
+	base.CorrelationID = extractCorrelationID(ctx)
+	return base, nil
+}
+
+// Emit emits a custom event through the producer's hooks, redaction (for events that
+// implement EventWithData), sampling, and sinks
+func (p *Producer) Emit(ctx context.Context, event Event) error {
+	return p.emitEvent(ctx, event, 0)
+}
+
+// EventTypes returns the built-in and registered event types, sorted by name
+func EventTypes() []EventTypeInfo {
+	eventTypeRegistry.mu.RLock()
+	types := make([]EventTypeInfo, 0, len(eventTypeRegistry.types))
+	for _, info := range eventTypeRegistry.types {
+		types = append(types, info)
+	}
+	eventTypeRegistry.mu.RUnlock()
+	sort.Slice(types, func(i, j int) bool { return types[i].EventType < types[j].EventType })
+	return types
+}
+
+// LookupEventType returns the description of a built-in or registered event type
+func LookupEventType(eventType string) (EventTypeInfo, bool) {
+	eventTypeRegistry.mu.RLock()
+	defer eventTypeRegistry.mu.RUnlock()
+	info, ok := eventTypeRegistry.types[eventType]
+	return info, ok
+}
+
+// describeEventType describes the fields of the event struct t
+func describeEventType(eventType, description string, t reflect.Type) EventTypeInfo {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	info := EventTypeInfo{EventType: eventType, Description: description, GoType: t.String()}
+	info.Fields = describeFields(t, map[reflect.Type]bool{})
+	return info
+}
+
+// describeFields describes the JSON fields of struct t, except the base event
+func describeFields(t reflect.Type, seen map[reflect.Type]bool) []EventFieldInfo {
+	if seen[t] {
+		return nil
+	}
+	seen[t] = true
+	defer delete(seen, t)
+
+	fields := []EventFieldInfo{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Anonymous && field.Tag.Get("json") == "" && indirectType(field.Type).Kind() == reflect.Struct {
+			fields = append(fields, describeFields(indirectType(field.Type), seen)...)
+			continue
+		}
+		name, skip := styledFieldName(field)
+		if skip || indirectType(field.Type) == reflect.TypeOf(BaseEvent{}) {
+			continue
+		}
+		_, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		info := EventFieldInfo{
+			Name:        name,
+			Required:    !strings.Contains(options, "omitempty"),
+			Description: field.Tag.Get("doc"),
+		}
+		if tag, ok := field.Tag.Lookup("lifecycle"); ok {
+			annotations := parseAnnotationTag(tag)
+			info.Annotations = &annotations
+		}
+		switch field.Type.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+			info.Nullable = true
+		}
+		info.Type, info.Items, info.Fields = describeType(field.Type, seen)
+		fields = append(fields, info)
+	}
+	return fields
+}
+
+// describeType returns the JSON type of t, its element type if it is an array, and the
+// fields of its objects
+func describeType(t reflect.Type, seen map[reflect.Type]bool) (string, string, []EventFieldInfo) {
+	t = indirectType(t)
+	switch {
+	case t == reflect.TypeOf(time.Time{}):
+		return "timestamp", "", nil
+	case t == reflect.TypeOf(json.RawMessage{}):
+		return "any", "", nil
+	case t.Implements(reflect.TypeOf((*json.Marshaler)(nil)).Elem()) ||
+		reflect.PointerTo(t).Implements(reflect.TypeOf((*json.Marshaler)(nil)).Elem()):
+		return "any", "", nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string", "", nil
+	case reflect.Bool:
+		return "boolean", "", nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer", "", nil
+	case reflect.Float32, reflect.Float64:
+		return "number", "", nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "string", "", nil // Base64
+		}
+		items, _, fields := describeType(t.Elem(), seen)
+		return "array", items, fields
+	case reflect.Map:
+		return "object", "", nil
+	case reflect.Struct:
+		return "object", "", describeFields(t, seen)
+	}
+	return "any", "", nil
+}
+
+// indirectType returns the type t points to, or t
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// WriteEventTypesMarkdown documents event types as Markdown: the base fields, then a
+// section per event type with a table of its fields
+func WriteEventTypesMarkdown(w io.Writer, types []EventTypeInfo) error {
+	var b strings.Builder
+
+	b.WriteString("# Lifecycle Event Types\n\n")
+	b.WriteString("Events are JSON objects with the base fields under `base` and the event's own fields at the top level.\n")
+	b.WriteString("\n## Base Fields\n\n")
+	writeFieldTable(&b, BaseEventFields)
+
+	b.WriteString("\n## Event Types\n\n")
+	for _, info := range types {
+		fmt.Fprintf(&b, "- [`%s`](#%s)\n", info.EventType, markdownAnchor(info.EventType))
+	}
+	for _, info := range types {
+		fmt.Fprintf(&b, "\n### %s\n\n", info.EventType)
+		if info.Description != "" {
+			fmt.Fprintf(&b, "%s.\n\n", strings.TrimSuffix(info.Description, "."))
+		}
+		fmt.Fprintf(&b, "Go type: `%s`", info.GoType)
+		if info.Custom {
+			b.WriteString(" (custom)")
+		}
+		b.WriteString("\n\n")
+		if len(info.Fields) == 0 {
+			b.WriteString("No fields besides the base fields.\n")
+			continue
+		}
+		writeFieldTable(&b, info.Fields)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeFieldTable writes a Markdown table of fields, with nested fields as dotted paths
+func writeFieldTable(b *strings.Builder, fields []EventFieldInfo) {
+	b.WriteString("| Field | Type | Required | Description |\n|---|---|---|---|\n")
+	var write func(prefix string, fields []EventFieldInfo)
+	write = func(prefix string, fields []EventFieldInfo) {
+		for _, f := range fields {
+			typ := f.Type
+			if f.Items != "" {
+				typ = "array of " + f.Items
+			}
+			required := ""
+			if f.Required {
+				required = "yes"
+			}
+			description := markdownEscape(f.Description)
+			if flags := annotationFlags(f.Annotations); flags != "" {
+				description = strings.TrimSpace(description + " " + flags)
+			}
+			fmt.Fprintf(b, "| `%s%s` | %s | %s | %s |\n", prefix, f.Name, typ, required, description)
+			if f.Items != "" {
+				write(prefix+f.Name+"[].", f.Fields)
+			} else {
+				write(prefix+f.Name+".", f.Fields)
+			}
+		}
+	}
+	write("", fields)
+}
+
+// annotationFlags lists a field's annotations for documentation, e.g., "**PII, sensitive**"
+func annotationFlags(a *FieldAnnotations) string {
+	if a == nil {
+		return ""
+	}
+	var flags []string
+	for _, flag := range []struct {
+		set  bool
+		name string
+	}{
+		{a.PII, "PII"}, {a.Encrypted, "encrypted"}, {a.Redactable, "redactable"},
+		{a.Sensitive, "sensitive"}, {a.Immutable, "immutable"}, {a.AnonymizeIP, "anonymized IP"},
+		{a.Noise > 0, "noised"}, {a.Bucket > 0, "bucketed"},
+	} {
+		if flag.set {
+			flags = append(flags, flag.name)
+		}
+	}
+	if len(flags) == 0 {
+		return ""
+	}
+	return "**" + strings.Join(flags, ", ") + "**"
+}
+
+// markdownAnchor returns the anchor GitHub generates for a heading
+func markdownAnchor(heading string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		case r == ' ':
+			return '-'
+		}
+		return -1
+	}, heading)
+}
@@ -1,6 +1,9 @@
 package lifecycle
 
-import "time"
+import (
+	"log/slog"
+	"time"
+)
 
 // Event is the base interface for all lifecycle events
 type Event interface {
@@ -10,6 +13,8 @@ type Event interface {
 	GetAPI() string
 	GetHost() string
 	GetCorrelationID() string
+	GetMetadata() map[string]interface{}
+	SetMetadata(key string, value interface{}) // Adds or replaces a metadata entry
 }
 
 // EventWithData is an event that contains data that may need PII redaction
@@ -20,13 +25,28 @@ type EventWithData interface {
 
 // BaseEvent contains common fields for all events
 type BaseEvent struct {
+	EventID       string                 `json:"event_id,omitempty"` // Set with WithEventIDs
 	EventType     string                 `json:"event_type"`
+	SchemaVersion int                    `json:"schema_version"` // Shape of the event (see CurrentSchemaVersion)
 	Timestamp     time.Time              `json:"timestamp"`
 	Service       string                 `json:"service"`       // Service instance (e.g., "user-service-pod-123")
 	API           string                 `json:"api,omitempty"` // API identifier (e.g., "examples.User", "idp.Account") - can be empty for service-level events
 	Host          string                 `json:"host"`          // Host/pod identifier
 	CorrelationID string                 `json:"correlation_id,omitempty"`
+	Retention     RetentionClass         `json:"retention,omitempty"` // Set with WithRetention or WithEventRetention
+	Level         string                 `json:"level,omitempty"`     // Set with WithEventLevels (e.g., "INFO")
 	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+
+	// OpenTelemetry resource attributes (e.g., service.version, deployment.environment),
+	// set by WithOTelResource; shared between events, so never modify it
+	ResourceAttributes map[string]string `json:"resource_attributes,omitempty"`
+
+	// Set with WithEventSigning: the hash of the event written before this one, and this
+	// event's signature
+	PrevHash  string `json:"prev_hash,omitempty"`
+	Signature string `json:"signature,omitempty"`
+
+	ownsMetadata bool // Metadata was copied, so SetMetadata does not modify the caller's map
 }
 
 func (e *BaseEvent) GetEventType() string     { return e.EventType }
@@ -35,6 +55,24 @@ func (e *BaseEvent) GetService() string       { return e.Service }
 func (e *BaseEvent) GetAPI() string           { return e.API }
 func (e *BaseEvent) GetHost() string          { return e.Host }
 func (e *BaseEvent) GetCorrelationID() string { return e.CorrelationID }
+func (e *BaseEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *BaseEvent) GetMetadata() map[string]interface{} { return e.Metadata }
+
+// SetMetadata adds or replaces a metadata entry
+// The metadata map passed to an Emit method is copied on the first call, so enriching an
+// event never modifies a map the caller may reuse
+func (e *BaseEvent) SetMetadata(key string, value interface{}) {
+	if !e.ownsMetadata {
+		metadata := make(map[string]interface{}, len(e.Metadata)+1)
+		for k, v := range e.Metadata {
+			metadata[k] = v
+		}
+		e.Metadata = metadata
+		e.ownsMetadata = true
+	}
+	e.Metadata[key] = value
+}
 
 // Actor represents the actor performing an action
 type Actor struct {
@@ -62,6 +100,7 @@ type Status string
 
 const (
 	StatusSuccess Status = "success"
+	StatusWarning Status = "warning"
 	StatusError   Status = "error"
 )
 
@@ -80,6 +119,12 @@ func (e *ServiceStartedEvent) GetService() string       { return e.Base.GetServi
 func (e *ServiceStartedEvent) GetAPI() string           { return e.Base.GetAPI() }
 func (e *ServiceStartedEvent) GetHost() string          { return e.Base.GetHost() }
 func (e *ServiceStartedEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *ServiceStartedEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *ServiceStartedEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *ServiceStartedEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
 
 // ServiceHealthyEvent represents a service.healthy event
 type ServiceHealthyEvent struct {
@@ -93,6 +138,12 @@ func (e *ServiceHealthyEvent) GetService() string       { return e.Base.GetServi
 func (e *ServiceHealthyEvent) GetAPI() string           { return e.Base.GetAPI() }
 func (e *ServiceHealthyEvent) GetHost() string          { return e.Base.GetHost() }
 func (e *ServiceHealthyEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *ServiceHealthyEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *ServiceHealthyEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *ServiceHealthyEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
 
 // ServiceShutdownEvent represents a service.shutdown event
 type ServiceShutdownEvent struct {
@@ -107,13 +158,20 @@ func (e *ServiceShutdownEvent) GetService() string       { return e.Base.GetServ
 func (e *ServiceShutdownEvent) GetAPI() string           { return e.Base.GetAPI() }
 func (e *ServiceShutdownEvent) GetHost() string          { return e.Base.GetHost() }
 func (e *ServiceShutdownEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *ServiceShutdownEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *ServiceShutdownEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *ServiceShutdownEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
 
 // ServiceCrashedEvent represents a service.crashed event
 type ServiceCrashedEvent struct {
-	Base       *BaseEvent `json:"base"`
-	Reason     string     `json:"reason"`
-	StackTrace string     `json:"stack_trace"`
-	ExitCode   int32      `json:"exit_code"`
+	Base       *BaseEvent   `json:"base"`
+	Reason     string       `json:"reason"`
+	StackTrace string       `json:"stack_trace"`
+	Stack      []StackFrame `json:"stack,omitempty"`
+	ExitCode   int32        `json:"exit_code"`
 }
 
 func (e *ServiceCrashedEvent) GetEventType() string     { return e.Base.GetEventType() }
@@ -122,16 +180,24 @@ func (e *ServiceCrashedEvent) GetService() string       { return e.Base.GetServi
 func (e *ServiceCrashedEvent) GetAPI() string           { return e.Base.GetAPI() }
 func (e *ServiceCrashedEvent) GetHost() string          { return e.Base.GetHost() }
 func (e *ServiceCrashedEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *ServiceCrashedEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *ServiceCrashedEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *ServiceCrashedEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
 
 // API Events
 
 // RequestReceivedEvent represents an api.request.received event
 type RequestReceivedEvent struct {
-	Base       *BaseEvent `json:"base"`
-	Method     string     `json:"method"`
-	Path       string     `json:"path"`
-	UserAgent  string     `json:"user_agent,omitempty"`
-	RemoteAddr string     `json:"remote_addr,omitempty"`
+	Base       *BaseEvent  `json:"base"`
+	Method     string      `json:"method"`
+	Path       string      `json:"path"`
+	Route      string      `json:"route,omitempty"`      // Route template (e.g., "/api/users/{id}"); see WithRoutes
+	UserAgent  string      `json:"user_agent,omitempty"` // Raw, hashed, or dropped, per WithUserAgentHandling
+	Client     *ClientInfo `json:"client,omitempty"`     // Parsed from the User-Agent
+	RemoteAddr string      `json:"remote_addr,omitempty"`
 }
 
 func (e *RequestReceivedEvent) GetEventType() string     { return e.Base.GetEventType() }
@@ -140,6 +206,12 @@ func (e *RequestReceivedEvent) GetService() string       { return e.Base.GetServ
 func (e *RequestReceivedEvent) GetAPI() string           { return e.Base.GetAPI() }
 func (e *RequestReceivedEvent) GetHost() string          { return e.Base.GetHost() }
 func (e *RequestReceivedEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *RequestReceivedEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *RequestReceivedEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *RequestReceivedEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
 
 // RequestHandledEvent represents an api.request.handled event
 type RequestHandledEvent struct {
@@ -158,6 +230,12 @@ func (e *RequestHandledEvent) GetService() string       { return e.Base.GetServi
 func (e *RequestHandledEvent) GetAPI() string           { return e.Base.GetAPI() }
 func (e *RequestHandledEvent) GetHost() string          { return e.Base.GetHost() }
 func (e *RequestHandledEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *RequestHandledEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *RequestHandledEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *RequestHandledEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
 
 // RequestErroredEvent represents an api.request.errored event
 type RequestErroredEvent struct {
@@ -175,6 +253,12 @@ func (e *RequestErroredEvent) GetService() string       { return e.Base.GetServi
 func (e *RequestErroredEvent) GetAPI() string           { return e.Base.GetAPI() }
 func (e *RequestErroredEvent) GetHost() string          { return e.Base.GetHost() }
 func (e *RequestErroredEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *RequestErroredEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *RequestErroredEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *RequestErroredEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
 
 // RequestRetriedEvent represents an api.request.retried event
 type RequestRetriedEvent struct {
@@ -190,6 +274,12 @@ func (e *RequestRetriedEvent) GetService() string       { return e.Base.GetServi
 func (e *RequestRetriedEvent) GetAPI() string           { return e.Base.GetAPI() }
 func (e *RequestRetriedEvent) GetHost() string          { return e.Base.GetHost() }
 func (e *RequestRetriedEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *RequestRetriedEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *RequestRetriedEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *RequestRetriedEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
 
 // Database Tracing Events
 
@@ -207,6 +297,12 @@ func (e *QueryStartedEvent) GetService() string       { return e.Base.GetService
 func (e *QueryStartedEvent) GetAPI() string           { return e.Base.GetAPI() }
 func (e *QueryStartedEvent) GetHost() string          { return e.Base.GetHost() }
 func (e *QueryStartedEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *QueryStartedEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *QueryStartedEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *QueryStartedEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
 
 // QueryCompletedEvent represents a db.query.completed event
 type QueryCompletedEvent struct {
@@ -222,6 +318,12 @@ func (e *QueryCompletedEvent) GetService() string       { return e.Base.GetServi
 func (e *QueryCompletedEvent) GetAPI() string           { return e.Base.GetAPI() }
 func (e *QueryCompletedEvent) GetHost() string          { return e.Base.GetHost() }
 func (e *QueryCompletedEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *QueryCompletedEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *QueryCompletedEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *QueryCompletedEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
 
 // QueryErroredEvent represents a db.query.errored event
 type QueryErroredEvent struct {
@@ -238,6 +340,12 @@ func (e *QueryErroredEvent) GetService() string       { return e.Base.GetService
 func (e *QueryErroredEvent) GetAPI() string           { return e.Base.GetAPI() }
 func (e *QueryErroredEvent) GetHost() string          { return e.Base.GetHost() }
 func (e *QueryErroredEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *QueryErroredEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *QueryErroredEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *QueryErroredEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
 
 // TransactionStartedEvent represents a db.transaction.started event
 type TransactionStartedEvent struct {
@@ -251,6 +359,12 @@ func (e *TransactionStartedEvent) GetService() string       { return e.Base.GetS
 func (e *TransactionStartedEvent) GetAPI() string           { return e.Base.GetAPI() }
 func (e *TransactionStartedEvent) GetHost() string          { return e.Base.GetHost() }
 func (e *TransactionStartedEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *TransactionStartedEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *TransactionStartedEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *TransactionStartedEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
 
 // TransactionCommittedEvent represents a db.transaction.committed event
 type TransactionCommittedEvent struct {
@@ -265,6 +379,12 @@ func (e *TransactionCommittedEvent) GetService() string       { return e.Base.Ge
 func (e *TransactionCommittedEvent) GetAPI() string           { return e.Base.GetAPI() }
 func (e *TransactionCommittedEvent) GetHost() string          { return e.Base.GetHost() }
 func (e *TransactionCommittedEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *TransactionCommittedEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *TransactionCommittedEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *TransactionCommittedEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
 
 // TransactionRolledBackEvent represents a db.transaction.rolled_back event
 type TransactionRolledBackEvent struct {
@@ -280,6 +400,14 @@ func (e *TransactionRolledBackEvent) GetService() string       { return e.Base.G
 func (e *TransactionRolledBackEvent) GetAPI() string           { return e.Base.GetAPI() }
 func (e *TransactionRolledBackEvent) GetHost() string          { return e.Base.GetHost() }
 func (e *TransactionRolledBackEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *TransactionRolledBackEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *TransactionRolledBackEvent) GetMetadata() map[string]interface{} {
+	return e.Base.GetMetadata()
+}
+func (e *TransactionRolledBackEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
 
 // Resource Events
 
@@ -297,6 +425,12 @@ func (e *ResourceCreatedEvent) GetService() string       { return e.Base.GetServ
 func (e *ResourceCreatedEvent) GetAPI() string           { return e.Base.GetAPI() }
 func (e *ResourceCreatedEvent) GetHost() string          { return e.Base.GetHost() }
 func (e *ResourceCreatedEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *ResourceCreatedEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *ResourceCreatedEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *ResourceCreatedEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
 
 func (e *ResourceCreatedEvent) RedactPII(detector *PIIDetector, redactor *Redactor) {
 	if e.ResourceData != nil {
@@ -320,6 +454,12 @@ func (e *ResourceUpdatedEvent) GetService() string       { return e.Base.GetServ
 func (e *ResourceUpdatedEvent) GetAPI() string           { return e.Base.GetAPI() }
 func (e *ResourceUpdatedEvent) GetHost() string          { return e.Base.GetHost() }
 func (e *ResourceUpdatedEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *ResourceUpdatedEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *ResourceUpdatedEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *ResourceUpdatedEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
 
 func (e *ResourceUpdatedEvent) RedactPII(detector *PIIDetector, redactor *Redactor) {
 	if e.PreviousData != nil {
@@ -345,6 +485,12 @@ func (e *ResourceDeletedEvent) GetService() string       { return e.Base.GetServ
 func (e *ResourceDeletedEvent) GetAPI() string           { return e.Base.GetAPI() }
 func (e *ResourceDeletedEvent) GetHost() string          { return e.Base.GetHost() }
 func (e *ResourceDeletedEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *ResourceDeletedEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *ResourceDeletedEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *ResourceDeletedEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
 
 func (e *ResourceDeletedEvent) RedactPII(detector *PIIDetector, redactor *Redactor) {
 	if e.FinalData != nil {
@@ -352,6 +498,75 @@ func (e *ResourceDeletedEvent) RedactPII(detector *PIIDetector, redactor *Redact
 	}
 }
 
+// Error Events
+
+// ErrorOccurredEvent represents an error.occurred event
+// It records an error that is not tied to a specific request or query, such as a
+// failed connection or a recovered panic found in intercepted log output
+type ErrorOccurredEvent struct {
+	Base         *BaseEvent      `json:"base"`
+	Status       Status          `json:"status"`
+	Kind         string          `json:"kind"` // Error category (e.g., "connection_refused", "panic")
+	ErrorMessage string          `json:"error_message"`
+	StackTrace   string          `json:"stack_trace,omitempty"`
+	Stack        []StackFrame    `json:"stack,omitempty"`
+	Source       *SourceLocation `json:"source,omitempty"`
+}
+
+func (e *ErrorOccurredEvent) GetEventType() string     { return e.Base.GetEventType() }
+func (e *ErrorOccurredEvent) GetTimestamp() time.Time  { return e.Base.GetTimestamp() }
+func (e *ErrorOccurredEvent) GetService() string       { return e.Base.GetService() }
+func (e *ErrorOccurredEvent) GetAPI() string           { return e.Base.GetAPI() }
+func (e *ErrorOccurredEvent) GetHost() string          { return e.Base.GetHost() }
+func (e *ErrorOccurredEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *ErrorOccurredEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *ErrorOccurredEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *ErrorOccurredEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
+
+// Log Events
+
+// SourceLocation identifies where in the code a log line originated
+type SourceLocation struct {
+	Function string `json:"function,omitempty"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// LogMessageEvent represents a log.message event
+// It carries log output intercepted from the log and log/slog packages; prefer
+// specific event types in new code
+type LogMessageEvent struct {
+	Base    *BaseEvent             `json:"base"`
+	Level   string                 `json:"level"`
+	Message string                 `json:"message"`
+	Actor   *Actor                 `json:"actor,omitempty"`
+	Tenant  string                 `json:"tenant,omitempty"`
+	Attrs   map[string]interface{} `json:"attrs,omitempty"`
+	Source  *SourceLocation        `json:"source,omitempty"`
+}
+
+func (e *LogMessageEvent) GetEventType() string     { return e.Base.GetEventType() }
+func (e *LogMessageEvent) GetTimestamp() time.Time  { return e.Base.GetTimestamp() }
+func (e *LogMessageEvent) GetService() string       { return e.Base.GetService() }
+func (e *LogMessageEvent) GetAPI() string           { return e.Base.GetAPI() }
+func (e *LogMessageEvent) GetHost() string          { return e.Base.GetHost() }
+func (e *LogMessageEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *LogMessageEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *LogMessageEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *LogMessageEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
+
+func (e *LogMessageEvent) RedactPII(detector *PIIDetector, redactor *Redactor) {
+	if e.Attrs != nil {
+		e.Attrs = redactor.RedactMap(e.Attrs, detector)
+	}
+}
+
 // FieldAnnotations represents field-level annotations from the API schema system
 // These match the FieldFlags from the API generator
 type FieldAnnotations struct {
@@ -360,4 +575,13 @@ type FieldAnnotations struct {
 	Redactable bool `json:"redactable"` // Can be redacted for GDPR Article 17
 	Sensitive  bool `json:"sensitive"`  // Sensitive data (general)
 	Immutable  bool `json:"immutable"`  // Field cannot be modified
+
+	// Numbers in analytics-sensitive fields (e.g., salary, age) can be reported
+	// approximately instead of redacted; other values are redacted as usual
+	Noise  float64 `json:"noise,omitempty"`  // Add uniform random noise of at most this magnitude
+	Bucket float64 `json:"bucket,omitempty"` // Report the range of this width the number falls in (e.g., 10: 34 -> "30-39")
+
+	// IP addresses are anonymized with AnonymizeIP instead of redacted; fields named like
+	// remote_addr, client_ip, or X-Forwarded-For are anonymized without it
+	AnonymizeIP bool `json:"anonymize_ip,omitempty"`
 }
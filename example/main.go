@@ -8,6 +8,7 @@ import (
 
 	"github.com/SCKelemen/lifecycle"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 )
 
 func main() {
@@ -69,15 +70,32 @@ func main() {
 	resource := lifecycle.NewResource("User", "user-789")
 
 	// Request received - event type and API will be colored
-	producer.EmitRequestReceived(ctx, correlationID, "GET", "/api/users/user-789", nil, "examples.User")
+	producer.EmitRequestReceivedWithOptions(ctx, lifecycle.RequestReceivedOptions{
+		CorrelationID: correlationID,
+		Method:        "GET",
+		Path:          "/api/users/user-789",
+	}, lifecycle.WithEventAPI("examples.User"))
 	time.Sleep(10 * time.Millisecond)
 
 	// Request handled - status code will be colored (green for 200)
-	producer.EmitRequestHandled(ctx, correlationID, actor, resource, 200, 10, 1024, "examples.User")
+	producer.EmitRequestHandledWithOptions(ctx, lifecycle.RequestHandledOptions{
+		CorrelationID:     correlationID,
+		Actor:             actor,
+		Resource:          resource,
+		StatusCode:        200,
+		Duration:          10 * time.Millisecond,
+		ResponseSizeBytes: 1024,
+	}, lifecycle.WithEventAPI("examples.User"))
 
 	// Request errored - status code will be colored (red for 500)
 	fmt.Println()
-	producer.EmitRequestErrored(ctx, "req-456", "database connection failed", "DB_CONN_ERROR", 500, 50, "examples.User")
+	producer.EmitRequestErroredWithOptions(ctx, lifecycle.RequestErroredOptions{
+		CorrelationID: "req-456",
+		ErrorMessage:  "database connection failed",
+		ErrorCode:     "DB_CONN_ERROR",
+		StatusCode:    500,
+		Duration:      50 * time.Millisecond,
+	}, lifecycle.WithEventAPI("examples.User"))
 
 	// Database tracing events - event type will be colored
 	fmt.Println()
@@ -108,7 +126,13 @@ func main() {
 		"name":  {PII: true, Redactable: true},
 	}
 
-	producer.EmitResourceCreated(ctx, correlationID, actor, resource, resourceData, schemaAnnotations, "examples.User")
+	producer.EmitResourceCreatedWithOptions(ctx, lifecycle.ResourceCreatedOptions{
+		CorrelationID: correlationID,
+		Actor:         actor,
+		Resource:      resource,
+		Data:          resourceData,
+		Annotations:   schemaAnnotations,
+	}, lifecycle.WithEventAPI("examples.User"))
 
 	// Service shutdown - event type will be colored
 	fmt.Println()
@@ -134,5 +158,19 @@ func main() {
 	fmt.Println("See example/TROUBLESHOOTING.md for help.")
 	fmt.Println()
 	fmt.Printf("Terminal type: %s\n", os.Getenv("TERM"))
-	fmt.Printf("Color profile: %s\n", lipgloss.ColorProfile())
+	fmt.Printf("Color profile: %s\n", profileName(lipgloss.ColorProfile()))
+}
+
+// profileName names a terminal color profile; termenv.Profile has no String method
+func profileName(profile termenv.Profile) string {
+	switch profile {
+	case termenv.TrueColor:
+		return "TrueColor"
+	case termenv.ANSI256:
+		return "ANSI256"
+	case termenv.ANSI:
+		return "ANSI"
+	default:
+		return "Ascii (no color)"
+	}
 }
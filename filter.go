@@ -0,0 +1,396 @@
+package lifecycle
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Filter is a compiled filter expression over the fields of a Record, e.g.,
+//
+//	status_code>=500 && api=="examples.User"
+//	event_type=="db.*" && !(duration_ms<100)
+//	service=~"^pay" || level==ERROR
+//
+// A comparison is a field name (anything Record.Get finds: base fields, event fields,
+// metadata, resource attributes), an operator (==, !=, <, <=, >, >=, =~ and !~ for regular
+// expressions), and a value: a quoted string, a number, true or false, or a bare word.
+// == and != match string values with '*' or '?' as path.Match patterns. Numbers compare
+// numerically and timestamps chronologically (against RFC 3339 values). A field alone is
+// true when present and not empty, false, or zero. Comparisons combine with &&, ||, !,
+// and parentheses. A missing field only satisfies != and !~
+type Filter struct {
+	expr string
+	root filterNode
+}
+
+// ParseFilter compiles a filter expression
+func ParseFilter(expr string) (*Filter, error) {
+	p := &filterParser{input: expr}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != filterTokenEOF {
+		return nil, p.errorf("unexpected %q", p.tok.text)
+	}
+	return &Filter{expr: expr, root: root}, nil
+}
+
+// Match reports whether r satisfies the filter
+func (f *Filter) Match(r *Record) bool {
+	return f.root.match(r)
+}
+
+// String returns the filter's expression
+func (f *Filter) String() string {
+	return f.expr
+}
+
+// filterNode is a node of a parsed filter expression
+type filterNode interface {
+	match(r *Record) bool
+}
+
+type filterAnd struct{ left, right filterNode }
+type filterOr struct{ left, right filterNode }
+type filterNot struct{ operand filterNode }
+
+func (n filterAnd) match(r *Record) bool { return n.left.match(r) && n.right.match(r) }
+func (n filterOr) match(r *Record) bool  { return n.left.match(r) || n.right.match(r) }
+func (n filterNot) match(r *Record) bool { return !n.operand.match(r) }
+
+// filterPresent is a field used on its own
+type filterPresent struct{ field string }
+
+func (n filterPresent) match(r *Record) bool {
+	value, ok := r.Get(n.field)
+	if !ok || value == nil {
+		return false
+	}
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case time.Time:
+		return !v.IsZero()
+	}
+	if number, ok := filterNumber(value); ok {
+		return number != 0
+	}
+	return true
+}
+
+// filterCompare compares a field with a value
+type filterCompare struct {
+	field   string
+	op      string
+	value   string
+	number  float64
+	numeric bool           // value is a number
+	pattern *regexp.Regexp // For =~ and !~
+	glob    bool           // value is a path.Match pattern for == and !=
+}
+
+func (n filterCompare) match(r *Record) bool {
+	value, ok := r.Get(n.field)
+	if !ok || value == nil {
+		return n.op == "!=" || n.op == "!~"
+	}
+
+	switch n.op {
+	case "=~":
+		return n.pattern.MatchString(filterString(value))
+	case "!~":
+		return !n.pattern.MatchString(filterString(value))
+	case "==":
+		return n.equal(value)
+	case "!=":
+		return !n.equal(value)
+	}
+
+	var cmp int
+	if t, ok := value.(time.Time); ok {
+		other, err := time.Parse(time.RFC3339Nano, n.value)
+		if err != nil {
+			return false
+		}
+		cmp = t.Compare(other)
+	} else if number, ok := filterNumber(value); ok && n.numeric {
+		cmp = compareFloats(number, n.number)
+	} else if n.numeric {
+		return false
+	} else {
+		cmp = strings.Compare(filterString(value), n.value)
+	}
+	switch n.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	default:
+		return cmp >= 0
+	}
+}
+
+// equal reports whether value equals the comparison's value
+func (n filterCompare) equal(value interface{}) bool {
+	if n.numeric {
+		if number, ok := filterNumber(value); ok {
+			return number == n.number
+		}
+	}
+	if n.glob {
+		return matchPattern(n.value, filterString(value))
+	}
+	return filterString(value) == n.value
+}
+
+// filterNumber returns value as a number, for numeric fields and numeric strings
+func filterNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// filterString returns value as a string, with timestamps in RFC 3339
+func filterString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case json.Number:
+		return v.String()
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+	return fmt.Sprint(value)
+}
+
+// compareFloats returns -1, 0, or 1 as a is less than, equal to, or greater than b
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Filter expression tokens
+const (
+	filterTokenEOF = iota
+	filterTokenWord
+	filterTokenString
+	filterTokenOp
+)
+
+type filterToken struct {
+	kind int
+	text string
+	pos  int
+}
+
+// filterParser is a recursive descent parser for filter expressions
+type filterParser struct {
+	input string
+	pos   int
+	tok   filterToken
+}
+
+// filterOperators are the operator tokens, longest first
+var filterOperators = []string{"&&", "||", "==", "!=", "<=", ">=", "=~", "!~", "<", ">", "!", "(", ")"}
+
+// next reads the next token
+func (p *filterParser) next() error {
+	for p.pos < len(p.input) && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t' || p.input[p.pos] == '\n') {
+		p.pos++
+	}
+	start := p.pos
+	if p.pos >= len(p.input) {
+		p.tok = filterToken{kind: filterTokenEOF, pos: start}
+		return nil
+	}
+
+	switch c := p.input[p.pos]; {
+	case c == '"' || c == '\'':
+		var b strings.Builder
+		for p.pos++; p.pos < len(p.input); p.pos++ {
+			switch p.input[p.pos] {
+			case c:
+				p.pos++
+				p.tok = filterToken{kind: filterTokenString, text: b.String(), pos: start}
+				return nil
+			case '\\':
+				if p.pos+1 < len(p.input) {
+					p.pos++
+				}
+			}
+			b.WriteByte(p.input[p.pos])
+		}
+		return fmt.Errorf("filter: unterminated string at offset %d", start)
+	case isFilterWordChar(c):
+		for p.pos < len(p.input) && isFilterWordChar(p.input[p.pos]) {
+			p.pos++
+		}
+		p.tok = filterToken{kind: filterTokenWord, text: p.input[start:p.pos], pos: start}
+		return nil
+	}
+	for _, op := range filterOperators {
+		if strings.HasPrefix(p.input[p.pos:], op) {
+			p.pos += len(op)
+			p.tok = filterToken{kind: filterTokenOp, text: op, pos: start}
+			return nil
+		}
+	}
+	return fmt.Errorf("filter: unexpected %q at offset %d", p.input[p.pos], start)
+}
+
+// isFilterWordChar reports whether c can be part of a field name or bare value
+func isFilterWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		strings.IndexByte("_.-+:/*?[]@", c) >= 0
+}
+
+// errorf returns a parse error at the current token
+func (p *filterParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("filter: %s at offset %d", fmt.Sprintf(format, args...), p.tok.pos)
+}
+
+// parseOr parses and-expressions joined by ||
+func (p *filterParser) parseOr() (filterNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == filterTokenOp && p.tok.text == "||" {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = filterOr{left, right}
+	}
+	return left, nil
+}
+
+// parseAnd parses unary expressions joined by &&
+func (p *filterParser) parseAnd() (filterNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == filterTokenOp && p.tok.text == "&&" {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = filterAnd{left, right}
+	}
+	return left, nil
+}
+
+// parseUnary parses a negation, a parenthesized expression, or a comparison
+func (p *filterParser) parseUnary() (filterNode, error) {
+	switch {
+	case p.tok.kind == filterTokenOp && p.tok.text == "!":
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return filterNot{operand}, nil
+	case p.tok.kind == filterTokenOp && p.tok.text == "(":
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != filterTokenOp || p.tok.text != ")" {
+			return nil, p.errorf("expected )")
+		}
+		return inner, p.next()
+	case p.tok.kind == filterTokenWord:
+		return p.parseComparison()
+	case p.tok.kind == filterTokenEOF:
+		return nil, p.errorf("unexpected end of expression")
+	default:
+		return nil, p.errorf("expected a field name, got %q", p.tok.text)
+	}
+}
+
+// parseComparison parses "field op value", or a field on its own
+func (p *filterParser) parseComparison() (filterNode, error) {
+	field := p.tok.text
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind != filterTokenOp {
+		return filterPresent{field: field}, nil
+	}
+	op := p.tok.text
+	switch op {
+	case "==", "!=", "<", "<=", ">", ">=", "=~", "!~":
+	default:
+		return filterPresent{field: field}, nil
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind != filterTokenWord && p.tok.kind != filterTokenString {
+		return nil, p.errorf("expected a value after %s", op)
+	}
+
+	n := filterCompare{field: field, op: op, value: p.tok.text}
+	switch {
+	case op == "=~" || op == "!~":
+		pattern, err := regexp.Compile(n.value)
+		if err != nil {
+			return nil, p.errorf("invalid regular expression: %v", err)
+		}
+		n.pattern = pattern
+	case p.tok.kind == filterTokenWord:
+		if number, err := strconv.ParseFloat(n.value, 64); err == nil {
+			n.number, n.numeric = number, true
+		}
+	}
+	n.glob = !n.numeric && n.pattern == nil && strings.ContainsAny(n.value, "*?[")
+	return n, p.next()
+}
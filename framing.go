@@ -0,0 +1,241 @@
+package lifecycle
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"sync"
+)
+
+// Framed streams carry one event per length-prefixed frame instead of one per line, so
+// payloads never need scanning for newlines and a reader knows each event's size up
+// front. A stream starts with FramePreamble; each frame is a uvarint header holding the
+// payload length shifted left by one, with the low bit set when the payload is followed
+// by its CRC-32C (Castagnoli), little-endian:
+//
+//	"\x00LCF" | uvarint(len<<1|crc) payload [crc32c] | uvarint(len<<1|crc) payload [crc32c] | ...
+//
+// NDJSON cannot start with a NUL byte, so readers tell the two formats apart (see
+// ReadRecords and SocketListener)
+
+// FramePreamble starts every framed stream
+const FramePreamble = "\x00LCF"
+
+// DefaultMaxFrameSize bounds the payloads FrameReader accepts, like the line limit of
+// ReadRecords
+const DefaultMaxFrameSize = 16 * 1024 * 1024
+
+var (
+	// ErrNotFramed is returned by FrameReader for streams without FramePreamble
+	ErrNotFramed = errors.New("lifecycle: stream is not framed")
+
+	// ErrFrameTooLarge is returned by FrameReader for payloads over its maximum size
+	ErrFrameTooLarge = errors.New("lifecycle: frame too large")
+
+	// ErrFrameChecksum is returned by FrameReader for payloads that fail their checksum
+	ErrFrameChecksum = errors.New("lifecycle: frame checksum mismatch")
+)
+
+// frameCRCTable is the CRC-32C table of frame checksums
+var frameCRCTable = crc32.MakeTable(crc32.Castagnoli)
+
+// FrameOption configures a FrameWriter or FrameReader
+type FrameOption func(*frameConfig)
+
+type frameConfig struct {
+	checksum bool
+	maxSize  int
+}
+
+// WithFrameChecksum makes a FrameWriter append a CRC-32C to each payload; readers verify
+// checksums whenever frames carry them
+func WithFrameChecksum() FrameOption {
+	return func(c *frameConfig) {
+		c.checksum = true
+	}
+}
+
+// WithMaxFrameSize sets the largest payload a FrameReader accepts (default:
+// DefaultMaxFrameSize)
+func WithMaxFrameSize(size int) FrameOption {
+	return func(c *frameConfig) {
+		c.maxSize = size
+	}
+}
+
+// newFrameConfig applies opts to the default configuration
+func newFrameConfig(opts []FrameOption) frameConfig {
+	config := frameConfig{maxSize: DefaultMaxFrameSize}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return config
+}
+
+// FrameWriter writes payloads as frames, starting with FramePreamble
+type FrameWriter struct {
+	w        io.Writer
+	checksum bool
+	started  bool
+	buf      []byte
+}
+
+// NewFrameWriter creates a frame writer on w; the preamble is written with the first frame
+func NewFrameWriter(w io.Writer, opts ...FrameOption) *FrameWriter {
+	config := newFrameConfig(opts)
+	return &FrameWriter{w: w, checksum: config.checksum}
+}
+
+// WriteFrame writes payload as one frame with a single Write call
+func (fw *FrameWriter) WriteFrame(payload []byte) error {
+	buf := fw.buf[:0]
+	if !fw.started {
+		buf = append(buf, FramePreamble...)
+	}
+	header := uint64(len(payload)) << 1
+	if fw.checksum {
+		header |= 1
+	}
+	buf = binary.AppendUvarint(buf, header)
+	buf = append(buf, payload...)
+	if fw.checksum {
+		buf = binary.LittleEndian.AppendUint32(buf, crc32.Checksum(payload, frameCRCTable))
+	}
+	fw.buf = buf
+
+	if _, err := fw.w.Write(buf); err != nil {
+		return err
+	}
+	fw.started = true
+	return nil
+}
+
+// FrameReader reads the payloads of a framed stream
+type FrameReader struct {
+	r       *bufio.Reader
+	maxSize int
+	started bool
+}
+
+// NewFrameReader creates a frame reader on r
+func NewFrameReader(r io.Reader, opts ...FrameOption) *FrameReader {
+	config := newFrameConfig(opts)
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &FrameReader{r: br, maxSize: config.maxSize}
+}
+
+// ReadFrame returns the next payload; it returns io.EOF at the end of the stream and
+// io.ErrUnexpectedEOF for a truncated frame
+func (fr *FrameReader) ReadFrame() ([]byte, error) {
+	if !fr.started {
+		preamble := make([]byte, len(FramePreamble))
+		n, err := io.ReadFull(fr.r, preamble)
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		if string(preamble[:n]) != FramePreamble[:n] {
+			return nil, ErrNotFramed
+		}
+		if err != nil {
+			return nil, err
+		}
+		fr.started = true
+	}
+
+	header, err := binary.ReadUvarint(fr.r)
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, unexpectedEOF(err)
+	}
+	size := header >> 1
+	if size > uint64(fr.maxSize) {
+		return nil, fmt.Errorf("%w: %d bytes", ErrFrameTooLarge, size)
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(fr.r, payload); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	if header&1 != 0 {
+		var sum [4]byte
+		if _, err := io.ReadFull(fr.r, sum[:]); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if binary.LittleEndian.Uint32(sum[:]) != crc32.Checksum(payload, frameCRCTable) {
+			return nil, ErrFrameChecksum
+		}
+	}
+	return payload, nil
+}
+
+// unexpectedEOF reports an end of stream inside a frame as io.ErrUnexpectedEOF
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// isFramed reports whether the buffered stream starts like a framed stream
+func isFramed(r *bufio.Reader) bool {
+	first, err := r.Peek(1)
+	return err == nil && first[0] == FramePreamble[0]
+}
+
+// readFramedRecords decodes the events of a framed stream, calling fn for each
+func readFramedRecords(r io.Reader, fn func(*Record) error) error {
+	fr := NewFrameReader(r)
+	for frame := 1; ; frame++ {
+		payload, err := fr.ReadFrame()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("frame %d: %w", frame, err)
+		}
+		record, err := DecodeRecord(payload)
+		if err != nil {
+			return fmt.Errorf("frame %d: %w", frame, err)
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+}
+
+// framedSink writes encoded events to an io.Writer as frames
+type framedSink struct {
+	mu      sync.Mutex
+	fw      *FrameWriter
+	encoder Encoder
+}
+
+// NewFramedSink creates a sink that writes events to w as frames, encoded with encoder
+// (nil: NewJSONEncoder), e.g., into a pipe read with ReadRecords
+func NewFramedSink(w io.Writer, encoder Encoder, opts ...FrameOption) Sink {
+	if encoder == nil {
+		encoder = NewJSONEncoder()
+	}
+	return &framedSink{fw: NewFrameWriter(w, opts...), encoder: encoder}
+}
+
+func (s *framedSink) WriteEvent(event Event) error {
+	data, err := s.encoder.Encode(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.fw.WriteFrame(data); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package lifecycle
+
+import (
+	"context"
+	"net"
+)
+
+// Geo is the location of a client address
+type Geo struct {
+	Country string // ISO 3166-1 alpha-2 code (e.g., "DE")
+	Region  string // Optional: subdivision name or ISO 3166-2 code (e.g., "BE")
+}
+
+// GeoResolver looks up where client addresses are, typically in a GeoIP database such as
+// MaxMind's, keeping the lifecycle package free of the database dependency:
+//
+//	db, _ := geoip2.Open("GeoLite2-City.mmdb")
+//	resolver := lifecycle.GeoResolverFunc(func(ctx context.Context, ip net.IP) (lifecycle.Geo, bool) {
+//		city, err := db.City(ip)
+//		if err != nil || city.Country.IsoCode == "" {
+//			return lifecycle.Geo{}, false
+//		}
+//		geo := lifecycle.Geo{Country: city.Country.IsoCode}
+//		if len(city.Subdivisions) > 0 {
+//			geo.Region = city.Subdivisions[0].IsoCode
+//		}
+//		return geo, true
+//	})
+//
+// It runs on the emit path, so it must be fast and safe for concurrent use
+type GeoResolver interface {
+	// ResolveGeo returns the location of ip, or false if it is unknown
+	ResolveGeo(ctx context.Context, ip net.IP) (Geo, bool)
+}
+
+// GeoResolverFunc adapts a function to GeoResolver
+type GeoResolverFunc func(ctx context.Context, ip net.IP) (Geo, bool)
+
+// ResolveGeo calls f
+func (f GeoResolverFunc) ResolveGeo(ctx context.Context, ip net.IP) (Geo, bool) {
+	return f(ctx, ip)
+}
+
+// WithGeoResolver adds the country and region of each request's client to request events
+// The resolver sees the address the event records, which is anonymized unless
+// WithFullIPAddresses is set; /24 and /48 prefixes still locate clients to a city
+func WithGeoResolver(resolver GeoResolver) ProducerOption {
+	return func(p *Producer) {
+		p.geoResolver = resolver
+	}
+}
+
+// describeClient sets a request event's client address, User-Agent, and client details
+func (p *Producer) describeClient(ctx context.Context, event *RequestReceivedEvent) {
+	event.RemoteAddr = p.remoteAddr(ctx)
+	event.UserAgent, event.Client = p.userAgent(ctx)
+	event.Client = p.locateClient(ctx, event.Client, event.RemoteAddr)
+}
+
+// locateClient adds the location of addr to client, creating it if nil, and returns it
+// client is returned unchanged without a GeoResolver or a known location
+func (p *Producer) locateClient(ctx context.Context, client *ClientInfo, addr string) *ClientInfo {
+	if p.geoResolver == nil || addr == "" {
+		return client
+	}
+	ip := parseIP(addr)
+	if ip == nil {
+		return client
+	}
+	geo, ok := p.geoResolver.ResolveGeo(ctx, ip)
+	if !ok {
+		return client
+	}
+	if client == nil {
+		client = &ClientInfo{}
+	}
+	client.Country, client.Region = geo.Country, geo.Region
+	return client
+}
@@ -0,0 +1,219 @@
+package lifecycle
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HealthMonitor tracks readiness and liveness separately, matching Kubernetes probe
+// semantics: a service that is not ready stops receiving traffic, while a service that
+// is not live should be restarted
+//
+// Events are emitted only when a state changes (and on the first check):
+// service.ready / service.not_ready for readiness and service.live / service.not_live
+// for liveness
+//
+// It is safe for concurrent use
+type HealthMonitor struct {
+	producer  *Producer
+	readiness []DependencyProbe
+	liveness  []DependencyProbe
+
+	mu      sync.Mutex
+	ready   *bool         // nil until the first readiness check
+	live    *bool         // nil until the first liveness check
+	changed chan struct{} // Closed at the next change; nil until requested
+}
+
+// HealthMonitorOption configures a HealthMonitor
+type HealthMonitorOption func(*HealthMonitor)
+
+// WithReadinessChecks sets the checks that must pass for the service to be ready
+// (e.g., database and downstream connectivity)
+func WithReadinessChecks(checks ...DependencyProbe) HealthMonitorOption {
+	return func(m *HealthMonitor) {
+		m.readiness = append(m.readiness, checks...)
+	}
+}
+
+// WithLivenessChecks sets the checks that must pass for the service to be live
+// These should only fail when a restart would help (e.g., a wedged event loop)
+func WithLivenessChecks(checks ...DependencyProbe) HealthMonitorOption {
+	return func(m *HealthMonitor) {
+		m.liveness = append(m.liveness, checks...)
+	}
+}
+
+// NewHealthMonitor creates a health monitor that emits through producer
+func NewHealthMonitor(producer *Producer, opts ...HealthMonitorOption) *HealthMonitor {
+	m := &HealthMonitor{producer: producer}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// HealthCheckFailure describes a failed readiness or liveness check
+type HealthCheckFailure struct {
+	Name         string `json:"name"`
+	ErrorMessage string `json:"error_message"`
+}
+
+// ServiceProbeEvent represents a service.ready, service.not_ready, service.live, or
+// service.not_live event
+type ServiceProbeEvent struct {
+	Base   *BaseEvent           `json:"base"`
+	Probe  string               `json:"probe"` // "readiness" or "liveness"
+	Status Status               `json:"status"`
+	Passed []string             `json:"passed,omitempty"`
+	Failed []HealthCheckFailure `json:"failed,omitempty"`
+}
+
+func (e *ServiceProbeEvent) GetEventType() string     { return e.Base.GetEventType() }
+func (e *ServiceProbeEvent) GetTimestamp() time.Time  { return e.Base.GetTimestamp() }
+func (e *ServiceProbeEvent) GetService() string       { return e.Base.GetService() }
+func (e *ServiceProbeEvent) GetAPI() string           { return e.Base.GetAPI() }
+func (e *ServiceProbeEvent) GetHost() string          { return e.Base.GetHost() }
+func (e *ServiceProbeEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *ServiceProbeEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *ServiceProbeEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *ServiceProbeEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
+
+// StyledFields renders failures as "name: error" pairs
+func (e *ServiceProbeEvent) StyledFields() []interface{} {
+	fields := []interface{}{"probe", e.Probe, "status", string(e.Status)}
+	if len(e.Passed) > 0 {
+		fields = append(fields, "passed", strings.Join(e.Passed, ", "))
+	}
+	if len(e.Failed) > 0 {
+		failed := make([]string, len(e.Failed))
+		for i, f := range e.Failed {
+			failed[i] = f.Name + ": " + f.ErrorMessage
+		}
+		fields = append(fields, "failed", strings.Join(failed, "; "))
+	}
+	return fields
+}
+
+// CheckReadiness runs the readiness checks and reports whether all passed
+// A service.ready or service.not_ready event is emitted if readiness changed
+func (m *HealthMonitor) CheckReadiness(ctx context.Context) (bool, error) {
+	return m.check(ctx, "readiness", m.readiness, &m.ready, "service.ready", "service.not_ready")
+}
+
+// CheckLiveness runs the liveness checks and reports whether all passed
+// A service.live or service.not_live event is emitted if liveness changed
+func (m *HealthMonitor) CheckLiveness(ctx context.Context) (bool, error) {
+	return m.check(ctx, "liveness", m.liveness, &m.live, "service.live", "service.not_live")
+}
+
+// Ready reports the result of the last readiness check (false before the first check)
+func (m *HealthMonitor) Ready() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ready != nil && *m.ready
+}
+
+// Live reports the result of the last liveness check (true before the first check, so
+// a slow start is not mistaken for a failure)
+func (m *HealthMonitor) Live() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.live == nil || *m.live
+}
+
+// Changed returns a channel that is closed at the next readiness or liveness change,
+// so watchers can wait for a change and then read Ready and Live
+func (m *HealthMonitor) Changed() <-chan struct{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.changed == nil {
+		m.changed = make(chan struct{})
+	}
+	return m.changed
+}
+
+// check runs checks and emits passEvent or failEvent if the result differs from *state
+func (m *HealthMonitor) check(ctx context.Context, probe string, checks []DependencyProbe, state **bool, passEvent, failEvent string) (bool, error) {
+	results := runProbes(ctx, checks)
+
+	event := &ServiceProbeEvent{Probe: probe, Status: StatusSuccess}
+	for i, check := range checks {
+		if err := results[i].err; err != nil {
+			event.Failed = append(event.Failed, HealthCheckFailure{Name: check.Name, ErrorMessage: err.Error()})
+		} else {
+			event.Passed = append(event.Passed, check.Name)
+		}
+	}
+	ok := len(event.Failed) == 0
+
+	m.mu.Lock()
+	changed := *state == nil || **state != ok
+	*state = &ok
+	if changed && m.changed != nil {
+		close(m.changed)
+		m.changed = nil
+	}
+	m.mu.Unlock()
+
+	if !changed {
+		return ok, nil
+	}
+
+	eventType := passEvent
+	if !ok {
+		eventType = failEvent
+		event.Status = StatusError
+	}
+	event.Base = m.producer.createBaseEvent(eventType, "", nil)
+	return ok, m.producer.emitEvent(ctx, event, 0)
+}
+
+// Run checks readiness and liveness every interval until ctx is done
+// It is typically started in its own goroutine
+func (m *HealthMonitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		_, _ = m.CheckLiveness(ctx)
+		_, _ = m.CheckReadiness(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ReadinessHandler serves the last readiness result for a Kubernetes readiness probe:
+// 200 when ready, 503 otherwise
+func (m *HealthMonitor) ReadinessHandler() http.Handler {
+	return probeHandler(m.Ready)
+}
+
+// LivenessHandler serves the last liveness result for a Kubernetes liveness probe:
+// 200 when live, 503 otherwise
+func (m *HealthMonitor) LivenessHandler() http.Handler {
+	return probeHandler(m.Live)
+}
+
+// probeHandler responds with 200 or 503 depending on healthy
+func probeHandler(healthy func() bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy() {
+			http.Error(w, "not ok", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok\n"))
+	})
+}
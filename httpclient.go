@@ -0,0 +1,187 @@
+package lifecycle
+
+import (
+	"crypto/tls"
+	"log/slog"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// APICallEvent represents an api.call.completed or api.call.failed event for an outbound
+// HTTP call
+// The connection phase timings separate network latency from server latency; they are
+// zero when the phase did not happen, such as on a reused connection
+type APICallEvent struct {
+	Base         *BaseEvent `json:"base"`
+	Method       string     `json:"method"`
+	URL          string     `json:"url"` // Without query string or credentials
+	Status       Status     `json:"status"`
+	StatusCode   int32      `json:"status_code,omitempty"`
+	ErrorMessage string     `json:"error_message,omitempty"`
+	DurationMs   int64      `json:"duration_ms"`           // Until response headers were received
+	DNSMs        int64      `json:"dns_ms,omitempty"`      // dns.lookup
+	ConnectMs    int64      `json:"connect_ms,omitempty"`  // tcp.connect
+	TLSMs        int64      `json:"tls_ms,omitempty"`      // tls.handshake
+	TTFBMs       int64      `json:"ttfb_ms,omitempty"`     // From writing the request to the first response byte
+	ConnReused   bool       `json:"conn_reused,omitempty"` // An idle connection was reused
+	RemoteAddr   string     `json:"remote_addr,omitempty"` // Address of the server connection
+	TLSVersion   string     `json:"tls_version,omitempty"` // Negotiated TLS version
+}
+
+func (e *APICallEvent) GetEventType() string     { return e.Base.GetEventType() }
+func (e *APICallEvent) GetTimestamp() time.Time  { return e.Base.GetTimestamp() }
+func (e *APICallEvent) GetService() string       { return e.Base.GetService() }
+func (e *APICallEvent) GetAPI() string           { return e.Base.GetAPI() }
+func (e *APICallEvent) GetHost() string          { return e.Base.GetHost() }
+func (e *APICallEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *APICallEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *APICallEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *APICallEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
+
+// Transport wraps base (http.DefaultTransport if nil) so every outbound request emits
+// api.call.completed, or api.call.failed when no response was received, with DNS, connect,
+// TLS, and time-to-first-byte timings collected through net/http/httptrace
+// The correlation ID is taken from the request context
+func (p *Producer) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &tracingTransport{producer: p, base: base}
+}
+
+// InstrumentClient returns a copy of client (http.DefaultClient if nil) whose transport
+// is wrapped with Transport
+func (p *Producer) InstrumentClient(client *http.Client) *http.Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	instrumented := *client
+	instrumented.Transport = p.Transport(client.Transport)
+	return &instrumented
+}
+
+// tracingTransport implements Transport
+type tracingTransport struct {
+	producer *Producer
+	base     http.RoundTripper
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	timings := &callTimings{}
+	ctx := httptrace.WithClientTrace(req.Context(), timings.clientTrace())
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	duration := time.Since(start)
+
+	event := &APICallEvent{
+		Method:     req.Method,
+		URL:        redactURL(req),
+		Status:     StatusSuccess,
+		DurationMs: duration.Milliseconds(),
+	}
+	timings.apply(event)
+
+	eventType := "api.call.completed"
+	if err != nil {
+		eventType = "api.call.failed"
+		event.Status = StatusError
+		event.ErrorMessage = err.Error()
+	} else {
+		event.StatusCode = int32(resp.StatusCode)
+		if resp.StatusCode >= 500 {
+			event.Status = StatusError
+		}
+		if resp.TLS != nil {
+			event.TLSVersion = tls.VersionName(resp.TLS.Version)
+		}
+	}
+	event.Base = t.producer.createBaseEvent(eventType, extractCorrelationID(req.Context()), nil)
+	_ = t.producer.emitEvent(req.Context(), event, duration)
+
+	return resp, err
+}
+
+// redactURL returns the request URL without credentials, query, or fragment
+func redactURL(req *http.Request) string {
+	if req.URL == nil {
+		return ""
+	}
+	u := *req.URL
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
+
+// callTimings collects connection phase timings from httptrace callbacks, which may run
+// on other goroutines
+type callTimings struct {
+	mu           sync.Mutex
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+	reused       bool
+	remoteAddr   string
+}
+
+// clientTrace returns the httptrace hooks that record into t
+func (t *callTimings) clientTrace() *httptrace.ClientTrace {
+	record := func(field *time.Time, onlyFirst bool) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if onlyFirst && !field.IsZero() {
+			return
+		}
+		*field = time.Now()
+	}
+
+	return &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { record(&t.dnsStart, true) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { record(&t.dnsDone, false) },
+		ConnectStart:         func(string, string) { record(&t.connectStart, true) },
+		ConnectDone:          func(string, string, error) { record(&t.connectDone, false) },
+		TLSHandshakeStart:    func() { record(&t.tlsStart, true) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { record(&t.tlsDone, false) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { record(&t.wroteRequest, false) },
+		GotFirstResponseByte: func() { record(&t.firstByte, true) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.reused = info.Reused
+			if info.Conn != nil {
+				t.remoteAddr = info.Conn.RemoteAddr().String()
+			}
+		},
+	}
+}
+
+// apply copies the collected timings into event
+func (t *callTimings) apply(event *APICallEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	event.DNSMs = phaseMs(t.dnsStart, t.dnsDone)
+	event.ConnectMs = phaseMs(t.connectStart, t.connectDone)
+	event.TLSMs = phaseMs(t.tlsStart, t.tlsDone)
+	event.TTFBMs = phaseMs(t.wroteRequest, t.firstByte)
+	event.ConnReused = t.reused
+	event.RemoteAddr = t.remoteAddr
+}
+
+// phaseMs returns the milliseconds between start and end, or 0 if either is missing
+func phaseMs(start, end time.Time) int64 {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0
+	}
+	return end.Sub(start).Milliseconds()
+}
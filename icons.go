@@ -0,0 +1,89 @@
+package lifecycle
+
+import "strings"
+
+// defaultEventIcons returns the default icons for common event families
+func defaultEventIcons() map[string]string {
+	return map[string]string{
+		"service.started":      "🚀",
+		"service.healthy":      "💚",
+		"service.shutdown":     "🛑",
+		"service.crashed":      "💥",
+		"api.request.received": "📥",
+		"api.request.handled":  "✅",
+		"api.request.errored":  "❌",
+		"db.*":                 "🗄️",
+		"db.query.errored":     "❌",
+		"redis.*":              "🧰",
+		"messaging.*":          "📨",
+		"resource.created":     "✨",
+		"resource.updated":     "✏️",
+		"resource.deleted":     "🗑️",
+		"error.occurred":       "🔥",
+		"operation.timed_out":  "⏱️",
+	}
+}
+
+// RegisterEventIcon registers the icon shown before events whose type matches
+// eventType, an exact type or a path.Match pattern (e.g., "db.*"); an empty icon
+// removes it
+// Exact types take precedence over patterns, and longer patterns over shorter ones
+func (r *ColorRegistry) RegisterEventIcon(eventType, icon string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if icon == "" {
+		delete(r.eventIcons, eventType)
+	} else {
+		r.eventIcons[eventType] = icon
+	}
+	r.snapshot.Store(nil)
+}
+
+// GetEventIcon returns the icon for an event type, or empty string if none matches
+func (r *ColorRegistry) GetEventIcon(eventType string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return lookupIcon(r.eventIcons, eventType)
+}
+
+// GetEventIcon returns the icon for an event type, or empty string if none matches
+func (s *ColorSnapshot) GetEventIcon(eventType string) string {
+	if s == nil {
+		return ""
+	}
+	return lookupIcon(s.eventIcons, eventType)
+}
+
+// lookupIcon returns the icon registered for eventType, or for the longest pattern
+// matching it
+func lookupIcon(icons map[string]string, eventType string) string {
+	if icon, ok := icons[eventType]; ok {
+		return icon
+	}
+	best, icon := "", ""
+	for pattern, candidate := range icons {
+		if len(pattern) > len(best) && strings.ContainsAny(pattern, "*?[") && matchPattern(pattern, eventType) {
+			best, icon = pattern, candidate
+		}
+	}
+	return icon
+}
+
+// WithStyledIcons starts each event line with its event family's icon (e.g., "🚀" for
+// service.started, "❌" for errors), from the color registry's event icons
+// Icons are set with ColorRegistry.RegisterEventIcon or a theme's EventIcons
+func WithStyledIcons() StyledOutputOption {
+	return func(s *StyledOutput) {
+		s.icons = true
+	}
+}
+
+// eventIcon returns the icon shown before event, or ""
+// Icons are looked up in the registry rather than a color snapshot, so they are shown
+// even when colors are disabled
+func (s *StyledOutput) eventIcon(eventType string) string {
+	if !s.icons || s.colorRegistry == nil {
+		return ""
+	}
+	return s.colorRegistry.Snapshot().GetEventIcon(eventType)
+}
@@ -0,0 +1,162 @@
+package lifecycle
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// IDKind is what an ID identifies
+type IDKind string
+
+const (
+	IDCorrelation IDKind = "correlation"
+	IDEvent       IDKind = "event"
+	IDQuery       IDKind = "query"
+	IDTransaction IDKind = "transaction"
+)
+
+// IDGenerator returns a new ID of the given kind; it must be safe for concurrent use
+type IDGenerator func(kind IDKind) string
+
+// WithIDGenerator sets how the producer generates correlation, event, query, and
+// transaction IDs (default: RandomIDs)
+func WithIDGenerator(generator IDGenerator) ProducerOption {
+	return func(p *Producer) {
+		p.idGenerator = generator
+	}
+}
+
+// WithEventIDs gives every event a unique base.event_id from the ID generator, so
+// consumers can deduplicate events delivered more than once
+func WithEventIDs() ProducerOption {
+	return func(p *Producer) {
+		p.eventIDs = true
+	}
+}
+
+// newID returns a new ID of the given kind from the producer's generator
+func (p *Producer) newID(kind IDKind) string {
+	if p.idGenerator != nil {
+		return p.idGenerator(kind)
+	}
+	return RandomIDs(kind)
+}
+
+// NewCorrelationID returns a new ID for correlating a request's events
+func (p *Producer) NewCorrelationID() string {
+	return p.newID(IDCorrelation)
+}
+
+// NewQueryID returns a new ID for correlating a query's events
+func (p *Producer) NewQueryID() string {
+	return p.newID(IDQuery)
+}
+
+// NewTransactionID returns a new ID for correlating a transaction's events
+func (p *Producer) NewTransactionID() string {
+	return p.newID(IDTransaction)
+}
+
+// RandomIDs returns 16 random bytes, hex encoded
+func RandomIDs(kind IDKind) string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// crockford is the Crockford base32 alphabet used by ULIDs
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULIDs returns ULIDs: 26 characters that sort by creation time to the millisecond
+func ULIDs(kind IDKind) string {
+	var b [16]byte
+	putMillis(b[:6], time.Now())
+	_, _ = rand.Read(b[6:])
+
+	// 128 bits as 26 base32 digits, the first carrying only 3 bits
+	hi, lo := binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(b[8:])
+	var out [26]byte
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// UUIDv7s returns version 7 UUIDs, which sort by creation time to the millisecond
+func UUIDv7s(kind IDKind) string {
+	var b [16]byte
+	putMillis(b[:6], time.Now())
+	_, _ = rand.Read(b[6:])
+	b[6] = b[6]&0x0f | 0x70 // Version 7
+	b[8] = b[8]&0x3f | 0x80 // RFC 9562 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// putMillis writes t's Unix time in milliseconds to b as a 48-bit big-endian integer
+func putMillis(b []byte, t time.Time) {
+	ms := uint64(t.UnixMilli())
+	for i := 5; i >= 0; i-- {
+		b[i] = byte(ms)
+		ms >>= 8
+	}
+}
+
+// snowflakeEpoch is the start of snowflake timestamps
+var snowflakeEpoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// NewSnowflakeIDs returns a generator of snowflake IDs: 64-bit integers, in decimal, made
+// of milliseconds since 2024, node (0-1023), and a per-millisecond sequence
+// IDs are unique across nodes as long as each process uses its own node number
+func NewSnowflakeIDs(node int64) IDGenerator {
+	var (
+		mu       sync.Mutex
+		lastMs   int64
+		sequence int64
+	)
+	node &= 1<<10 - 1
+	return func(kind IDKind) string {
+		mu.Lock()
+		defer mu.Unlock()
+
+		ms := time.Since(snowflakeEpoch).Milliseconds()
+		if ms < lastMs {
+			ms = lastMs // The clock went backwards; keep IDs increasing
+		}
+		if ms == lastMs {
+			sequence = (sequence + 1) & (1<<12 - 1)
+			if sequence == 0 {
+				// Sequence exhausted for this millisecond
+				for ms <= lastMs {
+					time.Sleep(100 * time.Microsecond)
+					ms = time.Since(snowflakeEpoch).Milliseconds()
+				}
+			}
+		} else {
+			sequence = 0
+		}
+		lastMs = ms
+		return strconv.FormatInt(ms<<22|node<<12|sequence, 10)
+	}
+}
+
+// NewSequentialIDs returns a deterministic generator for tests: each kind counts from 1
+// ("query-1", "query-2", "transaction-1", ...)
+func NewSequentialIDs() IDGenerator {
+	var (
+		mu     sync.Mutex
+		counts = make(map[IDKind]int64)
+	)
+	return func(kind IDKind) string {
+		mu.Lock()
+		defer mu.Unlock()
+		counts[kind]++
+		return fmt.Sprintf("%s-%d", kind, counts[kind])
+	}
+}
@@ -0,0 +1,139 @@
+package lifecycle
+
+import (
+	"context"
+	"net"
+	"strings"
+)
+
+// WithFullIPAddresses records client addresses on request events and access logs as
+// received (default: anonymized with AnonymizeIP)
+func WithFullIPAddresses() ProducerOption {
+	return func(p *Producer) {
+		p.fullIPs = true
+	}
+}
+
+// clientAddr returns a client address as the producer records it
+func (p *Producer) clientAddr(addr string) string {
+	if p.fullIPs {
+		return addr
+	}
+	return redactIP(addr, p.redactor.mask())
+}
+
+// remoteAddr returns the client address from ctx as the producer records it
+func (p *Producer) remoteAddr(ctx context.Context) string {
+	return p.clientAddr(extractRemoteAddr(ctx))
+}
+
+// AnonymizeIP zeroes the last octet of IPv4 addresses and truncates IPv6 addresses to
+// their /48 prefix, keeping enough of the address to locate a client roughly
+// addr may carry a port, which is dropped ("203.0.113.42:51234" -> "203.0.113.0"), or
+// be a comma-separated list such as an X-Forwarded-For header; parts that are not IP
+// addresses are returned unchanged, so check untrusted values with isIPList first
+func AnonymizeIP(addr string) string {
+	if strings.Contains(addr, ",") {
+		parts := strings.Split(addr, ",")
+		for i, part := range parts {
+			parts[i] = AnonymizeIP(strings.TrimSpace(part))
+		}
+		return strings.Join(parts, ", ")
+	}
+
+	ip := parseIP(addr)
+	if ip == nil {
+		return addr
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String()
+	}
+	return ip.Mask(net.CIDRMask(48, 128)).String()
+}
+
+// parseIP parses an IP address with an optional port and IPv6 zone
+func parseIP(addr string) net.IP {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	addr = strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]")
+	if i := strings.IndexByte(addr, '%'); i >= 0 {
+		addr = addr[:i]
+	}
+	return net.ParseIP(addr)
+}
+
+// ipFieldNames are the field names, lowercased without separators, whose values are
+// anonymized rather than redacted
+var ipFieldNames = map[string]bool{
+	"ip": true, "ipaddr": true, "ipaddress": true,
+	"remoteaddr": true, "remoteip": true, "clientip": true, "clientaddr": true,
+	"sourceip": true, "srcip": true, "xforwardedfor": true, "xrealip": true,
+}
+
+// isIPField reports whether a field name holds client IP addresses (e.g., "remote_addr",
+// "X-Forwarded-For")
+func isIPField(name string) bool {
+	if len(name) > len("x-forwarded-for") {
+		return false
+	}
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		switch c := name[i]; {
+		case c == '-' || c == '_' || c == '.':
+		case 'A' <= c && c <= 'Z':
+			b.WriteByte(c + 'a' - 'A')
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return ipFieldNames[b.String()]
+}
+
+// isIPList reports whether every comma-separated part of addr is an IP address
+func isIPList(addr string) bool {
+	for _, part := range strings.Split(addr, ",") {
+		if parseIP(strings.TrimSpace(part)) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// redactIP anonymizes addr if it is an IP address or a list of them, and replaces it
+// with mask otherwise, so names and hostnames in IP fields are never recorded
+func redactIP(addr, mask string) string {
+	if addr == "" {
+		return addr
+	}
+	if !isIPList(addr) {
+		return mask
+	}
+	return AnonymizeIP(addr)
+}
+
+// redactIPValue redacts an IP field's value with redactIP: a string, or the strings of
+// a decoded header; it returns false for other values
+func redactIPValue(value interface{}, mask string) (interface{}, bool) {
+	switch v := value.(type) {
+	case string:
+		return redactIP(v, mask), true
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			redacted[i] = redactIP(s, mask)
+		}
+		return redacted, true
+	case []string:
+		redacted := make([]interface{}, len(v))
+		for i, s := range v {
+			redacted[i] = redactIP(s, mask)
+		}
+		return redacted, true
+	}
+	return nil, false
+}
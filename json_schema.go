@@ -0,0 +1,188 @@
+package lifecycle
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// JSONSchemaDialect is the JSON Schema version of the schemas returned by SchemaFor
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// SchemaFor returns the JSON Schema of an event type (built-in or registered with
+// RegisterEventType) in the nested form written by the Producer, for consumers in other
+// languages. Unknown fields are allowed, so schemas stay valid as events gain fields
+func SchemaFor(eventType string) (map[string]interface{}, error) {
+	info, ok := LookupEventType(eventType)
+	if !ok {
+		return nil, fmt.Errorf("unknown event type %q", eventType)
+	}
+
+	base := objectSchema(BaseEventFields)
+	base["properties"].(map[string]interface{})["event_type"] = map[string]interface{}{"const": eventType}
+
+	schema := objectSchema(info.Fields)
+	schema["properties"].(map[string]interface{})["base"] = base
+	schema["required"] = append([]string{"base"}, schema["required"].([]string)...)
+	schema["$schema"] = JSONSchemaDialect
+	schema["title"] = eventType
+	if info.Description != "" {
+		schema["description"] = info.Description
+	}
+	return schema, nil
+}
+
+// objectSchema returns the schema of an object with fields
+func objectSchema(fields []EventFieldInfo) map[string]interface{} {
+	properties := make(map[string]interface{}, len(fields))
+	required := []string{}
+	for _, f := range fields {
+		properties[f.Name] = fieldSchema(f)
+		if f.Required {
+			required = append(required, f.Name)
+		}
+	}
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+// fieldSchema returns the schema of a field's values
+func fieldSchema(f EventFieldInfo) map[string]interface{} {
+	schema := typeSchema(f.Type, f.Items, f.Fields)
+	if f.Nullable {
+		if typ, ok := schema["type"].(string); ok {
+			schema["type"] = []string{typ, "null"}
+		}
+	}
+	if f.Description != "" {
+		schema["description"] = f.Description
+	}
+	return schema
+}
+
+// typeSchema returns the schema of an EventFieldInfo type
+func typeSchema(typ, items string, fields []EventFieldInfo) map[string]interface{} {
+	switch typ {
+	case "timestamp":
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case "object":
+		if len(fields) == 0 {
+			return map[string]interface{}{"type": "object"}
+		}
+		return objectSchema(fields)
+	case "array":
+		return map[string]interface{}{"type": "array", "items": typeSchema(items, "", fields)}
+	case "string", "integer", "number", "boolean":
+		return map[string]interface{}{"type": typ}
+	}
+	return map[string]interface{}{}
+}
+
+// ValidateRecordSchema checks a record's fields against its event type's schema: required
+// fields are present and every described field has the right type. Records of unknown
+// event types and fields the schema does not describe are accepted. Use it with
+// WithReceiverSchemaValidation, or on records from ReadRecords
+func ValidateRecordSchema(r *Record) error {
+	info, ok := LookupEventType(r.EventType)
+	if !ok {
+		return nil
+	}
+	return validateFields(r.Fields, info.Fields, "")
+}
+
+// validateFields checks the values of an object against its fields
+func validateFields(values map[string]interface{}, fields []EventFieldInfo, path string) error {
+	for _, f := range fields {
+		value, ok := values[f.Name]
+		switch {
+		case !ok && f.Required:
+			return fmt.Errorf("missing field %s%s", path, f.Name)
+		case !ok:
+			continue
+		case value == nil:
+			if !f.Nullable {
+				return fmt.Errorf("field %s%s: must not be null", path, f.Name)
+			}
+			continue
+		}
+		if err := validateValue(value, f.Type, f.Items, f.Fields, path+f.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateValue checks that value has the type described by typ, items, and fields
+func validateValue(value interface{}, typ, items string, fields []EventFieldInfo, path string) error {
+	var ok bool
+	switch typ {
+	case "string":
+		_, ok = value.(string)
+	case "boolean":
+		_, ok = value.(bool)
+	case "number":
+		_, ok = filterNumber(value)
+		_, isString := value.(string)
+		ok = ok && !isString
+	case "integer":
+		switch v := value.(type) {
+		case json.Number:
+			ok = !strings.ContainsAny(v.String(), ".eE")
+		case float64:
+			ok = v == math.Trunc(v)
+		}
+	case "timestamp":
+		if s, isString := value.(string); isString {
+			_, err := time.Parse(time.RFC3339Nano, s)
+			ok = err == nil
+		}
+	case "object":
+		var object map[string]interface{}
+		if object, ok = value.(map[string]interface{}); ok {
+			return validateFields(object, fields, path+".")
+		}
+	case "array":
+		var array []interface{}
+		if array, ok = value.([]interface{}); ok {
+			for i, item := range array {
+				if item == nil {
+					continue
+				}
+				if err := validateValue(item, items, "", fields, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	default:
+		return nil // Any value
+	}
+	if !ok {
+		return fmt.Errorf("field %s: expected %s, got %s", path, typ, jsonTypeName(value))
+	}
+	return nil
+}
+
+// jsonTypeName names the JSON type of a decoded value
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number, float64:
+		return "number"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+	return fmt.Sprintf("%T", value)
+}
@@ -0,0 +1,255 @@
+package lifecycle
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultLatencyBuckets are the upper bounds, in milliseconds, of latency histogram buckets
+// Slower requests fall in a final overflow bucket
+var DefaultLatencyBuckets = []float64{1, 2, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// DefaultHeatmapColumns is the number of time intervals in a latency heatmap
+const DefaultHeatmapColumns = 60
+
+// heatmapShades draws heatmap cells from empty to busiest
+const heatmapShades = " .:-=+*#%@"
+
+// LatencyStats is the latency distribution of requests per endpoint, overall and over time
+type LatencyStats struct {
+	Start     time.Time         // Timestamp of the earliest request
+	End       time.Time         // Timestamp of the latest request
+	Interval  time.Duration     // Time covered by each heatmap column
+	Buckets   []float64         // Histogram bucket upper bounds (ms), without the overflow bucket
+	Endpoints []EndpointLatency // Busiest first
+}
+
+// EndpointLatency is the latency distribution of one endpoint's requests
+// Histogram and each heatmap column count requests per bucket, with the overflow bucket last
+type EndpointLatency struct {
+	Endpoint  string
+	Requests  int
+	P50Ms     float64
+	P95Ms     float64
+	P99Ms     float64
+	MaxMs     float64
+	Histogram []int
+	Heatmap   [][]int // Columns in time order
+}
+
+// LatencyStatsOptions configures BuildLatencyStats
+type LatencyStatsOptions struct {
+	Buckets []float64          // Bucket upper bounds in ms, ascending (default: DefaultLatencyBuckets)
+	Columns int                // Heatmap columns (default: DefaultHeatmapColumns)
+	Filter  func(*Record) bool // Only requests whose completion event it accepts are counted, e.g., a Filter's Match
+}
+
+// BuildLatencyStats reads an NDJSON event stream and computes the latency distribution of
+// its requests (api.request.handled and api.request.errored events with a duration_ms),
+// grouped by endpoint as in BuildReport
+func BuildLatencyStats(r io.Reader, opts LatencyStatsOptions) (*LatencyStats, error) {
+	if len(opts.Buckets) == 0 {
+		opts.Buckets = DefaultLatencyBuckets
+	}
+	if opts.Columns <= 0 {
+		opts.Columns = DefaultHeatmapColumns
+	}
+
+	type sample struct {
+		endpoint  string
+		timestamp time.Time
+		duration  float64
+	}
+	var samples []sample
+	endpoints := make(endpointResolver)
+	stats := &LatencyStats{Buckets: opts.Buckets}
+
+	err := ReadRecords(r, func(record *Record) error {
+		switch record.EventType {
+		case "api.request.received":
+			endpoints.observe(record)
+		case "api.request.handled", "api.request.errored":
+			duration, ok := record.NumberField("duration_ms")
+			if !ok || (opts.Filter != nil && !opts.Filter(record)) {
+				return nil
+			}
+			samples = append(samples, sample{endpoint: endpoints.endpoint(record), timestamp: record.Timestamp, duration: duration})
+			if !record.Timestamp.IsZero() {
+				if stats.Start.IsZero() || record.Timestamp.Before(stats.Start) {
+					stats.Start = record.Timestamp
+				}
+				if record.Timestamp.After(stats.End) {
+					stats.End = record.Timestamp
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Round the interval up, to a readable length that lets the columns cover the window
+	interval := stats.End.Sub(stats.Start)/time.Duration(opts.Columns) + 1
+	unit := time.Millisecond
+	switch {
+	case interval >= time.Minute:
+		unit = time.Second
+	case interval >= time.Second:
+		unit = 100 * time.Millisecond
+	}
+	stats.Interval = (interval + unit - 1) / unit * unit
+
+	byEndpoint := make(map[string]*EndpointLatency)
+	durations := make(map[string][]float64)
+	for _, s := range samples {
+		e, ok := byEndpoint[s.endpoint]
+		if !ok {
+			e = &EndpointLatency{
+				Endpoint:  s.endpoint,
+				Histogram: make([]int, len(opts.Buckets)+1),
+				Heatmap:   make([][]int, opts.Columns),
+			}
+			for i := range e.Heatmap {
+				e.Heatmap[i] = make([]int, len(opts.Buckets)+1)
+			}
+			byEndpoint[s.endpoint] = e
+		}
+		e.Requests++
+		durations[s.endpoint] = append(durations[s.endpoint], s.duration)
+
+		bucket := sort.SearchFloat64s(opts.Buckets, s.duration)
+		e.Histogram[bucket]++
+		column := 0
+		if !s.timestamp.IsZero() {
+			column = int(s.timestamp.Sub(stats.Start) / stats.Interval)
+		}
+		if column >= opts.Columns {
+			column = opts.Columns - 1
+		}
+		e.Heatmap[column][bucket]++
+	}
+
+	for endpoint, e := range byEndpoint {
+		sorted := durations[endpoint]
+		sort.Float64s(sorted)
+		e.P50Ms = Percentile(sorted, 50)
+		e.P95Ms = Percentile(sorted, 95)
+		e.P99Ms = Percentile(sorted, 99)
+		e.MaxMs = sorted[len(sorted)-1]
+		stats.Endpoints = append(stats.Endpoints, *e)
+	}
+	sort.Slice(stats.Endpoints, func(i, j int) bool {
+		if stats.Endpoints[i].Requests != stats.Endpoints[j].Requests {
+			return stats.Endpoints[i].Requests > stats.Endpoints[j].Requests
+		}
+		return stats.Endpoints[i].Endpoint < stats.Endpoints[j].Endpoint
+	})
+	return stats, nil
+}
+
+// WriteText renders each endpoint's percentiles, histogram, and heatmap as plain text
+// The heatmap has the slowest bucket at the top and time running left to right; darker
+// characters are busier cells
+func (s *LatencyStats) WriteText(w io.Writer) error {
+	var b strings.Builder
+	if len(s.Endpoints) == 0 {
+		b.WriteString("No requests recorded.\n")
+	}
+	for i, e := range s.Endpoints {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%s  %d requests  p50 %s  p95 %s  p99 %s  max %s\n",
+			e.Endpoint, e.Requests, formatMs(e.P50Ms), formatMs(e.P95Ms), formatMs(e.P99Ms), formatMs(e.MaxMs))
+
+		low, high := bucketRange(e.Histogram)
+		labels := make([]string, len(e.Histogram))
+		width := 0
+		for bucket := low; bucket <= high; bucket++ {
+			labels[bucket] = s.bucketLabel(bucket)
+			width = max(width, len(labels[bucket]))
+		}
+
+		b.WriteString("\n  Histogram\n")
+		peak := 0
+		for _, count := range e.Histogram {
+			peak = max(peak, count)
+		}
+		for bucket := low; bucket <= high; bucket++ {
+			count := e.Histogram[bucket]
+			bar := 0
+			if peak > 0 {
+				bar = (count*40 + peak - 1) / peak
+			}
+			fmt.Fprintf(&b, "  %*s |%-40s %d\n", width, labels[bucket], strings.Repeat("#", bar), count)
+		}
+
+		fmt.Fprintf(&b, "\n  Over time (%s to %s, %s per column)\n",
+			s.Start.Format(time.RFC3339), s.End.Format(time.RFC3339), s.Interval)
+		peak = 0
+		for _, column := range e.Heatmap {
+			for _, count := range column {
+				peak = max(peak, count)
+			}
+		}
+		for bucket := high; bucket >= low; bucket-- {
+			fmt.Fprintf(&b, "  %*s |", width, labels[bucket])
+			for _, column := range e.Heatmap {
+				b.WriteByte(heatmapShade(column[bucket], peak))
+			}
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "  %*s +%s\n", width, "", strings.Repeat("-", len(e.Heatmap)))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// bucketLabel names a histogram bucket by its upper bound, e.g., "<=250ms" or ">10s"
+func (s *LatencyStats) bucketLabel(bucket int) string {
+	if bucket < len(s.Buckets) {
+		return "<=" + formatMs(s.Buckets[bucket])
+	}
+	return ">" + formatMs(s.Buckets[len(s.Buckets)-1])
+}
+
+// bucketRange returns the first and last non-empty buckets of histogram
+func bucketRange(histogram []int) (low, high int) {
+	low, high = len(histogram)-1, 0
+	for bucket, count := range histogram {
+		if count > 0 {
+			low = min(low, bucket)
+			high = max(high, bucket)
+		}
+	}
+	if low > high {
+		return 0, len(histogram) - 1
+	}
+	return low, high
+}
+
+// heatmapShade returns the character for a cell holding count of at most peak requests
+// Any non-empty cell is visible
+func heatmapShade(count, peak int) byte {
+	if count == 0 || peak == 0 {
+		return heatmapShades[0]
+	}
+	shades := len(heatmapShades) - 1
+	return heatmapShades[1+(count*shades-1)/peak]
+}
+
+// formatMs formats a duration in milliseconds compactly, e.g., "0.4ms", "12ms", or "2.5s"
+func formatMs(ms float64) string {
+	switch {
+	case ms >= 1000:
+		return strings.TrimSuffix(fmt.Sprintf("%.1f", ms/1000), ".0") + "s"
+	case ms >= 10:
+		return fmt.Sprintf("%.0fms", ms)
+	default:
+		return strings.TrimSuffix(fmt.Sprintf("%.1f", ms), ".0") + "ms"
+	}
+}
@@ -0,0 +1,126 @@
+package lifecycle
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// LeadershipEvent represents a leadership.acquired, leadership.renewed, or
+// leadership.lost event
+type LeadershipEvent struct {
+	Base   *BaseEvent `json:"base"`
+	Lock   string     `json:"lock"`             // Name of the election lock or lease
+	Holder string     `json:"holder"`           // Identity of this candidate
+	HeldMs int64      `json:"held_ms"`          // Time leadership has been held (renewed and lost only)
+	Reason string     `json:"reason,omitempty"` // Why leadership was lost
+}
+
+func (e *LeadershipEvent) GetEventType() string     { return e.Base.GetEventType() }
+func (e *LeadershipEvent) GetTimestamp() time.Time  { return e.Base.GetTimestamp() }
+func (e *LeadershipEvent) GetService() string       { return e.Base.GetService() }
+func (e *LeadershipEvent) GetAPI() string           { return e.Base.GetAPI() }
+func (e *LeadershipEvent) GetHost() string          { return e.Base.GetHost() }
+func (e *LeadershipEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *LeadershipEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *LeadershipEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *LeadershipEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
+
+// EmitLeadershipAcquired emits a leadership.acquired event
+func (p *Producer) EmitLeadershipAcquired(ctx context.Context, lock, holder string) error {
+	return p.emitLeadership(ctx, "leadership.acquired", lock, holder, "", 0)
+}
+
+// EmitLeadershipRenewed emits a leadership.renewed event
+func (p *Producer) EmitLeadershipRenewed(ctx context.Context, lock, holder string, held time.Duration) error {
+	return p.emitLeadership(ctx, "leadership.renewed", lock, holder, "", held)
+}
+
+// EmitLeadershipLost emits a leadership.lost event
+func (p *Producer) EmitLeadershipLost(ctx context.Context, lock, holder, reason string, held time.Duration) error {
+	return p.emitLeadership(ctx, "leadership.lost", lock, holder, reason, held)
+}
+
+// emitLeadership emits a leadership event
+func (p *Producer) emitLeadership(ctx context.Context, eventType, lock, holder, reason string, held time.Duration) error {
+	event := &LeadershipEvent{
+		Base:   p.createBaseEvent(eventType, "", nil),
+		Lock:   lock,
+		Holder: holder,
+		HeldMs: held.Milliseconds(),
+		Reason: reason,
+	}
+	return p.emitEvent(ctx, event, held)
+}
+
+// LeadershipRecorder is the interface leader election code reports through, so
+// Kubernetes lease, etcd, or custom elections emit consistent events
+//
+// With client-go's leaderelection package, for example:
+//
+//	rec := producer.LeadershipRecorder("controller-lock", identity)
+//	leaderelection.LeaderCallbacks{
+//		OnStartedLeading: func(ctx context.Context) { rec.Acquired(ctx) },
+//		OnStoppedLeading: func() { rec.Lost(context.Background(), "lease not renewed") },
+//	}
+type LeadershipRecorder interface {
+	Acquired(ctx context.Context)
+	Renewed(ctx context.Context)
+	Lost(ctx context.Context, reason string)
+}
+
+// LeadershipRecorder returns a recorder for one lock and candidate identity
+// It tracks how long leadership has been held and ignores Renewed and Lost calls
+// while not leading, so callbacks can be wired without extra bookkeeping
+func (p *Producer) LeadershipRecorder(lock, identity string) LeadershipRecorder {
+	return &leadershipRecorder{producer: p, lock: lock, identity: identity}
+}
+
+// leadershipRecorder implements LeadershipRecorder
+type leadershipRecorder struct {
+	producer *Producer
+	lock     string
+	identity string
+
+	mu       sync.Mutex
+	acquired time.Time // Zero while not leading
+}
+
+func (r *leadershipRecorder) Acquired(ctx context.Context) {
+	r.mu.Lock()
+	if !r.acquired.IsZero() {
+		r.mu.Unlock()
+		return
+	}
+	r.acquired = time.Now()
+	r.mu.Unlock()
+
+	_ = r.producer.EmitLeadershipAcquired(ctx, r.lock, r.identity)
+}
+
+func (r *leadershipRecorder) Renewed(ctx context.Context) {
+	r.mu.Lock()
+	acquired := r.acquired
+	r.mu.Unlock()
+	if acquired.IsZero() {
+		return
+	}
+
+	_ = r.producer.EmitLeadershipRenewed(ctx, r.lock, r.identity, time.Since(acquired))
+}
+
+func (r *leadershipRecorder) Lost(ctx context.Context, reason string) {
+	r.mu.Lock()
+	acquired := r.acquired
+	r.acquired = time.Time{}
+	r.mu.Unlock()
+	if acquired.IsZero() {
+		return
+	}
+
+	_ = r.producer.EmitLeadershipLost(ctx, r.lock, r.identity, reason, time.Since(acquired))
+}
@@ -0,0 +1,133 @@
+package lifecycle
+
+import (
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// levelRegistry maps event types and patterns to severity levels
+type levelRegistry struct {
+	mu     sync.RWMutex
+	levels map[string]slog.Level // Event type or path.Match pattern -> level
+	cache  map[string]slog.Level // Resolved event types, cleared on registration
+}
+
+// maxCachedEventLevels bounds the resolved event types cached; event types come from
+// received and decoded events too, so there may be any number of them. Types beyond it
+// are resolved on every lookup
+const maxCachedEventLevels = 4096
+
+// levels is the registry shared by EventLevel, sinks, and styled output
+var levels = &levelRegistry{levels: defaultEventLevels(), cache: make(map[string]slog.Level)}
+
+// defaultEventLevels returns the built-in levels: the lifecycle package's own event
+// types, then patterns classifying other event types by the words in them
+func defaultEventLevels() map[string]slog.Level {
+	return map[string]slog.Level{
+		// High-volume detail
+		"db.query.started":         slog.LevelDebug,
+		"db.query.completed":       slog.LevelDebug,
+		"db.transaction.started":   slog.LevelDebug,
+		"db.transaction.committed": slog.LevelDebug,
+		"redis.command.started":    slog.LevelDebug,
+		"redis.command.completed":  slog.LevelDebug,
+		"messaging.ack":            slog.LevelDebug,
+		"leadership.renewed":       slog.LevelDebug,
+		"diagnostics.goroutines":   slog.LevelDebug,
+		"diagnostics.memory":       slog.LevelDebug,
+
+		// Degradation worth a look
+		"db.query.slow":              slog.LevelWarn,
+		"db.transaction.rolled_back": slog.LevelWarn,
+		"api.request.retried":        slog.LevelWarn,
+		"operation.retried":          slog.LevelWarn,
+		"messaging.nack":             slog.LevelWarn,
+		"leadership.lost":            slog.LevelWarn,
+		"service.not_ready":          slog.LevelWarn,
+		"tls.cert.expiring":          slog.LevelWarn,
+		"lifecycle.anomaly":          slog.LevelWarn,
+		"lifecycle.events.dropped":   slog.LevelWarn,
+
+		// Failures
+		"error.occurred":         slog.LevelError,
+		"service.crashed":        slog.LevelError,
+		"service.not_live":       slog.LevelError,
+		"dependency.unavailable": slog.LevelError,
+		"operation.timed_out":    slog.LevelError,
+		"runtime.gopanic":        slog.LevelError,
+
+		// Other event types, by the words in them
+		"*error*":       slog.LevelError,
+		"*fail*":        slog.LevelError,
+		"*crash*":       slog.LevelError,
+		"*panic*":       slog.LevelError,
+		"*unavailable*": slog.LevelError,
+		"*timed_out*":   slog.LevelError,
+		"*warn*":        slog.LevelWarn,
+		"*not_ready*":   slog.LevelWarn,
+		"*lost*":        slog.LevelWarn,
+		"*expiring*":    slog.LevelWarn,
+		"*dropped*":     slog.LevelWarn,
+		"*slow*":        slog.LevelWarn,
+		"*debug*":       slog.LevelDebug,
+		"*trace*":       slog.LevelDebug,
+	}
+}
+
+// RegisterEventLevel sets the level of events whose type matches eventType, an exact type
+// or a path.Match pattern (e.g., "payments.*" or "*.audit"), for styled output, sink
+// levels (SinkMinLevel), and base.level (WithEventLevels)
+// Exact types take precedence over patterns, and longer patterns over shorter ones; event
+// types nothing matches are Info. Register levels before emitting, as events already
+// written keep the level they were given
+func RegisterEventLevel(eventType string, level slog.Level) {
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+	levels.levels[eventType] = level
+	levels.cache = make(map[string]slog.Level)
+}
+
+// eventTypeLevel returns the level registered for an event type
+func eventTypeLevel(eventType string) slog.Level {
+	levels.mu.RLock()
+	level, ok := levels.cache[eventType]
+	levels.mu.RUnlock()
+	if ok {
+		return level
+	}
+
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+	level = levels.resolve(eventType)
+	if len(levels.cache) < maxCachedEventLevels {
+		levels.cache[eventType] = level
+	}
+	return level
+}
+
+// resolve looks up the level of an event type; the lock must be held
+func (l *levelRegistry) resolve(eventType string) slog.Level {
+	if level, ok := l.levels[eventType]; ok {
+		return level
+	}
+	best, level := "", slog.LevelInfo
+	for pattern, candidate := range l.levels {
+		if !strings.ContainsAny(pattern, "*?[") || !matchPattern(pattern, eventType) {
+			continue
+		}
+		// Longer patterns are more specific; ties go to the more severe level
+		if len(pattern) > len(best) || (len(pattern) == len(best) && candidate > level) {
+			best, level = pattern, candidate
+		}
+	}
+	return level
+}
+
+// WithEventLevels records each event's level (see EventLevel) as base.level, such as
+// "INFO" or "ERROR", for log pipelines that filter or route by severity
+func WithEventLevels() ProducerOption {
+	return func(p *Producer) {
+		p.eventLevels = true
+	}
+}
@@ -0,0 +1,24 @@
+package lifecycle
+
+import (
+	"fmt"
+	"log/slog"
+	"testing"
+)
+
+// TestEventLevelCacheBounded checks that looking up many distinct event types, as a
+// receiver may, does not grow the level cache past its bound and still resolves levels
+func TestEventLevelCacheBounded(t *testing.T) {
+	for i := 0; i < 2*maxCachedEventLevels; i++ {
+		eventType := fmt.Sprintf("remote%d.payment.failed", i)
+		if level := eventTypeLevel(eventType); level != slog.LevelError {
+			t.Fatalf("level of %s = %v, want %v", eventType, level, slog.LevelError)
+		}
+	}
+
+	levels.mu.RLock()
+	defer levels.mu.RUnlock()
+	if n := len(levels.cache); n > maxCachedEventLevels {
+		t.Errorf("%d cached levels, want at most %d", n, maxCachedEventLevels)
+	}
+}
@@ -0,0 +1,98 @@
+// Package lifecycleamqp instruments RabbitMQ publishing and consuming (amqp091-go) with
+// lifecycle messaging events
+package lifecycleamqp
+
+import (
+	"context"
+	"time"
+
+	"github.com/SCKelemen/lifecycle"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Publish publishes msg on ch like ch.PublishWithContext, adding the correlation ID from
+// ctx as a header, and emits messaging.published or messaging.publish_failed
+func Publish(ctx context.Context, producer *lifecycle.Producer, ch *amqp.Channel, exchange, key string,
+	mandatory, immediate bool, msg amqp.Publishing) error {
+	if id := lifecycle.CorrelationIDFromContext(ctx); id != "" {
+		if msg.Headers == nil {
+			msg.Headers = amqp.Table{}
+		}
+		if _, ok := msg.Headers[lifecycle.CorrelationIDHeader]; !ok {
+			msg.Headers[lifecycle.CorrelationIDHeader] = id
+		}
+	}
+
+	start := time.Now()
+	err := ch.PublishWithContext(ctx, exchange, key, mandatory, immediate, msg)
+	_ = producer.EmitMessagePublished(ctx, &lifecycle.Message{
+		System:      "rabbitmq",
+		Destination: exchange,
+		RoutingKey:  key,
+		MessageID:   msg.MessageId,
+		SizeBytes:   len(msg.Body),
+	}, time.Since(start), err)
+	return err
+}
+
+// Handle runs handle for a delivery from queue, with the delivery's correlation ID in ctx,
+// and emits messaging.consumed or messaging.consume_failed with the processing time
+// It then settles the delivery: on success it is acked (messaging.ack); on failure it is
+// nacked (messaging.nack), and requeued only if this was its first delivery, so a message
+// that keeps failing is dead-lettered rather than redelivered forever
+// Deliveries must come from a consumer without auto-ack
+func Handle(ctx context.Context, producer *lifecycle.Producer, queue string, d amqp.Delivery,
+	handle func(ctx context.Context, d amqp.Delivery) error) error {
+	ctx = ContextFromDelivery(ctx, d)
+	msg := deliveryMessage(queue, d)
+
+	start := time.Now()
+	err := handle(ctx, d)
+	_ = producer.EmitMessageConsumed(ctx, msg, time.Since(start), err)
+
+	if err != nil {
+		if nackErr := Nack(ctx, producer, queue, d, !d.Redelivered, err.Error()); nackErr != nil {
+			return nackErr
+		}
+		return err
+	}
+	return Ack(ctx, producer, queue, d)
+}
+
+// Ack acknowledges a delivery from queue and emits messaging.ack
+func Ack(ctx context.Context, producer *lifecycle.Producer, queue string, d amqp.Delivery) error {
+	if err := d.Ack(false); err != nil {
+		return err
+	}
+	_ = producer.EmitMessageAck(ctx, deliveryMessage(queue, d))
+	return nil
+}
+
+// Nack rejects a delivery from queue, returning it to the queue if requeue is set, and
+// emits messaging.nack with reason
+func Nack(ctx context.Context, producer *lifecycle.Producer, queue string, d amqp.Delivery, requeue bool, reason string) error {
+	if err := d.Nack(false, requeue); err != nil {
+		return err
+	}
+	_ = producer.EmitMessageNack(ctx, deliveryMessage(queue, d), requeue, reason)
+	return nil
+}
+
+// ContextFromDelivery returns ctx carrying the correlation ID from the delivery's headers
+func ContextFromDelivery(ctx context.Context, d amqp.Delivery) context.Context {
+	if id, ok := d.Headers[lifecycle.CorrelationIDHeader].(string); ok && id != "" {
+		return lifecycle.ContextWithCorrelationID(ctx, id)
+	}
+	return ctx
+}
+
+// deliveryMessage describes a delivery
+func deliveryMessage(queue string, d amqp.Delivery) *lifecycle.Message {
+	return &lifecycle.Message{
+		System:      "rabbitmq",
+		Destination: queue,
+		RoutingKey:  d.RoutingKey,
+		MessageID:   d.MessageId,
+		SizeBytes:   len(d.Body),
+	}
+}
@@ -0,0 +1,155 @@
+// Package lifecyclegorm provides a GORM plugin that emits lifecycle query and transaction
+// events, with the model name (e.g., "models.User") as the API identifier
+package lifecyclegorm
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/SCKelemen/lifecycle"
+	"gorm.io/gorm"
+)
+
+// Instance keys for state carried between a statement's callbacks
+const (
+	startKey       = "lifecycle:started_at"
+	transactionKey = "lifecycle:transaction"
+)
+
+// Plugin emits db.query.* events for every GORM statement and db.transaction.* events for
+// GORM's default transactions (see Transaction for explicit ones)
+type Plugin struct {
+	producer *lifecycle.Producer
+}
+
+var _ gorm.Plugin = (*Plugin)(nil)
+
+// New creates a plugin that emits through producer:
+//
+//	db.Use(lifecyclegorm.New(producer))
+func New(producer *lifecycle.Producer) *Plugin {
+	return &Plugin{producer: producer}
+}
+
+// Name identifies the plugin to GORM
+func (p *Plugin) Name() string {
+	return "lifecycle"
+}
+
+// Initialize registers the plugin's callbacks around each GORM operation
+func (p *Plugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("lifecycle:before_create", p.before),
+		cb.Create().After("gorm:create").Register("lifecycle:after_create", p.after),
+		cb.Query().Before("gorm:query").Register("lifecycle:before_query", p.before),
+		cb.Query().After("gorm:query").Register("lifecycle:after_query", p.after),
+		cb.Update().Before("gorm:update").Register("lifecycle:before_update", p.before),
+		cb.Update().After("gorm:update").Register("lifecycle:after_update", p.after),
+		cb.Delete().Before("gorm:delete").Register("lifecycle:before_delete", p.before),
+		cb.Delete().After("gorm:delete").Register("lifecycle:after_delete", p.after),
+		cb.Row().Before("gorm:row").Register("lifecycle:before_row", p.before),
+		cb.Row().After("gorm:row").Register("lifecycle:after_row", p.after),
+		cb.Raw().Before("gorm:raw").Register("lifecycle:before_raw", p.before),
+		cb.Raw().After("gorm:raw").Register("lifecycle:after_raw", p.after),
+
+		cb.Create().After("gorm:begin_transaction").Before("gorm:create").Register("lifecycle:begin_create", p.begin),
+		cb.Create().After("gorm:commit_or_rollback_transaction").Register("lifecycle:end_create", p.end),
+		cb.Update().After("gorm:begin_transaction").Before("gorm:update").Register("lifecycle:begin_update", p.begin),
+		cb.Update().After("gorm:commit_or_rollback_transaction").Register("lifecycle:end_update", p.end),
+		cb.Delete().After("gorm:begin_transaction").Before("gorm:delete").Register("lifecycle:begin_delete", p.begin),
+		cb.Delete().After("gorm:commit_or_rollback_transaction").Register("lifecycle:end_delete", p.end),
+	)
+}
+
+// before records when a statement starts
+func (p *Plugin) before(db *gorm.DB) {
+	db.InstanceSet(startKey, time.Now())
+}
+
+// after emits db.query.started, backdated to the start, and db.query.completed or
+// db.query.errored once the statement has run
+// The started event is emitted late because GORM builds the SQL during the operation
+func (p *Plugin) after(db *gorm.DB) {
+	value, ok := db.InstanceGet(startKey)
+	if !ok {
+		return
+	}
+	start := value.(time.Time)
+	duration := time.Since(start)
+	query := db.Statement.SQL.String()
+	if query == "" && db.Error == nil {
+		return // Nothing was executed (e.g., DryRun)
+	}
+
+	ctx := db.Statement.Context
+	opts := modelOptions(db)
+	queryID := p.producer.NewQueryID()
+	_ = p.producer.EmitQueryStarted(ctx, queryID, query, db.Statement.Vars,
+		append(opts, lifecycle.WithEventTimestamp(start))...)
+
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		_ = p.producer.EmitQueryErrored(ctx, queryID, db.Error.Error(), "", duration.Milliseconds(), opts...)
+		return
+	}
+	_ = p.producer.EmitQueryCompleted(ctx, queryID, duration.Milliseconds(), db.RowsAffected, opts...)
+}
+
+// transaction is the state of a transaction GORM started for one statement
+type transaction struct {
+	id    string
+	start time.Time
+}
+
+// begin emits db.transaction.started if GORM opened a default transaction
+func (p *Plugin) begin(db *gorm.DB) {
+	if _, ok := db.InstanceGet("gorm:started_transaction"); !ok {
+		return
+	}
+	tx := transaction{id: p.producer.NewTransactionID(), start: time.Now()}
+	db.InstanceSet(transactionKey, tx)
+	_ = p.producer.EmitTransactionStarted(db.Statement.Context, tx.id, modelOptions(db)...)
+}
+
+// end emits db.transaction.committed or db.transaction.rolled_back for a default transaction
+func (p *Plugin) end(db *gorm.DB) {
+	value, ok := db.InstanceGet(transactionKey)
+	if !ok {
+		return
+	}
+	tx := value.(transaction)
+	durationMs := time.Since(tx.start).Milliseconds()
+	if db.Error != nil {
+		_ = p.producer.EmitTransactionRolledBack(db.Statement.Context, tx.id, db.Error.Error(), durationMs, modelOptions(db)...)
+		return
+	}
+	_ = p.producer.EmitTransactionCommitted(db.Statement.Context, tx.id, durationMs, modelOptions(db)...)
+}
+
+// Transaction runs fc in a transaction like db.Transaction, emitting db.transaction.started
+// and then db.transaction.committed, or db.transaction.rolled_back if fc or the commit
+// fails
+func (p *Plugin) Transaction(db *gorm.DB, fc func(tx *gorm.DB) error, opts ...*sql.TxOptions) error {
+	ctx := db.Statement.Context
+	id := p.producer.NewTransactionID()
+	start := time.Now()
+	_ = p.producer.EmitTransactionStarted(ctx, id)
+
+	err := db.Transaction(fc, opts...)
+	durationMs := time.Since(start).Milliseconds()
+	if err != nil {
+		_ = p.producer.EmitTransactionRolledBack(ctx, id, err.Error(), durationMs)
+		return err
+	}
+	_ = p.producer.EmitTransactionCommitted(ctx, id, durationMs)
+	return nil
+}
+
+// modelOptions sets the statement's model as the event API, if it has one
+func modelOptions(db *gorm.DB) []lifecycle.EmitOption {
+	if db.Statement.Schema == nil || db.Statement.Schema.ModelType == nil {
+		return nil
+	}
+	return []lifecycle.EmitOption{lifecycle.WithEventAPI(db.Statement.Schema.ModelType.String())}
+}
@@ -0,0 +1,111 @@
+// Package lifecyclegrpc serves a lifecycle.HealthMonitor through the standard
+// grpc.health.v1 Health service, publishes events to a lifecycle.Receiver, and reports
+// call deadlines as operation.timed_out events
+package lifecyclegrpc
+
+import (
+	"context"
+
+	"github.com/SCKelemen/lifecycle"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// Service names that report a single probe; the empty name reports the whole server
+const (
+	ReadinessService = "readiness"
+	LivenessService  = "liveness"
+)
+
+// HealthServer implements grpc.health.v1 Health from a HealthMonitor's last results:
+//   - "" (the whole server) is SERVING when the service is both ready and live
+//   - "readiness" and "liveness" report one probe each
+//   - names added with WithServices follow readiness
+//
+// It does not run checks itself; keep HealthMonitor.Run going. Status transitions are
+// emitted by the monitor as service.ready / service.not_ready and service.live /
+// service.not_live, and pushed to Watch streams as they happen
+type HealthServer struct {
+	healthpb.UnimplementedHealthServer
+
+	monitor  *lifecycle.HealthMonitor
+	services map[string]func() bool
+}
+
+var _ healthpb.HealthServer = (*HealthServer)(nil)
+
+// Option configures a HealthServer
+type Option func(*HealthServer)
+
+// WithServices reports names (typically fully qualified gRPC service names, such as
+// "orders.v1.Orders") with the monitor's readiness
+func WithServices(names ...string) Option {
+	return func(s *HealthServer) {
+		for _, name := range names {
+			s.services[name] = s.monitor.Ready
+		}
+	}
+}
+
+// NewHealthServer creates a health server backed by monitor:
+//
+//	healthpb.RegisterHealthServer(server, lifecyclegrpc.NewHealthServer(health))
+func NewHealthServer(monitor *lifecycle.HealthMonitor, opts ...Option) *HealthServer {
+	s := &HealthServer{monitor: monitor}
+	s.services = map[string]func() bool{
+		"":               func() bool { return monitor.Ready() && monitor.Live() },
+		ReadinessService: monitor.Ready,
+		LivenessService:  monitor.Live,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Check returns the service's current status, or NotFound for an unknown service
+func (s *HealthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	serving, ok := s.services[req.GetService()]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "unknown service")
+	}
+	return &healthpb.HealthCheckResponse{Status: servingStatus(serving())}, nil
+}
+
+// Watch sends the service's current status, then every change until the client goes away
+// An unknown service is reported as SERVICE_UNKNOWN, as the protocol requires
+func (s *HealthServer) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	serving, known := s.services[req.GetService()]
+
+	last := healthpb.HealthCheckResponse_UNKNOWN
+	for {
+		// Subscribe before reading so a change in between is not missed
+		changed := s.monitor.Changed()
+
+		current := healthpb.HealthCheckResponse_SERVICE_UNKNOWN
+		if known {
+			current = servingStatus(serving())
+		}
+		if current != last {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: current}); err != nil {
+				return err
+			}
+			last = current
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		case <-changed:
+		}
+	}
+}
+
+// servingStatus maps a probe result to a gRPC health status
+func servingStatus(ok bool) healthpb.HealthCheckResponse_ServingStatus {
+	if ok {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
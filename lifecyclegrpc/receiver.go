@@ -0,0 +1,96 @@
+package lifecyclegrpc
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/SCKelemen/lifecycle"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ReceiverServiceName is the gRPC service that publishes events to a lifecycle.Receiver
+const ReceiverServiceName = "lifecycle.v1.Receiver"
+
+// PublishMethod is the full name of the service's only method
+const PublishMethod = "/" + ReceiverServiceName + "/Publish"
+
+// RegisterReceiver serves receiver on s as the lifecycle.v1.Receiver service. Its Publish
+// method takes a google.protobuf.ListValue of event Structs and returns the
+// lifecycle.ReceiveResult as a google.protobuf.Struct, so clients need only the well-known
+// types:
+//
+//	var result structpb.Struct
+//	err := conn.Invoke(ctx, lifecyclegrpc.PublishMethod, events, &result)
+//
+// A receiver token (see lifecycle.WithReceiverToken) is read from the "authorization"
+// metadata, e.g., with grpc.WithPerRPCCredentials, and a missing or wrong token is
+// returned as codes.Unauthenticated. A sink failure is returned as codes.Unavailable
+func RegisterReceiver(s grpc.ServiceRegistrar, receiver *lifecycle.Receiver) {
+	s.RegisterService(&grpc.ServiceDesc{
+		ServiceName: ReceiverServiceName,
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "Publish",
+			Handler: func(_ interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				if !authorized(ctx, receiver) {
+					return nil, status.Error(codes.Unauthenticated, "unauthorized")
+				}
+				in := new(structpb.ListValue)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				publish := func(ctx context.Context, req interface{}) (interface{}, error) {
+					return publishEvents(receiver, req.(*structpb.ListValue))
+				}
+				if interceptor == nil {
+					return publish(ctx, in)
+				}
+				return interceptor(ctx, in, &grpc.UnaryServerInfo{FullMethod: PublishMethod}, publish)
+			},
+		}},
+		Metadata: "lifecycle/v1/receiver.proto",
+	}, nil)
+}
+
+// authorized reports whether the incoming metadata of ctx carries the receiver's token
+func authorized(ctx context.Context, receiver *lifecycle.Receiver) bool {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return receiver.Authorized("")
+	}
+	return receiver.Authorized(values[0])
+}
+
+// publishEvents decodes the events of a Publish request and hands them to receiver
+func publishEvents(receiver *lifecycle.Receiver, events *structpb.ListValue) (*structpb.Struct, error) {
+	values := events.GetValues()
+	records := make([]*lifecycle.Record, len(values))
+	errs := make([]error, len(values))
+	for i, value := range values {
+		data, err := value.MarshalJSON()
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		records[i], errs[i] = lifecycle.DecodeRecord(data)
+	}
+
+	result := receiver.Receive(records, errs)
+	if result.Error != "" {
+		return nil, status.Error(codes.Unavailable, result.Error)
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	out := new(structpb.Struct)
+	if err := out.UnmarshalJSON(data); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return out, nil
+}
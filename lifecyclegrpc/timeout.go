@@ -0,0 +1,43 @@
+package lifecyclegrpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/SCKelemen/lifecycle"
+	"google.golang.org/grpc"
+)
+
+// UnaryTimeoutInterceptor gives each unary call a deadline of timeout and emits an
+// operation.timed_out event, named by the call's full method, for calls still running
+// when it fires. A shorter deadline set by the client is kept
+// Handlers must respect their context for the timeout to take effect
+func UnaryTimeoutInterceptor(producer *lifecycle.Producer, timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		var resp any
+		err := producer.WithTimeout(ctx, timeout, info.FullMethod, func(ctx context.Context) error {
+			var err error
+			resp, err = handler(ctx, req)
+			return err
+		})
+		return resp, err
+	}
+}
+
+// StreamTimeoutInterceptor is UnaryTimeoutInterceptor for streaming calls; the deadline
+// covers the whole stream
+func StreamTimeoutInterceptor(producer *lifecycle.Producer, timeout time.Duration) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return producer.WithTimeout(stream.Context(), timeout, info.FullMethod, func(ctx context.Context) error {
+			return handler(srv, &timeoutStream{ServerStream: stream, ctx: ctx})
+		})
+	}
+}
+
+// timeoutStream is a server stream whose context carries the interceptor's deadline
+type timeoutStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *timeoutStream) Context() context.Context { return s.ctx }
@@ -0,0 +1,32 @@
+package lifecyclegrpc
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SCKelemen/lifecycle"
+	"google.golang.org/grpc"
+)
+
+// TestUnaryTimeoutInterceptor checks that a call still running at its deadline sees
+// the deadline and is reported by its full method
+func TestUnaryTimeoutInterceptor(t *testing.T) {
+	var output bytes.Buffer
+	producer := lifecycle.NewProducer("grpc", "localhost", lifecycle.WithOutput(&output))
+	interceptor := UnaryTimeoutInterceptor(producer, time.Millisecond)
+	info := &grpc.UnaryServerInfo{FullMethod: "/orders.v1.Orders/Get"}
+
+	_, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	if err != context.DeadlineExceeded {
+		t.Errorf("err = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if got := output.String(); !strings.Contains(got, `"operation":"/orders.v1.Orders/Get"`) {
+		t.Errorf("operation.timed_out = %s, want operation /orders.v1.Orders/Get", got)
+	}
+}
@@ -0,0 +1,99 @@
+// Package lifecyclekafka instruments segmentio/kafka-go writers and consumers with
+// lifecycle messaging events
+package lifecyclekafka
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/SCKelemen/lifecycle"
+	"github.com/segmentio/kafka-go"
+)
+
+// Writer is a *kafka.Writer that emits messaging.published or messaging.publish_failed for
+// every message it writes
+type Writer struct {
+	*kafka.Writer
+	producer *lifecycle.Producer
+}
+
+// WrapWriter instruments w to emit through producer
+func WrapWriter(w *kafka.Writer, producer *lifecycle.Producer) *Writer {
+	return &Writer{Writer: w, producer: producer}
+}
+
+// WriteMessages writes msgs, adding the correlation ID from ctx as a header
+// Each message's event carries the batch's latency and the message's own error
+func (w *Writer) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	if id := lifecycle.CorrelationIDFromContext(ctx); id != "" {
+		for i := range msgs {
+			msgs[i].Headers = withCorrelationID(msgs[i].Headers, id)
+		}
+	}
+
+	start := time.Now()
+	err := w.Writer.WriteMessages(ctx, msgs...)
+	duration := time.Since(start)
+
+	var writeErrs kafka.WriteErrors
+	perMessage := errors.As(err, &writeErrs) && len(writeErrs) == len(msgs)
+	for i, msg := range msgs {
+		msgErr := err
+		if perMessage {
+			msgErr = writeErrs[i]
+		}
+		topic := msg.Topic
+		if topic == "" {
+			topic = w.Topic
+		}
+		_ = w.producer.EmitMessagePublished(ctx, &lifecycle.Message{
+			System:      "kafka",
+			Destination: topic,
+			SizeBytes:   len(msg.Key) + len(msg.Value),
+		}, duration, msgErr)
+	}
+	return err
+}
+
+// Consume runs handle for a message read from a kafka.Reader, with the message's
+// correlation ID in ctx, and emits messaging.consumed or messaging.consume_failed with the
+// processing time
+//
+//	msg, err := reader.FetchMessage(ctx)
+//	...
+//	if err := lifecyclekafka.Consume(ctx, producer, group, msg, handle); err == nil {
+//		reader.CommitMessages(ctx, msg)
+//	}
+func Consume(ctx context.Context, producer *lifecycle.Producer, group string, msg kafka.Message,
+	handle func(ctx context.Context, msg kafka.Message) error) error {
+	for _, header := range msg.Headers {
+		if header.Key == lifecycle.CorrelationIDHeader {
+			ctx = lifecycle.ContextWithCorrelationID(ctx, string(header.Value))
+		}
+	}
+
+	start := time.Now()
+	err := handle(ctx, msg)
+
+	partition, offset := int32(msg.Partition), msg.Offset
+	_ = producer.EmitMessageConsumed(ctx, &lifecycle.Message{
+		System:        "kafka",
+		Destination:   msg.Topic,
+		Partition:     &partition,
+		Offset:        &offset,
+		ConsumerGroup: group,
+		SizeBytes:     len(msg.Key) + len(msg.Value),
+	}, time.Since(start), err)
+	return err
+}
+
+// withCorrelationID adds the correlation ID header unless one is already set
+func withCorrelationID(headers []kafka.Header, id string) []kafka.Header {
+	for _, header := range headers {
+		if header.Key == lifecycle.CorrelationIDHeader {
+			return headers
+		}
+	}
+	return append(headers, kafka.Header{Key: lifecycle.CorrelationIDHeader, Value: []byte(id)})
+}
@@ -0,0 +1,86 @@
+package lifecyclekafka
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/SCKelemen/lifecycle"
+	"github.com/segmentio/kafka-go"
+)
+
+// SinkWriter writes batches of events to Kafka, one message per event, keyed by
+// correlation ID so a request's events stay in order on one partition
+// Every message carries the batch's flush span in traceparent and tracestate headers
+type SinkWriter struct {
+	writer  *kafka.Writer
+	encoder lifecycle.Encoder
+}
+
+// NewSink creates a batch sink writing events to w's topic as JSON:
+//
+//	producer := lifecycle.NewProducer(service, host, lifecycle.WithSink(
+//		lifecyclekafka.NewSink(&kafka.Writer{Addr: kafka.TCP(broker), Topic: "lifecycle"}),
+//		lifecycle.SinkAsync(10000, lifecycle.BackpressureDropOldest),
+//	))
+//
+// Pass the plain *kafka.Writer, not one wrapped with WrapWriter, so writing events does
+// not emit more events
+func NewSink(w *kafka.Writer, opts ...lifecycle.BatchOption) *lifecycle.BatchSink {
+	writer := &SinkWriter{writer: w, encoder: lifecycle.NewJSONEncoder()}
+	return lifecycle.NewBatchSink(writer, append([]lifecycle.BatchOption{lifecycle.WithBatchName("kafka")}, opts...)...)
+}
+
+// WriteBatch writes events in one WriteMessages call
+func (s *SinkWriter) WriteBatch(ctx context.Context, events []lifecycle.Event) error {
+	var trace headerCarrier
+	lifecycle.InjectTraceContext(ctx, &trace)
+
+	msgs := make([]kafka.Message, 0, len(events))
+	for _, event := range events {
+		data, err := s.encoder.Encode(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event: %w", err)
+		}
+		msg := kafka.Message{Value: data, Headers: trace}
+		if id := event.GetCorrelationID(); id != "" {
+			msg.Key = []byte(id)
+		}
+		msgs = append(msgs, msg)
+	}
+	return s.writer.WriteMessages(ctx, msgs...)
+}
+
+// Close closes the underlying writer
+func (s *SinkWriter) Close() error {
+	return s.writer.Close()
+}
+
+// headerCarrier adapts Kafka headers to propagation.TextMapCarrier
+type headerCarrier []kafka.Header
+
+func (c *headerCarrier) Get(key string) string {
+	for _, header := range *c {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+func (c *headerCarrier) Set(key, value string) {
+	for i, header := range *c {
+		if header.Key == key {
+			(*c)[i].Value = []byte(value)
+			return
+		}
+	}
+	*c = append(*c, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+func (c *headerCarrier) Keys() []string {
+	keys := make([]string, len(*c))
+	for i, header := range *c {
+		keys[i] = header.Key
+	}
+	return keys
+}
@@ -0,0 +1,110 @@
+// Package lifecyclelogr adapts lifecycle events to the logr.LogSink interface
+// so libraries built on logr (e.g., controller-runtime) emit log.message events
+package lifecyclelogr
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"time"
+
+	"github.com/SCKelemen/lifecycle"
+	"github.com/go-logr/logr"
+)
+
+// NewLogger creates a logr.Logger that routes log lines through producer
+func NewLogger(producer *lifecycle.Producer, opts ...lifecycle.LifecycleHandlerOption) logr.Logger {
+	return logr.New(NewLogSink(producer, opts...))
+}
+
+// NewLogSink creates a logr.LogSink that routes log lines through producer
+// V-levels map to slog levels as -V, so V(1) is slog.LevelDebug+3 and is dropped
+// unless the handler's minimum level (see lifecycle.WithMinLevel) allows it
+func NewLogSink(producer *lifecycle.Producer, opts ...lifecycle.LifecycleHandlerOption) logr.LogSink {
+	return &sink{handler: lifecycle.NewLifecycleHandler(producer, opts...)}
+}
+
+// sink implements logr.LogSink and logr.CallDepthLogSink
+type sink struct {
+	handler   slog.Handler
+	name      string // Names from WithName, joined with "/"
+	callDepth int
+}
+
+var (
+	_ logr.LogSink          = (*sink)(nil)
+	_ logr.CallDepthLogSink = (*sink)(nil)
+)
+
+// Init receives the call depth added by logr itself
+func (s *sink) Init(info logr.RuntimeInfo) {
+	s.callDepth = info.CallDepth
+}
+
+// Enabled reports whether log lines at the given V-level are emitted
+func (s *sink) Enabled(level int) bool {
+	return s.handler.Enabled(context.Background(), slog.Level(-level))
+}
+
+// Info emits a log.message event for a non-error log line
+func (s *sink) Info(level int, msg string, keysAndValues ...interface{}) {
+	s.handle(slog.Level(-level), msg, keysAndValues)
+}
+
+// Error emits an error level log.message event with err in the "error" attribute
+func (s *sink) Error(err error, msg string, keysAndValues ...interface{}) {
+	if err != nil {
+		keysAndValues = append([]interface{}{"error", err}, keysAndValues...)
+	}
+	s.handle(slog.LevelError, msg, keysAndValues)
+}
+
+// WithValues returns a sink that adds keysAndValues to the attrs of every event
+func (s *sink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	s2 := *s
+	s2.handler = s.handler.WithAttrs(attrsFromPairs(keysAndValues))
+	return &s2
+}
+
+// WithName returns a sink that records name in the "logger" attribute
+func (s *sink) WithName(name string) logr.LogSink {
+	s2 := *s
+	if s.name == "" {
+		s2.name = name
+	} else {
+		s2.name = s.name + "/" + name
+	}
+	return &s2
+}
+
+// WithCallDepth returns a sink that skips depth more frames when finding the caller
+func (s *sink) WithCallDepth(depth int) logr.LogSink {
+	s2 := *s
+	s2.callDepth += depth
+	return &s2
+}
+
+// handle builds a slog record for the caller and passes it to the handler
+func (s *sink) handle(level slog.Level, msg string, keysAndValues []interface{}) {
+	var pcs [1]uintptr
+	runtime.Callers(s.callDepth+3, pcs[:]) // Skip runtime.Callers, handle, and Info/Error
+	record := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	if s.name != "" {
+		record.AddAttrs(slog.String("logger", s.name))
+	}
+	record.Add(keysAndValues...)
+	_ = s.handler.Handle(context.Background(), record)
+}
+
+// attrsFromPairs converts logr key/value pairs into slog attributes
+func attrsFromPairs(keysAndValues []interface{}) []slog.Attr {
+	var record slog.Record
+	record.Add(keysAndValues...)
+
+	attrs := make([]slog.Attr, 0, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, attr)
+		return true
+	})
+	return attrs
+}
@@ -0,0 +1,204 @@
+// Package lifecyclemongo provides a mongo-driver CommandMonitor that emits lifecycle
+// db.query.* events
+package lifecyclemongo
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/SCKelemen/lifecycle"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// maxPendingCommands bounds the started commands awaiting a reply
+const maxPendingCommands = 10000
+
+// ignoredCommands are connection handshake and session housekeeping, not queries
+var ignoredCommands = map[string]bool{
+	"hello": true, "ismaster": true, "isMaster": true, "saslStart": true, "saslContinue": true,
+	"authenticate": true, "getnonce": true, "endSessions": true, "ping": true, "buildInfo": true,
+}
+
+// omittedFields are command fields that carry driver bookkeeping or whole documents
+var omittedFields = map[string]bool{
+	"lsid": true, "$clusterTime": true, "$db": true, "txnNumber": true, "autocommit": true,
+	"startTransaction": true, "$readPreference": true, "documents": true, "apiVersion": true,
+}
+
+// monitor tracks started commands until their reply
+type monitor struct {
+	producer *lifecycle.Producer
+
+	mu      sync.Mutex
+	pending map[string]command // By connection and request ID
+}
+
+// command is a started command awaiting its reply
+type command struct {
+	queryID string
+	opts    []lifecycle.EmitOption
+}
+
+// NewCommandMonitor creates a command monitor that emits through producer:
+//
+//	opts := options.Client().ApplyURI(uri).SetMonitor(lifecyclemongo.NewCommandMonitor(producer))
+//
+// Each command emits db.query.started, then db.query.completed or db.query.errored, with the
+// database, collection, and operation as metadata
+// The query is the command with every value replaced by "?", so filters keep their shape
+// (fields and operators) but never their values; inserted documents are omitted
+func NewCommandMonitor(producer *lifecycle.Producer) *event.CommandMonitor {
+	m := &monitor{producer: producer, pending: make(map[string]command)}
+	return &event.CommandMonitor{
+		Started:   m.started,
+		Succeeded: m.succeeded,
+		Failed:    m.failed,
+	}
+}
+
+// started emits db.query.started
+func (m *monitor) started(ctx context.Context, e *event.CommandStartedEvent) {
+	if ignoredCommands[e.CommandName] {
+		return
+	}
+
+	opts := []lifecycle.EmitOption{
+		lifecycle.WithEventMetadata("database", e.DatabaseName),
+		lifecycle.WithEventMetadata("operation", e.CommandName),
+	}
+	if collection, ok := e.Command.Lookup(e.CommandName).StringValueOK(); ok {
+		opts = append(opts, lifecycle.WithEventMetadata("collection", collection))
+	}
+
+	cmd := command{queryID: m.producer.NewQueryID(), opts: opts}
+	m.mu.Lock()
+	if len(m.pending) >= maxPendingCommands {
+		m.mu.Unlock()
+		return
+	}
+	m.pending[commandKey(e.ConnectionID, e.RequestID)] = cmd
+	m.mu.Unlock()
+
+	_ = m.producer.EmitQueryStarted(ctx, cmd.queryID, SanitizeCommand(e.Command), nil, opts...)
+}
+
+// succeeded emits db.query.completed, with the number of documents affected when the
+// reply reports it
+func (m *monitor) succeeded(ctx context.Context, e *event.CommandSucceededEvent) {
+	cmd, ok := m.finish(e.ConnectionID, e.RequestID)
+	if !ok {
+		return
+	}
+	var rows int64
+	if n, ok := e.Reply.Lookup("n").AsInt64OK(); ok {
+		rows = n
+	}
+	_ = m.producer.EmitQueryCompleted(ctx, cmd.queryID, e.Duration.Milliseconds(), rows, cmd.opts...)
+}
+
+// failed emits db.query.errored
+func (m *monitor) failed(ctx context.Context, e *event.CommandFailedEvent) {
+	cmd, ok := m.finish(e.ConnectionID, e.RequestID)
+	if !ok {
+		return
+	}
+	_ = m.producer.EmitQueryErrored(ctx, cmd.queryID, e.Failure, "", e.Duration.Milliseconds(), cmd.opts...)
+}
+
+// finish removes and returns a started command
+func (m *monitor) finish(connectionID string, requestID int64) (command, bool) {
+	key := commandKey(connectionID, requestID)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cmd, ok := m.pending[key]
+	delete(m.pending, key)
+	return cmd, ok
+}
+
+// commandKey identifies a command; request IDs are only unique per connection
+func commandKey(connectionID string, requestID int64) string {
+	return connectionID + "/" + strconv.FormatInt(requestID, 10)
+}
+
+// SanitizeCommand renders a command as JSON with every value replaced by "?", keeping
+// only its shape: the command and collection names, field names, and operators
+// Arrays of values collapse to ["?"], and driver bookkeeping and inserted documents are
+// omitted
+func SanitizeCommand(cmd bson.Raw) string {
+	var b strings.Builder
+	elements, err := cmd.Elements()
+	if err != nil {
+		return ""
+	}
+	b.WriteByte('{')
+	for i, element := range elements {
+		key := element.Key()
+		if omittedFields[key] {
+			continue
+		}
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		writeString(&b, key)
+		b.WriteByte(':')
+		if i == 0 {
+			// The command name's value is the collection
+			if collection, ok := element.Value().StringValueOK(); ok {
+				writeString(&b, collection)
+				continue
+			}
+		}
+		writeValue(&b, element.Value())
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// writeValue writes the shape of a value
+func writeValue(b *strings.Builder, value bson.RawValue) {
+	switch value.Type {
+	case bsontype.EmbeddedDocument:
+		b.WriteByte('{')
+		elements, _ := value.Document().Elements()
+		for i, element := range elements {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeString(b, element.Key())
+			b.WriteByte(':')
+			writeValue(b, element.Value())
+		}
+		b.WriteByte('}')
+	case bsontype.Array:
+		values, _ := value.Array().Values()
+		b.WriteByte('[')
+		written := 0
+		for _, v := range values {
+			if v.Type != bsontype.EmbeddedDocument && v.Type != bsontype.Array {
+				continue
+			}
+			if written > 0 {
+				b.WriteByte(',')
+			}
+			writeValue(b, v)
+			written++
+		}
+		if written == 0 && len(values) > 0 {
+			b.WriteString(`"?"`)
+		}
+		b.WriteByte(']')
+	default:
+		b.WriteString(`"?"`)
+	}
+}
+
+// writeString writes s as a JSON string
+func writeString(b *strings.Builder, s string) {
+	data, _ := json.Marshal(s)
+	b.Write(data)
+}
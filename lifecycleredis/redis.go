@@ -0,0 +1,111 @@
+// Package lifecycleredis provides a go-redis hook that emits lifecycle
+// redis.command.* events
+package lifecycleredis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/SCKelemen/lifecycle"
+	"github.com/redis/go-redis/v9"
+)
+
+// keylessCommands do not take a key as their first argument
+var keylessCommands = map[string]bool{
+	"auth": true, "client": true, "cluster": true, "command": true, "config": true,
+	"dbsize": true, "eval": true, "evalsha": true, "flushall": true, "flushdb": true,
+	"hello": true, "info": true, "multi": true, "exec": true, "discard": true,
+	"ping": true, "scan": true, "script": true, "select": true, "time": true,
+}
+
+// Hook emits redis.command.started, then redis.command.completed or
+// redis.command.errored, for each command, including each command of a pipeline
+// Events record the command name and key pattern (see lifecycle.RedisKeyPattern), never
+// argument values
+type Hook struct {
+	producer *lifecycle.Producer
+}
+
+var _ redis.Hook = (*Hook)(nil)
+
+// NewHook creates a hook that emits through producer:
+//
+//	client.AddHook(lifecycleredis.NewHook(producer))
+func NewHook(producer *lifecycle.Producer) *Hook {
+	return &Hook{producer: producer}
+}
+
+// DialHook passes dials through unchanged
+func (h *Hook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+// ProcessHook traces a single command
+func (h *Hook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		id, command, pattern := h.started(ctx, cmd)
+		start := time.Now()
+		err := next(ctx, cmd)
+		h.finished(ctx, err, id, command, pattern, time.Since(start))
+		return err
+	}
+}
+
+// ProcessPipelineHook traces each command of a pipeline; they share the pipeline's latency
+func (h *Hook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		type started struct {
+			id, command, pattern string
+		}
+		pending := make([]started, len(cmds))
+		for i, cmd := range cmds {
+			id, command, pattern := h.started(ctx, cmd)
+			pending[i] = started{id, command, pattern}
+		}
+
+		start := time.Now()
+		err := next(ctx, cmds)
+		duration := time.Since(start)
+		for i, cmd := range cmds {
+			// Unlike single commands, pipelined commands carry their own errors by now
+			h.finished(ctx, cmd.Err(), pending[i].id, pending[i].command, pending[i].pattern, duration)
+		}
+		return err
+	}
+}
+
+// started emits redis.command.started and returns the command's ID, name, and key pattern
+func (h *Hook) started(ctx context.Context, cmd redis.Cmder) (id, command, pattern string) {
+	id = h.producer.NewQueryID()
+	command = strings.ToLower(cmd.Name())
+	pattern = keyPattern(command, cmd.Args())
+	_ = h.producer.EmitRedisCommandStarted(ctx, id, command, pattern)
+	return id, command, pattern
+}
+
+// finished emits redis.command.completed or redis.command.errored for a command that
+// returned err; redis.Nil is a miss, not an error
+func (h *Hook) finished(ctx context.Context, err error, id, command, pattern string, duration time.Duration) {
+	if err != nil && !errors.Is(err, redis.Nil) {
+		_ = h.producer.EmitRedisCommandErrored(ctx, id, command, pattern, err.Error(), duration.Milliseconds())
+		return
+	}
+	_ = h.producer.EmitRedisCommandCompleted(ctx, id, command, pattern, errors.Is(err, redis.Nil), duration.Milliseconds())
+}
+
+// keyPattern returns the pattern of the command's first key, if it has one
+func keyPattern(command string, args []interface{}) string {
+	if keylessCommands[command] || len(args) < 2 {
+		return ""
+	}
+	key, ok := args[1].(string)
+	if !ok {
+		return ""
+	}
+	return lifecycle.RedisKeyPattern(key)
+}
@@ -0,0 +1,136 @@
+// Package lifecyclesarama instruments sarama Kafka producers and consumers with lifecycle
+// messaging events
+package lifecyclesarama
+
+import (
+	"context"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/SCKelemen/lifecycle"
+)
+
+// SyncProducer is a sarama.SyncProducer that emits messaging.published or
+// messaging.publish_failed for every message it sends
+type SyncProducer struct {
+	sarama.SyncProducer
+	producer *lifecycle.Producer
+}
+
+// WrapSyncProducer instruments p to emit through producer
+func WrapSyncProducer(p sarama.SyncProducer, producer *lifecycle.Producer) *SyncProducer {
+	return &SyncProducer{SyncProducer: p, producer: producer}
+}
+
+// SendMessage sends msg; use SendMessageContext to propagate a correlation ID
+func (p *SyncProducer) SendMessage(msg *sarama.ProducerMessage) (int32, int64, error) {
+	return p.SendMessageContext(context.Background(), msg)
+}
+
+// SendMessageContext sends msg, adding the correlation ID from ctx as a header
+func (p *SyncProducer) SendMessageContext(ctx context.Context, msg *sarama.ProducerMessage) (int32, int64, error) {
+	injectCorrelationID(ctx, msg)
+	start := time.Now()
+	partition, offset, err := p.SyncProducer.SendMessage(msg)
+	_ = p.producer.EmitMessagePublished(ctx, producerMessage(msg, err == nil), time.Since(start), err)
+	return partition, offset, err
+}
+
+// SendMessages sends msgs; use SendMessagesContext to propagate a correlation ID
+func (p *SyncProducer) SendMessages(msgs []*sarama.ProducerMessage) error {
+	return p.SendMessagesContext(context.Background(), msgs)
+}
+
+// SendMessagesContext sends msgs as one batch, adding the correlation ID from ctx as a
+// header; each message's event carries the batch's latency and the message's own error
+func (p *SyncProducer) SendMessagesContext(ctx context.Context, msgs []*sarama.ProducerMessage) error {
+	for _, msg := range msgs {
+		injectCorrelationID(ctx, msg)
+	}
+	start := time.Now()
+	err := p.SyncProducer.SendMessages(msgs)
+	duration := time.Since(start)
+
+	failed := make(map[*sarama.ProducerMessage]error)
+	if errs, ok := err.(sarama.ProducerErrors); ok {
+		for _, e := range errs {
+			failed[e.Msg] = e.Err
+		}
+	}
+	for _, msg := range msgs {
+		msgErr, ok := failed[msg]
+		if !ok && len(failed) == 0 {
+			msgErr = err
+		}
+		_ = p.producer.EmitMessagePublished(ctx, producerMessage(msg, msgErr == nil), duration, msgErr)
+	}
+	return err
+}
+
+// Consume runs handle for a consumed message, with the message's correlation ID in ctx,
+// and emits messaging.consumed or messaging.consume_failed with the processing time
+// It is typically called from a sarama.ConsumerGroupHandler's ConsumeClaim:
+//
+//	for msg := range claim.Messages() {
+//		if err := lifecyclesarama.Consume(session.Context(), producer, group, msg, handle); err == nil {
+//			session.MarkMessage(msg, "")
+//		}
+//	}
+func Consume(ctx context.Context, producer *lifecycle.Producer, group string, msg *sarama.ConsumerMessage,
+	handle func(ctx context.Context, msg *sarama.ConsumerMessage) error) error {
+	for _, header := range msg.Headers {
+		if header != nil && string(header.Key) == lifecycle.CorrelationIDHeader {
+			ctx = lifecycle.ContextWithCorrelationID(ctx, string(header.Value))
+		}
+	}
+
+	start := time.Now()
+	err := handle(ctx, msg)
+
+	partition, offset := msg.Partition, msg.Offset
+	_ = producer.EmitMessageConsumed(ctx, &lifecycle.Message{
+		System:        "kafka",
+		Destination:   msg.Topic,
+		Partition:     &partition,
+		Offset:        &offset,
+		ConsumerGroup: group,
+		SizeBytes:     len(msg.Key) + len(msg.Value),
+	}, time.Since(start), err)
+	return err
+}
+
+// injectCorrelationID adds the correlation ID from ctx to msg's headers
+func injectCorrelationID(ctx context.Context, msg *sarama.ProducerMessage) {
+	id := lifecycle.CorrelationIDFromContext(ctx)
+	if id == "" {
+		return
+	}
+	for _, header := range msg.Headers {
+		if string(header.Key) == lifecycle.CorrelationIDHeader {
+			return
+		}
+	}
+	msg.Headers = append(msg.Headers, sarama.RecordHeader{
+		Key:   []byte(lifecycle.CorrelationIDHeader),
+		Value: []byte(id),
+	})
+}
+
+// producerMessage describes a message; sarama sets its partition and offset once sent
+func producerMessage(msg *sarama.ProducerMessage, sent bool) *lifecycle.Message {
+	m := &lifecycle.Message{
+		System:      "kafka",
+		Destination: msg.Topic,
+	}
+	if msg.Key != nil {
+		m.SizeBytes += msg.Key.Length()
+	}
+	if msg.Value != nil {
+		m.SizeBytes += msg.Value.Length()
+	}
+	if sent {
+		partition, offset := msg.Partition, msg.Offset
+		m.Partition, m.Offset = &partition, &offset
+	}
+	return m
+}
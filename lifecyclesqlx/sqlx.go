@@ -0,0 +1,239 @@
+// Package lifecyclesqlx wraps sqlx so queries and transactions emit lifecycle events, with
+// the destination model (e.g., "models.User") as the API identifier
+package lifecyclesqlx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/SCKelemen/lifecycle"
+	"github.com/jmoiron/sqlx"
+)
+
+// DB is a *sqlx.DB whose context methods emit db.query.* events
+// Methods without a context are inherited from sqlx.DB and are not traced
+type DB struct {
+	*sqlx.DB
+	producer *lifecycle.Producer
+}
+
+// NewDB wraps db so its queries emit through producer
+func NewDB(db *sqlx.DB, producer *lifecycle.Producer) *DB {
+	return &DB{DB: db, producer: producer}
+}
+
+// ExecContext executes a query without returning rows
+func (db *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return execContext(ctx, db.producer, db.DB, "", query, args)
+}
+
+// QueryxContext queries the database; the traced duration ends when the first rows are ready
+func (db *DB) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+	return queryxContext(ctx, db.producer, db.DB, query, args)
+}
+
+// QueryRowxContext queries the database for at most one row
+func (db *DB) QueryRowxContext(ctx context.Context, query string, args ...interface{}) *sqlx.Row {
+	return queryRowxContext(ctx, db.producer, db.DB, query, args)
+}
+
+// GetContext scans one row into dest; dest's type is the event API
+func (db *DB) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return trace(ctx, db.producer, modelName(dest), query, args, func() (int64, error) {
+		return 1, db.DB.GetContext(ctx, dest, query, args...)
+	})
+}
+
+// SelectContext scans rows into the slice dest; its element type is the event API
+func (db *DB) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return trace(ctx, db.producer, modelName(dest), query, args, func() (int64, error) {
+		err := db.DB.SelectContext(ctx, dest, query, args...)
+		return sliceLen(dest), err
+	})
+}
+
+// NamedExecContext executes a query with named parameters from arg; arg's type is the
+// event API
+func (db *DB) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	return namedExecContext(ctx, db.producer, db.DB, query, arg)
+}
+
+// BeginTxx starts a transaction, emitting db.transaction.started
+func (db *DB) BeginTxx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	tx, err := db.DB.BeginTxx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	t := &Tx{Tx: tx, producer: db.producer, ctx: ctx, id: db.producer.NewTransactionID(), start: time.Now()}
+	_ = db.producer.EmitTransactionStarted(ctx, t.id)
+	return t, nil
+}
+
+// Tx is a *sqlx.Tx whose context methods emit db.query.* events and whose Commit and
+// Rollback emit db.transaction.committed and db.transaction.rolled_back
+type Tx struct {
+	*sqlx.Tx
+	producer *lifecycle.Producer
+	ctx      context.Context // From BeginTxx, for the transaction's own events
+	id       string
+	start    time.Time
+}
+
+// ExecContext executes a query without returning rows
+func (tx *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return execContext(ctx, tx.producer, tx.Tx, "", query, args)
+}
+
+// QueryxContext queries the database; the traced duration ends when the first rows are ready
+func (tx *Tx) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+	return queryxContext(ctx, tx.producer, tx.Tx, query, args)
+}
+
+// QueryRowxContext queries the database for at most one row
+func (tx *Tx) QueryRowxContext(ctx context.Context, query string, args ...interface{}) *sqlx.Row {
+	return queryRowxContext(ctx, tx.producer, tx.Tx, query, args)
+}
+
+// GetContext scans one row into dest; dest's type is the event API
+func (tx *Tx) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return trace(ctx, tx.producer, modelName(dest), query, args, func() (int64, error) {
+		return 1, tx.Tx.GetContext(ctx, dest, query, args...)
+	})
+}
+
+// SelectContext scans rows into the slice dest; its element type is the event API
+func (tx *Tx) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return trace(ctx, tx.producer, modelName(dest), query, args, func() (int64, error) {
+		err := tx.Tx.SelectContext(ctx, dest, query, args...)
+		return sliceLen(dest), err
+	})
+}
+
+// NamedExecContext executes a query with named parameters from arg; arg's type is the
+// event API
+func (tx *Tx) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	return namedExecContext(ctx, tx.producer, tx.Tx, query, arg)
+}
+
+// Commit commits the transaction, emitting db.transaction.committed, or
+// db.transaction.rolled_back if the commit fails
+func (tx *Tx) Commit() error {
+	err := tx.Tx.Commit()
+	durationMs := time.Since(tx.start).Milliseconds()
+	if err != nil {
+		_ = tx.producer.EmitTransactionRolledBack(tx.ctx, tx.id, err.Error(), durationMs)
+		return err
+	}
+	_ = tx.producer.EmitTransactionCommitted(tx.ctx, tx.id, durationMs)
+	return nil
+}
+
+// Rollback aborts the transaction, emitting db.transaction.rolled_back
+// Rolling back a finished transaction (e.g., a deferred Rollback after Commit) emits nothing
+func (tx *Tx) Rollback() error {
+	err := tx.Tx.Rollback()
+	if errors.Is(err, sql.ErrTxDone) {
+		return err
+	}
+	_ = tx.producer.EmitTransactionRolledBack(tx.ctx, tx.id, "rollback", time.Since(tx.start).Milliseconds())
+	return err
+}
+
+// execContext executes and traces a query on e
+func execContext(ctx context.Context, producer *lifecycle.Producer, e sqlx.ExecerContext, model, query string, args []interface{}) (sql.Result, error) {
+	var result sql.Result
+	err := trace(ctx, producer, model, query, args, func() (int64, error) {
+		var err error
+		result, err = e.ExecContext(ctx, query, args...)
+		if err != nil {
+			return 0, err
+		}
+		rows, _ := result.RowsAffected()
+		return rows, nil
+	})
+	return result, err
+}
+
+// queryxContext runs and traces a query on q
+func queryxContext(ctx context.Context, producer *lifecycle.Producer, q sqlx.QueryerContext, query string, args []interface{}) (*sqlx.Rows, error) {
+	var rows *sqlx.Rows
+	err := trace(ctx, producer, "", query, args, func() (int64, error) {
+		var err error
+		rows, err = q.QueryxContext(ctx, query, args...)
+		return 0, err
+	})
+	return rows, err
+}
+
+// queryRowxContext runs and traces a single-row query on q
+func queryRowxContext(ctx context.Context, producer *lifecycle.Producer, q sqlx.QueryerContext, query string, args []interface{}) *sqlx.Row {
+	var row *sqlx.Row
+	_ = trace(ctx, producer, "", query, args, func() (int64, error) {
+		row = q.QueryRowxContext(ctx, query, args...)
+		return 0, row.Err()
+	})
+	return row
+}
+
+// namedExecContext binds arg's named parameters, then executes and traces the query on e
+func namedExecContext(ctx context.Context, producer *lifecycle.Producer, e sqlx.ExtContext, query string, arg interface{}) (sql.Result, error) {
+	bound, args, err := e.BindNamed(query, arg)
+	if err != nil {
+		return nil, err
+	}
+	return execContext(ctx, producer, e, modelName(arg), bound, args)
+}
+
+// trace emits db.query.started, runs the query, and emits db.query.completed with the
+// rows run reports, or db.query.errored
+func trace(ctx context.Context, producer *lifecycle.Producer, model, query string, args []interface{}, run func() (int64, error)) error {
+	var opts []lifecycle.EmitOption
+	if model != "" {
+		opts = append(opts, lifecycle.WithEventAPI(model))
+	}
+	queryID := producer.NewQueryID()
+	_ = producer.EmitQueryStarted(ctx, queryID, query, args, opts...)
+
+	start := time.Now()
+	rows, err := run()
+	durationMs := time.Since(start).Milliseconds()
+	if errors.Is(err, sql.ErrNoRows) {
+		rows = 0
+	} else if err != nil {
+		_ = producer.EmitQueryErrored(ctx, queryID, err.Error(), "", durationMs, opts...)
+		return err
+	}
+	_ = producer.EmitQueryCompleted(ctx, queryID, durationMs, rows, opts...)
+	return err
+}
+
+// modelName returns the struct type behind v (through pointers and slices), or "" for
+// other types such as scalars and maps
+func modelName(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == reflect.TypeOf(time.Time{}) {
+		return ""
+	}
+	return t.String()
+}
+
+// sliceLen returns the length of the slice dest points to
+func sliceLen(dest interface{}) int64 {
+	v := reflect.ValueOf(dest)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice {
+		return 0
+	}
+	return int64(v.Len())
+}
@@ -0,0 +1,308 @@
+// Package lifecycletest generates synthetic lifecycle event streams for demos, dashboard
+// development, and pipeline tests
+package lifecycletest
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/SCKelemen/lifecycle"
+)
+
+// Endpoint is an API endpoint that generated requests are spread across
+type Endpoint struct {
+	Method string
+	Path   string  // "{id}" is replaced with a random number (e.g., "/api/users/{id}")
+	API    string  // Optional: API identifier (e.g., "examples.User")
+	Weight float64 // Relative share of requests (default: 1)
+}
+
+// Latency describes a log-normal latency distribution by its median and 99th percentile
+type Latency struct {
+	Median time.Duration
+	P99    time.Duration
+}
+
+// Profile describes the stream Generate produces; zero fields take the defaults listed
+type Profile struct {
+	Service   string     // Default: "demo-service"
+	Hosts     int        // Requests are spread across this many hosts (default: 1)
+	Endpoints []Endpoint // Default: DefaultEndpoints
+	Requests  int        // Default: 1000
+	Rate      float64    // Mean requests per second, with random (Poisson) arrivals (default: 50)
+	Start     time.Time  // Time of the first request (default: now)
+	Seed      int64      // Streams with the same profile and seed are identical (default: random)
+
+	// Negative values disable errors and queries
+	ErrorRatio        float64 // Fraction of requests that fail without a query error (default: 0.02)
+	QueriesPerRequest int     // Each request runs 0 to this many queries (default: 3)
+	QueryErrorRatio   float64 // Fraction of queries that fail, failing their request (default: 0.01)
+
+	RequestLatency Latency // Time spent outside queries (default: 20ms median, 250ms p99)
+	QueryLatency   Latency // Default: 3ms median, 80ms p99
+}
+
+// DefaultEndpoints is a small CRUD API
+var DefaultEndpoints = []Endpoint{
+	{Method: "GET", Path: "/api/users/{id}", API: "examples.User", Weight: 6},
+	{Method: "GET", Path: "/api/users", API: "examples.User", Weight: 2},
+	{Method: "POST", Path: "/api/users", API: "examples.User", Weight: 1},
+	{Method: "PATCH", Path: "/api/users/{id}", API: "examples.User", Weight: 1},
+	{Method: "GET", Path: "/api/orders/{id}", API: "examples.Order", Weight: 3},
+	{Method: "DELETE", Path: "/api/orders/{id}", API: "examples.Order", Weight: 0.5},
+}
+
+// requestErrors are the failures of requests that fail without a query error
+var requestErrors = []struct {
+	status  int32
+	code    string
+	message string
+}{
+	{500, "INTERNAL", "internal server error"},
+	{502, "BAD_GATEWAY", "upstream connection reset"},
+	{503, "UNAVAILABLE", "service temporarily unavailable"},
+	{504, "DEADLINE_EXCEEDED", "upstream request timed out"},
+}
+
+// queryError is the failure of a query
+type queryError struct {
+	code    string
+	message string
+}
+
+// queryErrors are the failures of failing queries
+var queryErrors = []queryError{
+	{"40001", "could not serialize access due to concurrent update"},
+	{"57014", "canceling statement due to statement timeout"},
+	{"08006", "connection to server was lost"},
+}
+
+// Generate returns a stream of requests with their queries, in timestamp order:
+// api.request.received, db.query.started and db.query.completed or db.query.errored for
+// each query, then api.request.handled or api.request.errored
+// Requests overlap, so their events interleave as they would in a real service
+func Generate(profile Profile) []lifecycle.Event {
+	profile = withDefaults(profile)
+	rng := rand.New(rand.NewSource(profile.Seed))
+
+	sink := &collector{}
+	producers := make([]*lifecycle.Producer, profile.Hosts)
+	for i := range producers {
+		host := fmt.Sprintf("%s-%d", profile.Service, i)
+		producers[i] = lifecycle.NewProducer(profile.Service, host, lifecycle.WithSink(sink))
+	}
+
+	g := &generator{profile: profile, rng: rng}
+	for _, endpoint := range profile.Endpoints {
+		g.totalWeight += endpointWeight(endpoint)
+	}
+
+	at := profile.Start
+	for i := 0; i < profile.Requests; i++ {
+		g.request(producers[rng.Intn(len(producers))], at)
+		at = at.Add(time.Duration(rng.ExpFloat64() / profile.Rate * float64(time.Second)))
+	}
+
+	sort.SliceStable(sink.events, func(i, j int) bool {
+		return sink.events[i].GetTimestamp().Before(sink.events[j].GetTimestamp())
+	})
+	return sink.events
+}
+
+// withDefaults fills the zero fields of profile
+func withDefaults(profile Profile) Profile {
+	if profile.Service == "" {
+		profile.Service = "demo-service"
+	}
+	if profile.Hosts <= 0 {
+		profile.Hosts = 1
+	}
+	if len(profile.Endpoints) == 0 {
+		profile.Endpoints = DefaultEndpoints
+	}
+	if profile.Requests <= 0 {
+		profile.Requests = 1000
+	}
+	if profile.Rate <= 0 {
+		profile.Rate = 50
+	}
+	if profile.Start.IsZero() {
+		profile.Start = time.Now()
+	}
+	if profile.Seed == 0 {
+		profile.Seed = time.Now().UnixNano()
+	}
+	if profile.ErrorRatio == 0 {
+		profile.ErrorRatio = 0.02
+	}
+	if profile.QueriesPerRequest == 0 {
+		profile.QueriesPerRequest = 3
+	}
+	profile.QueriesPerRequest = max(profile.QueriesPerRequest, 0)
+	if profile.QueryErrorRatio == 0 {
+		profile.QueryErrorRatio = 0.01
+	}
+	if profile.RequestLatency.Median <= 0 {
+		profile.RequestLatency = Latency{Median: 20 * time.Millisecond, P99: 250 * time.Millisecond}
+	}
+	if profile.QueryLatency.Median <= 0 {
+		profile.QueryLatency = Latency{Median: 3 * time.Millisecond, P99: 80 * time.Millisecond}
+	}
+	return profile
+}
+
+// generator emits the events of one profile
+type generator struct {
+	profile     Profile
+	rng         *rand.Rand
+	totalWeight float64
+}
+
+// request emits the events of one request arriving at start
+func (g *generator) request(p *lifecycle.Producer, start time.Time) {
+	ctx := context.Background()
+	endpoint := g.endpoint()
+	path := strings.ReplaceAll(endpoint.Path, "{id}", fmt.Sprint(g.rng.Intn(100000)+1))
+	correlationID := g.id()
+	api := lifecycle.WithEventAPI(endpoint.API)
+
+	_ = p.EmitRequestReceivedWithOptions(ctx, lifecycle.RequestReceivedOptions{
+		CorrelationID: correlationID,
+		Method:        endpoint.Method,
+		Path:          path,
+		Route:         endpoint.Path,
+	}, api, lifecycle.WithEventTimestamp(start))
+
+	ctx = lifecycle.ContextWithCorrelationID(ctx, correlationID)
+	at := start.Add(g.latency(Latency{Median: time.Millisecond, P99: 5 * time.Millisecond}))
+	queries := g.rng.Intn(g.profile.QueriesPerRequest + 1)
+	var queryErr *queryError
+	for i := 0; i < queries && queryErr == nil; i++ {
+		queryID := g.id()
+		duration := g.latency(g.profile.QueryLatency)
+		_ = p.EmitQueryStarted(ctx, queryID, queryFor(endpoint), []interface{}{g.rng.Intn(100000) + 1},
+			api, lifecycle.WithEventTimestamp(at))
+		at = at.Add(duration)
+
+		if g.rng.Float64() < g.profile.QueryErrorRatio {
+			queryErr = &queryErrors[g.rng.Intn(len(queryErrors))]
+			_ = p.EmitQueryErrored(ctx, queryID, queryErr.message, queryErr.code, duration.Milliseconds(),
+				api, lifecycle.WithEventTimestamp(at))
+		} else {
+			_ = p.EmitQueryCompleted(ctx, queryID, duration.Milliseconds(), int64(g.rng.Intn(20)+1),
+				api, lifecycle.WithEventTimestamp(at))
+		}
+	}
+
+	end := at.Add(g.latency(g.profile.RequestLatency))
+	duration := end.Sub(start)
+	switch {
+	case queryErr != nil:
+		_ = p.EmitRequestErroredWithOptions(ctx, lifecycle.RequestErroredOptions{
+			CorrelationID: correlationID,
+			ErrorMessage:  "database error: " + queryErr.message,
+			ErrorCode:     "INTERNAL",
+			StatusCode:    500,
+			Duration:      duration,
+		}, api, lifecycle.WithEventTimestamp(end))
+	case g.rng.Float64() < g.profile.ErrorRatio:
+		failure := requestErrors[g.rng.Intn(len(requestErrors))]
+		_ = p.EmitRequestErroredWithOptions(ctx, lifecycle.RequestErroredOptions{
+			CorrelationID: correlationID,
+			ErrorMessage:  failure.message,
+			ErrorCode:     failure.code,
+			StatusCode:    failure.status,
+			Duration:      duration,
+		}, api, lifecycle.WithEventTimestamp(end))
+	default:
+		_ = p.EmitRequestHandledWithOptions(ctx, lifecycle.RequestHandledOptions{
+			CorrelationID:     correlationID,
+			StatusCode:        successStatus(endpoint.Method),
+			Duration:          duration,
+			ResponseSizeBytes: int64(g.rng.Intn(4096) + 64),
+		}, api, lifecycle.WithEventTimestamp(end))
+	}
+}
+
+// endpoint picks an endpoint by weight
+func (g *generator) endpoint() Endpoint {
+	pick := g.rng.Float64() * g.totalWeight
+	for _, endpoint := range g.profile.Endpoints {
+		pick -= endpointWeight(endpoint)
+		if pick < 0 {
+			return endpoint
+		}
+	}
+	return g.profile.Endpoints[len(g.profile.Endpoints)-1]
+}
+
+// latency samples a log-normal distribution with the given median and p99
+func (g *generator) latency(l Latency) time.Duration {
+	sigma := 0.0
+	if l.P99 > l.Median {
+		sigma = math.Log(float64(l.P99)/float64(l.Median)) / 2.326 // z-score of the 99th percentile
+	}
+	return time.Duration(float64(l.Median) * math.Exp(sigma*g.rng.NormFloat64()))
+}
+
+// id returns a random identifier
+func (g *generator) id() string {
+	return fmt.Sprintf("%016x", g.rng.Uint64())
+}
+
+// endpointWeight returns the endpoint's weight, defaulting to 1
+func endpointWeight(endpoint Endpoint) float64 {
+	if endpoint.Weight <= 0 {
+		return 1
+	}
+	return endpoint.Weight
+}
+
+// queryFor returns a plausible query for an endpoint, against the table named by the
+// last static segment of its path
+func queryFor(endpoint Endpoint) string {
+	table := "items"
+	for _, segment := range strings.Split(endpoint.Path, "/") {
+		if segment != "" && segment != "api" && !strings.HasPrefix(segment, "{") {
+			table = segment
+		}
+	}
+	switch endpoint.Method {
+	case "POST":
+		return "INSERT INTO " + table + " (data) VALUES ($1)"
+	case "PUT", "PATCH":
+		return "UPDATE " + table + " SET data = $2 WHERE id = $1"
+	case "DELETE":
+		return "DELETE FROM " + table + " WHERE id = $1"
+	}
+	if strings.Contains(endpoint.Path, "{id}") {
+		return "SELECT * FROM " + table + " WHERE id = $1"
+	}
+	return "SELECT * FROM " + table + " ORDER BY id LIMIT $1"
+}
+
+// successStatus returns the status code of a successful request
+func successStatus(method string) int32 {
+	switch method {
+	case "POST":
+		return 201
+	case "DELETE":
+		return 204
+	}
+	return 200
+}
+
+// collector is a sink that keeps events in memory
+type collector struct {
+	events []lifecycle.Event
+}
+
+func (c *collector) WriteEvent(event lifecycle.Event) error {
+	c.events = append(c.events, event)
+	return nil
+}
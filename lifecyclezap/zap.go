@@ -0,0 +1,112 @@
+// Package lifecyclezap provides a zapcore.Core that routes zap log entries into
+// lifecycle log.message events
+package lifecyclezap
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/SCKelemen/lifecycle"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// NewLogger creates a zap.Logger that routes log entries through producer
+func NewLogger(producer *lifecycle.Producer, opts ...lifecycle.LifecycleHandlerOption) *zap.Logger {
+	return zap.New(NewCore(producer, opts...), zap.AddCaller())
+}
+
+// NewCore creates a zapcore.Core that routes log entries through producer
+// Fields added with With and fields passed to a log call both become the event's attrs.
+// The level filter is the handler's (see lifecycle.WithMinLevel)
+func NewCore(producer *lifecycle.Producer, opts ...lifecycle.LifecycleHandlerOption) zapcore.Core {
+	return &core{handler: lifecycle.NewLifecycleHandler(producer, opts...)}
+}
+
+// core implements zapcore.Core on top of a slog handler
+type core struct {
+	handler slog.Handler
+}
+
+// Enabled reports whether entries at the given level are emitted
+func (c *core) Enabled(level zapcore.Level) bool {
+	return c.handler.Enabled(context.Background(), slogLevel(level))
+}
+
+// With returns a core that adds fields to the attrs of every event
+// A zap.Namespace field opens a group that holds all fields added after it
+func (c *core) With(fields []zapcore.Field) zapcore.Core {
+	handler := c.handler
+	var pending []zapcore.Field
+	for _, field := range fields {
+		if field.Type != zapcore.NamespaceType {
+			pending = append(pending, field)
+			continue
+		}
+		handler = handler.WithAttrs(attrsFromFields(pending)).WithGroup(field.Key)
+		pending = nil
+	}
+	return &core{handler: handler.WithAttrs(attrsFromFields(pending))}
+}
+
+// Check adds this core to the checked entry if the entry's level is enabled
+func (c *core) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write emits a log.message event for entry
+func (c *core) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	record := slog.NewRecord(entry.Time, slogLevel(entry.Level), entry.Message, entry.Caller.PC)
+	if entry.LoggerName != "" {
+		record.AddAttrs(slog.String("logger", entry.LoggerName))
+	}
+	record.AddAttrs(attrsFromFields(fields)...)
+	if entry.Stack != "" {
+		record.AddAttrs(slog.String("stack_trace", entry.Stack))
+	}
+	return c.handler.Handle(context.Background(), record)
+}
+
+// Sync is a no-op; the producer writes each event as it is emitted
+func (c *core) Sync() error {
+	return nil
+}
+
+// slogLevel maps a zap level onto the slog scale
+// DPanic, Panic and Fatal map above slog.LevelError
+func slogLevel(level zapcore.Level) slog.Level {
+	switch {
+	case level <= zapcore.DebugLevel:
+		return slog.LevelDebug
+	case level == zapcore.InfoLevel:
+		return slog.LevelInfo
+	case level == zapcore.WarnLevel:
+		return slog.LevelWarn
+	case level == zapcore.ErrorLevel:
+		return slog.LevelError
+	default:
+		return slog.LevelError + slog.Level(level-zapcore.ErrorLevel)
+	}
+}
+
+// attrsFromFields converts zap fields into slog attributes
+// Fields are encoded with zap's map encoder, so namespaces nest as maps
+func attrsFromFields(fields []zapcore.Field) []slog.Attr {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	encoder := zapcore.NewMapObjectEncoder()
+	for _, field := range fields {
+		field.AddTo(encoder)
+	}
+
+	attrs := make([]slog.Attr, 0, len(encoder.Fields))
+	for key, value := range encoder.Fields {
+		attrs = append(attrs, slog.Any(key, value))
+	}
+	return attrs
+}
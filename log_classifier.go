@@ -0,0 +1,206 @@
+package lifecycle
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LogClassifier recognizes a line of intercepted log output and emits a typed event for it
+// It reports whether it handled the line; unrecognized lines fall through to the next
+// classifier and finally become log.message events
+type LogClassifier func(ctx context.Context, p *Producer, line string, source *SourceLocation) (handled bool, err error)
+
+// WithLogClassifiers sets the classifiers applied to intercepted log package output
+// (default: DefaultLogClassifiers). Passing no classifiers disables classification
+func WithLogClassifiers(classifiers ...LogClassifier) ProducerOption {
+	return func(p *Producer) {
+		p.logClassifiers = classifiers
+	}
+}
+
+// DefaultLogClassifiers returns the built-in classifiers, in the order they are tried
+func DefaultLogClassifiers() []LogClassifier {
+	return []LogClassifier{
+		ClassifyPanics,
+		ClassifyAccessLogs,
+		ClassifyConnectionErrors,
+	}
+}
+
+// classifyLogLine runs the producer's classifiers against line
+func (p *Producer) classifyLogLine(ctx context.Context, line string, source *SourceLocation) (bool, error) {
+	for _, classify := range p.logClassifiers {
+		if handled, err := classify(ctx, p, line, source); handled || err != nil {
+			return handled, err
+		}
+	}
+	return false, nil
+}
+
+// ClassifyPanics emits an error.occurred event with kind "panic" for panic messages,
+// such as those logged by net/http when a handler panics
+// A goroutine dump following the message becomes the event's stack trace
+func ClassifyPanics(ctx context.Context, p *Producer, line string, source *SourceLocation) (bool, error) {
+	message, stack, hasStack := strings.Cut(line, "\ngoroutine ")
+	if !strings.HasPrefix(line, "panic: ") && !strings.Contains(message, "panic serving ") &&
+		!(hasStack && strings.Contains(stack, "[running]:")) {
+		return false, nil
+	}
+
+	if hasStack {
+		stack = "goroutine " + stack
+	}
+	return true, p.EmitErrorOccurred(ctx, "panic", strings.TrimSpace(message), stack, source)
+}
+
+// connectionErrors maps network error text to error.occurred kinds
+var connectionErrors = []struct {
+	text string
+	kind string
+}{
+	{"connection refused", "connection_refused"},
+	{"connection reset by peer", "connection_reset"},
+	{"broken pipe", "broken_pipe"},
+	{"no such host", "dns_lookup_failed"},
+	{"network is unreachable", "network_unreachable"},
+	{"i/o timeout", "timeout"},
+	{"connection timed out", "timeout"},
+}
+
+// ClassifyConnectionErrors emits an error.occurred event for lines describing network
+// failures (e.g., "dial tcp 10.0.0.5:5432: connect: connection refused")
+func ClassifyConnectionErrors(ctx context.Context, p *Producer, line string, source *SourceLocation) (bool, error) {
+	lower := strings.ToLower(line)
+	for _, ce := range connectionErrors {
+		if strings.Contains(lower, ce.text) {
+			return true, p.EmitErrorOccurred(ctx, ce.kind, line, "", source)
+		}
+	}
+	return false, nil
+}
+
+// accessLogEntry is a request parsed from an access log line
+type accessLogEntry struct {
+	timestamp  time.Time
+	method     string
+	path       string
+	statusCode int32
+	sizeBytes  int64
+	duration   time.Duration
+	remoteAddr string
+	referer    string
+	userAgent  string
+}
+
+// combinedLogPattern matches the Common and Combined Log Formats, e.g.
+// 10.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET /users HTTP/1.1" 200 2326 "-" "curl/8.0"
+var combinedLogPattern = regexp.MustCompile(`^(\S+) \S+ \S+ \[([^\]]+)\] "([A-Z]+) (\S+)[^"]*" (\d{3}) (\d+|-)(?: "([^"]*)" "([^"]*)")?`)
+
+// simpleAccessPattern matches the short form many Go middlewares log, e.g.
+// GET /users 200 12.5ms
+var simpleAccessPattern = regexp.MustCompile(`^(GET|HEAD|POST|PUT|PATCH|DELETE|OPTIONS|CONNECT|TRACE) (\S+) (\d{3})\b(.*)$`)
+
+// ClassifyAccessLogs emits api.request.handled (or api.request.errored for 4xx and 5xx
+// responses) for HTTP access log lines in Common/Combined Log Format or the short
+// "METHOD path status [duration]" form
+func ClassifyAccessLogs(ctx context.Context, p *Producer, line string, source *SourceLocation) (bool, error) {
+	entry, ok := parseAccessLog(line)
+	if !ok {
+		return false, nil
+	}
+	return true, p.emitAccessLog(ctx, entry)
+}
+
+// parseAccessLog parses an access log line
+func parseAccessLog(line string) (accessLogEntry, bool) {
+	if m := combinedLogPattern.FindStringSubmatch(line); m != nil {
+		entry := accessLogEntry{
+			method:     m[3],
+			path:       m[4],
+			remoteAddr: m[1],
+			referer:    dashToEmpty(m[7]),
+			userAgent:  dashToEmpty(m[8]),
+		}
+		entry.timestamp, _ = time.Parse("02/Jan/2006:15:04:05 -0700", m[2])
+		code, _ := strconv.Atoi(m[5])
+		entry.statusCode = int32(code)
+		entry.sizeBytes, _ = strconv.ParseInt(m[6], 10, 64)
+		return entry, true
+	}
+
+	if m := simpleAccessPattern.FindStringSubmatch(line); m != nil {
+		code, _ := strconv.Atoi(m[3])
+		entry := accessLogEntry{method: m[1], path: m[2], statusCode: int32(code)}
+		// The first token that parses as a duration is the request duration
+		for _, token := range strings.Fields(m[4]) {
+			if d, err := time.ParseDuration(strings.Trim(token, "()[],")); err == nil {
+				entry.duration = d
+				break
+			}
+		}
+		return entry, true
+	}
+
+	return accessLogEntry{}, false
+}
+
+// emitAccessLog emits the request event for a parsed access log entry
+// The method, path, and client details are recorded as metadata
+func (p *Producer) emitAccessLog(ctx context.Context, entry accessLogEntry) error {
+	metadata := map[string]interface{}{
+		"method": entry.method,
+		"path":   entry.path,
+	}
+	remoteAddr := p.clientAddr(entry.remoteAddr)
+	if remoteAddr != "" {
+		metadata["remote_addr"] = remoteAddr
+	}
+	if entry.referer != "" {
+		metadata["referer"] = entry.referer
+	}
+	if ua := p.rawUserAgent(entry.userAgent); ua != "" {
+		metadata["user_agent"] = ua
+	}
+	if client := p.locateClient(ctx, ParseUserAgent(entry.userAgent), remoteAddr); client != nil {
+		metadata["client"] = client
+	}
+
+	durationMs := entry.duration.Milliseconds()
+	var event Event
+	var base *BaseEvent
+	if entry.statusCode >= 400 {
+		base = p.createBaseEvent("api.request.errored", extractCorrelationID(ctx), metadata)
+		event = &RequestErroredEvent{
+			Base:         base,
+			Status:       StatusError,
+			ErrorMessage: http.StatusText(int(entry.statusCode)),
+			StatusCode:   entry.statusCode,
+			DurationMs:   durationMs,
+		}
+	} else {
+		base = p.createBaseEvent("api.request.handled", extractCorrelationID(ctx), metadata)
+		event = &RequestHandledEvent{
+			Base:              base,
+			Status:            StatusSuccess,
+			DurationMs:        durationMs,
+			StatusCode:        entry.statusCode,
+			ResponseSizeBytes: entry.sizeBytes,
+		}
+	}
+	if !entry.timestamp.IsZero() {
+		base.Timestamp = entry.timestamp
+	}
+	return p.emitEvent(ctx, event, entry.duration)
+}
+
+// dashToEmpty returns "" for the "-" placeholder access logs use for missing values
+func dashToEmpty(s string) string {
+	if s == "-" {
+		return ""
+	}
+	return s
+}
@@ -0,0 +1,106 @@
+package lifecycle
+
+import (
+	"log/slog"
+	"reflect"
+	"sort"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// eventLogValue implements slog.LogValuer for event structs, so passing an event to a plain
+// slog logger produces grouped fields instead of a struct dump
+// Keys come from json tags, zero values are skipped, and nested structs (including the
+// base event) and string-keyed maps become groups
+func eventLogValue(event interface{}) slog.Value {
+	v := reflect.ValueOf(event)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return slog.GroupValue()
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return slog.AnyValue(event)
+	}
+	return slog.GroupValue(structLogAttrs(v)...)
+}
+
+// structLogAttrs returns attributes for the non-zero exported fields of v
+func structLogAttrs(v reflect.Value) []slog.Attr {
+	t := v.Type()
+	attrs := make([]slog.Attr, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, skip := styledFieldName(field)
+		if skip {
+			continue
+		}
+
+		value := v.Field(i)
+		if value.IsZero() {
+			continue
+		}
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			// Embedded structs are flattened like encoding/json does
+			if inner := reflect.Indirect(value); inner.Kind() == reflect.Struct {
+				attrs = append(attrs, structLogAttrs(inner)...)
+				continue
+			}
+		}
+		attrs = append(attrs, slog.Attr{Key: name, Value: reflectLogValue(value)})
+	}
+	return attrs
+}
+
+// reflectLogValue converts a field value to a slog.Value
+func reflectLogValue(v reflect.Value) slog.Value {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return slog.AnyValue(nil)
+		}
+		v = v.Elem()
+	}
+
+	switch {
+	case v.Type() == timeType:
+		return slog.TimeValue(v.Interface().(time.Time))
+	case v.Type() == durationType:
+		return slog.DurationValue(time.Duration(v.Int()))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return slog.StringValue(v.String())
+	case reflect.Bool:
+		return slog.BoolValue(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return slog.Int64Value(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return slog.Uint64Value(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return slog.Float64Value(v.Float())
+	case reflect.Struct:
+		return slog.GroupValue(structLogAttrs(v)...)
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			break
+		}
+		keys := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+		attrs := make([]slog.Attr, 0, len(keys))
+		for _, key := range keys {
+			value := v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key()))
+			attrs = append(attrs, slog.Attr{Key: key, Value: reflectLogValue(value)})
+		}
+		return slog.GroupValue(attrs...)
+	}
+	return slog.AnyValue(v.Interface())
+}
@@ -2,16 +2,21 @@ package lifecycle
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"log/slog"
 	"os"
+	"runtime"
+	"strings"
+	"time"
 )
 
 // WrappedLogger wraps standard loggers to prevent direct logging
 // This ensures all logging goes through the lifecycle event system
 type WrappedLogger struct {
 	producer *Producer
-	fallback *slog.Logger
+	handler  *LifecycleHandler
+	fallback *slog.Logger // Used only when there is no producer
 }
 
 // NewWrappedLogger creates a wrapped logger that routes all logs through lifecycle events
@@ -19,46 +24,95 @@ type WrappedLogger struct {
 func NewWrappedLogger(producer *Producer) *WrappedLogger {
 	return &WrappedLogger{
 		producer: producer,
+		handler:  NewLifecycleHandler(producer),
 		fallback: slog.New(slog.NewTextHandler(os.Stderr, nil)),
 	}
 }
 
 // Log emits a generic log event (should be avoided in favor of specific event types)
 func (l *WrappedLogger) Log(level slog.Level, msg string, args ...interface{}) {
-	// Convert to lifecycle event
-	// In production, this should emit a structured event
-	l.fallback.Log(nil, level, msg, args...)
+	l.log(context.Background(), level, msg, args...)
 }
 
 // Debug logs a debug message (should use specific event types instead)
 func (l *WrappedLogger) Debug(msg string, args ...interface{}) {
-	l.Log(slog.LevelDebug, msg, args...)
+	l.log(context.Background(), slog.LevelDebug, msg, args...)
 }
 
 // Info logs an info message (should use specific event types instead)
 func (l *WrappedLogger) Info(msg string, args ...interface{}) {
-	l.Log(slog.LevelInfo, msg, args...)
+	l.log(context.Background(), slog.LevelInfo, msg, args...)
 }
 
 // Warn logs a warning message (should use specific event types instead)
 func (l *WrappedLogger) Warn(msg string, args ...interface{}) {
-	l.Log(slog.LevelWarn, msg, args...)
+	l.log(context.Background(), slog.LevelWarn, msg, args...)
 }
 
 // Error logs an error message (should use specific event types instead)
 func (l *WrappedLogger) Error(msg string, args ...interface{}) {
-	l.Log(slog.LevelError, msg, args...)
+	l.log(context.Background(), slog.LevelError, msg, args...)
+}
+
+// LogContext emits a log event using the correlation ID, actor, and tenant carried by ctx
+func (l *WrappedLogger) LogContext(ctx context.Context, level slog.Level, msg string, args ...interface{}) {
+	l.log(ctx, level, msg, args...)
+}
+
+// DebugContext logs a debug message with ctx
+func (l *WrappedLogger) DebugContext(ctx context.Context, msg string, args ...interface{}) {
+	l.log(ctx, slog.LevelDebug, msg, args...)
+}
+
+// InfoContext logs an info message with ctx
+func (l *WrappedLogger) InfoContext(ctx context.Context, msg string, args ...interface{}) {
+	l.log(ctx, slog.LevelInfo, msg, args...)
+}
+
+// WarnContext logs a warning message with ctx
+func (l *WrappedLogger) WarnContext(ctx context.Context, msg string, args ...interface{}) {
+	l.log(ctx, slog.LevelWarn, msg, args...)
+}
+
+// ErrorContext logs an error message with ctx
+func (l *WrappedLogger) ErrorContext(ctx context.Context, msg string, args ...interface{}) {
+	l.log(ctx, slog.LevelError, msg, args...)
+}
+
+// log converts a log call into a log.message event
+// It must be called directly by the exported logging methods so the caller's PC is found
+func (l *WrappedLogger) log(ctx context.Context, level slog.Level, msg string, args ...interface{}) {
+	if l.producer == nil {
+		l.fallback.Log(ctx, level, msg, args...)
+		return
+	}
+
+	if !l.handler.Enabled(ctx, level) {
+		return
+	}
+
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:]) // Skip runtime.Callers, log, and the exported method
+	record := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	record.Add(args...)
+	_ = l.handler.Handle(ctx, record)
 }
 
 // PreventDirectLogging replaces standard loggers with wrapped versions
 // This should be called at application startup to prevent direct logging
+// A nil producer leaves the standard loggers unchanged
 func PreventDirectLogging(producer *Producer) {
-	// Replace standard log package
-	log.SetOutput(&logWriter{producer: producer})
-	log.SetFlags(0) // Remove default flags to force structured logging
+	if producer == nil {
+		return
+	}
 
 	// Replace slog default logger
 	slog.SetDefault(slog.New(NewLifecycleHandler(producer)))
+
+	// Replace standard log package
+	// This must come after slog.SetDefault, which also redirects the log package
+	log.SetOutput(&logWriter{producer: producer})
+	log.SetFlags(0) // Remove default flags to force structured logging
 }
 
 // logWriter implements io.Writer to intercept log package output
@@ -66,43 +120,249 @@ type logWriter struct {
 	producer *Producer
 }
 
+// Write converts a line of log package output into an event
+// Lines recognized by the producer's log classifiers become typed events (e.g.,
+// error.occurred, api.request.handled); all others become log.message events
+// This is a fallback - ideally all code should use lifecycle events directly
 func (w *logWriter) Write(p []byte) (n int, err error) {
-	// Convert log output to lifecycle event
-	// This is a fallback - ideally all code should use lifecycle events directly
-	// Emit as a generic log event (should be avoided)
-	// In production, parse the log and emit appropriate event type
-	_ = string(p) // Suppress unused variable warning
+	message := strings.TrimRight(string(p), "\n")
+	if message == "" {
+		return len(p), nil
+	}
+
+	ctx := context.Background()
+	source := logCallerSource()
+	handled, err := w.producer.classifyLogLine(ctx, message, source)
+	if !handled && err == nil {
+		err = w.producer.EmitLogMessage(ctx, slog.LevelInfo, message, nil, source)
+	}
+	if err != nil {
+		return 0, err
+	}
 	return len(p), nil
 }
 
+// logCallerSource finds the first caller outside the log package and this package
+func logCallerSource() *SourceLocation {
+	var pcs [16]uintptr
+	n := runtime.Callers(2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "log.") && !strings.HasPrefix(frame.Function, packagePath+".") {
+			return sourceFromFrame(frame)
+		}
+		if !more {
+			return nil
+		}
+	}
+}
+
+// packagePath is this package's import path, used to skip its frames in stack walks
+const packagePath = "github.com/SCKelemen/lifecycle"
+
 // LifecycleHandler implements slog.Handler to route logs through lifecycle events
+// Attributes added with WithAttrs and those on the record both become the event's attrs,
+// nested as maps under the groups opened with WithGroup before them
 type LifecycleHandler struct {
 	producer *Producer
+	level    slog.Leveler
+	goas     []groupOrAttrs // Groups and attributes from WithGroup/WithAttrs, in call order
+	fallback slog.Handler   // Used only when there is no producer
+}
+
+// groupOrAttrs is either a group name or a list of attributes
+type groupOrAttrs struct {
+	group string
+	attrs []slog.Attr
+}
+
+// LifecycleHandlerOption configures the LifecycleHandler
+type LifecycleHandlerOption func(*LifecycleHandler)
+
+// WithMinLevel sets the minimum level the handler emits (default: the producer's
+// log level, see Producer.SetLogLevel)
+// Passing a *slog.LevelVar allows the level to be changed at runtime
+func WithMinLevel(level slog.Leveler) LifecycleHandlerOption {
+	return func(h *LifecycleHandler) {
+		h.level = level
+	}
 }
 
 // NewLifecycleHandler creates a new lifecycle handler
-func NewLifecycleHandler(producer *Producer) *LifecycleHandler {
-	return &LifecycleHandler{
+// Without a producer, records are written as text to stderr instead, like WrappedLogger
+func NewLifecycleHandler(producer *Producer, opts ...LifecycleHandlerOption) *LifecycleHandler {
+	h := &LifecycleHandler{
 		producer: producer,
+		level:    slog.LevelInfo,
+	}
+	if producer != nil {
+		h.level = &producer.logLevel
+	} else {
+		h.fallback = slog.NewTextHandler(os.Stderr, nil)
 	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
 }
 
+// Enabled reports whether the handler emits records at the given level
 func (h *LifecycleHandler) Enabled(ctx context.Context, level slog.Level) bool {
-	return true
+	minLevel := slog.LevelInfo
+	if h.level != nil {
+		minLevel = h.level.Level()
+	}
+	return level >= minLevel
 }
 
+// Handle converts a slog record into a log.message event
+// The correlation ID, actor, and tenant are taken from ctx
+// This is a fallback - ideally all code should use lifecycle events directly
 func (h *LifecycleHandler) Handle(ctx context.Context, record slog.Record) error {
-	// Convert slog record to lifecycle event
-	// This is a fallback - ideally all code should use lifecycle events directly
-	_ = record // Suppress unused variable warning
-	return nil
+	if h.fallback != nil {
+		return h.fallback.Handle(ctx, record)
+	}
+
+	var groups []string
+	attrs := make(map[string]interface{}, record.NumAttrs())
+	for _, goa := range h.goas {
+		if goa.group != "" {
+			groups = append(groups, goa.group)
+			continue
+		}
+		for _, attr := range goa.attrs {
+			addAttrAt(attrs, groups, attr)
+		}
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		addAttrAt(attrs, groups, attr)
+		return true
+	})
+
+	var source *SourceLocation
+	if record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		source = sourceFromFrame(frame)
+	}
+
+	return h.producer.emitLogMessage(ctx, record.Time, record.Level, record.Message, nilIfEmpty(attrs), source)
 }
 
+// WithAttrs returns a handler that adds attrs to the attrs of every event
 func (h *LifecycleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	return h
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.withGroupOrAttrs(groupOrAttrs{attrs: attrs})
 }
 
+// WithGroup returns a handler that nests subsequent attributes under name
 func (h *LifecycleHandler) WithGroup(name string) slog.Handler {
-	return h
+	if name == "" {
+		return h
+	}
+	return h.withGroupOrAttrs(groupOrAttrs{group: name})
+}
+
+// withGroupOrAttrs returns a copy of h with goa appended
+func (h *LifecycleHandler) withGroupOrAttrs(goa groupOrAttrs) *LifecycleHandler {
+	h2 := *h
+	h2.goas = make([]groupOrAttrs, len(h.goas)+1)
+	copy(h2.goas, h.goas)
+	h2.goas[len(h.goas)] = goa
+	if h.fallback != nil {
+		if goa.group != "" {
+			h2.fallback = h.fallback.WithGroup(goa.group)
+		} else {
+			h2.fallback = h.fallback.WithAttrs(goa.attrs)
+		}
+	}
+	return &h2
+}
+
+// addAttrAt adds attr to the map nested under groups in root, creating group maps
+// only when the attribute is not empty
+func addAttrAt(root map[string]interface{}, groups []string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) || (attr.Value.Kind() == slog.KindGroup && len(attr.Value.Group()) == 0) {
+		return
+	}
+
+	target := root
+	for _, group := range groups {
+		nested, ok := target[group].(map[string]interface{})
+		if !ok {
+			nested = make(map[string]interface{})
+			target[group] = nested
+		}
+		target = nested
+	}
+	addAttr(target, attr)
+}
+
+// nilIfEmpty returns nil for an empty map so it is omitted from JSON
+func nilIfEmpty(m map[string]interface{}) map[string]interface{} {
+	if len(m) == 0 {
+		return nil
+	}
+	return m
 }
 
+// addAttr adds a resolved slog attribute to m, nesting groups as maps
+func addAttr(m map[string]interface{}, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		group := attr.Value.Group()
+		if len(group) == 0 {
+			return
+		}
+		// Attributes of a group with an empty key are inlined
+		target := m
+		if attr.Key != "" {
+			nested := make(map[string]interface{}, len(group))
+			m[attr.Key] = nested
+			target = nested
+		}
+		for _, a := range group {
+			addAttr(target, a)
+		}
+		return
+	}
+
+	m[attr.Key] = attrValue(attr.Value)
+}
+
+// attrValue converts a resolved slog value into a JSON-friendly value
+func attrValue(v slog.Value) interface{} {
+	switch v.Kind() {
+	case slog.KindDuration:
+		return v.Duration().String()
+	case slog.KindAny:
+		switch a := v.Any().(type) {
+		case error:
+			return a.Error()
+		case fmt.Stringer:
+			return a.String()
+		}
+	}
+	return v.Any()
+}
+
+// sourceFromFrame converts a runtime frame into a SourceLocation
+func sourceFromFrame(frame runtime.Frame) *SourceLocation {
+	if frame.File == "" {
+		return nil
+	}
+	return &SourceLocation{
+		Function: frame.Function,
+		File:     frame.File,
+		Line:     frame.Line,
+	}
+}
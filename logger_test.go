@@ -0,0 +1,86 @@
+package lifecycle
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"testing/slogtest"
+	"time"
+)
+
+// TestLifecycleHandlerSlogtest checks LifecycleHandler against the slog.Handler contract
+func TestLifecycleHandlerSlogtest(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewProducer("slogtest", "localhost", WithOutput(&buf))
+	handler := NewLifecycleHandler(p, WithMinLevel(slog.LevelDebug))
+
+	results := func() []map[string]any {
+		var records []map[string]any
+		for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+			if len(line) == 0 {
+				continue
+			}
+			var event LogMessageEvent
+			if err := json.Unmarshal(line, &event); err != nil {
+				t.Fatalf("invalid event %s: %v", line, err)
+			}
+			records = append(records, slogRecord(&event))
+		}
+		return records
+	}
+
+	if err := slogtest.TestHandler(handler, results); err != nil {
+		t.Error(err)
+	}
+}
+
+// slogRecord returns a log.message event in the shape slogtest expects: the time, level,
+// and message under slog's keys, and the attrs at the top level
+func slogRecord(event *LogMessageEvent) map[string]any {
+	record := make(map[string]any, len(event.Attrs)+3)
+	for key, value := range event.Attrs {
+		record[key] = value
+	}
+	if !event.GetTimestamp().Equal(time.Time{}) {
+		record[slog.TimeKey] = event.GetTimestamp()
+	}
+	record[slog.LevelKey] = event.Level
+	record[slog.MessageKey] = event.Message
+	return record
+}
+
+// TestLifecycleHandlerNilProducer checks that a handler without a producer writes records
+// to stderr, with their groups and attributes, instead of panicking
+func TestLifecycleHandlerNilProducer(t *testing.T) {
+	stderr, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := os.Stderr
+	os.Stderr = stderr
+	handler := NewLifecycleHandler(nil)
+	os.Stderr = saved
+
+	slog.New(handler).WithGroup("request").With("id", 7).Info("handled", "status", 200)
+	got, err := os.ReadFile(stderr.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "msg=handled request.id=7 request.status=200"; !strings.Contains(string(got), want) {
+		t.Errorf("stderr = %q, want it to contain %q", got, want)
+	}
+}
+
+// TestPreventDirectLoggingNilProducer checks that a nil producer leaves the standard
+// loggers in place
+func TestPreventDirectLoggingNilProducer(t *testing.T) {
+	defaultLogger, output := slog.Default(), log.Writer()
+	PreventDirectLogging(nil)
+	if slog.Default() != defaultLogger || log.Writer() != output {
+		t.Error("PreventDirectLogging(nil) replaced the standard loggers")
+	}
+}
@@ -0,0 +1,153 @@
+package lifecycle
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// CorrelationIDHeader is the message header that carries the correlation ID from
+// publishers to consumers
+const CorrelationIDHeader = "X-Correlation-ID"
+
+// Message describes a broker message; payloads are never recorded
+type Message struct {
+	System        string `json:"system"`                // "kafka", "rabbitmq", ...
+	Destination   string `json:"destination"`           // Topic, exchange, or queue
+	RoutingKey    string `json:"routing_key,omitempty"` // AMQP routing key, or the Kafka message key's pattern
+	Partition     *int32 `json:"partition,omitempty"`
+	Offset        *int64 `json:"offset,omitempty"`
+	MessageID     string `json:"message_id,omitempty"`
+	ConsumerGroup string `json:"consumer_group,omitempty"`
+	SizeBytes     int    `json:"size_bytes"`
+}
+
+// MessagePublishedEvent represents a messaging.published or messaging.publish_failed event
+type MessagePublishedEvent struct {
+	Base         *BaseEvent `json:"base"`
+	Message      *Message   `json:"message"`
+	Status       Status     `json:"status"`
+	ErrorMessage string     `json:"error_message,omitempty"`
+	DurationMs   int64      `json:"duration_ms"`
+}
+
+func (e *MessagePublishedEvent) GetEventType() string     { return e.Base.GetEventType() }
+func (e *MessagePublishedEvent) GetTimestamp() time.Time  { return e.Base.GetTimestamp() }
+func (e *MessagePublishedEvent) GetService() string       { return e.Base.GetService() }
+func (e *MessagePublishedEvent) GetAPI() string           { return e.Base.GetAPI() }
+func (e *MessagePublishedEvent) GetHost() string          { return e.Base.GetHost() }
+func (e *MessagePublishedEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *MessagePublishedEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *MessagePublishedEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *MessagePublishedEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
+
+// MessageConsumedEvent represents a messaging.consumed or messaging.consume_failed event
+type MessageConsumedEvent struct {
+	Base         *BaseEvent `json:"base"`
+	Message      *Message   `json:"message"`
+	Status       Status     `json:"status"`
+	ErrorMessage string     `json:"error_message,omitempty"`
+	DurationMs   int64      `json:"duration_ms"` // Processing time
+}
+
+func (e *MessageConsumedEvent) GetEventType() string     { return e.Base.GetEventType() }
+func (e *MessageConsumedEvent) GetTimestamp() time.Time  { return e.Base.GetTimestamp() }
+func (e *MessageConsumedEvent) GetService() string       { return e.Base.GetService() }
+func (e *MessageConsumedEvent) GetAPI() string           { return e.Base.GetAPI() }
+func (e *MessageConsumedEvent) GetHost() string          { return e.Base.GetHost() }
+func (e *MessageConsumedEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *MessageConsumedEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *MessageConsumedEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *MessageConsumedEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
+
+// MessageSettledEvent represents a messaging.ack or messaging.nack event
+type MessageSettledEvent struct {
+	Base    *BaseEvent `json:"base"`
+	Message *Message   `json:"message"`
+	Requeue bool       `json:"requeue,omitempty"` // Nack only: the message was returned to the queue
+	Reason  string     `json:"reason,omitempty"`
+}
+
+func (e *MessageSettledEvent) GetEventType() string     { return e.Base.GetEventType() }
+func (e *MessageSettledEvent) GetTimestamp() time.Time  { return e.Base.GetTimestamp() }
+func (e *MessageSettledEvent) GetService() string       { return e.Base.GetService() }
+func (e *MessageSettledEvent) GetAPI() string           { return e.Base.GetAPI() }
+func (e *MessageSettledEvent) GetHost() string          { return e.Base.GetHost() }
+func (e *MessageSettledEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *MessageSettledEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *MessageSettledEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *MessageSettledEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
+
+// EmitMessagePublished emits messaging.published, or messaging.publish_failed if err is set
+func (p *Producer) EmitMessagePublished(ctx context.Context, msg *Message, duration time.Duration, err error,
+	opts ...EmitOption) error {
+	eventType, status, message := "messaging.published", StatusSuccess, ""
+	if err != nil {
+		eventType, status, message = "messaging.publish_failed", StatusError, err.Error()
+	}
+
+	b := builderBase{producer: p}
+	b.applyEmitOptions(opts)
+	event := &MessagePublishedEvent{
+		Base:         b.createBase(eventType, nil),
+		Message:      msg,
+		Status:       status,
+		ErrorMessage: message,
+		DurationMs:   duration.Milliseconds(),
+	}
+	return b.emit(ctx, event.Base, event, duration)
+}
+
+// EmitMessageConsumed emits messaging.consumed, or messaging.consume_failed if processing
+// the message failed with err
+func (p *Producer) EmitMessageConsumed(ctx context.Context, msg *Message, duration time.Duration, err error,
+	opts ...EmitOption) error {
+	eventType, status, message := "messaging.consumed", StatusSuccess, ""
+	if err != nil {
+		eventType, status, message = "messaging.consume_failed", StatusError, err.Error()
+	}
+
+	b := builderBase{producer: p}
+	b.applyEmitOptions(opts)
+	event := &MessageConsumedEvent{
+		Base:         b.createBase(eventType, nil),
+		Message:      msg,
+		Status:       status,
+		ErrorMessage: message,
+		DurationMs:   duration.Milliseconds(),
+	}
+	return b.emit(ctx, event.Base, event, duration)
+}
+
+// EmitMessageAck emits a messaging.ack event
+func (p *Producer) EmitMessageAck(ctx context.Context, msg *Message, opts ...EmitOption) error {
+	b := builderBase{producer: p}
+	b.applyEmitOptions(opts)
+	event := &MessageSettledEvent{
+		Base:    b.createBase("messaging.ack", nil),
+		Message: msg,
+	}
+	return b.emit(ctx, event.Base, event, 0)
+}
+
+// EmitMessageNack emits a messaging.nack event
+func (p *Producer) EmitMessageNack(ctx context.Context, msg *Message, requeue bool, reason string, opts ...EmitOption) error {
+	b := builderBase{producer: p}
+	b.applyEmitOptions(opts)
+	event := &MessageSettledEvent{
+		Base:    b.createBase("messaging.nack", nil),
+		Message: msg,
+		Requeue: requeue,
+		Reason:  reason,
+	}
+	return b.emit(ctx, event.Base, event, 0)
+}
@@ -0,0 +1,101 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrReservedMetadataKey is returned when metadata is attached under a key reserved for a
+// top-level event field
+var ErrReservedMetadataKey = errors.New("lifecycle: reserved metadata key")
+
+// reservedMetadataKeys are the top-level fields of the event envelope, which metadata
+// entries would shadow in flattened output and when records are queried by field
+var reservedMetadataKeys = map[string]bool{
+	"base": true, "event_id": true, "event_type": true, "schema_version": true, "timestamp": true,
+	"service": true, "api": true, "host": true, "correlation_id": true, "retention": true, "level": true, "metadata": true,
+	"resource_attributes": true, "prev_hash": true, "signature": true,
+}
+
+// ValidateMetadataKey returns an error wrapping ErrReservedMetadataKey if key is reserved
+// for a top-level event field, or is empty
+func ValidateMetadataKey(key string) error {
+	if key == "" || reservedMetadataKeys[key] {
+		return fmt.Errorf("%w: %q", ErrReservedMetadataKey, key)
+	}
+	return nil
+}
+
+// WithGlobalMetadata adds metadata entries to every event the producer emits (e.g., the
+// deployment region or build version)
+// Entries attached to the context or the emission itself take precedence. Reserved keys
+// (see ValidateMetadataKey) are dropped with a warning
+func WithGlobalMetadata(metadata map[string]interface{}) ProducerOption {
+	return func(p *Producer) {
+		if p.globalMetadata == nil {
+			p.globalMetadata = make(map[string]interface{}, len(metadata))
+		}
+		for key, value := range metadata {
+			p.globalMetadata[key] = value
+		}
+	}
+}
+
+// ContextWithMetadata returns a context carrying metadata entries for events emitted with
+// it, merged with (and overriding) those ctx already carries
+// Entries attached to the emission itself take precedence. Reserved keys (see
+// ValidateMetadataKey) are ignored
+func ContextWithMetadata(ctx context.Context, metadata map[string]interface{}) context.Context {
+	parent := MetadataFromContext(ctx)
+	merged := make(map[string]interface{}, len(parent)+len(metadata))
+	for key, value := range parent {
+		merged[key] = value
+	}
+	for key, value := range metadata {
+		if ValidateMetadataKey(key) == nil {
+			merged[key] = value
+		}
+	}
+	return context.WithValue(ctx, metadataKey, merged)
+}
+
+// MetadataFromContext returns the metadata entries carried by ctx, if any; the map must
+// not be modified
+func MetadataFromContext(ctx context.Context) map[string]interface{} {
+	metadata, _ := ctx.Value(metadataKey).(map[string]interface{})
+	return metadata
+}
+
+// dropReservedGlobalMetadata removes reserved keys from the global metadata, logging each
+func (p *Producer) dropReservedGlobalMetadata() {
+	for key := range p.globalMetadata {
+		if err := ValidateMetadataKey(key); err != nil {
+			p.logger.Warn("Ignoring global metadata entry", "error", err)
+			delete(p.globalMetadata, key)
+		}
+	}
+}
+
+// mergeMetadata adds the context's and then the producer's metadata entries to event,
+// keeping the entries it already has
+func (p *Producer) mergeMetadata(ctx context.Context, event Event) {
+	for _, metadata := range []map[string]interface{}{MetadataFromContext(ctx), p.globalMetadata} {
+		for key, value := range metadata {
+			if _, ok := event.GetMetadata()[key]; !ok {
+				event.SetMetadata(key, value)
+			}
+		}
+	}
+}
+
+// redactMetadata redacts PII in the event's metadata like event data, whether the entries
+// were attached to the emission, the context, or the producer
+func (p *Producer) redactMetadata(detector *PIIDetector, event Event) {
+	if len(event.GetMetadata()) == 0 {
+		return
+	}
+	if base := eventBase(event); base != nil {
+		base.Metadata = p.redactDataAt(detector, base.Metadata, nil, "metadata")
+	}
+}
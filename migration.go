@@ -0,0 +1,99 @@
+package lifecycle
+
+import (
+	"fmt"
+	"sync"
+)
+
+// CurrentSchemaVersion is the schema version of events emitted by this version of the
+// library. It is incremented whenever an event's fields are renamed or reshaped, with
+// a migration registered in DefaultMigrations for each change
+const CurrentSchemaVersion = 1
+
+// Migration upgrades a record in place from one schema version to the next
+type Migration func(r *Record) error
+
+// migrationKey identifies a migration by event type and source version
+type migrationKey struct {
+	eventType   string // "" applies to every event type
+	fromVersion int
+}
+
+// MigrationRegistry holds migrations that upgrade decoded events to newer schema versions,
+// so consumers keep working when the library renames or reshapes fields
+// It is safe for concurrent use
+type MigrationRegistry struct {
+	mu         sync.RWMutex
+	migrations map[migrationKey][]Migration
+}
+
+// NewMigrationRegistry creates an empty migration registry
+func NewMigrationRegistry() *MigrationRegistry {
+	return &MigrationRegistry{migrations: make(map[migrationKey][]Migration)}
+}
+
+// DefaultMigrations holds the library's own migrations between schema versions
+var DefaultMigrations = NewMigrationRegistry()
+
+// Register adds a migration that upgrades events of eventType from fromVersion to
+// fromVersion+1. An empty eventType applies to every event type
+// Migrations for the same step run in registration order, type-independent ones first
+func (m *MigrationRegistry) Register(eventType string, fromVersion int, migration Migration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := migrationKey{eventType: eventType, fromVersion: fromVersion}
+	m.migrations[key] = append(m.migrations[key], migration)
+}
+
+// Migrate upgrades r step by step to targetVersion
+// Steps with no registered migration only bump the version, since the event's shape
+// did not change. Records newer than targetVersion are left untouched
+func (m *MigrationRegistry) Migrate(r *Record, targetVersion int) error {
+	if r.SchemaVersion == 0 {
+		r.SchemaVersion = 1
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for r.SchemaVersion < targetVersion {
+		from := r.SchemaVersion
+		var steps []Migration
+		steps = append(steps, m.migrations[migrationKey{fromVersion: from}]...)
+		steps = append(steps, m.migrations[migrationKey{eventType: r.EventType, fromVersion: from}]...)
+		for _, migrate := range steps {
+			if err := migrate(r); err != nil {
+				return fmt.Errorf("failed to migrate %s from schema version %d: %w", r.EventType, from, err)
+			}
+		}
+		r.SchemaVersion = from + 1
+	}
+	return nil
+}
+
+// MigrateRecord upgrades r to CurrentSchemaVersion using DefaultMigrations
+func MigrateRecord(r *Record) error {
+	return DefaultMigrations.Migrate(r, CurrentSchemaVersion)
+}
+
+// RenameField returns a migration that renames an event-specific field
+// Records without the field are unchanged
+func RenameField(oldName, newName string) Migration {
+	return func(r *Record) error {
+		value, ok := r.Fields[oldName]
+		if !ok {
+			return nil
+		}
+		delete(r.Fields, oldName)
+		r.Fields[newName] = value
+		return nil
+	}
+}
+
+// RenameEventType returns a migration that renames the event type
+func RenameEventType(newType string) Migration {
+	return func(r *Record) error {
+		r.EventType = newType
+		return nil
+	}
+}
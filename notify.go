@@ -0,0 +1,293 @@
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultNotificationEventTypes are the critical event types a NotificationRule matches
+// when it lists none
+var DefaultNotificationEventTypes = []string{"service.crashed", "slo.violated", "lifecycle.anomaly"}
+
+// DefaultPagerDutyURL is the PagerDuty Events API v2 endpoint
+const DefaultPagerDutyURL = "https://events.pagerduty.com/v2/enqueue"
+
+// Notifier delivers a notification about a critical event
+type Notifier interface {
+	Notify(ctx context.Context, event Event) error
+}
+
+// NotificationRule routes matching events to notifiers, at most once per rate limit window
+// for each event type and service
+type NotificationRule struct {
+	EventTypes []string // Exact types or path.Match patterns (default: DefaultNotificationEventTypes)
+	Notifiers  []Notifier
+	RateLimit  time.Duration // Minimum time between notifications per event type and service (0: none)
+}
+
+// NotificationSink posts notifications about critical events to Slack, PagerDuty, or any
+// Notifier, according to routing rules
+// Events that a rule's rate limit holds back are counted, and the count is included in the
+// rule's next Slack message. Notifications are sent on the writing goroutine, so configure
+// the sink with SinkAsync to keep them off the request path:
+//
+//	lifecycle.WithSink(lifecycle.NewNotificationSink(
+//		lifecycle.NotificationRule{Notifiers: []lifecycle.Notifier{slack}, RateLimit: 5 * time.Minute},
+//		lifecycle.NotificationRule{EventTypes: []string{"service.crashed"}, Notifiers: []lifecycle.Notifier{pagerDuty}},
+//	), lifecycle.SinkAsync(100, lifecycle.BackpressureDropNewest))
+type NotificationSink struct {
+	rules   []NotificationRule
+	timeout time.Duration
+
+	mu         sync.Mutex
+	lastSent   map[notificationKey]time.Time
+	suppressed map[notificationKey]int
+}
+
+// notificationKey identifies what a rule's rate limit applies to
+type notificationKey struct {
+	rule      int
+	eventType string
+	service   string
+}
+
+// NewNotificationSink creates a sink notifying according to rules, in order; an event
+// matching several rules is sent by each
+func NewNotificationSink(rules ...NotificationRule) *NotificationSink {
+	return &NotificationSink{
+		rules:      rules,
+		timeout:    DefaultFlushTimeout,
+		lastSent:   make(map[notificationKey]time.Time),
+		suppressed: make(map[notificationKey]int),
+	}
+}
+
+// WriteEvent notifies the notifiers of each rule matching event, unless rate limited
+func (s *NotificationSink) WriteEvent(event Event) error {
+	var errs []error
+	for i, rule := range s.rules {
+		if !rule.matches(event.GetEventType()) {
+			continue
+		}
+		suppressed, ok := s.allow(i, rule, event)
+		if !ok {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		if suppressed > 0 {
+			ctx = context.WithValue(ctx, suppressedKey{}, suppressed)
+		}
+		for _, notifier := range rule.Notifiers {
+			if err := notifier.Notify(ctx, event); err != nil {
+				errs = append(errs, fmt.Errorf("failed to notify %T: %w", notifier, err))
+			}
+		}
+		cancel()
+	}
+	return errors.Join(errs...)
+}
+
+// allow reports whether rule may notify about event now, and how many notifications it
+// held back since the last one
+func (s *NotificationSink) allow(i int, rule NotificationRule, event Event) (int, bool) {
+	if rule.RateLimit <= 0 {
+		return 0, true
+	}
+	key := notificationKey{rule: i, eventType: event.GetEventType(), service: event.GetService()}
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if last, ok := s.lastSent[key]; ok && now.Sub(last) < rule.RateLimit {
+		s.suppressed[key]++
+		return 0, false
+	}
+	s.lastSent[key] = now
+	suppressed := s.suppressed[key]
+	delete(s.suppressed, key)
+	return suppressed, true
+}
+
+// matches reports whether the rule routes eventType
+func (r NotificationRule) matches(eventType string) bool {
+	patterns := r.EventTypes
+	if len(patterns) == 0 {
+		patterns = DefaultNotificationEventTypes
+	}
+	for _, pattern := range patterns {
+		if matchPattern(pattern, eventType) {
+			return true
+		}
+	}
+	return false
+}
+
+// suppressedKey is the context key of the number of rate-limited notifications
+type suppressedKey struct{}
+
+// SuppressedNotifications returns how many notifications the rate limit held back before
+// the one being sent with ctx, for custom Notifiers
+func SuppressedNotifications(ctx context.Context) int {
+	n, _ := ctx.Value(suppressedKey{}).(int)
+	return n
+}
+
+// notificationSummary describes event in one line, e.g.,
+// "service.crashed on orders (pod-1): out of memory"
+func notificationSummary(event Event) string {
+	summary := fmt.Sprintf("%s on %s", event.GetEventType(), event.GetService())
+	if host := event.GetHost(); host != "" {
+		summary += " (" + host + ")"
+	}
+	if detail := notificationDetail(event); detail != "" {
+		summary += ": " + detail
+	}
+	return summary
+}
+
+// notificationDetail returns the event's reason or message, if it has one
+func notificationDetail(event Event) string {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return ""
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return ""
+	}
+	for _, name := range []string{"reason", "message", "error_message"} {
+		if value, ok := fields[name].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// SlackNotifier posts notifications to a Slack incoming webhook
+type SlackNotifier struct {
+	WebhookURL string
+	Client     *http.Client // Default: http.DefaultClient
+}
+
+// Notify posts a message with the event's summary and key fields
+func (n *SlackNotifier) Notify(ctx context.Context, event Event) error {
+	text := notificationSummary(event)
+	if icon := defaultEventIcons()[event.GetEventType()]; icon != "" {
+		text = icon + " " + text
+	}
+
+	var fields []string
+	fields = append(fields, "*Time:* "+event.GetTimestamp().UTC().Format(time.RFC3339))
+	if api := event.GetAPI(); api != "" {
+		fields = append(fields, "*API:* "+api)
+	}
+	if id := event.GetCorrelationID(); id != "" {
+		fields = append(fields, "*Correlation ID:* `"+id+"`")
+	}
+	if suppressed := SuppressedNotifications(ctx); suppressed > 0 {
+		fields = append(fields, fmt.Sprintf("_%d similar notifications suppressed_", suppressed))
+	}
+
+	message := map[string]interface{}{
+		"text": text,
+		"blocks": []interface{}{
+			map[string]interface{}{
+				"type": "section",
+				"text": map[string]string{"type": "mrkdwn", "text": "*" + text + "*"},
+			},
+			map[string]interface{}{
+				"type":     "context",
+				"elements": []interface{}{map[string]string{"type": "mrkdwn", "text": strings.Join(fields, "  •  ")}},
+			},
+		},
+	}
+	return postJSON(ctx, n.Client, n.WebhookURL, message)
+}
+
+// PagerDutyNotifier triggers PagerDuty incidents through the Events API v2
+// Repeated events of the same type from the same service share a dedup key, so they add
+// to one open incident rather than paging again
+type PagerDutyNotifier struct {
+	RoutingKey string       // Integration key of the PagerDuty service
+	URL        string       // Default: DefaultPagerDutyURL
+	Client     *http.Client // Default: http.DefaultClient
+}
+
+// Notify triggers an incident for event, with the event as custom details
+func (n *PagerDutyNotifier) Notify(ctx context.Context, event Event) error {
+	details, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	message := map[string]interface{}{
+		"routing_key":  n.RoutingKey,
+		"event_action": "trigger",
+		"dedup_key":    event.GetService() + "/" + event.GetEventType(),
+		"payload": map[string]interface{}{
+			"summary":        truncateString(notificationSummary(event), 1024),
+			"source":         firstNonEmpty(event.GetHost(), event.GetService()),
+			"severity":       pagerDutySeverity(event),
+			"timestamp":      event.GetTimestamp().UTC().Format(time.RFC3339Nano),
+			"component":      event.GetService(),
+			"group":          eventFamily(event.GetEventType()),
+			"class":          event.GetEventType(),
+			"custom_details": json.RawMessage(details),
+		},
+	}
+	return postJSON(ctx, n.Client, firstNonEmpty(n.URL, DefaultPagerDutyURL), message)
+}
+
+// pagerDutySeverity maps an event's level to a PagerDuty severity; crashes are critical
+func pagerDutySeverity(event Event) string {
+	level := EventLevel(event)
+	switch {
+	case event.GetEventType() == "service.crashed":
+		return "critical"
+	case level >= slog.LevelError:
+		return "error"
+	case level >= slog.LevelWarn:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+// truncateString shortens s to at most n bytes
+func truncateString(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
+
+// postJSON posts message as JSON, retrying throttled requests
+func postJSON(ctx context.Context, client *http.Client, url string, message interface{}) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+	status, resp, err := doWithRetry(ctx, client, RetryPolicy{}, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	})
+	if err != nil {
+		return err
+	}
+	if status < 200 || status > 299 {
+		return fmt.Errorf("unexpected status %d: %s", status, truncateBody(resp))
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Store puts objects in an S3 bucket, or a bucket of any S3-compatible store such as
+// MinIO or Google Cloud Storage with HMAC keys (Endpoint "https://storage.googleapis.com",
+// Region "auto"), signing requests with AWS Signature Version 4
+// Empty credentials are read from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, and
+// AWS_SESSION_TOKEN. Requests answered with 429 or 503 are retried with backoff
+type S3Store struct {
+	Bucket          string
+	Region          string       // Default: AWS_REGION, then "us-east-1"
+	Endpoint        string       // Default: AWS; when set, buckets are addressed by path
+	AccessKeyID     string       // Default: AWS_ACCESS_KEY_ID
+	SecretAccessKey string       // Default: AWS_SECRET_ACCESS_KEY
+	SessionToken    string       // Default: AWS_SESSION_TOKEN
+	Client          *http.Client // Default: http.DefaultClient
+	Retry           RetryPolicy  // Backoff for throttled requests (zero: DefaultRetryPolicy)
+}
+
+// PutObject uploads body as the object key
+func (s *S3Store) PutObject(ctx context.Context, key string, body []byte, contentType string) error {
+	region := firstNonEmpty(s.Region, os.Getenv("AWS_REGION"), "us-east-1")
+	endpoint := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.Bucket, region, s3EscapePath(key))
+	if s.Endpoint != "" {
+		endpoint = fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(s.Endpoint, "/"), s.Bucket, s3EscapePath(key))
+	}
+
+	status, resp, err := doWithRetry(ctx, s.Client, s.Retry, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", contentType)
+		s.sign(req, body, region, time.Now())
+		return req, nil
+	})
+	if err != nil {
+		return err
+	}
+	if status < 200 || status > 299 {
+		return fmt.Errorf("unexpected status %d: %s", status, truncateBody(resp))
+	}
+	return nil
+}
+
+// sign adds an AWS Signature Version 4 Authorization header to req
+func (s *S3Store) sign(req *http.Request, body []byte, region string, now time.Time) {
+	accessKeyID := firstNonEmpty(s.AccessKeyID, os.Getenv("AWS_ACCESS_KEY_ID"))
+	secretAccessKey := firstNonEmpty(s.SecretAccessKey, os.Getenv("AWS_SECRET_ACCESS_KEY"))
+	sessionToken := firstNonEmpty(s.SessionToken, os.Getenv("AWS_SESSION_TOKEN"))
+
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", sessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for key, values := range req.Header {
+		headers[strings.ToLower(key)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	for _, part := range []string{region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKeyID, scope, signedHeaders, signature))
+}
+
+// s3EscapePath escapes an object key for a request path as Signature Version 4 expects:
+// everything but unreserved characters and slashes
+func s3EscapePath(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '.', c == '_', c == '~', c == '/':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// sha256Hex returns the hex-encoded SHA-256 of data
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data with key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// firstNonEmpty returns the first of values that is not empty
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// GCSStore puts objects in a Google Cloud Storage bucket with the JSON API's media upload
+// Token returns an OAuth 2.0 access token for each request, e.g., from a
+// golang.org/x/oauth2/google token source. Requests answered with 429 or 503 are retried
+// with backoff
+type GCSStore struct {
+	Bucket   string
+	Token    func(ctx context.Context) (string, error)
+	Endpoint string       // Default: "https://storage.googleapis.com"
+	Client   *http.Client // Default: http.DefaultClient
+	Retry    RetryPolicy  // Backoff for throttled requests (zero: DefaultRetryPolicy)
+}
+
+// PutObject uploads body as the object key
+func (s *GCSStore) PutObject(ctx context.Context, key string, body []byte, contentType string) error {
+	endpoint := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?uploadType=media&name=%s",
+		strings.TrimSuffix(firstNonEmpty(s.Endpoint, "https://storage.googleapis.com"), "/"),
+		url.PathEscape(s.Bucket), url.QueryEscape(key))
+
+	status, resp, err := doWithRetry(ctx, s.Client, s.Retry, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", contentType)
+		if s.Token != nil {
+			token, err := s.Token(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get access token: %w", err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		return req, nil
+	})
+	if err != nil {
+		return err
+	}
+	if status < 200 || status > 299 {
+		return fmt.Errorf("unexpected status %d: %s", status, truncateBody(resp))
+	}
+	return nil
+}
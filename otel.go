@@ -2,34 +2,82 @@ package lifecycle
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
 	"go.opentelemetry.io/otel/trace"
 )
 
 // OTelIntegration provides OpenTelemetry integration for lifecycle events
+// It is safe for concurrent use; instrument caches are guarded by a mutex
 type OTelIntegration struct {
 	tracer    trace.Tracer
 	meter     metric.Meter
+	mu        sync.Mutex // Guards counter and histogram
 	counter   map[string]metric.Int64Counter
 	histogram map[string]metric.Float64Histogram
+
+	spanMu     sync.Mutex            // Guards querySpans
+	querySpans map[string]trace.Span // Open query spans by query ID
+
+	resourceAttrs map[string]string // Copied to every event; never modified
 }
 
+// OTelOption configures an OTelIntegration
+type OTelOption func(*OTelIntegration)
+
+// WithOTelResource describes the emitting process with res, typically the resource the
+// tracer and meter providers were created with
+// Its attributes (e.g., service.version, deployment.environment, k8s.pod.name) are also
+// written on every event as resource_attributes, except service.name, which is already the
+// event's service, and the telemetry.sdk.* attributes
+func WithOTelResource(res *resource.Resource) OTelOption {
+	return func(o *OTelIntegration) {
+		o.resourceAttrs = nil
+		for iter := res.Iter(); iter.Next(); {
+			kv := iter.Attribute()
+			key := string(kv.Key)
+			if kv.Key == semconv.ServiceNameKey || strings.HasPrefix(key, "telemetry.sdk.") {
+				continue
+			}
+			if o.resourceAttrs == nil {
+				o.resourceAttrs = make(map[string]string)
+			}
+			o.resourceAttrs[key] = kv.Value.Emit()
+		}
+	}
+}
+
+// maxOpenQuerySpans bounds the query spans waiting for completion; queries started beyond
+// it get instantaneous spans instead
+const maxOpenQuerySpans = 10000
+
 // NewOTelIntegration creates a new OpenTelemetry integration
-func NewOTelIntegration(serviceName string) *OTelIntegration {
+func NewOTelIntegration(serviceName string, opts ...OTelOption) *OTelIntegration {
 	tracer := otel.Tracer("lifecycle")
 	meter := otel.Meter("lifecycle")
 
-	return &OTelIntegration{
-		tracer:    tracer,
-		meter:     meter,
-		counter:   make(map[string]metric.Int64Counter),
-		histogram: make(map[string]metric.Float64Histogram),
+	o := &OTelIntegration{
+		tracer:     tracer,
+		meter:      meter,
+		counter:    make(map[string]metric.Int64Counter),
+		histogram:  make(map[string]metric.Float64Histogram),
+		querySpans: make(map[string]trace.Span),
+	}
+	for _, opt := range opts {
+		opt(o)
 	}
+	return o
 }
 
 // StartSpan starts an OpenTelemetry span for an event
@@ -39,33 +87,81 @@ func (o *OTelIntegration) StartSpan(ctx context.Context, eventType string, attrs
 	return ctx, span
 }
 
+// eventSpan starts the span for an event and returns the span's context and a function
+// ending it
+// Query events share one span per query ID, a child of the span in ctx at db.query.started
+// that ends at db.query.completed or db.query.errored, so it covers the query's duration
+// Its db.statement is the normalized query (see NormalizeQuery), so literal values such
+// as IDs or emails in the SQL do not reach the trace backend
+func (o *OTelIntegration) eventSpan(ctx context.Context, event Event, attrs []attribute.KeyValue) (context.Context, func()) {
+	if correlationID := event.GetCorrelationID(); correlationID != "" {
+		// attrs is shared with the metrics, which must not get a per-request attribute
+		attrs = append(attrs[:len(attrs):len(attrs)], attribute.String("correlation.id", correlationID))
+	}
+
+	switch e := event.(type) {
+	case *QueryStartedEvent:
+		if e.QueryID == "" {
+			break
+		}
+		o.spanMu.Lock()
+		defer o.spanMu.Unlock()
+		if _, ok := o.querySpans[e.QueryID]; ok || len(o.querySpans) >= maxOpenQuerySpans {
+			break
+		}
+		attrs = append(attrs, attribute.String("db.query.id", e.QueryID), semconv.DBStatementKey.String(NormalizeQuery(e.Query)))
+		spanCtx, span := o.tracer.Start(ctx, "db.query",
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithTimestamp(e.GetTimestamp()),
+			trace.WithAttributes(attrs...))
+		o.querySpans[e.QueryID] = span
+		return spanCtx, func() {}
+
+	case *QueryCompletedEvent:
+		if span := o.takeQuerySpan(e.QueryID); span != nil {
+			span.SetAttributes(attribute.Int64("db.rows_affected", e.RowsAffected))
+			span.End(trace.WithTimestamp(e.GetTimestamp()))
+			return trace.ContextWithSpan(ctx, span), func() {}
+		}
+
+	case *QueryErroredEvent:
+		if span := o.takeQuerySpan(e.QueryID); span != nil {
+			if e.ErrorCode != "" {
+				span.SetAttributes(attribute.String("db.error_code", e.ErrorCode))
+			}
+			span.SetStatus(codes.Error, e.ErrorMessage)
+			span.End(trace.WithTimestamp(e.GetTimestamp()))
+			return trace.ContextWithSpan(ctx, span), func() {}
+		}
+	}
+
+	spanCtx, span := o.StartSpan(ctx, event.GetEventType(), attrs...)
+	return spanCtx, func() { span.End() }
+}
+
+// takeQuerySpan removes and returns the open span for queryID, if any
+func (o *OTelIntegration) takeQuerySpan(queryID string) trace.Span {
+	o.spanMu.Lock()
+	defer o.spanMu.Unlock()
+	span, ok := o.querySpans[queryID]
+	if !ok {
+		return nil
+	}
+	delete(o.querySpans, queryID)
+	return span
+}
+
 // RecordMetric records a metric for an event
 func (o *OTelIntegration) RecordMetric(ctx context.Context, eventType string, duration time.Duration, attrs ...attribute.KeyValue) {
 	// Record counter
-	counterName := o.getCounterName(eventType)
-	counter, ok := o.counter[counterName]
-	if !ok {
-		var err error
-		counter, err = o.meter.Int64Counter(counterName, metric.WithDescription("Count of "+eventType+" events"))
-		if err == nil {
-			o.counter[counterName] = counter
-		}
-	}
+	counter := o.getCounter(o.getCounterName(eventType), metric.WithDescription("Count of "+eventType+" events"))
 	if counter != nil {
 		counter.Add(ctx, 1, metric.WithAttributes(attrs...))
 	}
 
 	// Record duration histogram for timed events
 	if duration > 0 {
-		histogramName := o.getHistogramName(eventType)
-		histogram, ok := o.histogram[histogramName]
-		if !ok {
-			var err error
-			histogram, err = o.meter.Float64Histogram(histogramName, metric.WithDescription("Duration of "+eventType+" events"))
-			if err == nil {
-				o.histogram[histogramName] = histogram
-			}
-		}
+		histogram := o.getHistogram(o.getHistogramName(eventType), metric.WithDescription("Duration of "+eventType+" events"))
 		if histogram != nil {
 			histogram.Record(ctx, duration.Seconds(), metric.WithAttributes(attrs...))
 		}
@@ -74,19 +170,52 @@ func (o *OTelIntegration) RecordMetric(ctx context.Context, eventType string, du
 
 // RecordValue records a value metric (for gauges or histograms)
 func (o *OTelIntegration) RecordValue(ctx context.Context, metricName string, value float64, attrs ...attribute.KeyValue) {
-	histogram, ok := o.histogram[metricName]
-	if !ok {
-		var err error
-		histogram, err = o.meter.Float64Histogram(metricName)
-		if err == nil {
-			o.histogram[metricName] = histogram
-		}
-	}
+	histogram := o.getHistogram(metricName)
 	if histogram != nil {
 		histogram.Record(ctx, value, metric.WithAttributes(attrs...))
 	}
 }
 
+// RecordDropped counts events a sink discarded under backpressure
+func (o *OTelIntegration) RecordDropped(ctx context.Context, sink, policy string, dropped int64) {
+	counter := o.getCounter("lifecycle.events.dropped", metric.WithDescription("Events discarded by sinks under backpressure"))
+	if counter != nil {
+		counter.Add(ctx, dropped, metric.WithAttributes(attribute.String("sink", sink), attribute.String("policy", policy)))
+	}
+}
+
+// getCounter returns the cached counter for name, creating it on first use
+func (o *OTelIntegration) getCounter(name string, opts ...metric.Int64CounterOption) metric.Int64Counter {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if counter, ok := o.counter[name]; ok {
+		return counter
+	}
+	counter, err := o.meter.Int64Counter(name, opts...)
+	if err != nil {
+		return nil
+	}
+	o.counter[name] = counter
+	return counter
+}
+
+// getHistogram returns the cached histogram for name, creating it on first use
+func (o *OTelIntegration) getHistogram(name string, opts ...metric.Float64HistogramOption) metric.Float64Histogram {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if histogram, ok := o.histogram[name]; ok {
+		return histogram
+	}
+	histogram, err := o.meter.Float64Histogram(name, opts...)
+	if err != nil {
+		return nil
+	}
+	o.histogram[name] = histogram
+	return histogram
+}
+
 // getSpanName converts event type to span name
 func (o *OTelIntegration) getSpanName(eventType string) string {
 	// Convert event type to span name
@@ -114,11 +243,15 @@ func splitEventType(eventType string) []string {
 }
 
 // EventAttributes converts event data to OpenTelemetry attributes
+// Names follow the semantic conventions where one exists (event.name, http.request.method,
+// db.system, messaging.destination.name, ...). The attributes are shared by spans and
+// metrics, so they stay low-cardinality: queries are identified by db.statement.hash (see
+// QueryFingerprint), and the statement itself and correlation.id are only set on spans
 func EventAttributes(event Event) []attribute.KeyValue {
 	attrs := []attribute.KeyValue{
-		attribute.String("event.type", event.GetEventType()),
-		attribute.String("service.name", event.GetService()),
-		attribute.String("service.instance.id", event.GetHost()),
+		semconv.EventNameKey.String(event.GetEventType()),
+		semconv.ServiceNameKey.String(event.GetService()),
+		semconv.ServiceInstanceIDKey.String(event.GetHost()),
 	}
 
 	// Add API identifier if present (allows filtering by API across services)
@@ -126,9 +259,149 @@ func EventAttributes(event Event) []attribute.KeyValue {
 		attrs = append(attrs, attribute.String("api.name", api))
 	}
 
-	if correlationID := event.GetCorrelationID(); correlationID != "" {
-		attrs = append(attrs, attribute.String("correlation.id", correlationID))
+	return appendSemconvAttributes(attrs, event)
+}
+
+// appendSemconvAttributes appends the semantic convention attributes of known event types
+func appendSemconvAttributes(attrs []attribute.KeyValue, event Event) []attribute.KeyValue {
+	switch e := event.(type) {
+	case *RequestReceivedEvent:
+		attrs = append(attrs, semconv.HTTPRequestMethodKey.String(e.Method))
+		if e.Route != "" {
+			attrs = append(attrs, semconv.HTTPRoute(e.Route))
+		}
+	case *RequestHandledEvent:
+		attrs = append(attrs, semconv.HTTPResponseStatusCodeKey.Int(int(e.StatusCode)))
+	case *RequestErroredEvent:
+		if e.StatusCode != 0 {
+			attrs = append(attrs, semconv.HTTPResponseStatusCodeKey.Int(int(e.StatusCode)))
+		}
+	case *APICallEvent:
+		attrs = append(attrs, semconv.HTTPRequestMethodKey.String(e.Method))
+		if e.StatusCode != 0 {
+			attrs = append(attrs, semconv.HTTPResponseStatusCodeKey.Int(int(e.StatusCode)))
+		}
+	case *QueryStartedEvent:
+		attrs = append(attrs, attribute.String("db.statement.hash", QueryFingerprint(e.Query)))
+	case *QuerySlowEvent:
+		attrs = append(attrs, attribute.String("db.statement.hash", e.Fingerprint))
+	case *RedisCommandStartedEvent:
+		attrs = append(attrs, semconv.DBSystemRedis, semconv.DBOperationKey.String(e.Command))
+	case *RedisCommandCompletedEvent:
+		attrs = append(attrs, semconv.DBSystemRedis, semconv.DBOperationKey.String(e.Command))
+	case *RedisCommandErroredEvent:
+		attrs = append(attrs, semconv.DBSystemRedis, semconv.DBOperationKey.String(e.Command))
+	case *MessagePublishedEvent:
+		attrs = appendMessageAttributes(attrs, e.Message, semconv.MessagingOperationPublish)
+	case *MessageConsumedEvent:
+		attrs = appendMessageAttributes(attrs, e.Message, semconv.MessagingOperationReceive)
+	case *MessageSettledEvent:
+		attrs = appendMessageAttributes(attrs, e.Message, semconv.MessagingOperationReceive)
+	}
+	return attrs
+}
+
+// appendMessageAttributes appends the messaging attributes of msg
+func appendMessageAttributes(attrs []attribute.KeyValue, msg *Message, operation attribute.KeyValue) []attribute.KeyValue {
+	if msg == nil {
+		return attrs
+	}
+	return append(attrs,
+		semconv.MessagingSystemKey.String(msg.System),
+		semconv.MessagingDestinationNameKey.String(msg.Destination),
+		operation,
+	)
+}
+
+// AddLogSpanEvent attaches a log message to the span active in ctx as a span event,
+// so trace views show logs inline. It does nothing if there is no recording span
+func (o *OTelIntegration) AddLogSpanEvent(ctx context.Context, level slog.Level, event *LogMessageEvent) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
 	}
 
+	opts := []trace.EventOption{trace.WithAttributes(LogAttributes(level, event)...)}
+	if ts := event.GetTimestamp(); !ts.IsZero() {
+		opts = append(opts, trace.WithTimestamp(ts))
+	}
+	span.AddEvent("log", opts...)
+}
+
+// TraceIDHook is an EventHook recording the trace and span IDs of the span in the emitting
+// context as the metadata keys trace_id and span_id, so log platforms can link events to
+// traces (see DatadogWriter). Add it with WithEventHook
+func TraceIDHook(ctx context.Context, event Event) {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return
+	}
+	metadata := event.GetMetadata()
+	if _, ok := metadata["trace_id"]; !ok {
+		event.SetMetadata("trace_id", spanContext.TraceID().String())
+	}
+	if _, ok := metadata["span_id"]; !ok {
+		event.SetMetadata("span_id", spanContext.SpanID().String())
+	}
+}
+
+// LogAttributes converts a log message to OpenTelemetry attributes
+// Severity numbers follow the OpenTelemetry log data model (INFO = 9, ERROR = 17)
+// and nested attrs are flattened with dotted keys
+func LogAttributes(level slog.Level, event *LogMessageEvent) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		attribute.String("log.severity", event.Level),
+		attribute.Int("log.severity_number", int(level)+9),
+		attribute.String("log.message", event.Message),
+	}
+
+	if event.Source != nil {
+		attrs = append(attrs,
+			attribute.String("code.function", event.Source.Function),
+			attribute.String("code.filepath", event.Source.File),
+			attribute.Int("code.lineno", event.Source.Line),
+		)
+	}
+	if event.Tenant != "" {
+		attrs = append(attrs, attribute.String("tenant.id", event.Tenant))
+	}
+	if event.Actor != nil && event.Actor.UserID != "" {
+		attrs = append(attrs, attribute.String("enduser.id", event.Actor.UserID))
+	}
+
+	return appendMapAttributes(attrs, "", event.Attrs)
+}
+
+// appendMapAttributes appends the values of m as attributes, flattening nested maps
+func appendMapAttributes(attrs []attribute.KeyValue, prefix string, m map[string]interface{}) []attribute.KeyValue {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		name := prefix + key
+		switch v := m[key].(type) {
+		case map[string]interface{}:
+			attrs = appendMapAttributes(attrs, name+".", v)
+		case string:
+			attrs = append(attrs, attribute.String(name, v))
+		case bool:
+			attrs = append(attrs, attribute.Bool(name, v))
+		case int:
+			attrs = append(attrs, attribute.Int(name, v))
+		case int64:
+			attrs = append(attrs, attribute.Int64(name, v))
+		case uint64:
+			attrs = append(attrs, attribute.Int64(name, int64(v)))
+		case float64:
+			attrs = append(attrs, attribute.Float64(name, v))
+		case time.Time:
+			attrs = append(attrs, attribute.String(name, v.Format(time.RFC3339Nano)))
+		default:
+			attrs = append(attrs, attribute.String(name, fmt.Sprint(v)))
+		}
+	}
 	return attrs
 }
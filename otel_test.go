@@ -0,0 +1,47 @@
+package lifecycle
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// TestOTelQuerySpan checks that correlation.id stays off metric attributes but is set on
+// spans, and that the db.query span does not export the query's literal values
+func TestOTelQuerySpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	integration := NewOTelIntegration("otel")
+	integration.tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+	p := NewProducer("otel", "localhost", WithOutput(io.Discard), WithOTelIntegration(integration))
+
+	ctx := ContextWithCorrelationID(context.Background(), "req-1")
+	event, _ := p.NewBaseEvent(ctx, "custom.event")
+	for _, attr := range EventAttributes(event) {
+		if attr.Key == "correlation.id" {
+			t.Errorf("EventAttributes has correlation.id = %s", attr.Value.Emit())
+		}
+	}
+
+	if err := p.EmitQueryStarted(ctx, "q1", "SELECT * FROM users WHERE email = 'ann@example.com' AND id = 42", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.EmitQueryCompleted(ctx, "q1", 3, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Name() != "db.query" {
+		t.Fatalf("ended spans = %v, want one db.query span", spans)
+	}
+	attrs := attribute.NewSet(spans[0].Attributes()...)
+	if got, _ := attrs.Value("db.statement"); got.AsString() != "select * from users where email = ? and id = ?" {
+		t.Errorf("db.statement = %q, want the normalized query", got.AsString())
+	}
+	if got, _ := attrs.Value("correlation.id"); got.AsString() != "req-1" {
+		t.Errorf("correlation.id = %q, want req-1", got.AsString())
+	}
+}
@@ -4,73 +4,213 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
 )
 
 // PIIDetector detects PII in data based on field names and patterns
+// It is immutable after construction and safe for concurrent use
 type PIIDetector struct {
-	// PII field patterns (field names that indicate PII)
-	piiFieldPatterns []*regexp.Regexp
-	// PII value patterns (values that match PII patterns)
-	piiValuePatterns []*regexp.Regexp
+	fields *keywordMatcher // Field names that indicate PII
+	values *regexp.Regexp  // Values that look like PII, tried only on plausible candidates
+
+	// Set on the per-event copies a producer redacts with
+	cache     *redactionCache
+	eventType string
+}
+
+// piiFieldKeywords are matched case-insensitively anywhere in a field name; '.' matches
+// any character
+var piiFieldKeywords = []string{
+	"email", "e-mail",
+	"phone", "telephone", "mobile",
+	"ssn", "social.security",
+	"credit.card", "card.number",
+	"password", "passwd", "pwd",
+	"secret", "token", "key",
+	"address", "street", "city", "zip", "postal",
+	"name", "firstname", "lastname", "fullname",
+	"dob", "date.of.birth", "birthdate",
+	"ip.address", "ip_addr",
+}
+
+// piiValuePattern matches whole values that look like PII
+var piiValuePattern = regexp.MustCompile(`^(?:` + strings.Join([]string{
+	`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`, // Email
+	`\+?[1-9]\d{1,14}`,                          // Phone (E.164)
+	`\(?\d{3}\)?[-.\s]?\d{3}[-.\s]?\d{4}`,       // Phone (common formats)
+	`\d{4}[\s\-]?\d{4}[\s\-]?\d{4}[\s\-]?\d{4}`, // Credit card (basic)
+	`\d{3}-?\d{2}-?\d{4}`,                       // SSN
+}, "|") + `)$`)
+
+// defaultPIIDetector is shared by every detector and redactor; compiling the patterns is
+// far more expensive than matching them
+var defaultPIIDetector = &PIIDetector{
+	fields: newKeywordMatcher(piiFieldKeywords),
+	values: piiValuePattern,
 }
 
-// NewPIIDetector creates a new PII detector with default patterns
+// NewPIIDetector returns a PII detector with default patterns
+// The patterns are compiled once and shared
 func NewPIIDetector() *PIIDetector {
-	return &PIIDetector{
-		piiFieldPatterns: []*regexp.Regexp{
-			// Common PII field names
-			regexp.MustCompile(`(?i)(email|e-mail)`),
-			regexp.MustCompile(`(?i)(phone|telephone|mobile)`),
-			regexp.MustCompile(`(?i)(ssn|social.security)`),
-			regexp.MustCompile(`(?i)(credit.card|card.number)`),
-			regexp.MustCompile(`(?i)(password|passwd|pwd)`),
-			regexp.MustCompile(`(?i)(secret|token|key)`),
-			regexp.MustCompile(`(?i)(address|street|city|zip|postal)`),
-			regexp.MustCompile(`(?i)(name|firstname|lastname|fullname)`),
-			regexp.MustCompile(`(?i)(dob|date.of.birth|birthdate)`),
-			regexp.MustCompile(`(?i)(ip.address|ip_addr)`),
-		},
-		piiValuePatterns: []*regexp.Regexp{
-			// Email pattern
-			regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`),
-			// Phone pattern (E.164 or common formats)
-			regexp.MustCompile(`^\+?[1-9]\d{1,14}$`),
-			regexp.MustCompile(`^\(?\d{3}\)?[-.\s]?\d{3}[-.\s]?\d{4}$`),
-			// Credit card pattern (basic)
-			regexp.MustCompile(`^\d{4}[\s\-]?\d{4}[\s\-]?\d{4}[\s\-]?\d{4}$`),
-			// SSN pattern
-			regexp.MustCompile(`^\d{3}-?\d{2}-?\d{4}$`),
-		},
-	}
+	return defaultPIIDetector
 }
 
 // IsPIIField checks if a field name indicates PII
 func (d *PIIDetector) IsPIIField(fieldName string) bool {
-	for _, pattern := range d.piiFieldPatterns {
-		if pattern.MatchString(fieldName) {
-			return true
-		}
-	}
-	return false
+	return d.fields.match(fieldName)
 }
 
 // IsPIIValue checks if a value matches PII patterns
 func (d *PIIDetector) IsPIIValue(value interface{}) bool {
 	str, ok := value.(string)
-	if !ok {
+	if !ok || !maybePIIValue(str) {
+		return false
+	}
+	return d.values.MatchString(str)
+}
+
+// maybePIIValue reports whether s could match a PII value pattern: it contains '@', or it
+// is short and made only of digits and phone or card punctuation
+// Most values fail this cheaply, without running the regular expression
+func maybePIIValue(s string) bool {
+	if strings.IndexByte(s, '@') >= 0 {
+		return true
+	}
+	if len(s) < 2 || len(s) > 19 {
 		return false
 	}
+	digits := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c >= '0' && c <= '9':
+			digits++
+		case c == '+' || c == '-' || c == '.' || c == '(' || c == ')' || c == ' ' || c == '\t' || c == '\n' || c == '\f' || c == '\r':
+		default:
+			return false
+		}
+	}
+	return digits >= 2
+}
+
+// keywordMatcher finds any of a set of keywords in a string in one pass over its
+// starting positions, walking a trie of the keywords
+// Matching follows the (?i) regular expressions it replaces: runes are compared with
+// Unicode simple case folding (so the Kelvin sign matches 'k' and 'ſ' matches 's'), and
+// '.' in a keyword matches any one rune except a newline
+type keywordMatcher struct {
+	next [][keywordClasses]int32 // Trie transitions by character class; 0 means none
+	end  []bool                  // Whether a keyword ends at the node
+}
+
+// keywordClasses is the number of character classes: other, a-z, '-', '_', and newline
+const keywordClasses = 30
 
-	for _, pattern := range d.piiValuePatterns {
-		if pattern.MatchString(str) {
-			return true
+// newlineClass is the class of '\n', the one rune '.' does not match
+const newlineClass = 29
+
+// keywordClass maps ASCII bytes to character classes
+var keywordClass = func() (classes [utf8.RuneSelf]uint8) {
+	for c := 'a'; c <= 'z'; c++ {
+		classes[c] = uint8(c-'a') + 1
+		classes[c-'a'+'A'] = uint8(c-'a') + 1
+	}
+	classes['-'] = 27
+	classes['_'] = 28
+	classes['\n'] = newlineClass
+	return classes
+}()
+
+// keywordFoldClass maps the non-ASCII runes that fold to ASCII letters to their classes
+var keywordFoldClass = func() map[rune]uint8 {
+	classes := make(map[rune]uint8)
+	for c := 'a'; c <= 'z'; c++ {
+		for f := unicode.SimpleFold(c); f != c; f = unicode.SimpleFold(f) {
+			if f >= utf8.RuneSelf {
+				classes[f] = keywordClass[c]
+			}
 		}
 	}
+	return classes
+}()
+
+// runeClass returns the character class of the rune starting s, and its width
+func runeClass(s string) (uint8, int) {
+	if c := s[0]; c < utf8.RuneSelf {
+		return keywordClass[c], 1
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	return keywordFoldClass[r], size
+}
+
+// newKeywordMatcher builds a matcher for keywords made of letters, '-', '_', and '.'
+func newKeywordMatcher(keywords []string) *keywordMatcher {
+	m := &keywordMatcher{}
+	m.node() // Root
+	for _, keyword := range keywords {
+		m.insert(0, keyword)
+	}
+	return m
+}
+
+// node adds an empty trie node and returns its index
+func (m *keywordMatcher) node() int32 {
+	m.next = append(m.next, [keywordClasses]int32{})
+	m.end = append(m.end, false)
+	return int32(len(m.next) - 1)
+}
+
+// insert adds the rest of a keyword below node
+func (m *keywordMatcher) insert(node int32, keyword string) {
+	if keyword == "" {
+		m.end[node] = true
+		return
+	}
+	classes := []uint8{keywordClass[keyword[0]]}
+	if keyword[0] == '.' {
+		classes = classes[:0]
+		for c := uint8(0); c < keywordClasses; c++ {
+			if c != newlineClass {
+				classes = append(classes, c)
+			}
+		}
+	}
+	for _, c := range classes {
+		child := m.next[node][c]
+		if child == 0 {
+			child = m.node()
+			m.next[node][c] = child
+		}
+		m.insert(child, keyword[1:])
+	}
+}
+
+// match reports whether s contains any keyword
+func (m *keywordMatcher) match(s string) bool {
+	for start := 0; start < len(s); {
+		node := int32(0)
+		for i := start; i < len(s); {
+			class, size := runeClass(s[i:])
+			node = m.next[node][class]
+			if node == 0 {
+				break
+			}
+			if m.end[node] {
+				return true
+			}
+			i += size
+		}
+		_, size := runeClass(s[start:])
+		start += size
+	}
 	return false
 }
 
 // Redactor redacts PII from data
+// It is safe for concurrent use, including changing the redaction string
 type Redactor struct {
+	mu              sync.RWMutex
 	redactionString string
 }
 
@@ -83,10 +223,19 @@ func NewRedactor() *Redactor {
 
 // WithRedactionString sets a custom redaction string
 func (r *Redactor) WithRedactionString(s string) *Redactor {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.redactionString = s
 	return r
 }
 
+// mask returns the current redaction string
+func (r *Redactor) mask() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.redactionString
+}
+
 // Redact redacts a value if it's PII
 func (r *Redactor) Redact(value interface{}) interface{} {
 	if value == nil {
@@ -95,9 +244,8 @@ func (r *Redactor) Redact(value interface{}) interface{} {
 
 	// Check if it's a string that looks like PII
 	if str, ok := value.(string); ok {
-		detector := NewPIIDetector()
-		if detector.IsPIIValue(str) {
-			return r.redactionString
+		if defaultPIIDetector.IsPIIValue(str) {
+			return r.mask()
 		}
 	}
 
@@ -105,40 +253,89 @@ func (r *Redactor) Redact(value interface{}) interface{} {
 }
 
 // RedactMap redacts PII from a map based on field names and values
+// Client IP addresses, in fields such as remote_addr, are anonymized with AnonymizeIP;
+// values of those fields that are not IP addresses are redacted
 func (r *Redactor) RedactMap(data map[string]interface{}, detector *PIIDetector) map[string]interface{} {
+	return r.redactMap(data, detector, "")
+}
+
+// redactMap redacts PII from the map at path
+func (r *Redactor) redactMap(data map[string]interface{}, detector *PIIDetector, path string) map[string]interface{} {
 	if data == nil {
 		return nil
 	}
 
 	redacted := make(map[string]interface{})
 	for key, value := range data {
+		// Client IP addresses are anonymized rather than redacted
+		if isIPField(key) && !detector.IsPIIValue(value) {
+			if anonymized, ok := redactIPValue(value, r.mask()); ok {
+				redacted[key] = anonymized
+				continue
+			}
+		}
+
 		// Check if field name indicates PII
-		if detector.IsPIIField(key) {
-			redacted[key] = r.redactionString
+		if detector.isPIIFieldAt(path, key, nil) {
+			redacted[key] = r.mask()
 			continue
 		}
 
 		// Check if value matches PII patterns
 		if detector.IsPIIValue(value) {
-			redacted[key] = r.redactionString
+			redacted[key] = r.mask()
 			continue
 		}
 
-		// Recursively handle nested maps
-		if nestedMap, ok := value.(map[string]interface{}); ok {
-			redacted[key] = r.RedactMap(nestedMap, detector)
-		} else if nestedSlice, ok := value.([]interface{}); ok {
-			redacted[key] = r.RedactSlice(nestedSlice, detector)
-		} else {
-			redacted[key] = value
-		}
+		redacted[key] = r.redactNested(value, detector, fieldPath(path, key))
 	}
 
 	return redacted
 }
 
+// redactNested recursively redacts maps and slices, including the string-typed forms
+// produced by decoding headers and query parameters; other values are returned as is
+func (r *Redactor) redactNested(value interface{}, detector *PIIDetector, path string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return r.redactMap(v, detector, path)
+	case []interface{}:
+		return r.redactSlice(v, detector, path)
+	case map[string]string:
+		nested := make(map[string]interface{}, len(v))
+		for key, s := range v {
+			nested[key] = s
+		}
+		return r.redactMap(nested, detector, path)
+	case map[string][]string:
+		nested := make(map[string]interface{}, len(v))
+		for key, values := range v {
+			nested[key] = stringsToInterfaces(values)
+		}
+		return r.redactMap(nested, detector, path)
+	case []string:
+		return r.redactSlice(stringsToInterfaces(v), detector, path)
+	default:
+		return value
+	}
+}
+
+// stringsToInterfaces converts a []string to a []interface{}
+func stringsToInterfaces(values []string) []interface{} {
+	converted := make([]interface{}, len(values))
+	for i, s := range values {
+		converted[i] = s
+	}
+	return converted
+}
+
 // RedactSlice redacts PII from a slice
 func (r *Redactor) RedactSlice(slice []interface{}, detector *PIIDetector) []interface{} {
+	return r.redactSlice(slice, detector, "")
+}
+
+// redactSlice redacts PII from the slice at path; its elements share the path
+func (r *Redactor) redactSlice(slice []interface{}, detector *PIIDetector, path string) []interface{} {
 	if slice == nil {
 		return nil
 	}
@@ -146,11 +343,9 @@ func (r *Redactor) RedactSlice(slice []interface{}, detector *PIIDetector) []int
 	redacted := make([]interface{}, len(slice))
 	for i, value := range slice {
 		if detector.IsPIIValue(value) {
-			redacted[i] = r.redactionString
-		} else if nestedMap, ok := value.(map[string]interface{}); ok {
-			redacted[i] = r.RedactMap(nestedMap, detector)
+			redacted[i] = r.mask()
 		} else {
-			redacted[i] = value
+			redacted[i] = r.redactNested(value, detector, path)
 		}
 	}
 
@@ -163,11 +358,11 @@ func (r *Redactor) RedactParams(params []interface{}) []interface{} {
 		return nil
 	}
 
-	detector := NewPIIDetector()
+	detector := defaultPIIDetector
 	redacted := make([]interface{}, len(params))
 	for i, param := range params {
 		if detector.IsPIIValue(param) {
-			redacted[i] = r.redactionString
+			redacted[i] = r.mask()
 		} else {
 			redacted[i] = param
 		}
@@ -178,25 +373,24 @@ func (r *Redactor) RedactParams(params []interface{}) []interface{} {
 
 // RedactString redacts PII from a string value
 func (r *Redactor) RedactString(value string) string {
-	detector := NewPIIDetector()
-	if detector.IsPIIValue(value) {
-		return r.redactionString
+	if defaultPIIDetector.IsPIIValue(value) {
+		return r.mask()
 	}
 	return value
 }
 
 // FormatRedacted formats a redacted value for display
 func (r *Redactor) FormatRedacted(fieldName string, value interface{}) string {
-	detector := NewPIIDetector()
-	
+	detector := defaultPIIDetector
+
 	// Check field name
 	if detector.IsPIIField(fieldName) {
-		return fmt.Sprintf("%s=%s", fieldName, r.redactionString)
+		return fmt.Sprintf("%s=%s", fieldName, r.mask())
 	}
 
 	// Check value
 	if detector.IsPIIValue(value) {
-		return fmt.Sprintf("%s=%s", fieldName, r.redactionString)
+		return fmt.Sprintf("%s=%s", fieldName, r.mask())
 	}
 
 	// Return original
@@ -211,14 +405,14 @@ func (r *Redactor) MaskEmail(email string) string {
 
 	parts := strings.Split(email, "@")
 	if len(parts) != 2 {
-		return r.redactionString
+		return r.mask()
 	}
 
 	local := parts[0]
 	domain := parts[1]
 
 	if len(local) == 0 {
-		return r.redactionString
+		return r.mask()
 	}
 
 	// Mask local part (keep first character)
@@ -239,5 +433,3 @@ func (r *Redactor) MaskPhone(phone string) string {
 
 	return phone[:2] + strings.Repeat("*", len(phone)-4) + phone[len(phone)-2:]
 }
-
-
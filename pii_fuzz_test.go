@@ -0,0 +1,301 @@
+package lifecycle
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// redactionSeeds are JSON documents the fuzz targets start from: nested structures,
+// mixed types, IP fields, and strings close to the PII patterns
+var redactionSeeds = []string{
+	`{"email":"alice@example.com","name":"Alice"}`,
+	`{"user":{"contact":{"phone":"+14155550123","notes":["call 555-123-4567","ok"]}}}`,
+	`{"items":[["4111 1111 1111 1111"],[{"ssn":"123-45-6789"}],[[["bob@example.org"]]]]}`,
+	`{"client_ip":"Alice Smith","ip_address":"alice-laptop.corp.example","remote_addr":"203.0.113.42:51234"}`,
+	`{"x-forwarded-for":["198.51.100.7, 10.0.0.1","bob@example.org",{"a":"b"}],"ip":{"email":"x@y.zz"}}`,
+	`{"password":null,"token":{"nested":true},"key":[1,2,3],"count":12345678901}`,
+	`{"Credit_Card":"4111-1111-1111-1111","date-of-birth":"1990-01-01","social security":"123456789"}`,
+	`{"":"","\u0000":"a@b.cc","k":"\u00e9@\u00e9.com","deep":{"deep":{"deep":{"deep":{"v":"+1 (415) 555-0123"}}}}}`,
+	`["alice@example.com",1,true,null,{"email":"a@b.co"},["555.123.4567"]]`,
+	`{"v":"a@b.c","w":"@","x":"+1","y":"1234567890123456789012","z":"12"}`,
+}
+
+// FuzzRedactMap checks that RedactMap leaves no PII in any map decoded from JSON
+func FuzzRedactMap(f *testing.F) {
+	for _, seed := range redactionSeeds {
+		f.Add([]byte(seed))
+	}
+	detector := NewPIIDetector()
+	redactor := NewRedactor()
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var value map[string]interface{}
+		if err := json.Unmarshal(data, &value); err != nil {
+			return
+		}
+		checkRedactedJSON(t, redactor.RedactMap(value, detector), detector, redactor.mask())
+	})
+}
+
+// FuzzRedactSlice checks that RedactSlice leaves no PII in any array decoded from JSON
+func FuzzRedactSlice(f *testing.F) {
+	for _, seed := range redactionSeeds {
+		f.Add([]byte(seed))
+	}
+	detector := NewPIIDetector()
+	redactor := NewRedactor()
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var value []interface{}
+		if err := json.Unmarshal(data, &value); err != nil {
+			return
+		}
+		checkRedactedJSON(t, redactor.RedactSlice(value, detector), detector, redactor.mask())
+	})
+}
+
+// FuzzRedact checks that Redact and RedactString replace every string that looks like PII
+func FuzzRedact(f *testing.F) {
+	for _, seed := range []string{"alice@example.com", "+14155550123", "(415) 555-0123", "4111 1111 1111 1111", "123-45-6789", "hello", "", "@", "12"} {
+		f.Add(seed)
+	}
+	detector := NewPIIDetector()
+	redactor := NewRedactor()
+	f.Fuzz(func(t *testing.T, s string) {
+		redacted := redactor.Redact(s)
+		if detector.IsPIIValue(redacted) {
+			t.Fatalf("Redact(%q) = %q, which looks like PII", s, redacted)
+		}
+		if detector.IsPIIValue(s) && redacted != redactor.mask() {
+			t.Fatalf("Redact(%q) = %q, want %q", s, redacted, redactor.mask())
+		}
+		if got := redactor.RedactString(s); got != redacted {
+			t.Fatalf("RedactString(%q) = %q, Redact = %q", s, got, redacted)
+		}
+	})
+}
+
+// FuzzRedactJSON checks that RedactJSON leaves no PII in any JSON stream
+func FuzzRedactJSON(f *testing.F) {
+	for _, seed := range redactionSeeds {
+		f.Add([]byte(seed))
+	}
+	f.Add([]byte(strings.Join(redactionSeeds, "\n")))
+	detector := NewPIIDetector()
+	redactor := NewRedactor()
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var out bytes.Buffer
+		if err := redactor.RedactJSON(bytes.NewReader(data), &out, detector); err != nil {
+			return
+		}
+		decoder := json.NewDecoder(&out)
+		for decoder.More() {
+			var value interface{}
+			if err := decoder.Decode(&value); err != nil {
+				t.Fatalf("RedactJSON wrote invalid JSON: %v\n%s", err, out.Bytes())
+			}
+			checkRedacted(t, value, detector, redactor.mask())
+		}
+	})
+}
+
+// FuzzRedactData checks that a producer leaves no PII in event data, whatever the
+// annotations
+func FuzzRedactData(f *testing.F) {
+	for _, seed := range redactionSeeds {
+		f.Add([]byte(seed), "email")
+		f.Add([]byte(seed), "client_ip")
+	}
+	p := NewProducer("fuzz", "localhost")
+	f.Fuzz(func(t *testing.T, data []byte, piiField string) {
+		var value map[string]interface{}
+		if err := json.Unmarshal(data, &value); err != nil {
+			return
+		}
+		annotations := map[string]FieldAnnotations{piiField: {PII: true}}
+		redacted := p.redactData("fuzz.event", value, annotations)
+		checkRedactedJSON(t, redacted, p.piiDetector, p.redactor.mask())
+		checkAnnotated(t, redacted, annotations, p.redactor.mask())
+	})
+}
+
+// TestRedactionProperties checks, on random nested data mixing PII with other values,
+// that no PII value survives RedactMap, RedactJSON, or event data redaction once
+// marshaled
+func TestRedactionProperties(t *testing.T) {
+	p := NewProducer("property", "localhost")
+	detector := NewPIIDetector()
+	redactor := NewRedactor()
+	for seed := int64(1); seed <= 200; seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		data := randomData(rng, 4)
+		annotations := map[string]FieldAnnotations{propertyKeys[rng.Intn(len(propertyKeys))]: {PII: true}}
+
+		input, err := json.Marshal(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var streamed bytes.Buffer
+		if err := redactor.RedactJSON(bytes.NewReader(input), &streamed, detector); err != nil {
+			t.Fatalf("seed %d: RedactJSON: %v", seed, err)
+		}
+
+		for name, output := range map[string][]byte{
+			"RedactMap":  mustMarshal(t, redactor.RedactMap(data, detector)),
+			"RedactJSON": streamed.Bytes(),
+			"redactData": mustMarshal(t, p.redactData("property.event", data, annotations)),
+		} {
+			for _, value := range propertyPII {
+				if quoted := mustMarshal(t, value); bytes.Contains(output, quoted) {
+					t.Errorf("seed %d: %s output contains %s:\n%s", seed, name, quoted, output)
+				}
+			}
+			for _, value := range propertyHosts {
+				if quoted := mustMarshal(t, value); bytes.Contains(output, quoted) {
+					t.Errorf("seed %d: %s output contains hostname %s in an IP field:\n%s", seed, name, quoted, output)
+				}
+			}
+			var decoded interface{}
+			if err := json.Unmarshal(output, &decoded); err != nil {
+				t.Fatalf("seed %d: %s output is invalid JSON: %v", seed, name, err)
+			}
+			checkRedacted(t, decoded, detector, redactor.mask())
+		}
+	}
+}
+
+// propertyKeys are the member names of random data: PII names, IP fields, and others
+var propertyKeys = []string{
+	"email", "phone", "user_name", "password", "Credit-Card", "ip_address",
+	"remote_addr", "client_ip", "x-forwarded-for",
+	"id", "status", "count", "items", "meta", "tags", "enabled",
+}
+
+// propertyPII are values matching the PII patterns
+var propertyPII = []string{
+	"alice@example.com", "bob.smith+tag@mail.example.org", "+14155550123",
+	"(415) 555-0123", "415.555.0123", "4111 1111 1111 1111", "4111-1111-1111-1111", "123-45-6789",
+}
+
+// propertyHosts are values of IP fields that are not IP addresses, such as hostnames
+var propertyHosts = []string{"alice-laptop.corp.example", "Alice Smith"}
+
+// propertyOther are values that are not PII
+var propertyOther = []interface{}{
+	"active", "widget", "", "203.0.113.42", "2001:db8::1", "198.51.100.7:443",
+	float64(42), 3.5, true, false, nil,
+}
+
+// randomData returns a random object nested up to depth levels
+func randomData(rng *rand.Rand, depth int) map[string]interface{} {
+	data := make(map[string]interface{})
+	for i, n := 0, rng.Intn(6); i < n; i++ {
+		key := propertyKeys[rng.Intn(len(propertyKeys))]
+		if isIPField(key) && rng.Intn(2) == 0 {
+			data[key] = propertyHosts[rng.Intn(len(propertyHosts))]
+			continue
+		}
+		data[key] = randomValue(rng, depth-1)
+	}
+	return data
+}
+
+// randomValue returns a random value, an object or array while depth remains
+func randomValue(rng *rand.Rand, depth int) interface{} {
+	choice := rng.Intn(9)
+	if depth <= 0 {
+		choice %= 5
+	}
+	switch choice {
+	case 0, 1:
+		return propertyPII[rng.Intn(len(propertyPII))]
+	case 2, 3, 4:
+		return propertyOther[rng.Intn(len(propertyOther))]
+	case 5, 6:
+		return randomData(rng, depth)
+	case 7:
+		values := make([]interface{}, rng.Intn(4))
+		for i := range values {
+			values[i] = randomValue(rng, depth-1)
+		}
+		return values
+	default:
+		values := make([]string, rng.Intn(4))
+		for i := range values {
+			values[i] = fmt.Sprint(randomValue(rng, 0))
+		}
+		return values
+	}
+}
+
+// mustMarshal encodes value as JSON
+func mustMarshal(t *testing.T, value interface{}) []byte {
+	t.Helper()
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// checkRedactedJSON checks value as checkRedacted does, both as returned and after a
+// JSON round trip
+func checkRedactedJSON(t *testing.T, value interface{}, detector *PIIDetector, mask string) {
+	t.Helper()
+	checkRedacted(t, value, detector, mask)
+	var decoded interface{}
+	if err := json.Unmarshal(mustMarshal(t, value), &decoded); err != nil {
+		t.Fatalf("redacted value does not round trip: %v", err)
+	}
+	checkRedacted(t, decoded, detector, mask)
+}
+
+// checkRedacted fails if value holds a string that looks like PII, a member named like
+// PII that is not redacted, or a string in an IP field that is not an IP address
+func checkRedacted(t *testing.T, value interface{}, detector *PIIDetector, mask string) {
+	t.Helper()
+	switch v := value.(type) {
+	case string:
+		if detector.IsPIIValue(v) {
+			t.Fatalf("PII value survived redaction: %q", v)
+		}
+	case []interface{}:
+		for _, item := range v {
+			checkRedacted(t, item, detector, mask)
+		}
+	case map[string]interface{}:
+		for key, item := range v {
+			switch s, isString := item.(string); {
+			case isIPField(key) && isString:
+				if s != "" && s != mask && !isIPList(s) {
+					t.Fatalf("IP field %q holds %q", key, s)
+				}
+			case detector.IsPIIField(key) && !isIPField(key):
+				if item != mask && item != nil {
+					t.Fatalf("PII field %q holds %v", key, item)
+				}
+			}
+			checkRedacted(t, item, detector, mask)
+		}
+	}
+}
+
+// checkAnnotated fails if a member annotated for redaction holds anything but mask or null
+func checkAnnotated(t *testing.T, value interface{}, annotations map[string]FieldAnnotations, mask string) {
+	t.Helper()
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			checkAnnotated(t, item, annotations, mask)
+		}
+	case map[string]interface{}:
+		for key, item := range v {
+			if ShouldRedact(annotations[key]) && item != mask && item != nil {
+				t.Fatalf("field %q annotated for redaction holds %v", key, item)
+			}
+			checkAnnotated(t, item, annotations, mask)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package lifecycle
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// legacyPIIFieldPattern is the field name expression the keyword matcher replaced
+var legacyPIIFieldPattern = regexp.MustCompile(`(?i)(` + strings.Join(piiFieldKeywords, "|") + `)`)
+
+func TestIsPIIFieldUnicode(t *testing.T) {
+	detector := NewPIIDetector()
+	for _, field := range []string{
+		"socialésecurity",
+		"credit€card",
+		"date—of—birth",
+		"api\u212aey", // Kelvin sign
+		"\u017fecret", // Long s
+		"EMAIL",
+		"user_Phone",
+		"card number",
+	} {
+		if !detector.IsPIIField(field) {
+			t.Errorf("IsPIIField(%q) = false, want true", field)
+		}
+	}
+	for _, field := range []string{
+		"status",
+		"social\nsecurity",
+		"date\nof\nbirth",
+		"crédit",
+		"",
+	} {
+		if detector.IsPIIField(field) {
+			t.Errorf("IsPIIField(%q) = true, want false", field)
+		}
+	}
+}
+
+// FuzzIsPIIField checks that the keyword matcher agrees with the expression it replaced
+func FuzzIsPIIField(f *testing.F) {
+	for _, seed := range []string{"email", "socialésecurity", "credit€card", "\u212aey", "\u017fecret", "date\nof\nbirth", "\xffkey", "name\xc3"} {
+		f.Add(seed)
+	}
+	detector := NewPIIDetector()
+	f.Fuzz(func(t *testing.T, field string) {
+		if got, want := detector.IsPIIField(field), legacyPIIFieldPattern.MatchString(field); got != want {
+			t.Fatalf("IsPIIField(%q) = %v, regular expression: %v", field, got, want)
+		}
+	})
+}
+
+// benchmarkFields are field names of a typical event, most of them not PII
+var benchmarkFields = []string{
+	"event_type", "timestamp", "service", "host", "correlation_id", "status_code",
+	"duration_ms", "method", "path", "user_id", "email", "request_size_bytes",
+	"response_size_bytes", "user_agent", "remote_addr", "api_key",
+}
+
+func BenchmarkIsPIIField(b *testing.B) {
+	detector := NewPIIDetector()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, field := range benchmarkFields {
+			detector.IsPIIField(field)
+		}
+	}
+}
+
+// BenchmarkIsPIIFieldRegexp is the cost of the expressions the keyword matcher replaced,
+// for comparison with BenchmarkIsPIIField
+func BenchmarkIsPIIFieldRegexp(b *testing.B) {
+	var patterns []*regexp.Regexp
+	for _, group := range []string{
+		"email|e-mail", "phone|telephone|mobile", "ssn|social.security", "credit.card|card.number",
+		"password|passwd|pwd", "secret|token|key", "address|street|city|zip|postal",
+		"name|firstname|lastname|fullname", "dob|date.of.birth|birthdate", "ip.address|ip_addr",
+	} {
+		patterns = append(patterns, regexp.MustCompile(`(?i)(`+group+`)`))
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, field := range benchmarkFields {
+			for _, pattern := range patterns {
+				if pattern.MatchString(field) {
+					break
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkIsPIIValue(b *testing.B) {
+	detector := NewPIIDetector()
+	values := []string{"GET", "/v1/users/42", "alice@example.com", "200", "Mozilla/5.0", "+14155550123", "203.0.113.42"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, value := range values {
+			detector.IsPIIValue(value)
+		}
+	}
+}
+
+func BenchmarkRedactMap(b *testing.B) {
+	detector := NewPIIDetector()
+	redactor := NewRedactor()
+	data := map[string]interface{}{
+		"method":      "POST",
+		"path":        "/v1/users",
+		"email":       "alice@example.com",
+		"status_code": 201,
+		"user_agent":  "Mozilla/5.0",
+		"remote_addr": "203.0.113.42:51234",
+		"profile":     map[string]interface{}{"plan": "pro", "phone": "+14155550123", "tags": []interface{}{"a", "b"}},
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		redactor.RedactMap(data, detector)
+	}
+}
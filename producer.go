@@ -2,11 +2,12 @@ package lifecycle
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -19,17 +20,61 @@ import (
 // - A single API may span multiple services (e.g., examples.User API in user-service and user-cache-service)
 // - Service: identifies the service instance (e.g., "user-service-pod-123")
 // - API: identifies the API/resource type (e.g., "examples.User", "idp.Account") - optional for service-level events
+//
+// Concurrency: a Producer is safe for concurrent use by multiple goroutines.
+// Its configuration is fixed once NewProducer returns, apart from its sinks (see
+// ReplaceSinks); the color registry, redactor, and OTel instrument caches
+// synchronize internally, and writes to the output are serialized so events
+// are never interleaved.
 type Producer struct {
 	service       string
 	api           string // Optional: API identifier for API-specific events
 	host          string
 	logger        *slog.Logger
 	output        io.Writer
+	encoder       Encoder        // Serializes events written to output
+	outputMu      sync.Mutex     // Serializes writes to output
 	styled        *StyledOutput  // Optional: styled output for beautiful terminal logs
 	colorRegistry *ColorRegistry // Color registry for services, APIs, events, statuses
 	piiDetector   *PIIDetector
 	redactor      *Redactor
 	otel          *OTelIntegration
+	aggregator    *Aggregator // Optional: rolling statistics over emitted events
+	eventHooks    []EventHook // Run on every event before it is redacted and written
+	sampler       *Sampler    // Optional: decides which events are written
+	tailSampler   *TailSampler
+	sinks         atomic.Pointer[sinkSet] // Optional: replace output and styled when set
+	sinksMu       sync.Mutex              // Serializes ReplaceSinks
+	closed        atomic.Bool             // Set by Close
+	emitTimeout   time.Duration           // Optional: upper bound on writing one event
+	writeSlots    chan struct{}           // Held by background writes, see maxPendingWrites
+
+	redactionCache *redactionCache // Optional: field redaction decisions by event type and path
+	logClassifiers []LogClassifier // Applied to intercepted log package output
+	maxStackFrames int             // Limit on frames in structured stacks
+	logLevel       slog.LevelVar   // Default minimum level for log.message events (zero value: Info)
+	configSnapshot func() map[string]interface{}
+	profileStore   ProfileStore      // Where captured profiles are written
+	profileTrigger *profileTrigger   // Optional: profile when latency anomalies fire
+	slowQueries    *slowQueryTracker // Optional: reports queries over a latency threshold
+	idGenerator    IDGenerator       // Default: RandomIDs
+	fullIPs        bool              // Record client addresses without anonymizing them
+	userAgents     UserAgentHandling // What to record of raw User-Agent strings
+	geoResolver    GeoResolver       // Optional: locates request clients
+	routes         []routeTemplate   // Route templates of request paths
+	eventIDs       bool              // Give every event an ID
+	retention      []retentionRule   // Retention classes by event type
+	chain          *eventChain       // Optional: signs events and chains them by hash
+	eventLevels    bool              // Record each event's level
+	tracker        *Tracker          // Optional: assembles events into traces by correlation
+	sentry         *sentryReporter   // Optional: reports error events to Sentry
+
+	globalMetadata map[string]interface{} // Added to every event's metadata
+
+	debugEvents atomic.Pointer[eventRing] // Recent events for DebugHandler, once enabled
+
+	anomalyMu        sync.Mutex // Serializes anomaly detector calls
+	anomalyDetectors []AnomalyDetector
 }
 
 // ProducerOption configures the Producer
@@ -95,6 +140,30 @@ func WithColorRegistry(registry *ColorRegistry) ProducerOption {
 	}
 }
 
+// WithEmitTimeout bounds how long emitting waits for an event to be written, so a wedged
+// sink cannot hang request handlers; the write is abandoned, not interrupted
+// Writes are also bounded by the emitting context when it has a deadline; emitting with
+// one that can only be cancelled writes on the emitting goroutine, unbounded. At most
+// maxPendingWrites abandoned writes are left running; once a wedged sink holds them all,
+// emitting waits its timeout for one of them to finish
+func WithEmitTimeout(timeout time.Duration) ProducerOption {
+	return func(p *Producer) {
+		p.emitTimeout = timeout
+	}
+}
+
+// EventHook inspects or enriches an event before it is redacted and written
+// Hooks can add context to any event type through SetMetadata
+type EventHook func(ctx context.Context, event Event)
+
+// WithEventHook adds a hook that runs on every emitted event, in the order hooks were added
+// Hooks run synchronously on the emitting goroutine
+func WithEventHook(hook EventHook) ProducerOption {
+	return func(p *Producer) {
+		p.eventHooks = append(p.eventHooks, hook)
+	}
+}
+
 // NewProducer creates a new lifecycle event producer
 // This replaces standard loggers - developers should use this instead of log.Printf, etc.
 // These are OBSERVABILITY events for engineers, NOT domain events
@@ -109,15 +178,22 @@ func NewProducer(service, host string, opts ...ProducerOption) *Producer {
 		host:          host,
 		logger:        slog.Default(),
 		output:        os.Stdout,
+		encoder:       NewJSONEncoder(),
 		colorRegistry: NewColorRegistry(), // Default color registry
 		piiDetector:   NewPIIDetector(),
 		redactor:      NewRedactor(),
 		otel:          NewOTelIntegration(service),
+		writeSlots:    make(chan struct{}, maxPendingWrites),
+
+		logClassifiers: DefaultLogClassifiers(),
+		maxStackFrames: DefaultMaxStackFrames,
 	}
 
 	for _, opt := range opts {
 		opt(p)
 	}
+	p.dropReservedGlobalMetadata()
+	p.startSentry()
 
 	// Register service color if registry is available
 	if p.colorRegistry != nil {
@@ -138,6 +214,7 @@ func (p *Producer) createBaseEvent(eventType, correlationID string, metadata map
 
 	base := &BaseEvent{
 		EventType:     eventType,
+		SchemaVersion: CurrentSchemaVersion,
 		Timestamp:     time.Now(),
 		Service:       p.service,
 		API:           apiID,
@@ -145,85 +222,254 @@ func (p *Producer) createBaseEvent(eventType, correlationID string, metadata map
 		CorrelationID: correlationID,
 		Metadata:      metadata,
 	}
+	if p.otel != nil {
+		base.ResourceAttributes = p.otel.resourceAttrs
+	}
+	if p.eventIDs {
+		base.EventID = p.newID(IDEvent)
+	}
+	base.Retention = p.retentionClass(eventType)
 
 	return base
 }
 
-// redactData redacts PII from data based on schema annotations from the API generator
+// redactData redacts PII from an eventType event's data based on schema annotations from the API generator
 // schemaAnnotations: Map of field name -> FieldAnnotations from the API schema system
-// Fields are redacted if they have PII=true OR Redactable=true OR Encrypted=true
-func (p *Producer) redactData(data map[string]interface{}, schemaAnnotations map[string]FieldAnnotations) map[string]interface{} {
+// Fields are redacted if they have PII=true OR Redactable=true OR Encrypted=true, or if
+// their names indicate PII as in RedactMap
+func (p *Producer) redactData(eventType string, data map[string]interface{}, schemaAnnotations map[string]FieldAnnotations) map[string]interface{} {
+	return p.redactDataAt(p.piiDetector.forEvent(eventType, p.redactionCache), data, schemaAnnotations, "")
+}
+
+// redactDataAt redacts PII from the data at path
+func (p *Producer) redactDataAt(detector *PIIDetector, data map[string]interface{}, schemaAnnotations map[string]FieldAnnotations, path string) map[string]interface{} {
 	if data == nil {
 		return nil
 	}
 
 	redacted := make(map[string]interface{})
 	for key, value := range data {
-		// Check if field has PII annotations from schema
-		annotations, hasAnnotations := schemaAnnotations[key]
-
-		// Redact if field is marked as PII, redactable, or encrypted in schema
-		shouldRedact := false
-		if hasAnnotations {
-			shouldRedact = annotations.PII || annotations.Redactable || annotations.Encrypted || annotations.Sensitive
+		// Numbers in noise or bucket fields are reported approximately
+		annotations := schemaAnnotations[key]
+		if annotations.approximates() {
+			if approximate, ok := approximateNumber(value, annotations); ok {
+				redacted[key] = approximate
+				continue
+			}
 		}
 
-		// Also check if value itself looks like PII (fallback if no schema annotations)
-		if !shouldRedact {
-			shouldRedact = p.piiDetector.IsPIIField(key) || p.piiDetector.IsPIIValue(value)
+		// Fields annotated for redaction are redacted whatever their values look like
+		if ShouldRedact(annotations) {
+			redacted[key] = p.maskData(value)
+			continue
 		}
 
-		if shouldRedact {
-			// Redact PII fields
-			redacted[key] = p.redactor.Redact(value)
-		} else {
-			// Recursively check nested structures
-			if nestedMap, ok := value.(map[string]interface{}); ok {
-				redacted[key] = p.redactData(nestedMap, schemaAnnotations)
-			} else if nestedSlice, ok := value.([]interface{}); ok {
-				// Handle arrays/slices
-				redactedSlice := make([]interface{}, len(nestedSlice))
-				for i, item := range nestedSlice {
-					if itemMap, ok := item.(map[string]interface{}); ok {
-						redactedSlice[i] = p.redactData(itemMap, schemaAnnotations)
-					} else {
-						// Check if item itself is PII
-						if p.piiDetector.IsPIIValue(item) {
-							redactedSlice[i] = p.redactor.Redact(item)
-						} else {
-							redactedSlice[i] = item
-						}
-					}
-				}
-				redacted[key] = redactedSlice
-			} else {
-				redacted[key] = value
+		// Client IP addresses are anonymized rather than redacted; values of IP fields
+		// that are not IP addresses are redacted
+		if (annotations.AnonymizeIP || isIPField(key)) && !detector.IsPIIValue(value) {
+			if anonymized, ok := redactIPValue(value, p.redactor.mask()); ok {
+				redacted[key] = anonymized
+				continue
 			}
 		}
+
+		// Redact if the field's name indicates PII or its value looks like PII; other
+		// values are checked recursively
+		if detector.isPIIFieldAt(path, key, schemaAnnotations) || detector.IsPIIValue(value) {
+			redacted[key] = p.maskData(value)
+			continue
+		}
+		redacted[key] = p.redactDataValue(detector, value, schemaAnnotations, fieldPath(path, key))
 	}
 
 	return redacted
 }
 
+// redactDataValue redacts a value nested in the data at path: maps and slices
+// recursively, including the string-typed forms of headers and query parameters, and
+// strings that look like PII
+func (p *Producer) redactDataValue(detector *PIIDetector, value interface{}, schemaAnnotations map[string]FieldAnnotations, path string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return p.redactDataAt(detector, v, schemaAnnotations, path)
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = p.redactDataValue(detector, item, schemaAnnotations, path)
+		}
+		return redacted
+	case map[string]string:
+		nested := make(map[string]interface{}, len(v))
+		for key, s := range v {
+			nested[key] = s
+		}
+		return p.redactDataAt(detector, nested, schemaAnnotations, path)
+	case map[string][]string:
+		nested := make(map[string]interface{}, len(v))
+		for key, values := range v {
+			nested[key] = stringsToInterfaces(values)
+		}
+		return p.redactDataAt(detector, nested, schemaAnnotations, path)
+	case []string:
+		return p.redactDataValue(detector, stringsToInterfaces(v), schemaAnnotations, path)
+	}
+	if detector.IsPIIValue(value) {
+		return p.redactor.mask()
+	}
+	return value
+}
+
+// maskData returns the redaction string in place of a redacted value, keeping nil values
+func (p *Producer) maskData(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	return p.redactor.mask()
+}
+
 // emitEvent writes the event to the configured output as JSON
 // Also creates OpenTelemetry spans and records metrics
 func (p *Producer) emitEvent(ctx context.Context, event Event, duration time.Duration) error {
-	// Redact PII before serialization
-	if eventWithData, ok := event.(EventWithData); ok {
-		eventWithData.RedactPII(p.piiDetector, p.redactor)
+	if p.closed.Load() {
+		return ErrProducerClosed
+	}
+
+	p.mergeMetadata(ctx, event)
+	if p.eventLevels {
+		if base := eventBase(event); base != nil {
+			base.Level = EventLevel(event).String()
+		}
 	}
 
+	p.prepareEvent(ctx, event)
+
 	// Create OpenTelemetry span
 	if p.otel != nil {
 		attrs := EventAttributes(event)
-		spanCtx, span := p.otel.StartSpan(ctx, event.GetEventType(), attrs...)
-		defer span.End()
+		spanCtx, endSpan := p.otel.eventSpan(ctx, event, attrs)
+		defer endSpan()
 
 		// Record metrics
 		p.otel.RecordMetric(spanCtx, event.GetEventType(), duration, attrs...)
 	}
 
-	// Emit output (styled or JSON)
+	if p.aggregator != nil {
+		p.aggregator.Observe(event, duration)
+	}
+	if p.tracker != nil {
+		p.tracker.Observe(event, duration)
+	}
+	if ring := p.debugEvents.Load(); ring != nil {
+		ring.add(event)
+	}
+	if p.sentry != nil {
+		p.sentry.observe(event)
+	}
+
+	if p.sampler != nil && !p.sampler.Sample(ctx, event) {
+		return nil
+	}
+	if p.tailSampler != nil && p.tailSampler.hold(event, duration) {
+		return nil
+	}
+
+	return p.writeEventContext(ctx, event)
+}
+
+// prepareEvent runs the event hooks, then redacts PII before serialization
+func (p *Producer) prepareEvent(ctx context.Context, event Event) {
+	for _, hook := range p.eventHooks {
+		hook(ctx, event)
+	}
+
+	detector := p.piiDetector.forEvent(event.GetEventType(), p.redactionCache)
+	if eventWithData, ok := event.(EventWithData); ok {
+		eventWithData.RedactPII(detector, p.redactor)
+	}
+	p.redactMetadata(detector, event)
+}
+
+// minEmitGrace is the least time a write is given once its context is done, so events
+// describing the cancellation itself (e.g., request.errored) are still written
+const minEmitGrace = 50 * time.Millisecond
+
+// maxPendingWrites bounds the writes running in the background for emitters that can give
+// up on them (see writeEventContext), so writes abandoned on a wedged sink cannot pile up
+const maxPendingWrites = 64
+
+// writeEventContext writes the event, giving up once ctx is done (after minEmitGrace) or
+// the emit timeout elapses
+// Unless ctx has a deadline or an emit timeout is set, the event is written directly, even
+// if ctx can be cancelled. Otherwise it is written in the background once one of the
+// producer's write slots is free, and the slot is held until the write returns, even
+// when the emitter has given up on it
+func (p *Producer) writeEventContext(ctx context.Context, event Event) error {
+	if _, ok := ctx.Deadline(); !ok && p.emitTimeout <= 0 {
+		return p.writeEvent(event)
+	}
+
+	var timeout <-chan time.Time
+	if p.emitTimeout > 0 {
+		timer := time.NewTimer(p.emitTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	slots := p.writeSlots // Set to nil once a slot is taken
+	ctxDone := ctx.Done() // Set to nil once the grace period starts
+	var done chan error
+	var grace <-chan time.Time
+	for {
+		select {
+		case slots <- struct{}{}:
+			slots = nil
+			done = make(chan error, 1)
+			go func(done chan<- error) {
+				defer func() { <-p.writeSlots }()
+				done <- p.writeEvent(event)
+			}(done)
+		case err := <-done:
+			return err
+		case <-timeout:
+			return fmt.Errorf("failed to write event: %w", context.DeadlineExceeded)
+		case <-ctxDone:
+			ctxDone = nil
+			timer := time.NewTimer(minEmitGrace)
+			defer timer.Stop()
+			grace = timer.C
+		case <-grace:
+			return fmt.Errorf("failed to write event: %w", ctx.Err())
+		}
+	}
+}
+
+// writeEvent writes the event to the configured sinks, the styled output or, by default,
+// as encoded JSON
+func (p *Producer) writeEvent(event Event) error {
+	if p.chain != nil {
+		p.chain.mu.Lock()
+		defer p.chain.mu.Unlock()
+		if err := p.chain.sign(event); err != nil {
+			return err
+		}
+	}
+
+	// Writes register with the sinks they use, so ReplaceSinks waits for them
+	sinks, err := p.acquireSinks()
+	if err != nil {
+		return err
+	}
+	if sinks != nil {
+		defer sinks.release()
+		if len(sinks.entries) > 0 {
+			return p.writeSinks(sinks.entries, event, false)
+		}
+	}
+	return p.writeOutput(event)
+}
+
+// writeOutput writes the event to the styled output or, by default, as encoded JSON
+func (p *Producer) writeOutput(event Event) error {
 	if p.styled != nil {
 		// Use styled output (beautiful terminal formatting)
 		// StyledOutput handles JSON output separately if configured
@@ -232,12 +478,15 @@ func (p *Producer) emitEvent(ctx context.Context, event Event, duration time.Dur
 		}
 	} else {
 		// Default: emit structured JSON log
-		jsonData, err := json.Marshal(event)
+		jsonData, err := p.encoder.Encode(event)
 		if err != nil {
 			return fmt.Errorf("failed to marshal event: %w", err)
 		}
 
-		if _, err := fmt.Fprintln(p.output, string(jsonData)); err != nil {
+		p.outputMu.Lock()
+		_, err = fmt.Fprintln(p.output, string(jsonData))
+		p.outputMu.Unlock()
+		if err != nil {
 			return fmt.Errorf("failed to write event: %w", err)
 		}
 	}
@@ -277,11 +526,21 @@ func (p *Producer) EmitServiceShutdown(ctx context.Context, reason string, exitC
 }
 
 // EmitServiceCrashed emits a service.crashed event
+// A Go traceback in stackTrace is also recorded as structured frames; if stackTrace is
+// empty, the caller's stack is captured instead
 func (p *Producer) EmitServiceCrashed(ctx context.Context, reason, stackTrace string, exitCode int32) error {
+	var stack []StackFrame
+	if stackTrace != "" {
+		stack = limitFrames(ParseStack(stackTrace), p.maxStackFrames)
+	} else {
+		stack = captureStack(1, p.maxStackFrames)
+	}
+
 	event := &ServiceCrashedEvent{
 		Base:       p.createBaseEvent("service.crashed", "", nil),
 		Reason:     reason,
 		StackTrace: stackTrace,
+		Stack:      stack,
 		ExitCode:   exitCode,
 	}
 	return p.emitEvent(ctx, event, 0)
@@ -291,19 +550,25 @@ func (p *Producer) EmitServiceCrashed(ctx context.Context, reason, stackTrace st
 
 // EmitRequestReceived emits an api.request.received event
 // api: Optional API identifier (e.g., "examples.User") - if not provided, uses producer-level API
+//
+// Deprecated: the trailing api parameter is easy to misuse; use EmitRequestReceivedWithOptions, which
+// sets the API with WithEventAPI
 func (p *Producer) EmitRequestReceived(ctx context.Context, correlationID, method, path string, metadata map[string]interface{}, api ...string) error {
 	event := &RequestReceivedEvent{
-		Base:       p.createBaseEvent("api.request.received", correlationID, metadata, api...),
-		Method:     method,
-		Path:       path,
-		UserAgent:  extractUserAgent(ctx),
-		RemoteAddr: extractRemoteAddr(ctx),
+		Base:   p.createBaseEvent("api.request.received", correlationID, metadata, api...),
+		Method: method,
+		Path:   path,
+		Route:  p.route(ctx, path, ""),
 	}
+	p.describeClient(ctx, event)
 	return p.emitEvent(ctx, event, 0)
 }
 
 // EmitRequestHandled emits an api.request.handled event
 // api: Optional API identifier (e.g., "examples.User") - if not provided, uses producer-level API or resource type
+//
+// Deprecated: the trailing api parameter is easy to misuse; use EmitRequestHandledWithOptions, which
+// sets the API with WithEventAPI
 func (p *Producer) EmitRequestHandled(ctx context.Context, correlationID string, actor *Actor, resource *Resource,
 	statusCode int32, durationMs int64, responseSizeBytes int64, api ...string) error {
 	// If API not provided, try to infer from resource type
@@ -328,6 +593,9 @@ func (p *Producer) EmitRequestHandled(ctx context.Context, correlationID string,
 
 // EmitRequestErrored emits an api.request.errored event
 // api: Optional API identifier (e.g., "examples.User") - if not provided, uses producer-level API
+//
+// Deprecated: the trailing api parameter is easy to misuse; use EmitRequestErroredWithOptions, which
+// sets the API with WithEventAPI
 func (p *Producer) EmitRequestErrored(ctx context.Context, correlationID, errorMessage, errorCode string,
 	statusCode int32, durationMs int64, api ...string) error {
 	event := &RequestErroredEvent{
@@ -354,82 +622,112 @@ func (p *Producer) EmitRequestRetried(ctx context.Context, correlationID string,
 }
 
 // Database Tracing Events
+// The optional EmitOptions set the API identifier (e.g., the ORM model) and metadata
 
 // EmitQueryStarted emits a db.query.started event
-func (p *Producer) EmitQueryStarted(ctx context.Context, queryID, query string, params []interface{}) error {
+func (p *Producer) EmitQueryStarted(ctx context.Context, queryID, query string, params []interface{}, opts ...EmitOption) error {
+	if p.slowQueries != nil {
+		p.slowQueries.start(queryID, query, params)
+	}
+
 	// Redact PII from query parameters
 	redactedParams := p.redactor.RedactParams(params)
 
+	b := builderBase{producer: p}
+	b.applyEmitOptions(opts)
 	event := &QueryStartedEvent{
-		Base:    p.createBaseEvent("db.query.started", extractCorrelationID(ctx), nil),
+		Base:    b.createBase("db.query.started", nil),
 		QueryID: queryID,
 		Query:   query,
 		Params:  redactedParams,
 	}
-	return p.emitEvent(ctx, event, 0)
+	return b.emit(ctx, event.Base, event, 0)
 }
 
 // EmitQueryCompleted emits a db.query.completed event
-func (p *Producer) EmitQueryCompleted(ctx context.Context, queryID string, durationMs int64, rowsAffected int64) error {
+func (p *Producer) EmitQueryCompleted(ctx context.Context, queryID string, durationMs int64, rowsAffected int64, opts ...EmitOption) error {
+	b := builderBase{producer: p}
+	b.applyEmitOptions(opts)
 	event := &QueryCompletedEvent{
-		Base:         p.createBaseEvent("db.query.completed", extractCorrelationID(ctx), nil),
+		Base:         b.createBase("db.query.completed", nil),
 		QueryID:      queryID,
 		DurationMs:   durationMs,
 		RowsAffected: rowsAffected,
 	}
-	return p.emitEvent(ctx, event, time.Duration(durationMs)*time.Millisecond)
+	if err := b.emit(ctx, event.Base, event, time.Duration(durationMs)*time.Millisecond); err != nil {
+		return err
+	}
+	if p.slowQueries != nil {
+		return p.emitQuerySlow(ctx, b, queryID, time.Duration(durationMs)*time.Millisecond)
+	}
+	return nil
 }
 
 // EmitQueryErrored emits a db.query.errored event
-func (p *Producer) EmitQueryErrored(ctx context.Context, queryID, errorMessage, errorCode string, durationMs int64) error {
+func (p *Producer) EmitQueryErrored(ctx context.Context, queryID, errorMessage, errorCode string, durationMs int64, opts ...EmitOption) error {
+	if p.slowQueries != nil {
+		p.slowQueries.finish(queryID)
+	}
+
+	b := builderBase{producer: p}
+	b.applyEmitOptions(opts)
 	event := &QueryErroredEvent{
-		Base:         p.createBaseEvent("db.query.errored", extractCorrelationID(ctx), nil),
+		Base:         b.createBase("db.query.errored", nil),
 		QueryID:      queryID,
 		ErrorMessage: errorMessage,
 		ErrorCode:    errorCode,
 		DurationMs:   durationMs,
 	}
-	return p.emitEvent(ctx, event, time.Duration(durationMs)*time.Millisecond)
+	return b.emit(ctx, event.Base, event, time.Duration(durationMs)*time.Millisecond)
 }
 
 // EmitTransactionStarted emits a db.transaction.started event
-func (p *Producer) EmitTransactionStarted(ctx context.Context, transactionID string) error {
+func (p *Producer) EmitTransactionStarted(ctx context.Context, transactionID string, opts ...EmitOption) error {
+	b := builderBase{producer: p}
+	b.applyEmitOptions(opts)
 	event := &TransactionStartedEvent{
-		Base:          p.createBaseEvent("db.transaction.started", extractCorrelationID(ctx), nil),
+		Base:          b.createBase("db.transaction.started", nil),
 		TransactionID: transactionID,
 	}
-	return p.emitEvent(ctx, event, 0)
+	return b.emit(ctx, event.Base, event, 0)
 }
 
 // EmitTransactionCommitted emits a db.transaction.committed event
-func (p *Producer) EmitTransactionCommitted(ctx context.Context, transactionID string, durationMs int64) error {
+func (p *Producer) EmitTransactionCommitted(ctx context.Context, transactionID string, durationMs int64, opts ...EmitOption) error {
+	b := builderBase{producer: p}
+	b.applyEmitOptions(opts)
 	event := &TransactionCommittedEvent{
-		Base:          p.createBaseEvent("db.transaction.committed", extractCorrelationID(ctx), nil),
+		Base:          b.createBase("db.transaction.committed", nil),
 		TransactionID: transactionID,
 		DurationMs:    durationMs,
 	}
-	return p.emitEvent(ctx, event, time.Duration(durationMs)*time.Millisecond)
+	return b.emit(ctx, event.Base, event, time.Duration(durationMs)*time.Millisecond)
 }
 
 // EmitTransactionRolledBack emits a db.transaction.rolled_back event
-func (p *Producer) EmitTransactionRolledBack(ctx context.Context, transactionID, reason string, durationMs int64) error {
+func (p *Producer) EmitTransactionRolledBack(ctx context.Context, transactionID, reason string, durationMs int64, opts ...EmitOption) error {
+	b := builderBase{producer: p}
+	b.applyEmitOptions(opts)
 	event := &TransactionRolledBackEvent{
-		Base:          p.createBaseEvent("db.transaction.rolled_back", extractCorrelationID(ctx), nil),
+		Base:          b.createBase("db.transaction.rolled_back", nil),
 		TransactionID: transactionID,
 		Reason:        reason,
 		DurationMs:    durationMs,
 	}
-	return p.emitEvent(ctx, event, time.Duration(durationMs)*time.Millisecond)
+	return b.emit(ctx, event.Base, event, time.Duration(durationMs)*time.Millisecond)
 }
 
 // Resource Events
 
 // EmitResourceCreated emits a resource.created event
 // api: Optional API identifier (e.g., "examples.User") - if not provided, uses producer-level API or resource type
+//
+// Deprecated: the trailing api parameter is easy to misuse; use EmitResourceCreatedWithOptions, which
+// sets the API with WithEventAPI
 func (p *Producer) EmitResourceCreated(ctx context.Context, correlationID string, actor *Actor,
 	resource *Resource, resourceData map[string]interface{}, schemaAnnotations map[string]FieldAnnotations, api ...string) error {
 	// Redact PII from resource data
-	redactedData := p.redactData(resourceData, schemaAnnotations)
+	redactedData := p.redactData("resource.created", resourceData, schemaAnnotations)
 
 	// If API not provided, try to infer from resource type
 	apiID := ""
@@ -450,11 +748,14 @@ func (p *Producer) EmitResourceCreated(ctx context.Context, correlationID string
 
 // EmitResourceUpdated emits a resource.updated event
 // api: Optional API identifier (e.g., "examples.User") - if not provided, uses producer-level API or resource type
+//
+// Deprecated: the trailing api parameter is easy to misuse; use EmitResourceUpdatedWithOptions, which
+// sets the API with WithEventAPI
 func (p *Producer) EmitResourceUpdated(ctx context.Context, correlationID string, actor *Actor,
 	resource *Resource, previousData, newData map[string]interface{}, updatedFields []string, schemaAnnotations map[string]FieldAnnotations, api ...string) error {
 	// Redact PII from both previous and new data
-	redactedPrevious := p.redactData(previousData, schemaAnnotations)
-	redactedNew := p.redactData(newData, schemaAnnotations)
+	redactedPrevious := p.redactData("resource.updated", previousData, schemaAnnotations)
+	redactedNew := p.redactData("resource.updated", newData, schemaAnnotations)
 
 	// If API not provided, try to infer from resource type
 	apiID := ""
@@ -477,10 +778,13 @@ func (p *Producer) EmitResourceUpdated(ctx context.Context, correlationID string
 
 // EmitResourceDeleted emits a resource.deleted event
 // api: Optional API identifier (e.g., "examples.User") - if not provided, uses producer-level API or resource type
+//
+// Deprecated: the trailing api parameter is easy to misuse; use EmitResourceDeletedWithOptions, which
+// sets the API with WithEventAPI
 func (p *Producer) EmitResourceDeleted(ctx context.Context, correlationID string, actor *Actor,
 	resource *Resource, softDelete bool, finalData map[string]interface{}, schemaAnnotations map[string]FieldAnnotations, api ...string) error {
 	// Redact PII from final data
-	redactedData := p.redactData(finalData, schemaAnnotations)
+	redactedData := p.redactData("resource.deleted", finalData, schemaAnnotations)
 
 	// If API not provided, try to infer from resource type
 	apiID := ""
@@ -500,14 +804,103 @@ func (p *Producer) EmitResourceDeleted(ctx context.Context, correlationID string
 	return p.emitEvent(ctx, event, 0)
 }
 
+// Error Events
+
+// EmitErrorOccurred emits an error.occurred event
+// kind categorizes the error (e.g., "connection_refused", "panic"); source is optional
+// A Go traceback in stackTrace is also recorded as structured frames
+func (p *Producer) EmitErrorOccurred(ctx context.Context, kind, errorMessage, stackTrace string, source *SourceLocation) error {
+	event := &ErrorOccurredEvent{
+		Base:         p.createBaseEvent("error.occurred", extractCorrelationID(ctx), nil),
+		Status:       StatusError,
+		Kind:         kind,
+		ErrorMessage: errorMessage,
+		StackTrace:   stackTrace,
+		Stack:        limitFrames(ParseStack(stackTrace), p.maxStackFrames),
+		Source:       source,
+	}
+	return p.emitEvent(ctx, event, 0)
+}
+
+// Recover recovers from a panic and emits an error.occurred event with kind "panic"
+// and the stack of the code that panicked. It must be deferred directly:
+//
+//	defer producer.Recover(ctx)
+//
+// The panic is not re-raised, so use it at goroutine boundaries where a crash is unwanted
+func (p *Producer) Recover(ctx context.Context) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	// Capture extra frames to allow for the runtime's panic frames, which are trimmed
+	stack := limitFrames(trimPanicFrames(captureStack(1, p.maxStackFrames+8)), p.maxStackFrames)
+	event := &ErrorOccurredEvent{
+		Base:         p.createBaseEvent("error.occurred", extractCorrelationID(ctx), nil),
+		Status:       StatusError,
+		Kind:         "panic",
+		ErrorMessage: fmt.Sprint(r),
+		StackTrace:   FormatStack(stack),
+		Stack:        stack,
+	}
+	if len(stack) > 0 {
+		event.Source = &SourceLocation{Function: stack[0].Function, File: stack[0].File, Line: stack[0].Line}
+	}
+	_ = p.emitEvent(ctx, event, 0)
+}
+
+// Log Events
+
+// SetLogLevel sets the minimum level of log.message events emitted by handlers and
+// wrapped loggers that were not given their own level with WithMinLevel
+// It may be called at any time, e.g., to enable debug logging in a running service
+func (p *Producer) SetLogLevel(level slog.Level) {
+	p.logLevel.Set(level)
+}
+
+// LogLevel returns the producer's current log level
+func (p *Producer) LogLevel() slog.Level {
+	return p.logLevel.Level()
+}
+
+// EmitLogMessage emits a log.message event
+// This is a fallback for intercepted log output - prefer specific event types
+func (p *Producer) EmitLogMessage(ctx context.Context, level slog.Level, message string,
+	attrs map[string]interface{}, source *SourceLocation) error {
+	return p.emitLogMessage(ctx, time.Now(), level, message, attrs, source)
+}
+
+// emitLogMessage emits a log.message event with an explicit timestamp
+// The correlation ID, actor, and tenant are taken from ctx
+// A zero timestamp is preserved, matching slog's convention for records without a time
+func (p *Producer) emitLogMessage(ctx context.Context, timestamp time.Time, level slog.Level, message string,
+	attrs map[string]interface{}, source *SourceLocation) error {
+	event := &LogMessageEvent{
+		Base:    p.createBaseEvent("log.message", extractCorrelationID(ctx), nil),
+		Level:   level.String(),
+		Message: message,
+		Actor:   ActorFromContext(ctx),
+		Tenant:  TenantFromContext(ctx),
+		Attrs:   attrs,
+		Source:  source,
+	}
+	event.Base.Timestamp = timestamp
+
+	err := p.emitEvent(ctx, event, 0)
+
+	// Attrs have been redacted by emitEvent, so they are safe to attach to the span
+	if p.otel != nil {
+		p.otel.AddLogSpanEvent(ctx, level, event)
+	}
+	return err
+}
+
 // Helper functions
 
 // extractCorrelationID extracts correlation ID from context
 func extractCorrelationID(ctx context.Context) string {
-	if id, ok := ctx.Value("correlation_id").(string); ok {
-		return id
-	}
-	return ""
+	return CorrelationIDFromContext(ctx)
 }
 
 // extractUserAgent extracts user agent from context
@@ -0,0 +1,144 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestProducerConcurrentEmit calls every Emit method from many goroutines at once, so
+// "go test -race" reports any state the producer shares between emissions unguarded
+func TestProducerConcurrentEmit(t *testing.T) {
+	p := NewProducer("race", "localhost",
+		WithOutput(io.Discard),
+		WithGlobalMetadata(map[string]interface{}{"region": "test"}),
+		WithEventSigning(NewHMACSigner([]byte("race"))),
+		WithDebugEvents(16),
+	)
+	defer p.Close(context.Background())
+
+	methods := emitMethods(t, p)
+	if len(methods) == 0 {
+		t.Fatal("no Emit methods found")
+	}
+
+	const goroutines = 8
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			ctx := ContextWithCorrelationID(context.Background(), "race")
+			for i := range methods {
+				// Start each goroutine at a different method, so different emissions overlap
+				method := methods[(i+g)%len(methods)]
+				func() {
+					defer func() {
+						if r := recover(); r != nil {
+							t.Errorf("%s panicked: %v", method.name, r)
+						}
+					}()
+					method.fn.Call(emitArgs(ctx, p, method.fn.Type()))
+				}()
+			}
+		}(g)
+	}
+
+	// Read the recent events while they are emitted
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		handler := p.DebugHandler()
+		for i := 0; i < 100; i++ {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/?format=json", nil))
+		}
+	}()
+	wg.Wait()
+}
+
+// emitMethod is an Emit method of a producer
+type emitMethod struct {
+	name string
+	fn   reflect.Value
+}
+
+// emitMethods returns the producer's Emit methods taking a context first
+func emitMethods(t *testing.T, p *Producer) []emitMethod {
+	t.Helper()
+	contextType := reflect.TypeOf((*context.Context)(nil)).Elem()
+	v := reflect.ValueOf(p)
+	var methods []emitMethod
+	for i := 0; i < v.NumMethod(); i++ {
+		name := v.Type().Method(i).Name
+		fn := v.Method(i)
+		if !strings.HasPrefix(name, "Emit") || fn.Type().NumIn() == 0 || fn.Type().In(0) != contextType {
+			continue
+		}
+		methods = append(methods, emitMethod{name: name, fn: fn})
+	}
+	return methods
+}
+
+// emitArgs returns plausible arguments for an Emit method, leaving out variadic ones
+func emitArgs(ctx context.Context, p *Producer, fn reflect.Type) []reflect.Value {
+	n := fn.NumIn()
+	if fn.IsVariadic() {
+		n--
+	}
+	args := make([]reflect.Value, n)
+	args[0] = reflect.ValueOf(ctx)
+	for i := 1; i < n; i++ {
+		if fn.In(i) == reflect.TypeOf((*Event)(nil)).Elem() {
+			event, _ := p.NewBaseEvent(ctx, "race.custom")
+			args[i] = reflect.ValueOf(event)
+			continue
+		}
+		args[i] = emitArg(fn.In(i))
+	}
+	return args
+}
+
+// emitArg returns a non-zero value of type typ where one is easy to make
+func emitArg(typ reflect.Type) reflect.Value {
+	switch typ {
+	case reflect.TypeOf(time.Duration(0)):
+		return reflect.ValueOf(25 * time.Millisecond).Convert(typ)
+	case reflect.TypeOf(time.Time{}):
+		return reflect.ValueOf(time.Now())
+	case reflect.TypeOf((*error)(nil)).Elem():
+		return reflect.ValueOf(errors.New("race")).Convert(typ)
+	}
+
+	value := reflect.New(typ).Elem()
+	switch typ.Kind() {
+	case reflect.String:
+		value.SetString("race")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value.SetInt(1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value.SetUint(1)
+	case reflect.Float32, reflect.Float64:
+		value.SetFloat(1)
+	case reflect.Bool:
+		value.SetBool(true)
+	case reflect.Map:
+		value.Set(reflect.MakeMap(typ))
+		if typ.Key().Kind() == reflect.String && typ.Elem().Kind() == reflect.Interface {
+			value.SetMapIndex(reflect.ValueOf("email"), reflect.ValueOf("race@example.com"))
+		}
+	case reflect.Slice:
+		value.Set(reflect.MakeSlice(typ, 0, 0))
+	case reflect.Pointer:
+		if typ.Elem().Kind() == reflect.Struct {
+			value.Set(reflect.New(typ.Elem()))
+		}
+	}
+	return value
+}
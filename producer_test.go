@@ -0,0 +1,36 @@
+package lifecycle
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// cancelingSink cancels the emitting context, then takes longer than minEmitGrace
+type cancelingSink struct {
+	cancel context.CancelFunc
+	writes int
+}
+
+func (s *cancelingSink) WriteEvent(Event) error {
+	s.cancel()
+	time.Sleep(2 * minEmitGrace)
+	s.writes++
+	return nil
+}
+
+// TestEmitInline checks that an event emitted with a context that has no deadline is
+// written before Emit returns, even when the context is cancelled during the write
+func TestEmitInline(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sink := &cancelingSink{cancel: cancel}
+	p := NewProducer("inline", "localhost", WithSink(sink))
+
+	event, _ := p.NewBaseEvent(ctx, "inline.write")
+	if err := p.Emit(ctx, event); err != nil {
+		t.Errorf("Emit = %v, want nil", err)
+	}
+	if sink.writes != 1 {
+		t.Errorf("%d writes when Emit returned, want 1", sink.writes)
+	}
+}
@@ -0,0 +1,189 @@
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"sync"
+	"time"
+)
+
+// ProfileKind identifies a runtime profile
+type ProfileKind string
+
+const (
+	ProfileCPU       ProfileKind = "cpu"
+	ProfileHeap      ProfileKind = "heap"
+	ProfileAllocs    ProfileKind = "allocs"
+	ProfileGoroutine ProfileKind = "goroutine"
+	ProfileMutex     ProfileKind = "mutex"
+	ProfileBlock     ProfileKind = "block"
+)
+
+// ProfileStore persists captured profiles and returns where each was written
+// (e.g., a file path or an object storage URL)
+type ProfileStore interface {
+	StoreProfile(ctx context.Context, name string, data []byte) (location string, err error)
+}
+
+// ProfileStoreFunc adapts a function to a ProfileStore, e.g. to upload profiles to
+// object storage
+type ProfileStoreFunc func(ctx context.Context, name string, data []byte) (string, error)
+
+// StoreProfile calls f
+func (f ProfileStoreFunc) StoreProfile(ctx context.Context, name string, data []byte) (string, error) {
+	return f(ctx, name, data)
+}
+
+// DirProfileStore writes profiles as files in a directory, creating it if needed
+type DirProfileStore string
+
+// StoreProfile writes data to a file named name in the directory
+func (d DirProfileStore) StoreProfile(ctx context.Context, name string, data []byte) (string, error) {
+	if err := os.MkdirAll(string(d), 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(string(d), name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// WithProfileStore sets where captured profiles are written
+// (default: a lifecycle-profiles directory under os.TempDir)
+func WithProfileStore(store ProfileStore) ProducerOption {
+	return func(p *Producer) {
+		p.profileStore = store
+	}
+}
+
+// WithProfileOnAnomaly captures a CPU profile of the given duration whenever
+// CheckAnomalies reports a latency anomaly, at most once per cooldown
+// Combine with ThresholdDetector(MetricP99, sloMs, minCount) to profile on SLO breaches
+func WithProfileOnAnomaly(duration, cooldown time.Duration) ProducerOption {
+	return func(p *Producer) {
+		p.profileTrigger = &profileTrigger{duration: duration, cooldown: cooldown}
+	}
+}
+
+// profileTrigger rate-limits profiles captured in response to anomalies
+type profileTrigger struct {
+	duration time.Duration
+	cooldown time.Duration
+	mu       sync.Mutex
+	last     time.Time
+}
+
+// ProfileEvent represents a diagnostics.profile event
+type ProfileEvent struct {
+	Base       *BaseEvent  `json:"base"`
+	Kind       ProfileKind `json:"kind"`
+	Reason     string      `json:"reason"`
+	DurationMs int64       `json:"duration_ms,omitempty"` // CPU profiles only
+	SizeBytes  int         `json:"size_bytes"`
+	Location   string      `json:"location"` // Where the profile was written
+}
+
+func (e *ProfileEvent) GetEventType() string     { return e.Base.GetEventType() }
+func (e *ProfileEvent) GetTimestamp() time.Time  { return e.Base.GetTimestamp() }
+func (e *ProfileEvent) GetService() string       { return e.Base.GetService() }
+func (e *ProfileEvent) GetAPI() string           { return e.Base.GetAPI() }
+func (e *ProfileEvent) GetHost() string          { return e.Base.GetHost() }
+func (e *ProfileEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *ProfileEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *ProfileEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *ProfileEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
+
+// CaptureProfile captures a profile, stores it, and emits a diagnostics.profile event
+// referencing where it was written
+// CPU profiles run for duration (or until ctx is done); other kinds are snapshots and
+// ignore duration. Only one CPU profile can run at a time per process
+func (p *Producer) CaptureProfile(ctx context.Context, kind ProfileKind, duration time.Duration, reason string) (string, error) {
+	var buf bytes.Buffer
+	start := time.Now()
+
+	if kind == ProfileCPU {
+		if err := pprof.StartCPUProfile(&buf); err != nil {
+			return "", fmt.Errorf("failed to start CPU profile: %w", err)
+		}
+		timer := time.NewTimer(duration)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+		}
+		pprof.StopCPUProfile()
+	} else {
+		profile := pprof.Lookup(string(kind))
+		if profile == nil {
+			return "", fmt.Errorf("unknown profile kind %q", kind)
+		}
+		if err := profile.WriteTo(&buf, 0); err != nil {
+			return "", fmt.Errorf("failed to write %s profile: %w", kind, err)
+		}
+	}
+
+	store := p.profileStore
+	if store == nil {
+		store = DirProfileStore(filepath.Join(os.TempDir(), "lifecycle-profiles"))
+	}
+	name := fmt.Sprintf("%s-%s-%s.pprof", p.service, kind, start.UTC().Format("20060102T150405.000"))
+	location, err := store.StoreProfile(ctx, name, buf.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("failed to store %s profile: %w", kind, err)
+	}
+
+	event := &ProfileEvent{
+		Base:      p.createBaseEvent("diagnostics.profile", extractCorrelationID(ctx), nil),
+		Kind:      kind,
+		Reason:    reason,
+		SizeBytes: buf.Len(),
+		Location:  location,
+	}
+	if kind == ProfileCPU {
+		event.DurationMs = time.Since(start).Milliseconds()
+	}
+	return location, p.emitEvent(ctx, event, 0)
+}
+
+// profileOnAnomalies starts a background CPU profile if any anomaly concerns latency
+// and the trigger is not cooling down
+func (p *Producer) profileOnAnomalies(anomalies []Anomaly) {
+	trigger := p.profileTrigger
+	if trigger == nil {
+		return
+	}
+
+	var latency *Anomaly
+	for i := range anomalies {
+		switch anomalies[i].Metric {
+		case MetricP50, MetricP95, MetricP99:
+			latency = &anomalies[i]
+		}
+	}
+	if latency == nil {
+		return
+	}
+
+	trigger.mu.Lock()
+	now := time.Now()
+	if !trigger.last.IsZero() && now.Sub(trigger.last) < trigger.cooldown {
+		trigger.mu.Unlock()
+		return
+	}
+	trigger.last = now
+	trigger.mu.Unlock()
+
+	reason := "anomaly: " + latency.Message
+	go func() {
+		_, _ = p.CaptureProfile(context.Background(), ProfileCPU, trigger.duration, reason)
+	}()
+}
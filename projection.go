@@ -0,0 +1,204 @@
+package lifecycle
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Projection is a view of events for one audience, removing or masking the fields it
+// should not see; assign it to a sink with SinkProjection
+// Field paths use json names, dotted into nested structs and maps (e.g., "resource_data",
+// "actor.user_id", "metadata.remote_addr"). Base event fields can be named directly or
+// under "base." (e.g., "base.metadata")
+type Projection struct {
+	Name        string
+	Exclude     []string // Fields removed from the event
+	Mask        []string // Fields whose values are replaced with Replacement, keeping their keys
+	Replacement string   // Default: "[REDACTED]"
+}
+
+// resourcePayloads are the fields of resource events holding the resource's data
+var resourcePayloads = []string{"resource_data", "previous_data", "new_data", "final_data"}
+
+// Built-in projections
+var (
+	// ProjectionEngineer keeps the technical detail engineers debug with (queries, stack
+	// traces, client details) but masks resource payloads
+	ProjectionEngineer = &Projection{Name: "engineer", Mask: resourcePayloads}
+
+	// ProjectionSupport keeps who did what to which resource, for support tooling, and
+	// removes resource payloads, queries, stack traces, and client details
+	ProjectionSupport = &Projection{
+		Name: "support",
+		Exclude: append([]string{
+			"query", "params", "stack", "stack_trace", "remote_addr", "user_agent", "client",
+		}, resourcePayloads...),
+	}
+
+	// ProjectionAuditor keeps actors, resources, and their changes, and removes queries,
+	// stack traces, and client software details
+	ProjectionAuditor = &Projection{
+		Name:    "auditor",
+		Exclude: []string{"query", "params", "stack", "stack_trace", "user_agent", "client"},
+	}
+)
+
+// Apply returns a copy of event with the projection's fields removed and masked; event
+// itself is not modified
+// Events that are not pointers to structs are returned unchanged. The copy has its own
+// base without the signature and prev_hash from WithEventSigning, which covered the
+// fields the projection removed; sinks with a SinkProjection sign it again
+func (pr *Projection) Apply(event Event) Event {
+	projected := unsignedCopy(event)
+	clone := reflect.ValueOf(projected)
+	if clone.Kind() != reflect.Pointer || clone.IsNil() || clone.Elem().Kind() != reflect.Struct {
+		return event
+	}
+
+	replacement := pr.Replacement
+	if replacement == "" {
+		replacement = "[REDACTED]"
+	}
+	for _, path := range pr.Exclude {
+		projectStruct(clone.Elem(), strings.Split(path, "."), func(reflect.Value) reflect.Value { return reflect.Value{} })
+	}
+	for _, path := range pr.Mask {
+		projectStruct(clone.Elem(), strings.Split(path, "."), func(v reflect.Value) reflect.Value { return maskValue(v, replacement) })
+	}
+	return projected
+}
+
+// projectStruct replaces the field at path in the struct v, which must be addressable, with
+// replace's result; an invalid result zeroes the field
+// Pointers on the way are copied, so the original event is never modified
+func projectStruct(v reflect.Value, path []string, replace func(reflect.Value) reflect.Value) {
+	field, ok := jsonField(v, path[0])
+	if !ok {
+		// Base event fields can be named without "base."
+		if base, ok := jsonField(v, "base"); ok && path[0] != "base" {
+			projectValue(base, path, replace)
+		}
+		return
+	}
+	projectValue(field, path[1:], replace)
+}
+
+// projectValue replaces the value at path within v, which must be settable
+func projectValue(v reflect.Value, path []string, replace func(reflect.Value) reflect.Value) {
+	if len(path) == 0 {
+		if replaced := replace(v); replaced.IsValid() {
+			v.Set(replaced)
+		} else {
+			v.Set(reflect.Zero(v.Type()))
+		}
+		return
+	}
+
+	switch {
+	case v.Kind() == reflect.Pointer && !v.IsNil() && v.Elem().Kind() == reflect.Struct:
+		clone := reflect.New(v.Elem().Type())
+		clone.Elem().Set(v.Elem())
+		v.Set(clone)
+		projectStruct(clone.Elem(), path, replace)
+	case v.Kind() == reflect.Struct:
+		projectStruct(v, path, replace)
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String && !v.IsNil():
+		clone := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			clone.SetMapIndex(iter.Key(), iter.Value())
+		}
+		v.Set(clone)
+
+		key := reflect.ValueOf(path[0]).Convert(v.Type().Key())
+		entry := clone.MapIndex(key)
+		if !entry.IsValid() {
+			return
+		}
+		// Map entries are not settable, so project a copy and store it back
+		value := reflect.New(v.Type().Elem()).Elem()
+		value.Set(entry)
+		if len(path) == 1 && !replace(value).IsValid() {
+			clone.SetMapIndex(key, reflect.Value{})
+			return
+		}
+		if value.Kind() == reflect.Interface && !value.IsNil() && len(path) > 1 {
+			inner := reflect.New(value.Elem().Type()).Elem()
+			inner.Set(value.Elem())
+			projectValue(inner, path[1:], replace)
+			value.Set(inner)
+		} else {
+			projectValue(value, path[1:], replace)
+		}
+		clone.SetMapIndex(key, value)
+	}
+}
+
+// jsonField returns the exported field of the struct v whose json name is name
+func jsonField(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if fieldName, skip := styledFieldName(field); !skip && fieldName == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// maskValue returns v with its content replaced by replacement: strings and interface
+// values are replaced, maps and structs have each of their values masked, and other
+// values are zeroed
+func maskValue(v reflect.Value, replacement string) reflect.Value {
+	if v.IsZero() {
+		return v
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(replacement).Convert(v.Type())
+	case reflect.Interface:
+		// Interfaces can hold the replacement itself, so only containers are masked inside
+		masked := reflect.ValueOf(replacement)
+		switch v.Elem().Kind() {
+		case reflect.Map, reflect.Pointer, reflect.Struct:
+			masked = maskValue(v.Elem(), replacement)
+		}
+		result := reflect.New(v.Type()).Elem()
+		if masked.Type().AssignableTo(v.Type()) {
+			result.Set(masked)
+		}
+		return result
+	case reflect.Map:
+		if elem := v.Type().Elem(); elem.Kind() == reflect.Interface || elem.Kind() == reflect.String {
+			clone := reflect.MakeMapWithSize(v.Type(), v.Len())
+			iter := v.MapRange()
+			for iter.Next() {
+				clone.SetMapIndex(iter.Key(), reflect.ValueOf(replacement).Convert(elem))
+			}
+			return clone
+		}
+	case reflect.Pointer:
+		if v.Elem().Kind() == reflect.Struct {
+			clone := reflect.New(v.Elem().Type())
+			clone.Elem().Set(maskValue(v.Elem(), replacement))
+			return clone
+		}
+	case reflect.Struct:
+		clone := reflect.New(v.Type()).Elem()
+		clone.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if field := clone.Field(i); field.CanSet() {
+				if masked := maskValue(field, replacement); masked.IsValid() {
+					field.Set(masked)
+				} else {
+					field.Set(reflect.Zero(field.Type()))
+				}
+			}
+		}
+		return clone
+	}
+	return reflect.Zero(v.Type())
+}
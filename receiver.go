@@ -0,0 +1,344 @@
+package lifecycle
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Receiver defaults
+const (
+	DefaultReceiverPath              = "/v1/events"
+	DefaultReceiverMaxBody           = 10 << 20
+	DefaultReceiverReadHeaderTimeout = 10 * time.Second // Bounds slow or stalled clients
+	DefaultReceiverReadTimeout       = time.Minute      // Reading a whole request, up to DefaultReceiverMaxBody
+)
+
+// eventTypePattern is the shape of a valid event type, dot-separated names
+var eventTypePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+(\.[A-Za-z0-9_-]+)+$`)
+
+// Receiver ingests events from other processes over HTTP, validates them, and forwards
+// them to local sinks, making a process a lightweight aggregation point for its neighbors
+// It accepts POST requests to DefaultReceiverPath with a body of:
+//   - NDJSON (application/x-ndjson), as written by NewHTTPSink or "lifecycle replay"
+//   - a JSON array of events (application/json)
+//   - a protobuf google.protobuf.ListValue of event Structs (application/x-protobuf)
+//
+// The response is a ReceiveResult: 200 when every event was accepted, 422 when some were
+// rejected (the others are still forwarded), and 500 when a sink failed. gRPC clients can
+// publish through lifecyclegrpc.RegisterReceiver
+// Received events pass through a producer's event hooks and PII redaction before they are
+// written, like events emitted locally (see WithReceiverProducer)
+type Receiver struct {
+	addr       string
+	sinks      []Sink
+	validators []func(*Record) error
+	maxBody    int64
+	token      string
+	server     *http.Server
+	producer   *Producer // Runs hooks and redaction on received events
+	forward    bool      // Write received events to producer, set with WithReceiverProducer
+
+	accepted atomic.Int64
+	rejected atomic.Int64
+}
+
+// ReceiverOption configures a Receiver
+type ReceiverOption func(*Receiver)
+
+// WithReceiverSink adds a sink received events are forwarded to
+func WithReceiverSink(sink Sink) ReceiverOption {
+	return func(r *Receiver) {
+		r.sinks = append(r.sinks, sink)
+	}
+}
+
+// WithReceiverValidator adds a check run on each event after ValidateRecord, such as
+// validation against the event type's schema
+func WithReceiverValidator(validate func(*Record) error) ReceiverOption {
+	return func(r *Receiver) {
+		r.validators = append(r.validators, validate)
+	}
+}
+
+// WithReceiverSchemaValidation rejects events that do not match their event type's schema
+// (see ValidateRecordSchema), such as events from other languages with a missing or
+// mistyped field
+func WithReceiverSchemaValidation() ReceiverOption {
+	return WithReceiverValidator(ValidateRecordSchema)
+}
+
+// WithReceiverToken requires requests to carry "Authorization: Bearer <token>", as an
+// HTTP header or, through lifecyclegrpc.RegisterReceiver, gRPC metadata
+func WithReceiverToken(token string) ReceiverOption {
+	return func(r *Receiver) {
+		r.token = token
+	}
+}
+
+// WithReceiverMaxBody limits the size of a request body (default: DefaultReceiverMaxBody)
+func WithReceiverMaxBody(maxBody int64) ReceiverOption {
+	return func(r *Receiver) {
+		if maxBody > 0 {
+			r.maxBody = maxBody
+		}
+	}
+}
+
+// WithReceiverProducer forwards received events through p as well: its event hooks and PII
+// redaction run on each event, which is then written to p's sinks (signed, if p signs
+// events) and to the receiver's own sinks. Without it, received events are redacted with
+// the default PII detector and written to the receiver's sinks only
+func WithReceiverProducer(p *Producer) ReceiverOption {
+	return func(r *Receiver) {
+		r.producer = p
+		r.forward = p != nil
+	}
+}
+
+// NewReceiver creates a receiver to serve on addr (e.g., ":4318") with ListenAndServe, or
+// to mount with Handler
+func NewReceiver(addr string, opts ...ReceiverOption) *Receiver {
+	r := &Receiver{
+		addr:    addr,
+		maxBody: DefaultReceiverMaxBody,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.producer == nil {
+		r.producer = NewProducer("lifecycle-receiver", addr, WithOutput(io.Discard))
+	}
+	mux := http.NewServeMux()
+	mux.Handle(DefaultReceiverPath, r.Handler())
+	r.server = &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: DefaultReceiverReadHeaderTimeout,
+		ReadTimeout:       DefaultReceiverReadTimeout,
+	}
+	return r
+}
+
+// ReceiveResult reports what happened to the events of one request
+type ReceiveResult struct {
+	Accepted int             `json:"accepted"`
+	Rejected []RejectedEvent `json:"rejected,omitempty"`
+	Error    string          `json:"error,omitempty"` // A sink failed
+}
+
+// RejectedEvent is an event that failed to decode or validate
+type RejectedEvent struct {
+	Index int    `json:"index"` // Position in the request, from 0
+	Error string `json:"error"`
+}
+
+// ValidateRecord checks that a received record is a well-formed lifecycle event: it has a
+// dot-separated event type, a service, and a timestamp, its schema version is not newer
+// than this library's, and its metadata has no reserved keys. Older records are migrated
+// to CurrentSchemaVersion
+func ValidateRecord(r *Record) error {
+	switch {
+	case !eventTypePattern.MatchString(r.EventType):
+		return fmt.Errorf("invalid event_type %q", r.EventType)
+	case r.Service == "":
+		return errors.New("missing service")
+	case r.Timestamp.IsZero():
+		return errors.New("missing timestamp")
+	case r.SchemaVersion > CurrentSchemaVersion:
+		return fmt.Errorf("unsupported schema_version %d (newest: %d)", r.SchemaVersion, CurrentSchemaVersion)
+	}
+	for key := range r.Metadata {
+		if err := ValidateMetadataKey(key); err != nil {
+			return err
+		}
+	}
+	return MigrateRecord(r)
+}
+
+// Receive validates events and forwards the valid ones, redacted, to the sinks; decode
+// errors are passed as nil records with their error in errs at the same index
+func (r *Receiver) Receive(records []*Record, errs []error) ReceiveResult {
+	var result ReceiveResult
+	var sinkErrs []error
+	for i, record := range records {
+		var err error
+		if i < len(errs) {
+			err = errs[i]
+		}
+		if err == nil {
+			err = ValidateRecord(record)
+		}
+		for _, validate := range r.validators {
+			if err != nil {
+				break
+			}
+			err = validate(record)
+		}
+		if err != nil {
+			result.Rejected = append(result.Rejected, RejectedEvent{Index: i, Error: err.Error()})
+			continue
+		}
+
+		event := NewRecordEvent(record)
+		r.producer.prepareEvent(context.Background(), event)
+		if r.forward {
+			if err := r.producer.writeEvent(event); err != nil {
+				sinkErrs = append(sinkErrs, err)
+			}
+		}
+		for _, sink := range r.sinks {
+			if err := sink.WriteEvent(event); err != nil {
+				sinkErrs = append(sinkErrs, err)
+			}
+		}
+		result.Accepted++
+	}
+	if len(sinkErrs) > 0 {
+		result.Error = errors.Join(sinkErrs...).Error()
+	}
+	r.accepted.Add(int64(result.Accepted))
+	r.rejected.Add(int64(len(result.Rejected)))
+	return result
+}
+
+// Authorized reports whether an Authorization header value carries the receiver's token
+// (see WithReceiverToken); every value is authorized when the receiver has no token
+// The comparison takes constant time, so it does not reveal the token
+func (r *Receiver) Authorized(authorization string) bool {
+	if r.token == "" {
+		return true
+	}
+	return subtle.ConstantTimeCompare([]byte(authorization), []byte("Bearer "+r.token)) == 1
+}
+
+// Handler returns the receiver's HTTP handler, for mounting on an existing server
+func (r *Receiver) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !r.Authorized(req.Header.Get("Authorization")) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, r.maxBody))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, "failed to read request body", http.StatusBadRequest)
+			return
+		}
+
+		records, errs, err := decodeReceived(req.Header.Get("Content-Type"), body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result := r.Receive(records, errs)
+
+		status := http.StatusOK
+		switch {
+		case result.Error != "":
+			status = http.StatusInternalServerError
+		case len(result.Rejected) > 0:
+			status = http.StatusUnprocessableEntity
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(result)
+	})
+}
+
+// decodeReceived decodes a request body by content type into records, with a decode
+// error per event that could not be decoded
+func decodeReceived(contentType string, body []byte) ([]*Record, []error, error) {
+	var raws [][]byte
+	switch mediaType, _, _ := strings.Cut(contentType, ";"); strings.TrimSpace(mediaType) {
+	case "application/x-protobuf", "application/protobuf":
+		var list structpb.ListValue
+		if err := proto.Unmarshal(body, &list); err != nil {
+			return nil, nil, fmt.Errorf("invalid protobuf body: %w", err)
+		}
+		for _, value := range list.GetValues() {
+			data, err := value.MarshalJSON()
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid protobuf body: %w", err)
+			}
+			raws = append(raws, data)
+		}
+	case "application/json":
+		var list []json.RawMessage
+		if err := json.Unmarshal(body, &list); err != nil {
+			return nil, nil, fmt.Errorf("invalid JSON array: %w", err)
+		}
+		for _, data := range list {
+			raws = append(raws, data)
+		}
+	default:
+		scanner := bufio.NewScanner(bytes.NewReader(body))
+		scanner.Buffer(make([]byte, 0, 64*1024), len(body)+1)
+		for scanner.Scan() {
+			if data := bytes.TrimSpace(scanner.Bytes()); len(data) > 0 {
+				raws = append(raws, append([]byte(nil), data...))
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, nil, fmt.Errorf("invalid NDJSON body: %w", err)
+		}
+	}
+
+	records := make([]*Record, len(raws))
+	errs := make([]error, len(raws))
+	for i, data := range raws {
+		records[i], errs[i] = DecodeRecord(data)
+	}
+	return records, errs, nil
+}
+
+// Stats returns how many events the receiver has accepted and rejected
+func (r *Receiver) Stats() (accepted, rejected int64) {
+	return r.accepted.Load(), r.rejected.Load()
+}
+
+// ListenAndServe serves the receiver on its address until Shutdown
+func (r *Receiver) ListenAndServe() error {
+	if err := r.server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops the server once requests in progress are done, then flushes
+// (Flush() error) and closes (io.Closer) the sinks
+func (r *Receiver) Shutdown(ctx context.Context) error {
+	errs := []error{r.server.Shutdown(ctx)}
+	for _, sink := range r.sinks {
+		if f, ok := sink.(flusher); ok {
+			errs = append(errs, f.Flush())
+		}
+		if c, ok := sink.(io.Closer); ok {
+			errs = append(errs, c.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,45 @@
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestReceiverRedacts checks that received events pass through the producer's hooks and
+// PII redaction before they reach the producer's and the receiver's sinks, and are
+// redacted without a producer too
+func TestReceiverRedacts(t *testing.T) {
+	var forwarded, received, plain bytes.Buffer
+	hooked := 0
+	p := NewProducer("aggregator", "localhost",
+		WithSink(NewWriterSink(&forwarded, nil)),
+		WithEventHook(func(ctx context.Context, event Event) { hooked++ }))
+	receiver := NewReceiver(":0", WithReceiverProducer(p), WithReceiverSink(NewWriterSink(&received, nil)))
+	if receiver.server.ReadHeaderTimeout <= 0 || receiver.server.ReadTimeout <= 0 {
+		t.Errorf("server timeouts = %v, %v, want both set", receiver.server.ReadHeaderTimeout, receiver.server.ReadTimeout)
+	}
+
+	body := `{"event_type":"resource.created","timestamp":"2026-01-02T03:04:05Z","service":"orders","email":"ann@example.com","metadata":{"contact":"ann@example.com"}}` + "\n"
+	for _, receiver := range []*Receiver{receiver, NewReceiver(":0", WithReceiverSink(NewWriterSink(&plain, nil)))} {
+		req := httptest.NewRequest(http.MethodPost, DefaultReceiverPath, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-ndjson")
+		rec := httptest.NewRecorder()
+		receiver.Handler().ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+		}
+	}
+
+	if hooked != 1 {
+		t.Errorf("hook ran %d times, want 1", hooked)
+	}
+	for name, buf := range map[string]*bytes.Buffer{"producer": &forwarded, "receiver": &received, "default": &plain} {
+		if got := buf.String(); got == "" || strings.Contains(got, "ann@example.com") {
+			t.Errorf("%s sink got %q, want the event with the email redacted", name, got)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package lifecycle
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// Record is a decoded lifecycle event read back from an NDJSON stream
+// Base event fields are lifted to the top level; all other fields stay in Fields
+// Both the nested form written by the Producer ({"base": {...}, ...}) and the
+// flat form ({"event_type": ..., ...}) are accepted
+type Record struct {
+	EventID       string                 `json:"event_id,omitempty"`
+	EventType     string                 `json:"event_type"`
+	SchemaVersion int                    `json:"schema_version"` // 1 for events written before versioning
+	Timestamp     time.Time              `json:"timestamp"`
+	Service       string                 `json:"service"`
+	API           string                 `json:"api,omitempty"`
+	Host          string                 `json:"host"`
+	CorrelationID string                 `json:"correlation_id,omitempty"`
+	Retention     string                 `json:"retention,omitempty"`
+	Level         string                 `json:"level,omitempty"` // Set by WithEventLevels
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+	Fields        map[string]interface{} `json:"fields,omitempty"` // Event-specific fields
+
+	ResourceAttributes map[string]string `json:"resource_attributes,omitempty"`
+
+	PrevHash  string `json:"prev_hash,omitempty"` // Set on signed events (see VerifyEvents)
+	Signature string `json:"signature,omitempty"`
+}
+
+// baseFieldNames are the keys lifted from the event into Record's base fields
+var baseFieldNames = []string{"event_id", "event_type", "schema_version", "timestamp", "service", "api", "host", "correlation_id", "retention", "metadata", "resource_attributes", "prev_hash", "signature"}
+
+// DecodeRecord decodes a single JSON-encoded lifecycle event
+func DecodeRecord(data []byte) (*Record, error) {
+	var raw map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to decode event: %w", err)
+	}
+
+	base := raw
+	if nested, ok := raw["base"].(map[string]interface{}); ok {
+		base = nested
+		delete(raw, "base")
+	}
+
+	record := &Record{
+		EventID:       stringField(base, "event_id"),
+		EventType:     stringField(base, "event_type"),
+		Service:       stringField(base, "service"),
+		API:           stringField(base, "api"),
+		Host:          stringField(base, "host"),
+		CorrelationID: stringField(base, "correlation_id"),
+		Retention:     stringField(base, "retention"),
+		Level:         stringField(base, "level"),
+		PrevHash:      stringField(base, "prev_hash"),
+		Signature:     stringField(base, "signature"),
+	}
+	record.SchemaVersion = 1
+	if version, ok := base["schema_version"].(json.Number); ok {
+		v, err := version.Int64()
+		if err != nil || v < 1 {
+			return nil, fmt.Errorf("invalid schema_version %q", version)
+		}
+		record.SchemaVersion = int(v)
+	}
+	if metadata, ok := base["metadata"].(map[string]interface{}); ok {
+		record.Metadata = metadata
+	}
+	if attrs, ok := base["resource_attributes"].(map[string]interface{}); ok {
+		record.ResourceAttributes = make(map[string]string, len(attrs))
+		for key, value := range attrs {
+			if s, ok := value.(string); ok {
+				record.ResourceAttributes[key] = s
+			}
+		}
+	}
+	if ts := stringField(base, "timestamp"); ts != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, ts)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp %q: %w", ts, err)
+		}
+		record.Timestamp = parsed
+	}
+	if record.EventType == "" {
+		return nil, fmt.Errorf("missing event_type")
+	}
+
+	for _, name := range baseFieldNames {
+		delete(raw, name)
+	}
+	if len(raw) > 0 {
+		record.Fields = raw
+	}
+
+	return record, nil
+}
+
+// ReadRecords decodes an NDJSON stream, calling fn for each event
+// Blank lines are skipped; a line that fails to decode aborts reading with its line number.
+// Framed streams (see FramePreamble) are read frame by frame
+func ReadRecords(r io.Reader, fn func(*Record) error) error {
+	br := bufio.NewReader(r)
+	if isFramed(br) {
+		return readFramedRecords(br, fn)
+	}
+
+	scanner := bufio.NewScanner(br)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		record, err := DecodeRecord(data)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read events: %w", err)
+	}
+	return nil
+}
+
+// StringField returns the named event-specific field as a string, or "" if absent
+func (r *Record) StringField(name string) string {
+	return stringField(r.Fields, name)
+}
+
+// NumberField returns the named event-specific field as a float64
+func (r *Record) NumberField(name string) (float64, bool) {
+	switch v := r.Fields[name].(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// Get returns a field by name, checking base fields first, then event-specific
+// fields, then metadata, then resource attributes (e.g., "deployment.environment")
+func (r *Record) Get(name string) (interface{}, bool) {
+	switch name {
+	case "event_id":
+		return r.EventID, r.EventID != ""
+	case "event_type":
+		return r.EventType, true
+	case "schema_version":
+		return r.SchemaVersion, true
+	case "timestamp":
+		return r.Timestamp, !r.Timestamp.IsZero()
+	case "service":
+		return r.Service, true
+	case "api":
+		return r.API, true
+	case "host":
+		return r.Host, true
+	case "correlation_id":
+		return r.CorrelationID, true
+	case "retention":
+		return r.Retention, r.Retention != ""
+	case "level":
+		return r.Level, r.Level != ""
+	}
+	if v, ok := r.Fields[name]; ok {
+		return v, true
+	}
+	if v, ok := r.Metadata[name]; ok {
+		return v, true
+	}
+	if v, ok := r.ResourceAttributes[name]; ok {
+		return v, true
+	}
+	return nil, false
+}
+
+// RecordEvent is a decoded record as an Event, so events read from a stream or received
+// from other processes can be written to sinks; it is encoded in the producer's nested form
+type RecordEvent struct {
+	Base   *BaseEvent
+	Fields map[string]interface{} // Event-specific fields
+}
+
+// NewRecordEvent creates an event from r, sharing its metadata and fields
+func NewRecordEvent(r *Record) *RecordEvent {
+	return &RecordEvent{
+		Base: &BaseEvent{
+			EventID:            r.EventID,
+			EventType:          r.EventType,
+			SchemaVersion:      r.SchemaVersion,
+			Timestamp:          r.Timestamp,
+			Service:            r.Service,
+			API:                r.API,
+			Host:               r.Host,
+			CorrelationID:      r.CorrelationID,
+			Retention:          RetentionClass(r.Retention),
+			Level:              r.Level,
+			Metadata:           r.Metadata,
+			ResourceAttributes: r.ResourceAttributes,
+			PrevHash:           r.PrevHash,
+			Signature:          r.Signature,
+		},
+		Fields: r.Fields,
+	}
+}
+
+func (e *RecordEvent) GetEventType() string     { return e.Base.GetEventType() }
+func (e *RecordEvent) GetTimestamp() time.Time  { return e.Base.GetTimestamp() }
+func (e *RecordEvent) GetService() string       { return e.Base.GetService() }
+func (e *RecordEvent) GetAPI() string           { return e.Base.GetAPI() }
+func (e *RecordEvent) GetHost() string          { return e.Base.GetHost() }
+func (e *RecordEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *RecordEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *RecordEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *RecordEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
+
+// RedactPII redacts PII in the event-specific fields, which may hold anything when the
+// event was received from another process
+func (e *RecordEvent) RedactPII(detector *PIIDetector, redactor *Redactor) {
+	if e.Fields != nil {
+		e.Fields = redactor.RedactMap(e.Fields, detector)
+	}
+}
+
+// StyledFields returns the event-specific fields sorted by name, for styled output
+func (e *RecordEvent) StyledFields() []interface{} {
+	names := make([]string, 0, len(e.Fields))
+	for name := range e.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fields := make([]interface{}, 0, 2*len(names))
+	for _, name := range names {
+		value := e.Fields[name]
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			if data, err := json.Marshal(value); err == nil {
+				value = string(data)
+			}
+		}
+		fields = append(fields, name, value)
+	}
+	return fields
+}
+
+// MarshalJSON encodes the event with its base fields under "base"
+func (e *RecordEvent) MarshalJSON() ([]byte, error) {
+	object := make(map[string]interface{}, len(e.Fields)+1)
+	for key, value := range e.Fields {
+		object[key] = value
+	}
+	object["base"] = e.Base
+	return json.Marshal(object)
+}
+
+// stringField returns m[name] if it is a string
+func stringField(m map[string]interface{}, name string) string {
+	s, _ := m[name].(string)
+	return s
+}
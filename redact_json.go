@@ -0,0 +1,223 @@
+package lifecycle
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// RedactJSON redacts PII in a stream of JSON values read from r, such as a captured request
+// body or an NDJSON file, with the default detector and redaction string
+// See Redactor.RedactJSON
+func RedactJSON(r io.Reader, w io.Writer) error {
+	return NewRedactor().RedactJSON(r, w, NewPIIDetector())
+}
+
+// RedactJSON redacts PII in a stream of JSON values read from in, writing each value to out
+// compactly on its own line
+// Values are redacted token by token as they are read, so memory grows with nesting depth
+// rather than document size. Objects are redacted like RedactMap: a member whose name
+// indicates PII has its whole value replaced, nested objects and arrays included, strings
+// that look like PII are replaced wherever they appear, and client IP addresses are
+// anonymized with AnonymizeIP (other strings in IP fields are replaced)
+func (r *Redactor) RedactJSON(in io.Reader, out io.Writer, detector *PIIDetector) error {
+	decoder := json.NewDecoder(in)
+	decoder.UseNumber()
+	s := &jsonRedaction{decoder: decoder, out: bufio.NewWriter(out), detector: detector, mask: r.mask()}
+	s.encoder = json.NewEncoder(&s.scratch)
+	s.encoder.SetEscapeHTML(false)
+
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			return s.out.Flush()
+		}
+		if err == nil {
+			err = s.write(tok)
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return fmt.Errorf("failed to redact JSON: %w", err)
+		}
+		s.out.WriteByte('\n')
+		// Flush each value so streams such as NDJSON are written as they are read
+		if err := s.out.Flush(); err != nil {
+			return err
+		}
+	}
+}
+
+// jsonRedaction is the state of one RedactJSON call
+type jsonRedaction struct {
+	decoder  *json.Decoder
+	out      *bufio.Writer
+	detector *PIIDetector
+	mask     string
+	scratch  bytes.Buffer  // Holds one encoded string
+	encoder  *json.Encoder // Encodes strings to scratch
+}
+
+// value reads and writes the next value
+func (s *jsonRedaction) value() error {
+	tok, err := s.decoder.Token()
+	if err != nil {
+		return err
+	}
+	return s.write(tok)
+}
+
+// write writes the value starting with tok, reading the rest of it if it is an object or array
+func (s *jsonRedaction) write(tok json.Token) error {
+	switch v := tok.(type) {
+	case json.Delim:
+		if v == '{' {
+			return s.object()
+		}
+		return s.array()
+	case string:
+		if s.detector.IsPIIValue(v) {
+			v = s.mask
+		}
+		return s.writeString(v)
+	case json.Number:
+		s.out.WriteString(v.String())
+	case bool:
+		if v {
+			s.out.WriteString("true")
+		} else {
+			s.out.WriteString("false")
+		}
+	case nil:
+		s.out.WriteString("null")
+	}
+	return nil
+}
+
+// object writes the members of an object whose '{' has been read
+func (s *jsonRedaction) object() error {
+	s.out.WriteByte('{')
+	for i := 0; s.decoder.More(); i++ {
+		tok, err := s.decoder.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := tok.(string) // The decoder only returns strings for member names
+		if i > 0 {
+			s.out.WriteByte(',')
+		}
+		if err := s.writeString(key); err != nil {
+			return err
+		}
+		s.out.WriteByte(':')
+
+		switch {
+		case isIPField(key):
+			err = s.ipValue()
+		case s.detector.IsPIIField(key):
+			if err := s.skip(); err != nil {
+				return err
+			}
+			err = s.writeString(s.mask)
+		default:
+			err = s.value()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := s.decoder.Token(); err != nil {
+		return err
+	}
+	return s.out.WriteByte('}')
+}
+
+// array writes the elements of an array whose '[' has been read
+func (s *jsonRedaction) array() error {
+	s.out.WriteByte('[')
+	for i := 0; s.decoder.More(); i++ {
+		if i > 0 {
+			s.out.WriteByte(',')
+		}
+		if err := s.value(); err != nil {
+			return err
+		}
+	}
+	if _, err := s.decoder.Token(); err != nil {
+		return err
+	}
+	return s.out.WriteByte(']')
+}
+
+// ipValue writes the next value, anonymizing the IP addresses in it if it is a string or
+// an array of strings, and redacting strings that are not IP addresses
+func (s *jsonRedaction) ipValue() error {
+	tok, err := s.decoder.Token()
+	if err != nil {
+		return err
+	}
+	if tok != json.Delim('[') {
+		return s.writeIP(tok)
+	}
+
+	s.out.WriteByte('[')
+	for i := 0; s.decoder.More(); i++ {
+		if i > 0 {
+			s.out.WriteByte(',')
+		}
+		tok, err := s.decoder.Token()
+		if err != nil {
+			return err
+		}
+		if err := s.writeIP(tok); err != nil {
+			return err
+		}
+	}
+	if _, err := s.decoder.Token(); err != nil {
+		return err
+	}
+	return s.out.WriteByte(']')
+}
+
+// writeIP writes the value starting with tok, anonymizing it if it is a string of IP
+// addresses and redacting any other string
+func (s *jsonRedaction) writeIP(tok json.Token) error {
+	if v, ok := tok.(string); ok && !s.detector.IsPIIValue(v) {
+		return s.writeString(redactIP(v, s.mask))
+	}
+	return s.write(tok)
+}
+
+// skip reads the next value without writing it
+func (s *jsonRedaction) skip() error {
+	depth := 0
+	for {
+		tok, err := s.decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// writeString writes v as a JSON string
+func (s *jsonRedaction) writeString(v string) error {
+	s.scratch.Reset()
+	if err := s.encoder.Encode(v); err != nil {
+		return err
+	}
+	_, err := s.out.Write(bytes.TrimSuffix(s.scratch.Bytes(), []byte{'\n'}))
+	return err
+}
@@ -0,0 +1,123 @@
+package lifecycle
+
+import (
+	"container/list"
+	"sync"
+)
+
+// WithRedactionCacheSize caches up to size field redaction decisions, keyed by event type
+// and field path, so events of a shape seen before skip matching field names (default:
+// no cache)
+// Only decisions driven by field names and schema annotations are cached; values are
+// always checked. Field names are matched in a single pass, so the cache pays off only
+// for shapes with many long field names
+func WithRedactionCacheSize(size int) ProducerOption {
+	return func(p *Producer) {
+		p.redactionCache = newRedactionCache(size)
+	}
+}
+
+// redactionKey identifies a field of an event shape
+// The path and field are kept apart, so a top-level "a.b" and a "b" nested in "a" differ
+type redactionKey struct {
+	eventType string
+	path      string // Dotted path of the map holding the field (e.g., "user.address")
+	field     string
+}
+
+// redactionEntry is a cached decision and the annotation it was made with
+type redactionEntry struct {
+	key        redactionKey
+	annotation FieldAnnotations
+	redact     bool
+}
+
+// redactionCache is a least-recently-used cache of field redaction decisions
+// It belongs to one producer, whose detector is immutable, so entries never go stale
+// because of the detector; an entry is ignored when the field's schema annotation no
+// longer matches the one it was decided with
+type redactionCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[redactionKey]*list.Element
+	order   *list.List // Most recently used first
+}
+
+// newRedactionCache returns a cache of size decisions, or nil if size is not positive
+func newRedactionCache(size int) *redactionCache {
+	if size <= 0 {
+		return nil
+	}
+	return &redactionCache{
+		size:    size,
+		entries: make(map[redactionKey]*list.Element, size),
+		order:   list.New(),
+	}
+}
+
+// get returns the cached decision for key, if it was made with annotation
+func (c *redactionCache) get(key redactionKey, annotation FieldAnnotations) (redact, ok bool) {
+	c.mu.Lock()
+	element, found := c.entries[key]
+	if found && element.Value.(*redactionEntry).annotation == annotation {
+		c.order.MoveToFront(element)
+		redact, ok = element.Value.(*redactionEntry).redact, true
+	}
+	c.mu.Unlock()
+	return redact, ok
+}
+
+// put caches the decision for key, evicting the least recently used entry when full
+func (c *redactionCache) put(key redactionKey, annotation FieldAnnotations, redact bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, found := c.entries[key]; found {
+		entry := element.Value.(*redactionEntry)
+		entry.annotation, entry.redact = annotation, redact
+		c.order.MoveToFront(element)
+		return
+	}
+	if c.order.Len() >= c.size {
+		oldest := c.order.Back()
+		delete(c.entries, oldest.Value.(*redactionEntry).key)
+		c.order.Remove(oldest)
+	}
+	c.entries[key] = c.order.PushFront(&redactionEntry{key: key, annotation: annotation, redact: redact})
+}
+
+// forEvent returns a copy of the detector that caches field decisions for eventType in
+// cache, or the detector itself if cache is nil
+func (d *PIIDetector) forEvent(eventType string, cache *redactionCache) *PIIDetector {
+	if cache == nil {
+		return d
+	}
+	scoped := *d
+	scoped.cache, scoped.eventType = cache, eventType
+	return &scoped
+}
+
+// isPIIFieldAt reports whether the field named key in the map at path should be redacted
+// because of its schema annotation or its name, using the cache when the detector has one
+func (d *PIIDetector) isPIIFieldAt(path, key string, annotations map[string]FieldAnnotations) bool {
+	annotation := annotations[key]
+	if d.cache == nil {
+		return ShouldRedact(annotation) || d.IsPIIField(key)
+	}
+
+	cacheKey := redactionKey{eventType: d.eventType, path: path, field: key}
+	if redact, ok := d.cache.get(cacheKey, annotation); ok {
+		return redact
+	}
+	redact := ShouldRedact(annotation) || d.IsPIIField(key)
+	d.cache.put(cacheKey, annotation, redact)
+	return redact
+}
+
+// fieldPath returns the path of the field named key inside the map at path
+func fieldPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
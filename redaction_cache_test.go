@@ -0,0 +1,79 @@
+package lifecycle
+
+import (
+	"fmt"
+	"testing"
+)
+
+// redactionBenchmarkData returns event data of a typical shape: mostly fields that are
+// not PII, with long names, nested objects, and a few PII fields
+func redactionBenchmarkData() map[string]interface{} {
+	data := map[string]interface{}{
+		"customer_email_address": "alice",
+		"billing_postal_code":    "94107",
+		"shipping_preferences":   map[string]interface{}{"delivery_window_start": "09:00", "delivery_window_end": "17:00"},
+		"line_items":             []interface{}{map[string]interface{}{"product_identifier": "sku-1", "quantity_ordered": 2}},
+	}
+	for i := 0; i < 12; i++ {
+		data[fmt.Sprintf("order_fulfillment_attribute_%02d", i)] = "value"
+	}
+	return data
+}
+
+// BenchmarkRedactData measures event data redaction without a redaction cache, with a
+// cache that misses on every field, and with a warm cache, whose lookups skip matching
+// field names
+func BenchmarkRedactData(b *testing.B) {
+	data := redactionBenchmarkData()
+	annotations := map[string]FieldAnnotations{"billing_postal_code": {Redactable: true}}
+
+	b.Run("uncached", func(b *testing.B) {
+		p := NewProducer("bench", "localhost")
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			p.redactData("order.placed", data, annotations)
+		}
+	})
+	b.Run("cold", func(b *testing.B) {
+		p := NewProducer("bench", "localhost")
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			p.redactionCache = newRedactionCache(1024)
+			b.StartTimer()
+			p.redactData("order.placed", data, annotations)
+		}
+	})
+	b.Run("warm", func(b *testing.B) {
+		p := NewProducer("bench", "localhost", WithRedactionCacheSize(1024))
+		p.redactData("order.placed", data, annotations)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			p.redactData("order.placed", data, annotations)
+		}
+	})
+}
+
+// TestRedactionCacheSkipsMatching checks that fields of a shape seen before are decided
+// from the cache, without matching their names again
+func TestRedactionCacheSkipsMatching(t *testing.T) {
+	detector := *defaultPIIDetector
+	p := NewProducer("cache", "localhost", WithRedactionCacheSize(64))
+	p.piiDetector = &detector
+
+	data := map[string]interface{}{"customer_email": "alice", "status": "active"}
+	first := p.redactData("order.placed", data, nil)
+	if first["customer_email"] != p.redactor.mask() || first["status"] != "active" {
+		t.Fatalf("first redaction = %v", first)
+	}
+
+	// A matcher that matches nothing: only cached decisions still redact the field
+	detector.fields = newKeywordMatcher(nil)
+	if cached := p.redactData("order.placed", data, nil); cached["customer_email"] != p.redactor.mask() {
+		t.Errorf("customer_email was matched again instead of decided from the cache: %v", cached)
+	}
+	if other := p.redactData("order.shipped", data, nil); other["customer_email"] != "alice" {
+		t.Errorf("customer_email of another event type was decided from the cache: %v", other)
+	}
+}
@@ -0,0 +1,177 @@
+package lifecycle
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// RedisCommandStartedEvent represents a redis.command.started event
+// Only the command name and key pattern are recorded; argument values never are
+type RedisCommandStartedEvent struct {
+	Base       *BaseEvent `json:"base"`
+	CommandID  string     `json:"command_id"`
+	Command    string     `json:"command"`               // Lowercase command name (e.g., "get")
+	KeyPattern string     `json:"key_pattern,omitempty"` // See RedisKeyPattern
+}
+
+func (e *RedisCommandStartedEvent) GetEventType() string     { return e.Base.GetEventType() }
+func (e *RedisCommandStartedEvent) GetTimestamp() time.Time  { return e.Base.GetTimestamp() }
+func (e *RedisCommandStartedEvent) GetService() string       { return e.Base.GetService() }
+func (e *RedisCommandStartedEvent) GetAPI() string           { return e.Base.GetAPI() }
+func (e *RedisCommandStartedEvent) GetHost() string          { return e.Base.GetHost() }
+func (e *RedisCommandStartedEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *RedisCommandStartedEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *RedisCommandStartedEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *RedisCommandStartedEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
+
+// RedisCommandCompletedEvent represents a redis.command.completed event
+type RedisCommandCompletedEvent struct {
+	Base       *BaseEvent `json:"base"`
+	CommandID  string     `json:"command_id"`
+	Command    string     `json:"command"`
+	KeyPattern string     `json:"key_pattern,omitempty"`
+	Miss       bool       `json:"miss,omitempty"` // The key did not exist (a nil reply)
+	DurationMs int64      `json:"duration_ms"`
+}
+
+func (e *RedisCommandCompletedEvent) GetEventType() string     { return e.Base.GetEventType() }
+func (e *RedisCommandCompletedEvent) GetTimestamp() time.Time  { return e.Base.GetTimestamp() }
+func (e *RedisCommandCompletedEvent) GetService() string       { return e.Base.GetService() }
+func (e *RedisCommandCompletedEvent) GetAPI() string           { return e.Base.GetAPI() }
+func (e *RedisCommandCompletedEvent) GetHost() string          { return e.Base.GetHost() }
+func (e *RedisCommandCompletedEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *RedisCommandCompletedEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *RedisCommandCompletedEvent) GetMetadata() map[string]interface{} {
+	return e.Base.GetMetadata()
+}
+func (e *RedisCommandCompletedEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
+
+// RedisCommandErroredEvent represents a redis.command.errored event
+type RedisCommandErroredEvent struct {
+	Base         *BaseEvent `json:"base"`
+	CommandID    string     `json:"command_id"`
+	Command      string     `json:"command"`
+	KeyPattern   string     `json:"key_pattern,omitempty"`
+	Status       Status     `json:"status"`
+	ErrorMessage string     `json:"error_message"`
+	DurationMs   int64      `json:"duration_ms"`
+}
+
+func (e *RedisCommandErroredEvent) GetEventType() string     { return e.Base.GetEventType() }
+func (e *RedisCommandErroredEvent) GetTimestamp() time.Time  { return e.Base.GetTimestamp() }
+func (e *RedisCommandErroredEvent) GetService() string       { return e.Base.GetService() }
+func (e *RedisCommandErroredEvent) GetAPI() string           { return e.Base.GetAPI() }
+func (e *RedisCommandErroredEvent) GetHost() string          { return e.Base.GetHost() }
+func (e *RedisCommandErroredEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *RedisCommandErroredEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *RedisCommandErroredEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *RedisCommandErroredEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
+
+// EmitRedisCommandStarted emits a redis.command.started event
+func (p *Producer) EmitRedisCommandStarted(ctx context.Context, commandID, command, keyPattern string, opts ...EmitOption) error {
+	b := builderBase{producer: p}
+	b.applyEmitOptions(opts)
+	event := &RedisCommandStartedEvent{
+		Base:       b.createBase("redis.command.started", nil),
+		CommandID:  commandID,
+		Command:    command,
+		KeyPattern: keyPattern,
+	}
+	return b.emit(ctx, event.Base, event, 0)
+}
+
+// EmitRedisCommandCompleted emits a redis.command.completed event
+func (p *Producer) EmitRedisCommandCompleted(ctx context.Context, commandID, command, keyPattern string, miss bool,
+	durationMs int64, opts ...EmitOption) error {
+	b := builderBase{producer: p}
+	b.applyEmitOptions(opts)
+	event := &RedisCommandCompletedEvent{
+		Base:       b.createBase("redis.command.completed", nil),
+		CommandID:  commandID,
+		Command:    command,
+		KeyPattern: keyPattern,
+		Miss:       miss,
+		DurationMs: durationMs,
+	}
+	return b.emit(ctx, event.Base, event, time.Duration(durationMs)*time.Millisecond)
+}
+
+// EmitRedisCommandErrored emits a redis.command.errored event
+func (p *Producer) EmitRedisCommandErrored(ctx context.Context, commandID, command, keyPattern, errorMessage string,
+	durationMs int64, opts ...EmitOption) error {
+	b := builderBase{producer: p}
+	b.applyEmitOptions(opts)
+	event := &RedisCommandErroredEvent{
+		Base:         b.createBase("redis.command.errored", nil),
+		CommandID:    commandID,
+		Command:      command,
+		KeyPattern:   keyPattern,
+		Status:       StatusError,
+		ErrorMessage: errorMessage,
+		DurationMs:   durationMs,
+	}
+	return b.emit(ctx, event.Base, event, time.Duration(durationMs)*time.Millisecond)
+}
+
+// RedisKeyPattern reduces a key to a low-cardinality pattern by replacing the segments
+// (separated by ':' or '/') that look like identifiers with '*':
+// numbers, UUIDs, long hex strings, and anything with '@'
+// "user:42:session" becomes "user:*:session"
+func RedisKeyPattern(key string) string {
+	var b strings.Builder
+	b.Grow(len(key))
+	start := 0
+	for i := 0; i <= len(key); i++ {
+		if i < len(key) && key[i] != ':' && key[i] != '/' {
+			continue
+		}
+		segment := key[start:i]
+		if isIdentifierSegment(segment) {
+			b.WriteByte('*')
+		} else {
+			b.WriteString(segment)
+		}
+		if i < len(key) {
+			b.WriteByte(key[i])
+		}
+		start = i + 1
+	}
+	return b.String()
+}
+
+// isIdentifierSegment reports whether a key segment looks like a per-entity value
+func isIdentifierSegment(segment string) bool {
+	if segment == "" {
+		return false
+	}
+	if strings.Contains(segment, "@") {
+		return true
+	}
+	digits, hex := 0, 0
+	for i := 0; i < len(segment); i++ {
+		c := segment[i]
+		switch {
+		case isDigit(c):
+			digits++
+			hex++
+		case (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F') || c == '-':
+			hex++
+		}
+	}
+	if digits == len(segment) {
+		return true
+	}
+	// UUIDs and hashes
+	return hex == len(segment) && len(segment) >= 16 && digits > 0
+}
@@ -0,0 +1,320 @@
+package lifecycle
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Report summarizes an event stream: volumes, error rates, latencies, and slow queries
+type Report struct {
+	Events         int              // Total number of events
+	Start          time.Time        // Timestamp of the earliest event
+	End            time.Time        // Timestamp of the latest event
+	EventTypes     []EventTypeCount // Event counts by type, most frequent first
+	Endpoints      []EndpointStats  // Request statistics by endpoint, busiest first
+	SlowestQueries []QueryStats     // Slowest completed queries, slowest first
+}
+
+// EventTypeCount is the number of events of a given type
+type EventTypeCount struct {
+	EventType string
+	Count     int
+}
+
+// EndpointStats summarizes the requests for one endpoint
+// The endpoint is "METHOD route" from api.request.received (or "METHOD path" for events
+// recorded without a route), falling back to the API identifier
+type EndpointStats struct {
+	Endpoint  string
+	Requests  int
+	Errors    int
+	ErrorRate float64 // Errors / Requests
+	P50Ms     float64
+	P95Ms     float64
+	P99Ms     float64
+	MaxMs     float64
+}
+
+// QueryStats describes a single completed query
+type QueryStats struct {
+	QueryID       string
+	Query         string
+	CorrelationID string
+	DurationMs    float64
+	RowsAffected  float64
+}
+
+// ReportOptions configures report generation
+type ReportOptions struct {
+	SlowQueryLimit int // Number of slowest queries to include (default: 10)
+}
+
+// BuildReport reads an NDJSON event stream and computes a Report
+func BuildReport(r io.Reader, opts ReportOptions) (*Report, error) {
+	if opts.SlowQueryLimit <= 0 {
+		opts.SlowQueryLimit = 10
+	}
+
+	report := &Report{}
+	eventTypes := make(map[string]int)
+	endpoints := make(endpointResolver)
+	queryText := make(map[string]string)
+	type endpointAccumulator struct {
+		requests  int
+		errors    int
+		durations []float64
+	}
+	accumulators := make(map[string]*endpointAccumulator)
+	var queries []QueryStats
+
+	accumulator := func(endpoint string) *endpointAccumulator {
+		acc, ok := accumulators[endpoint]
+		if !ok {
+			acc = &endpointAccumulator{}
+			accumulators[endpoint] = acc
+		}
+		return acc
+	}
+
+	err := ReadRecords(r, func(record *Record) error {
+		report.Events++
+		eventTypes[record.EventType]++
+		if !record.Timestamp.IsZero() {
+			if report.Start.IsZero() || record.Timestamp.Before(report.Start) {
+				report.Start = record.Timestamp
+			}
+			if record.Timestamp.After(report.End) {
+				report.End = record.Timestamp
+			}
+		}
+
+		switch record.EventType {
+		case "api.request.received":
+			endpoints.observe(record)
+		case "api.request.handled", "api.request.errored":
+			acc := accumulator(endpoints.endpoint(record))
+			acc.requests++
+			if record.EventType == "api.request.errored" {
+				acc.errors++
+			}
+			if duration, ok := record.NumberField("duration_ms"); ok {
+				acc.durations = append(acc.durations, duration)
+			}
+		case "db.query.started":
+			queryText[record.StringField("query_id")] = record.StringField("query")
+		case "db.query.completed":
+			duration, _ := record.NumberField("duration_ms")
+			rows, _ := record.NumberField("rows_affected")
+			queryID := record.StringField("query_id")
+			queries = append(queries, QueryStats{
+				QueryID:       queryID,
+				Query:         queryText[queryID],
+				CorrelationID: record.CorrelationID,
+				DurationMs:    duration,
+				RowsAffected:  rows,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for eventType, count := range eventTypes {
+		report.EventTypes = append(report.EventTypes, EventTypeCount{EventType: eventType, Count: count})
+	}
+	sort.Slice(report.EventTypes, func(i, j int) bool {
+		if report.EventTypes[i].Count != report.EventTypes[j].Count {
+			return report.EventTypes[i].Count > report.EventTypes[j].Count
+		}
+		return report.EventTypes[i].EventType < report.EventTypes[j].EventType
+	})
+
+	for endpoint, acc := range accumulators {
+		stats := EndpointStats{
+			Endpoint: endpoint,
+			Requests: acc.requests,
+			Errors:   acc.errors,
+		}
+		if acc.requests > 0 {
+			stats.ErrorRate = float64(acc.errors) / float64(acc.requests)
+		}
+		sort.Float64s(acc.durations)
+		stats.P50Ms = Percentile(acc.durations, 50)
+		stats.P95Ms = Percentile(acc.durations, 95)
+		stats.P99Ms = Percentile(acc.durations, 99)
+		if len(acc.durations) > 0 {
+			stats.MaxMs = acc.durations[len(acc.durations)-1]
+		}
+		report.Endpoints = append(report.Endpoints, stats)
+	}
+	sort.Slice(report.Endpoints, func(i, j int) bool {
+		if report.Endpoints[i].Requests != report.Endpoints[j].Requests {
+			return report.Endpoints[i].Requests > report.Endpoints[j].Requests
+		}
+		return report.Endpoints[i].Endpoint < report.Endpoints[j].Endpoint
+	})
+
+	sort.SliceStable(queries, func(i, j int) bool { return queries[i].DurationMs > queries[j].DurationMs })
+	if len(queries) > opts.SlowQueryLimit {
+		queries = queries[:opts.SlowQueryLimit]
+	}
+	report.SlowestQueries = queries
+
+	return report, nil
+}
+
+// endpointResolver names the endpoints of completed requests after the
+// api.request.received events that share their correlation ID
+type endpointResolver map[string]string
+
+// observe remembers the endpoint of an api.request.received event
+func (e endpointResolver) observe(record *Record) {
+	if record.CorrelationID == "" {
+		return
+	}
+	path := record.StringField("route")
+	if path == "" {
+		path = record.StringField("path")
+	}
+	e[record.CorrelationID] = strings.TrimSpace(record.StringField("method") + " " + path)
+}
+
+// endpoint returns the endpoint of a request event, as described on EndpointStats
+func (e endpointResolver) endpoint(record *Record) string {
+	if endpoint, ok := e[record.CorrelationID]; ok && record.CorrelationID != "" {
+		return endpoint
+	}
+	if record.API != "" {
+		return record.API
+	}
+	return "(unknown)"
+}
+
+// Percentile returns the p-th percentile (0-100) of sorted values using linear interpolation
+// values must be sorted in ascending order; an empty slice returns 0
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	if p <= 0 {
+		return sorted[0]
+	}
+	if p >= 100 {
+		return sorted[len(sorted)-1]
+	}
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	if lower == upper {
+		return sorted[lower]
+	}
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+// WriteMarkdown writes the report as Markdown
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+
+	b.WriteString("# Lifecycle Event Report\n\n")
+	fmt.Fprintf(&b, "- **Events:** %d\n", r.Events)
+	if !r.Start.IsZero() {
+		fmt.Fprintf(&b, "- **Window:** %s to %s (%s)\n", r.Start.Format(time.RFC3339), r.End.Format(time.RFC3339), r.End.Sub(r.Start))
+	}
+
+	b.WriteString("\n## Event Types\n\n")
+	b.WriteString("| Event Type | Count |\n|---|---:|\n")
+	for _, et := range r.EventTypes {
+		fmt.Fprintf(&b, "| `%s` | %d |\n", et.EventType, et.Count)
+	}
+
+	b.WriteString("\n## Endpoints\n\n")
+	if len(r.Endpoints) == 0 {
+		b.WriteString("No requests recorded.\n")
+	} else {
+		b.WriteString("| Endpoint | Requests | Errors | Error Rate | p50 (ms) | p95 (ms) | p99 (ms) | Max (ms) |\n")
+		b.WriteString("|---|---:|---:|---:|---:|---:|---:|---:|\n")
+		for _, e := range r.Endpoints {
+			fmt.Fprintf(&b, "| `%s` | %d | %d | %.2f%% | %.1f | %.1f | %.1f | %.1f |\n",
+				markdownEscape(e.Endpoint), e.Requests, e.Errors, e.ErrorRate*100, e.P50Ms, e.P95Ms, e.P99Ms, e.MaxMs)
+		}
+	}
+
+	b.WriteString("\n## Slowest Queries\n\n")
+	if len(r.SlowestQueries) == 0 {
+		b.WriteString("No queries recorded.\n")
+	} else {
+		b.WriteString("| Duration (ms) | Rows | Query | Correlation ID |\n|---:|---:|---|---|\n")
+		for _, q := range r.SlowestQueries {
+			query := q.Query
+			if query == "" {
+				query = q.QueryID
+			}
+			fmt.Fprintf(&b, "| %.1f | %.0f | `%s` | %s |\n", q.DurationMs, q.RowsAffected, markdownEscape(query), q.CorrelationID)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownEscape makes s safe for use inside a Markdown table cell
+func markdownEscape(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+// reportHTMLTemplate renders a Report as a standalone HTML page
+var reportHTMLTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"percent": func(f float64) string { return fmt.Sprintf("%.2f%%", f*100) },
+	"ms":      func(f float64) string { return fmt.Sprintf("%.1f", f) },
+	"rfc3339": func(t time.Time) string { return t.Format(time.RFC3339) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Lifecycle Event Report</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; margin: 2rem; color: #1f2937; }
+table { border-collapse: collapse; margin-bottom: 2rem; }
+th, td { border: 1px solid #e5e7eb; padding: 0.35rem 0.75rem; text-align: left; }
+th { background: #f3f4f6; }
+td.num { text-align: right; font-variant-numeric: tabular-nums; }
+code { font-size: 0.9em; }
+</style>
+</head>
+<body>
+<h1>Lifecycle Event Report</h1>
+<p><strong>Events:</strong> {{.Events}}{{if not .Start.IsZero}} &middot; <strong>Window:</strong> {{rfc3339 .Start}} to {{rfc3339 .End}}{{end}}</p>
+
+<h2>Event Types</h2>
+<table>
+<tr><th>Event Type</th><th>Count</th></tr>
+{{range .EventTypes}}<tr><td><code>{{.EventType}}</code></td><td class="num">{{.Count}}</td></tr>
+{{end}}</table>
+
+<h2>Endpoints</h2>
+{{if .Endpoints}}<table>
+<tr><th>Endpoint</th><th>Requests</th><th>Errors</th><th>Error Rate</th><th>p50 (ms)</th><th>p95 (ms)</th><th>p99 (ms)</th><th>Max (ms)</th></tr>
+{{range .Endpoints}}<tr><td><code>{{.Endpoint}}</code></td><td class="num">{{.Requests}}</td><td class="num">{{.Errors}}</td><td class="num">{{percent .ErrorRate}}</td><td class="num">{{ms .P50Ms}}</td><td class="num">{{ms .P95Ms}}</td><td class="num">{{ms .P99Ms}}</td><td class="num">{{ms .MaxMs}}</td></tr>
+{{end}}</table>{{else}}<p>No requests recorded.</p>{{end}}
+
+<h2>Slowest Queries</h2>
+{{if .SlowestQueries}}<table>
+<tr><th>Duration (ms)</th><th>Rows</th><th>Query</th><th>Correlation ID</th></tr>
+{{range .SlowestQueries}}<tr><td class="num">{{ms .DurationMs}}</td><td class="num">{{printf "%.0f" .RowsAffected}}</td><td><code>{{if .Query}}{{.Query}}{{else}}{{.QueryID}}{{end}}</code></td><td>{{.CorrelationID}}</td></tr>
+{{end}}</table>{{else}}<p>No queries recorded.</p>{{end}}
+</body>
+</html>
+`))
+
+// WriteHTML writes the report as a standalone HTML page
+func (r *Report) WriteHTML(w io.Writer) error {
+	return reportHTMLTemplate.Execute(w, r)
+}
@@ -0,0 +1,217 @@
+package lifecycle
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Default regression thresholds of DiffReports
+const (
+	DefaultLatencyRegression   = 0.2  // p95 or p99 latency 20% higher
+	DefaultErrorRateRegression = 0.01 // Error rate one percentage point higher
+	DefaultDiffMinRequests     = 10
+)
+
+// ReportDiff compares the reports of two runs, e.g., before and after a deploy
+type ReportDiff struct {
+	Before      *Report
+	After       *Report
+	EventTypes  []EventTypeDiff // Largest change in share first
+	Endpoints   []EndpointDiff  // Busiest first, by requests in both runs
+	Regressions []string        // Changes past the thresholds, worst first
+}
+
+// EventTypeDiff compares the volume of one event type
+type EventTypeDiff struct {
+	EventType   string
+	Before      int
+	After       int
+	BeforeShare float64 // Fraction of all events in the run before
+	AfterShare  float64
+}
+
+// EndpointDiff compares one endpoint's requests; an endpoint missing from a run has zero
+// stats for it
+type EndpointDiff struct {
+	Endpoint string
+	Before   EndpointStats
+	After    EndpointStats
+}
+
+// DiffOptions configures what DiffReports counts as a regression
+type DiffOptions struct {
+	LatencyThreshold   float64 // Relative p95/p99 increase (default: DefaultLatencyRegression)
+	ErrorRateThreshold float64 // Absolute error rate increase (default: DefaultErrorRateRegression)
+	MinRequests        int     // Requests an endpoint needs in both runs to be judged (default: DefaultDiffMinRequests)
+}
+
+// DiffReports compares two runs' reports: their event type distributions, and each
+// endpoint's error rate and latency percentiles, listing the regressions
+func DiffReports(before, after *Report, opts DiffOptions) *ReportDiff {
+	if opts.LatencyThreshold <= 0 {
+		opts.LatencyThreshold = DefaultLatencyRegression
+	}
+	if opts.ErrorRateThreshold <= 0 {
+		opts.ErrorRateThreshold = DefaultErrorRateRegression
+	}
+	if opts.MinRequests <= 0 {
+		opts.MinRequests = DefaultDiffMinRequests
+	}
+	diff := &ReportDiff{Before: before, After: after}
+
+	eventTypes := make(map[string]*EventTypeDiff)
+	eventType := func(name string) *EventTypeDiff {
+		d, ok := eventTypes[name]
+		if !ok {
+			d = &EventTypeDiff{EventType: name}
+			eventTypes[name] = d
+		}
+		return d
+	}
+	for _, et := range before.EventTypes {
+		d := eventType(et.EventType)
+		d.Before = et.Count
+		d.BeforeShare = share(et.Count, before.Events)
+	}
+	for _, et := range after.EventTypes {
+		d := eventType(et.EventType)
+		d.After = et.Count
+		d.AfterShare = share(et.Count, after.Events)
+	}
+	for _, d := range eventTypes {
+		diff.EventTypes = append(diff.EventTypes, *d)
+	}
+	sort.Slice(diff.EventTypes, func(i, j int) bool {
+		a, b := diff.EventTypes[i], diff.EventTypes[j]
+		if change := math.Abs(a.AfterShare-a.BeforeShare) - math.Abs(b.AfterShare-b.BeforeShare); change != 0 {
+			return change > 0
+		}
+		return a.EventType < b.EventType
+	})
+
+	endpoints := make(map[string]*EndpointDiff)
+	endpoint := func(name string) *EndpointDiff {
+		d, ok := endpoints[name]
+		if !ok {
+			d = &EndpointDiff{Endpoint: name}
+			endpoints[name] = d
+		}
+		return d
+	}
+	for _, e := range before.Endpoints {
+		endpoint(e.Endpoint).Before = e
+	}
+	for _, e := range after.Endpoints {
+		endpoint(e.Endpoint).After = e
+	}
+	for _, d := range endpoints {
+		diff.Endpoints = append(diff.Endpoints, *d)
+	}
+	sort.Slice(diff.Endpoints, func(i, j int) bool {
+		a, b := diff.Endpoints[i], diff.Endpoints[j]
+		if requestsA, requestsB := a.Before.Requests+a.After.Requests, b.Before.Requests+b.After.Requests; requestsA != requestsB {
+			return requestsA > requestsB
+		}
+		return a.Endpoint < b.Endpoint
+	})
+
+	// Regressions are ranked by how far past its threshold each one is
+	type regression struct {
+		message  string
+		severity float64
+	}
+	var regressions []regression
+	for _, d := range diff.Endpoints {
+		if d.Before.Requests < opts.MinRequests || d.After.Requests < opts.MinRequests {
+			continue
+		}
+		if increase := d.After.ErrorRate - d.Before.ErrorRate; increase >= opts.ErrorRateThreshold {
+			regressions = append(regressions, regression{
+				message: fmt.Sprintf("%s: error rate rose from %.2f%% to %.2f%%",
+					d.Endpoint, d.Before.ErrorRate*100, d.After.ErrorRate*100),
+				severity: increase / opts.ErrorRateThreshold,
+			})
+		}
+		for _, p := range []struct {
+			name          string
+			before, after float64
+		}{{"p95", d.Before.P95Ms, d.After.P95Ms}, {"p99", d.Before.P99Ms, d.After.P99Ms}} {
+			if p.before <= 0 || p.after-p.before < 1 {
+				continue // Sub-millisecond changes are noise
+			}
+			if increase := p.after/p.before - 1; increase >= opts.LatencyThreshold {
+				regressions = append(regressions, regression{
+					message: fmt.Sprintf("%s: %s latency rose from %.1fms to %.1fms (+%.0f%%)",
+						d.Endpoint, p.name, p.before, p.after, increase*100),
+					severity: increase / opts.LatencyThreshold,
+				})
+			}
+		}
+	}
+	sort.SliceStable(regressions, func(i, j int) bool { return regressions[i].severity > regressions[j].severity })
+	for _, r := range regressions {
+		diff.Regressions = append(diff.Regressions, r.message)
+	}
+	return diff
+}
+
+// share returns count as a fraction of total
+func share(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(count) / float64(total)
+}
+
+// WriteMarkdown writes the comparison as Markdown, regressions first
+func (d *ReportDiff) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+
+	b.WriteString("# Lifecycle Event Diff\n\n")
+	fmt.Fprintf(&b, "- **Events:** %d before, %d after\n", d.Before.Events, d.After.Events)
+
+	b.WriteString("\n## Regressions\n\n")
+	if len(d.Regressions) == 0 {
+		b.WriteString("No regressions found.\n")
+	}
+	for _, r := range d.Regressions {
+		fmt.Fprintf(&b, "- %s\n", markdownEscape(r))
+	}
+
+	b.WriteString("\n## Endpoints\n\n")
+	if len(d.Endpoints) == 0 {
+		b.WriteString("No requests recorded.\n")
+	} else {
+		b.WriteString("| Endpoint | Requests | Error Rate | p50 (ms) | p95 (ms) | p99 (ms) |\n")
+		b.WriteString("|---|---:|---:|---:|---:|---:|\n")
+		for _, e := range d.Endpoints {
+			fmt.Fprintf(&b, "| `%s` | %d → %d | %.2f%% → %.2f%% | %s | %s | %s |\n",
+				markdownEscape(e.Endpoint), e.Before.Requests, e.After.Requests,
+				e.Before.ErrorRate*100, e.After.ErrorRate*100,
+				latencyChange(e.Before.P50Ms, e.After.P50Ms),
+				latencyChange(e.Before.P95Ms, e.After.P95Ms),
+				latencyChange(e.Before.P99Ms, e.After.P99Ms))
+		}
+	}
+
+	b.WriteString("\n## Event Types\n\n")
+	b.WriteString("| Event Type | Before | After | Share Change |\n|---|---:|---:|---:|\n")
+	for _, et := range d.EventTypes {
+		fmt.Fprintf(&b, "| `%s` | %d | %d | %+.2f pp |\n",
+			et.EventType, et.Before, et.After, (et.AfterShare-et.BeforeShare)*100)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// latencyChange formats a latency change for a table cell, e.g., "12.0 → 15.0 (+25%)"
+func latencyChange(before, after float64) string {
+	if before <= 0 {
+		return fmt.Sprintf("%.1f → %.1f", before, after)
+	}
+	return fmt.Sprintf("%.1f → %.1f (%+.0f%%)", before, after, (after/before-1)*100)
+}
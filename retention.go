@@ -0,0 +1,54 @@
+package lifecycle
+
+import "time"
+
+// RetentionClass tells downstream storage how long to keep an event, so debug chatter and
+// audit trails can be routed to different tiers; it is written as base.retention
+type RetentionClass string
+
+const (
+	RetentionDebug    RetentionClass = "debug"    // Troubleshooting detail, kept about 7 days
+	RetentionStandard RetentionClass = "standard" // Operational events, kept about 30 days
+	RetentionAudit    RetentionClass = "audit"    // Compliance records, kept about 7 years
+)
+
+// Period returns the suggested retention period of the class, or 0 for classes it does
+// not know; storage tiers are free to apply their own
+func (c RetentionClass) Period() time.Duration {
+	switch c {
+	case RetentionDebug:
+		return 7 * 24 * time.Hour
+	case RetentionStandard:
+		return 30 * 24 * time.Hour
+	case RetentionAudit:
+		return 7 * 365 * 24 * time.Hour
+	}
+	return 0
+}
+
+// retentionRule assigns a retention class to the event types matching a pattern
+type retentionRule struct {
+	eventType string
+	class     RetentionClass
+}
+
+// WithRetention sets the retention class of events whose type matches eventType, a
+// path.Match pattern (e.g., "resource.*" or "db.query.*"); "*" matches every event
+// Rules are evaluated in the order they are added and the first match decides, so
+// specific families go before a catch-all. Events matching no rule have no retention class
+// unless WithEventRetention sets one
+func WithRetention(eventType string, class RetentionClass) ProducerOption {
+	return func(p *Producer) {
+		p.retention = append(p.retention, retentionRule{eventType: eventType, class: class})
+	}
+}
+
+// retentionClass returns the retention class of eventType events
+func (p *Producer) retentionClass(eventType string) RetentionClass {
+	for _, rule := range p.retention {
+		if matchPattern(rule.eventType, eventType) {
+			return rule.class
+		}
+	}
+	return ""
+}
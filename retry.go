@@ -0,0 +1,229 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy configures Retry
+// Zero fields take the values from DefaultRetryPolicy
+type RetryPolicy struct {
+	MaxAttempts  int           // Total attempts, including the first
+	InitialDelay time.Duration // Delay before the first retry
+	MaxDelay     time.Duration // Upper bound on any delay
+	Multiplier   float64       // Growth factor between delays
+	Jitter       float64       // Fraction of each delay that is randomized (0 to 1)
+
+	// Operation names what is retried. When empty, retries are reported as
+	// api.request.retried events; otherwise as operation.retried events
+	Operation string
+
+	// Retryable reports whether an error should be retried (default: every error
+	// except those wrapped with Permanent)
+	Retryable func(err error) bool
+}
+
+// DefaultRetryPolicy returns a policy of 3 attempts with exponential backoff from
+// 100ms to at most 10s and 20% jitter
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:  3,
+		InitialDelay: 100 * time.Millisecond,
+		MaxDelay:     10 * time.Second,
+		Multiplier:   2,
+		Jitter:       0.2,
+	}
+}
+
+// withDefaults fills zero fields from DefaultRetryPolicy
+func (r RetryPolicy) withDefaults() RetryPolicy {
+	d := DefaultRetryPolicy()
+	if r.MaxAttempts <= 0 {
+		r.MaxAttempts = d.MaxAttempts
+	}
+	if r.InitialDelay <= 0 {
+		r.InitialDelay = d.InitialDelay
+	}
+	if r.MaxDelay <= 0 {
+		r.MaxDelay = d.MaxDelay
+	}
+	if r.Multiplier <= 0 {
+		r.Multiplier = d.Multiplier
+	}
+	return r
+}
+
+// delay returns the jittered backoff before retry number attempt (1-based)
+func (r RetryPolicy) delay(attempt int) time.Duration {
+	delay := float64(r.InitialDelay)
+	for i := 1; i < attempt; i++ {
+		delay *= r.Multiplier
+		if delay >= float64(r.MaxDelay) {
+			delay = float64(r.MaxDelay)
+			break
+		}
+	}
+	if r.Jitter > 0 {
+		delay += delay * r.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(min(delay, float64(r.MaxDelay)))
+}
+
+// permanentError marks an error that must not be retried
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so Retry returns it immediately instead of retrying
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// OperationRetriedEvent represents an operation.retried event
+type OperationRetriedEvent struct {
+	Base        *BaseEvent `json:"base"`
+	Operation   string     `json:"operation"`
+	RetryCount  int32      `json:"retry_count"`
+	DelayMs     int64      `json:"delay_ms"`
+	RetryReason string     `json:"retry_reason,omitempty"`
+}
+
+func (e *OperationRetriedEvent) GetEventType() string     { return e.Base.GetEventType() }
+func (e *OperationRetriedEvent) GetTimestamp() time.Time  { return e.Base.GetTimestamp() }
+func (e *OperationRetriedEvent) GetService() string       { return e.Base.GetService() }
+func (e *OperationRetriedEvent) GetAPI() string           { return e.Base.GetAPI() }
+func (e *OperationRetriedEvent) GetHost() string          { return e.Base.GetHost() }
+func (e *OperationRetriedEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *OperationRetriedEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *OperationRetriedEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *OperationRetriedEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
+
+// RetryOutcomeEvent represents an operation.retry_succeeded or operation.retry_failed
+// event, emitted when an operation that was retried at least once finishes
+type RetryOutcomeEvent struct {
+	Base         *BaseEvent `json:"base"`
+	Operation    string     `json:"operation,omitempty"`
+	Status       Status     `json:"status"`
+	Attempts     int32      `json:"attempts"`
+	ErrorMessage string     `json:"error_message,omitempty"`
+	DurationMs   int64      `json:"duration_ms"`
+}
+
+func (e *RetryOutcomeEvent) GetEventType() string     { return e.Base.GetEventType() }
+func (e *RetryOutcomeEvent) GetTimestamp() time.Time  { return e.Base.GetTimestamp() }
+func (e *RetryOutcomeEvent) GetService() string       { return e.Base.GetService() }
+func (e *RetryOutcomeEvent) GetAPI() string           { return e.Base.GetAPI() }
+func (e *RetryOutcomeEvent) GetHost() string          { return e.Base.GetHost() }
+func (e *RetryOutcomeEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *RetryOutcomeEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *RetryOutcomeEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *RetryOutcomeEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
+
+// Retry calls fn until it succeeds, returns a Permanent or non-retryable error, the
+// policy's attempts are used up, or ctx is done, waiting with exponential backoff and
+// jitter between attempts
+// Each retry emits api.request.retried (or operation.retried when policy.Operation is
+// set) with the retry number, delay, and error that caused it; if any retry happened,
+// operation.retry_succeeded or operation.retry_failed records the final outcome
+// The correlation ID is taken from ctx; the events are best-effort, so a failed emit
+// neither stops the retries nor changes the returned error
+// When ctx is done while waiting, the returned error wraps both ctx.Err() and the
+// error of the last attempt
+func Retry(ctx context.Context, producer *Producer, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	policy = policy.withDefaults()
+	start := time.Now()
+
+	var err error
+	attempt := 1
+	for ; ; attempt++ {
+		err = fn(ctx)
+		if err == nil || attempt >= policy.MaxAttempts || !policy.retryable(err) {
+			break
+		}
+
+		delay := policy.delay(attempt)
+		_ = producer.emitRetried(ctx, policy.Operation, int32(attempt), delay, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		if ctx.Err() != nil {
+			err = fmt.Errorf("%w (last error: %w)", ctx.Err(), err)
+			break
+		}
+	}
+
+	var permanent *permanentError
+	if errors.As(err, &permanent) {
+		err = permanent.err
+	}
+	if attempt > 1 {
+		_ = producer.emitRetryOutcome(ctx, policy.Operation, int32(attempt), time.Since(start), err)
+	}
+	return err
+}
+
+// retryable reports whether err may be retried under the policy
+func (r RetryPolicy) retryable(err error) bool {
+	var permanent *permanentError
+	if errors.As(err, &permanent) {
+		return false
+	}
+	if r.Retryable != nil {
+		return r.Retryable(err)
+	}
+	return true
+}
+
+// emitRetried emits the event for one retry
+func (p *Producer) emitRetried(ctx context.Context, operation string, retryCount int32, delay time.Duration, cause error) error {
+	if operation == "" {
+		return p.EmitRequestRetried(ctx, extractCorrelationID(ctx), retryCount, delay.Milliseconds(), cause.Error())
+	}
+
+	event := &OperationRetriedEvent{
+		Base:        p.createBaseEvent("operation.retried", extractCorrelationID(ctx), nil),
+		Operation:   operation,
+		RetryCount:  retryCount,
+		DelayMs:     delay.Milliseconds(),
+		RetryReason: cause.Error(),
+	}
+	return p.emitEvent(ctx, event, 0)
+}
+
+// emitRetryOutcome emits the final outcome of a retried operation
+func (p *Producer) emitRetryOutcome(ctx context.Context, operation string, attempts int32, duration time.Duration, err error) error {
+	eventType := "operation.retry_succeeded"
+	event := &RetryOutcomeEvent{
+		Operation:  operation,
+		Status:     StatusSuccess,
+		Attempts:   attempts,
+		DurationMs: duration.Milliseconds(),
+	}
+	if err != nil {
+		eventType = "operation.retry_failed"
+		event.Status = StatusError
+		event.ErrorMessage = err.Error()
+	}
+	event.Base = p.createBaseEvent(eventType, extractCorrelationID(ctx), nil)
+	return p.emitEvent(context.WithoutCancel(ctx), event, duration)
+}
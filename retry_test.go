@@ -0,0 +1,55 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// TestRetryEmitFailure checks that events that cannot be emitted neither stop the
+// retries nor replace the result of fn
+func TestRetryEmitFailure(t *testing.T) {
+	p := NewProducer("retry", "localhost", WithOutput(io.Discard))
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	policy := RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond}
+
+	failed := errors.New("failed")
+	attempts := 0
+	err := Retry(context.Background(), p, policy, func(context.Context) error {
+		attempts++
+		return failed
+	})
+	if !errors.Is(err, failed) || attempts != 3 {
+		t.Errorf("Retry = %v after %d attempts, want %v after 3", err, attempts, failed)
+	}
+
+	attempts = 0
+	err = Retry(context.Background(), p, policy, func(context.Context) error {
+		if attempts++; attempts < 2 {
+			return failed
+		}
+		return nil
+	})
+	if err != nil || attempts != 2 {
+		t.Errorf("Retry = %v after %d attempts, want nil after 2", err, attempts)
+	}
+}
+
+// TestRetryCanceled checks that cancelling ctx during the backoff returns both the
+// cancellation and the error of the last attempt
+func TestRetryCanceled(t *testing.T) {
+	p := NewProducer("retry", "localhost", WithOutput(io.Discard))
+	ctx, cancel := context.WithCancel(context.Background())
+	failed := errors.New("failed")
+	err := Retry(ctx, p, RetryPolicy{MaxAttempts: 3, InitialDelay: time.Hour}, func(context.Context) error {
+		cancel()
+		return failed
+	})
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, failed) {
+		t.Errorf("Retry = %v, want it to wrap %v and %v", err, context.Canceled, failed)
+	}
+}
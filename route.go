@@ -0,0 +1,203 @@
+package lifecycle
+
+import (
+	"context"
+	"strings"
+)
+
+// WithRoutes registers the route templates of the service's endpoints (e.g.,
+// "/api/users/{id}", "/api/users/:id/orders", "/static/*"), used to set the route of
+// request events whose route is not set explicitly or carried by the context
+// Parameters are written {name} or :name and match one segment; a trailing * or
+// {name...} matches the rest of the path. When several templates match, the one with
+// the most literal segments wins. Paths no template matches are templated heuristically
+// (see TemplatePath)
+func WithRoutes(templates ...string) ProducerOption {
+	return func(p *Producer) {
+		for _, template := range templates {
+			p.routes = append(p.routes, parseRouteTemplate(template))
+		}
+	}
+}
+
+// ContextWithRoute returns a context carrying the route template that matched the
+// request (e.g., "/api/users/{id}"), as reported by the router; request events emitted
+// with it record this route
+func ContextWithRoute(ctx context.Context, route string) context.Context {
+	return context.WithValue(ctx, routeKey, route)
+}
+
+// RouteFromContext returns the route template carried by ctx, if any
+func RouteFromContext(ctx context.Context) string {
+	route, _ := ctx.Value(routeKey).(string)
+	return route
+}
+
+// route returns the route of a request for path: the explicit route if set, then the
+// route carried by ctx, a registered template, or the heuristically templated path
+func (p *Producer) route(ctx context.Context, path, explicit string) string {
+	if explicit != "" {
+		return explicit
+	}
+	if route := RouteFromContext(ctx); route != "" {
+		return route
+	}
+	if path == "" {
+		return ""
+	}
+	path, _, _ = strings.Cut(path, "?")
+
+	best, bestLiterals := "", -1
+	segments := splitPath(path)
+	for _, route := range p.routes {
+		if route.literals > bestLiterals && route.matches(segments) {
+			best, bestLiterals = route.template, route.literals
+		}
+	}
+	if best != "" {
+		return best
+	}
+	return TemplatePath(path)
+}
+
+// requestRoute returns a request's route, or its path if it has none
+func requestRoute(event *RequestReceivedEvent) string {
+	if event.Route != "" {
+		return event.Route
+	}
+	return event.Path
+}
+
+// routeTemplate is a parsed route template
+type routeTemplate struct {
+	template string
+	segments []string
+	literals int  // Segments that are not parameters
+	rest     bool // The last segment matches the rest of the path
+}
+
+// parseRouteTemplate parses a route template
+func parseRouteTemplate(template string) routeTemplate {
+	route := routeTemplate{template: template, segments: splitPath(template)}
+	if n := len(route.segments); n > 0 {
+		if last := route.segments[n-1]; last == "*" || (isRouteParam(last) && strings.HasSuffix(last, "...}")) {
+			route.rest = true
+			route.segments = route.segments[:n-1]
+		}
+	}
+	for _, segment := range route.segments {
+		if !isRouteParam(segment) {
+			route.literals++
+		}
+	}
+	return route
+}
+
+// matches reports whether a path's segments match the template
+func (r routeTemplate) matches(segments []string) bool {
+	if len(segments) < len(r.segments) || (!r.rest && len(segments) != len(r.segments)) {
+		return false
+	}
+	for i, segment := range r.segments {
+		if !isRouteParam(segment) && segment != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// isRouteParam reports whether a template segment is a parameter
+func isRouteParam(segment string) bool {
+	return strings.HasPrefix(segment, ":") || (strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}"))
+}
+
+// splitPath splits a path into its segments, ignoring leading and trailing slashes
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// TemplatePath replaces the segments of path that look like identifiers with ":id"
+// ("/api/users/user-789/orders/42" -> "/api/users/:id/orders/:id"), so paths can be
+// grouped by endpoint without knowing the service's routes
+// Numbers, UUIDs, long hex strings, long tokens containing digits (such as ULIDs), and
+// prefixed IDs (such as "user-789" or "ord_8f3k2") are identifiers; the query string
+// is dropped
+func TemplatePath(path string) string {
+	path, _, _ = strings.Cut(path, "?")
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if isIDSegment(segment) {
+			segments[i] = ":id"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// isIDSegment reports whether a path segment looks like an identifier
+func isIDSegment(segment string) bool {
+	if segment == "" {
+		return false
+	}
+
+	digits, hex, separators := 0, 0, 0
+	for i := 0; i < len(segment); i++ {
+		switch c := segment[i]; {
+		case '0' <= c && c <= '9':
+			digits++
+			hex++
+		case 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F':
+			hex++
+		case 'g' <= c && c <= 'z' || 'G' <= c && c <= 'Z':
+		case c == '-' || c == '_':
+			separators++
+		default:
+			return false // Dots, percent escapes, and the like: file names or encoded values
+		}
+	}
+
+	switch {
+	case digits == 0:
+		return false
+	case digits == len(segment):
+		return true // 42
+	case isUUID(segment):
+		return true
+	case separators == 0 && hex == len(segment) && len(segment) >= 16:
+		return true // Hashes and object IDs
+	case separators == 0 && len(segment) >= 16:
+		return true // ULIDs and other opaque tokens
+	}
+
+	// Prefixed IDs: a word, one separator, then a part with digits (user-789, ord_8f3k2)
+	i := strings.IndexAny(segment, "-_")
+	if separators != 1 || i <= 0 || i == len(segment)-1 {
+		return false
+	}
+	prefix, id := segment[:i], segment[i+1:]
+	return strings.IndexAny(prefix, "0123456789") < 0 && strings.IndexAny(id, "0123456789") >= 0
+}
+
+// isUUID reports whether s is a UUID in its canonical 8-4-4-4-12 hex form
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,172 @@
+package lifecycle
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"path"
+	"strconv"
+	"sync"
+)
+
+// SamplingRule keeps a fraction of the events it matches
+// Empty fields match every event; patterns use path.Match syntax (e.g., "api.request.*",
+// "/api/users/*")
+type SamplingRule struct {
+	EventType   string  `json:"event_type,omitempty"`   // Event type pattern
+	API         string  `json:"api,omitempty"`          // API identifier pattern
+	Path        string  `json:"path,omitempty"`         // HTTP path pattern
+	StatusClass string  `json:"status_class,omitempty"` // "2xx" through "5xx", or an exact code such as "404"
+	Rate        float64 `json:"rate"`                   // Fraction kept: 1 keeps all, 0 drops all
+}
+
+// SamplingConfig configures a Sampler; it can be loaded from a configuration file
+type SamplingConfig struct {
+	// DefaultRate is the fraction of events kept when no rule matches (default: 1)
+	DefaultRate *float64 `json:"default_rate,omitempty"`
+
+	// Rules are evaluated in order and the first match decides, so specific rules
+	// (e.g., always keep 5xx) go before broad ones
+	Rules []SamplingRule `json:"rules,omitempty"`
+}
+
+// Sampler decides which events are written, based on rules matching the event type, API,
+// HTTP path, and status code class
+// Events sharing a correlation ID get the same decision under the same rate, so sampled
+// requests stay complete
+// Its configuration can be replaced at runtime with Update; it is safe for concurrent use
+type Sampler struct {
+	mu     sync.RWMutex
+	config SamplingConfig
+}
+
+// NewSampler creates a sampler with the given configuration
+func NewSampler(config SamplingConfig) *Sampler {
+	return &Sampler{config: config}
+}
+
+// WithSampler samples the events written by the producer
+// Sampling only affects output: metrics and aggregates still observe every event
+func WithSampler(sampler *Sampler) ProducerOption {
+	return func(p *Producer) {
+		p.sampler = sampler
+	}
+}
+
+// Update replaces the sampler's configuration
+func (s *Sampler) Update(config SamplingConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config = config
+}
+
+// Config returns the sampler's current configuration
+func (s *Sampler) Config() SamplingConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.config
+}
+
+// Sample reports whether event should be kept
+// The HTTP path is taken from the event or, failing that, from ctx (see
+// ContextWithRequestPath)
+func (s *Sampler) Sample(ctx context.Context, event Event) bool {
+	s.mu.RLock()
+	rate := 1.0
+	if s.config.DefaultRate != nil {
+		rate = *s.config.DefaultRate
+	}
+	for _, rule := range s.config.Rules {
+		if rule.matches(ctx, event) {
+			rate = rule.Rate
+			break
+		}
+	}
+	s.mu.RUnlock()
+
+	switch {
+	case rate >= 1:
+		return true
+	case rate <= 0:
+		return false
+	}
+	return samplingFraction(event.GetCorrelationID()) < rate
+}
+
+// matches reports whether the rule applies to event
+func (r SamplingRule) matches(ctx context.Context, event Event) bool {
+	if r.EventType != "" && !matchPattern(r.EventType, event.GetEventType()) {
+		return false
+	}
+	if r.API != "" && !matchPattern(r.API, event.GetAPI()) {
+		return false
+	}
+	if r.Path != "" {
+		eventPath := eventRequestPath(ctx, event)
+		if eventPath == "" || !matchPattern(r.Path, eventPath) {
+			return false
+		}
+	}
+	if r.StatusClass != "" {
+		code, ok := eventStatusCode(event)
+		if !ok || !matchStatusClass(r.StatusClass, code) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchPattern reports whether value matches a path.Match pattern
+func matchPattern(pattern, value string) bool {
+	matched, err := path.Match(pattern, value)
+	return err == nil && matched
+}
+
+// matchStatusClass reports whether code is in class ("5xx") or equals it ("404")
+func matchStatusClass(class string, code int32) bool {
+	if len(class) == 3 && class[1:] == "xx" {
+		return class[0] >= '1' && class[0] <= '5' && code/100 == int32(class[0]-'0')
+	}
+	exact, err := strconv.Atoi(class)
+	return err == nil && int32(exact) == code
+}
+
+// eventRequestPath returns the HTTP path of a request event, or the one carried by ctx
+func eventRequestPath(ctx context.Context, event Event) string {
+	if e, ok := event.(*RequestReceivedEvent); ok && e.Path != "" {
+		return e.Path
+	}
+	return RequestPathFromContext(ctx)
+}
+
+// eventStatusCode returns the HTTP status code of events that have one
+func eventStatusCode(event Event) (int32, bool) {
+	switch e := event.(type) {
+	case *RequestHandledEvent:
+		return e.StatusCode, true
+	case *RequestErroredEvent:
+		return e.StatusCode, true
+	case *APICallEvent:
+		return e.StatusCode, e.StatusCode != 0
+	}
+	return 0, false
+}
+
+// samplingFraction maps a correlation ID to a stable value in [0, 1), or a random one
+// for events without a correlation ID
+func samplingFraction(correlationID string) float64 {
+	if correlationID == "" {
+		return rand.Float64()
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(correlationID))
+	// FNV's high bits are poorly mixed for short, similar IDs; apply the splitmix64 finalizer
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return float64(x>>11) / float64(math.MaxUint64>>11+1)
+}
@@ -44,6 +44,15 @@ func ConvertFromSchemaFieldFlags(schemaFlags map[string]interface{}) map[string]
 			if immutable, ok := flagsMap["immutable"].(bool); ok {
 				annotations.Immutable = immutable
 			}
+			if noise, ok := flagsMap["noise"].(float64); ok {
+				annotations.Noise = noise
+			}
+			if bucket, ok := flagsMap["bucket"].(float64); ok {
+				annotations.Bucket = bucket
+			}
+			if anonymizeIP, ok := flagsMap["anonymize_ip"].(bool); ok {
+				annotations.AnonymizeIP = anonymizeIP
+			}
 			
 			result[fieldName] = annotations
 		}
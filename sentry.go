@@ -0,0 +1,347 @@
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sentryQueueSize is how many events wait to be sent to Sentry before new ones are dropped
+const sentryQueueSize = 100
+
+// DefaultSentryEventTypes are the event types WithSentry reports by default
+var DefaultSentryEventTypes = []string{"api.request.errored", "service.crashed", "error.occurred"}
+
+// sentryReporter sends error events to Sentry as issues, in the background
+type sentryReporter struct {
+	endpoint    string // Envelope endpoint
+	auth        string // X-Sentry-Auth header
+	dsn         string
+	err         error // DSN parse error, reported when the producer is created
+	eventTypes  []string
+	environment string
+	release     string
+	client      *http.Client
+
+	mu     sync.RWMutex // Guards closing queue against observe
+	closed bool
+	queue  chan Event
+	done   chan struct{}
+}
+
+// SentryOption configures WithSentry
+type SentryOption func(*sentryReporter)
+
+// WithSentryEnvironment sets the environment issues are reported in (e.g., "production")
+func WithSentryEnvironment(environment string) SentryOption {
+	return func(r *sentryReporter) {
+		r.environment = environment
+	}
+}
+
+// WithSentryRelease sets the release issues are attributed to
+func WithSentryRelease(release string) SentryOption {
+	return func(r *sentryReporter) {
+		r.release = release
+	}
+}
+
+// WithSentryEventTypes sets the event types reported, as exact types or path.Match
+// patterns (default: DefaultSentryEventTypes)
+func WithSentryEventTypes(eventTypes ...string) SentryOption {
+	return func(r *sentryReporter) {
+		r.eventTypes = eventTypes
+	}
+}
+
+// WithSentryClient sets the HTTP client used to reach Sentry (default: http.DefaultClient)
+func WithSentryClient(client *http.Client) SentryOption {
+	return func(r *sentryReporter) {
+		r.client = client
+	}
+}
+
+// WithSentry reports error events to the Sentry project of dsn as issues, alongside the
+// producer's normal output: api.request.errored, service.crashed, and error.occurred by
+// default. Issues are grouped by event type, error code, and the top of the stack
+// Events are reported after PII redaction and before sampling, in the background; when
+// Sentry falls behind, new issues are dropped. Producer.Close sends those still queued
+// An invalid DSN is logged and disables reporting
+func WithSentry(dsn string, opts ...SentryOption) ProducerOption {
+	return func(p *Producer) {
+		r := &sentryReporter{
+			dsn:        dsn,
+			eventTypes: DefaultSentryEventTypes,
+			client:     http.DefaultClient,
+			queue:      make(chan Event, sentryQueueSize),
+			done:       make(chan struct{}),
+		}
+		r.endpoint, r.auth, r.err = parseSentryDSN(dsn)
+		for _, opt := range opts {
+			opt(r)
+		}
+		p.sentry = r
+	}
+}
+
+// startSentry starts reporting to Sentry, or logs why it cannot
+func (p *Producer) startSentry() {
+	if p.sentry == nil {
+		return
+	}
+	if p.sentry.err != nil {
+		p.logger.Warn("lifecycle: Sentry reporting disabled", "error", p.sentry.err)
+		p.sentry = nil
+		return
+	}
+	go p.sentry.run()
+}
+
+// parseSentryDSN returns the envelope endpoint and auth header of a DSN of the form
+// https://public_key@host/project_id
+func parseSentryDSN(dsn string) (endpoint, auth string, err error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid Sentry DSN: %w", err)
+	}
+	key := u.User.Username()
+	path := strings.Trim(u.Path, "/")
+	slash := strings.LastIndex(path, "/")
+	project := path[slash+1:]
+	if u.Scheme == "" || u.Host == "" || key == "" || project == "" {
+		return "", "", fmt.Errorf("invalid Sentry DSN: expected scheme://public_key@host/project_id")
+	}
+	prefix := ""
+	if slash >= 0 {
+		prefix = "/" + path[:slash]
+	}
+	endpoint = fmt.Sprintf("%s://%s%s/api/%s/envelope/", u.Scheme, u.Host, prefix, project)
+	auth = "Sentry sentry_version=7, sentry_client=lifecycle-go, sentry_key=" + key
+	return endpoint, auth, nil
+}
+
+// observe queues event for Sentry if its type is reported
+func (r *sentryReporter) observe(event Event) {
+	eventType := event.GetEventType()
+	for _, pattern := range r.eventTypes {
+		if matchPattern(pattern, eventType) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			if r.closed {
+				return
+			}
+			select {
+			case r.queue <- event:
+			default:
+			}
+			return
+		}
+	}
+}
+
+// run sends queued events until close
+func (r *sentryReporter) run() {
+	defer close(r.done)
+	for event := range r.queue {
+		_ = r.send(event)
+	}
+}
+
+// close sends the events still queued before ctx is done
+func (r *sentryReporter) close(ctx context.Context) error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.queue)
+	}
+	r.mu.Unlock()
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to send queued Sentry events: %w", ctx.Err())
+	}
+}
+
+// send posts event to Sentry as an envelope holding one error event
+func (r *sentryReporter) send(event Event) error {
+	payload := r.sentryEvent(event)
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal Sentry event: %w", err)
+	}
+
+	var body bytes.Buffer
+	header, _ := json.Marshal(map[string]string{
+		"event_id": payload.EventID,
+		"sent_at":  time.Now().UTC().Format(time.RFC3339Nano),
+		"dsn":      r.dsn,
+	})
+	body.Write(header)
+	fmt.Fprintf(&body, "\n{\"type\":\"event\",\"length\":%d}\n", len(data))
+	body.Write(data)
+	body.WriteByte('\n')
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultFlushTimeout)
+	defer cancel()
+	status, resp, err := doWithRetry(ctx, r.client, RetryPolicy{}, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, bytes.NewReader(body.Bytes()))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/x-sentry-envelope")
+		req.Header.Set("X-Sentry-Auth", r.auth)
+		return req, nil
+	})
+	if err != nil {
+		return err
+	}
+	if status < 200 || status > 299 {
+		return fmt.Errorf("unexpected status %d: %s", status, truncateBody(resp))
+	}
+	return nil
+}
+
+// sentryEvent is the part of the Sentry event payload lifecycle fills in
+type sentryEvent struct {
+	EventID     string                            `json:"event_id"`
+	Timestamp   string                            `json:"timestamp"`
+	Platform    string                            `json:"platform"`
+	Level       string                            `json:"level"`
+	Logger      string                            `json:"logger"`
+	ServerName  string                            `json:"server_name,omitempty"`
+	Environment string                            `json:"environment,omitempty"`
+	Release     string                            `json:"release,omitempty"`
+	Message     string                            `json:"message,omitempty"`
+	Fingerprint []string                          `json:"fingerprint"`
+	Tags        map[string]string                 `json:"tags"`
+	Extra       map[string]interface{}            `json:"extra,omitempty"`
+	Contexts    map[string]map[string]interface{} `json:"contexts,omitempty"`
+	Exception   *sentryExceptions                 `json:"exception,omitempty"`
+}
+
+// sentryExceptions is the exception interface of a Sentry event
+type sentryExceptions struct {
+	Values []sentryException `json:"values"`
+}
+
+// sentryException is one exception, with its stack if known
+type sentryException struct {
+	Type       string            `json:"type"`
+	Value      string            `json:"value"`
+	Stacktrace *sentryStacktrace `json:"stacktrace,omitempty"`
+}
+
+// sentryStacktrace lists frames oldest first, as Sentry expects
+type sentryStacktrace struct {
+	Frames []sentryFrame `json:"frames"`
+}
+
+// sentryFrame is one stack frame
+type sentryFrame struct {
+	Function string `json:"function"`
+	Filename string `json:"filename"`
+	Lineno   int    `json:"lineno"`
+}
+
+// sentryFingerprintFrames is how many of the innermost frames group issues
+const sentryFingerprintFrames = 3
+
+// sentryEvent converts event to a Sentry error event
+func (r *sentryReporter) sentryEvent(event Event) *sentryEvent {
+	eventType := event.GetEventType()
+	payload := &sentryEvent{
+		EventID:     sentryEventID(event),
+		Timestamp:   event.GetTimestamp().UTC().Format(time.RFC3339Nano),
+		Platform:    "go",
+		Level:       "error",
+		Logger:      "lifecycle",
+		ServerName:  event.GetHost(),
+		Environment: r.environment,
+		Release:     r.release,
+		Tags: map[string]string{
+			"event_type": eventType,
+			"service":    event.GetService(),
+		},
+	}
+	if api := event.GetAPI(); api != "" {
+		payload.Tags["api"] = api
+	}
+	if id := event.GetCorrelationID(); id != "" {
+		payload.Tags["correlation_id"] = id
+	}
+	if metadata := event.GetMetadata(); len(metadata) > 0 {
+		payload.Extra = map[string]interface{}{"metadata": metadata}
+		traceID, _ := metadata["trace_id"].(string)
+		spanID, _ := metadata["span_id"].(string)
+		if traceID != "" && spanID != "" {
+			payload.Contexts = map[string]map[string]interface{}{
+				"trace": {"trace_id": traceID, "span_id": spanID},
+			}
+		}
+	}
+
+	var exception sentryException
+	var code string
+	var stack []StackFrame
+	switch e := event.(type) {
+	case *RequestErroredEvent:
+		code = e.ErrorCode
+		if code == "" {
+			code = strconv.Itoa(int(e.StatusCode))
+		}
+		exception = sentryException{Type: code, Value: e.ErrorMessage}
+	case *ServiceCrashedEvent:
+		payload.Level = "fatal"
+		code = "exit " + strconv.Itoa(int(e.ExitCode))
+		exception = sentryException{Type: "crash", Value: e.Reason}
+		stack = e.Stack
+	case *ErrorOccurredEvent:
+		code = e.Kind
+		exception = sentryException{Type: firstNonEmpty(e.Kind, "error"), Value: e.ErrorMessage}
+		stack = e.Stack
+	default:
+		payload.Message = eventType
+		payload.Fingerprint = []string{eventType}
+		return payload
+	}
+
+	payload.Fingerprint = []string{eventType, code}
+	for i, frame := range stack {
+		if i >= sentryFingerprintFrames {
+			break
+		}
+		payload.Fingerprint = append(payload.Fingerprint, frame.Function)
+	}
+	if len(stack) > 0 {
+		frames := make([]sentryFrame, len(stack))
+		for i, frame := range stack {
+			frames[len(stack)-1-i] = sentryFrame{Function: frame.Function, Filename: frame.File, Lineno: frame.Line}
+		}
+		exception.Stacktrace = &sentryStacktrace{Frames: frames}
+	}
+	payload.Exception = &sentryExceptions{Values: []sentryException{exception}}
+	return payload
+}
+
+// sentryEventID returns the event's ID in Sentry's form, 32 hex digits, or a random one
+func sentryEventID(event Event) string {
+	if base := eventBase(event); base != nil {
+		id := strings.ReplaceAll(base.EventID, "-", "")
+		if _, err := hex.DecodeString(id); err == nil && len(id) == 32 {
+			return strings.ToLower(id)
+		}
+	}
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
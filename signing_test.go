@@ -0,0 +1,81 @@
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+)
+
+// TestProjectedEventsSigned checks that sinks with a projection receive events signed
+// over the projected content, in a chain of their own, without changing the signatures
+// other sinks receive
+func TestProjectedEventsSigned(t *testing.T) {
+	key := []byte("projection")
+	var full, support bytes.Buffer
+	p := NewProducer("signing", "localhost",
+		WithEventSigning(NewHMACSigner(key)),
+		WithSink(NewWriterSink(&full, nil)),
+		WithSink(NewWriterSink(&support, nil), SinkProjection(ProjectionSupport)),
+	)
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		err := p.EmitResourceCreatedWithOptions(ctx, ResourceCreatedOptions{
+			Resource: &Resource{Type: "orders.Order", ID: "order-1"},
+			Data:     map[string]interface{}{"total": 42},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for name, buf := range map[string]*bytes.Buffer{"full": &full, "support": &support} {
+		n, err := VerifyEvents(bytes.NewReader(buf.Bytes()), NewHMACVerifier(key))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if n != 3 {
+			t.Errorf("%s: verified %d events, want 3", name, n)
+		}
+	}
+	if bytes.Contains(support.Bytes(), []byte("resource_data")) {
+		t.Errorf("projected events kept resource_data: %s", support.String())
+	}
+}
+
+// TestFilteredSinksSigned checks that sinks receiving only some events get a chain of
+// their own, without gaps
+func TestFilteredSinksSigned(t *testing.T) {
+	key := []byte("filtered")
+	var all, errorsOnly, sampled bytes.Buffer
+	p := NewProducer("signing", "localhost",
+		WithEventSigning(NewHMACSigner(key)),
+		WithSink(NewWriterSink(&all, nil)),
+		WithSink(NewWriterSink(&errorsOnly, nil), SinkMinLevel(slog.LevelError)),
+		WithSink(NewWriterSink(&sampled, nil), SinkSampleRate(0.5)),
+	)
+
+	for i := 0; i < 20; i++ {
+		ctx := ContextWithCorrelationID(context.Background(), string(rune('a'+i)))
+		if err := p.EmitServiceStarted(ctx, "1.0.0", int32(i)); err != nil {
+			t.Fatal(err)
+		}
+		if err := p.EmitErrorOccurred(ctx, "test", "failed", "", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for name, tt := range map[string]struct {
+		buf  *bytes.Buffer
+		want int
+	}{"all": {&all, 40}, "errors only": {&errorsOnly, 20}, "sampled": {&sampled, -1}} {
+		n, err := VerifyEvents(bytes.NewReader(tt.buf.Bytes()), NewHMACVerifier(key))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if tt.want >= 0 && n != tt.want {
+			t.Errorf("%s: verified %d events, want %d", name, n, tt.want)
+		}
+	}
+}
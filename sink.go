@@ -0,0 +1,372 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+// Sink receives the events written by a producer
+// *StyledOutput is a Sink; NewWriterSink adapts any io.Writer
+type Sink interface {
+	WriteEvent(event Event) error
+}
+
+// EventFilter reports whether an event should be written to a sink
+type EventFilter func(event Event) bool
+
+// sinkEntry is a configured sink with its own filtering and sampling
+type sinkEntry struct {
+	sink       Sink
+	filter     EventFilter
+	minLevel   *slog.Level
+	sampleRate float64
+	projection *Projection // Optional: applied before the event is written
+	chain      *eventChain // Signs projected events when the producer signs events
+
+	name       string             // For drop reports
+	bufferSize int                // Buffered asynchronously when set
+	policy     BackpressurePolicy // Used when the buffer is full
+	async      *asyncSink         // Wraps sink when buffered
+}
+
+// SinkOption configures one sink
+type SinkOption func(*sinkEntry)
+
+// SinkFilter writes only events for which filter returns true
+func SinkFilter(filter EventFilter) SinkOption {
+	return func(e *sinkEntry) {
+		e.filter = filter
+	}
+}
+
+// SinkMinLevel writes only events at or above level (see EventLevel)
+func SinkMinLevel(level slog.Level) SinkOption {
+	return func(e *sinkEntry) {
+		e.minLevel = &level
+	}
+}
+
+// SinkSampleRate writes a fraction of events (default: 1); events sharing a
+// correlation ID get the same decision
+func SinkSampleRate(rate float64) SinkOption {
+	return func(e *sinkEntry) {
+		e.sampleRate = rate
+	}
+}
+
+// SinkProjection writes events as seen by projection, so each audience only receives the
+// fields it should (e.g., ProjectionSupport for support tooling)
+// With WithEventSigning, projected events are signed over their projected content
+func SinkProjection(projection *Projection) SinkOption {
+	return func(e *sinkEntry) {
+		e.projection = projection
+	}
+}
+
+// WithSink adds a destination for events, with its own filter, level, and sample rate
+// Once any sink is configured, events are written only to sinks, and WithOutput and
+// WithStyledOutput no longer apply:
+//
+//	lifecycle.NewProducer(service, host,
+//		lifecycle.WithSink(lifecycle.NewStyledOutput(os.Stdout), lifecycle.SinkMinLevel(slog.LevelInfo)),
+//		lifecycle.WithSink(lifecycle.NewWriterSink(file, nil)),
+//		lifecycle.WithSink(webhook, lifecycle.SinkMinLevel(slog.LevelError)),
+//	)
+func WithSink(sink Sink, opts ...SinkOption) ProducerOption {
+	return func(p *Producer) {
+		p.sinks.Store(&sinkSet{entries: append(p.currentSinks(), p.newSinkEntry(sink, opts))})
+	}
+}
+
+// newSinkEntry configures sink, starting its worker if it is buffered
+func (p *Producer) newSinkEntry(sink Sink, opts []SinkOption) *sinkEntry {
+	if configured, ok := sink.(*configuredSink); ok {
+		sink, opts = configured.Sink, append(configured.opts, opts...)
+	}
+	entry := &sinkEntry{sink: sink, sampleRate: 1}
+	for _, opt := range opts {
+		opt(entry)
+	}
+	if entry.name == "" {
+		entry.name = defaultSinkName(sink)
+	}
+	if entry.bufferSize > 0 {
+		entry.async = newAsyncSink(p, sink, entry.name, entry.bufferSize, entry.policy)
+	}
+	return entry
+}
+
+// configuredSink is a sink with options, for ReplaceSinks
+type configuredSink struct {
+	Sink
+	opts []SinkOption
+}
+
+// ConfigureSink attaches options to a sink passed to ReplaceSinks
+func ConfigureSink(sink Sink, opts ...SinkOption) Sink {
+	return &configuredSink{Sink: sink, opts: opts}
+}
+
+// ReplaceSinks swaps the producer's sinks at runtime, for example to rotate to a new file
+// or switch collector endpoints without a restart; wrap a sink with ConfigureSink to give
+// it options. With no sinks, events go back to WithOutput or WithStyledOutput
+// New events go to the new sinks as soon as they are swapped in. The replaced sinks'
+// buffers are written out, and writes in progress to them finish, before ctx is done;
+// replaced sinks are then flushed and closed, except those passed again. A replaced sink
+// still being written to when ctx is done is left open
+func (p *Producer) ReplaceSinks(ctx context.Context, sinks ...Sink) error {
+	next := &sinkSet{}
+	p.sinksMu.Lock()
+	if p.closed.Load() {
+		p.sinksMu.Unlock()
+		return ErrProducerClosed
+	}
+	for _, sink := range sinks {
+		next.entries = append(next.entries, p.newSinkEntry(sink, nil))
+	}
+	replaced := p.sinks.Swap(next)
+	p.sinksMu.Unlock()
+	if replaced == nil {
+		return nil
+	}
+	kept := make(map[Sink]bool, len(next.entries))
+	for _, entry := range next.entries {
+		kept[entry.sink] = true
+	}
+
+	// Closing the buffers first releases writers waiting for space in a full one
+	var errs []error
+	var dropped int64
+	for _, entry := range replaced.entries {
+		if entry.async != nil {
+			_, d := entry.async.close(ctx)
+			dropped += d
+			if d > 0 && p.otel != nil {
+				p.otel.RecordDropped(context.WithoutCancel(ctx), entry.name, string(entry.policy), d)
+			}
+		}
+	}
+	if dropped > 0 {
+		errs = append(errs, fmt.Errorf("dropped %d buffered events: %w", dropped, ctx.Err()))
+	}
+	if err := replaced.retire(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to close replaced sinks: writes in progress: %w", err))
+		return errors.Join(errs...)
+	}
+
+	for _, entry := range replaced.entries {
+		if entry.async != nil && !entry.async.exited() {
+			// Its worker is still writing an abandoned event
+			continue
+		}
+		if kept[entry.sink] {
+			continue
+		}
+		if f, ok := entry.sink.(flusher); ok {
+			if err := f.Flush(); err != nil {
+				errs = append(errs, fmt.Errorf("failed to flush sink %s: %w", entry.name, err))
+			}
+		}
+		if c, ok := entry.sink.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("failed to close sink %s: %w", entry.name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sinkSet is the producer's sinks, swapped as a whole by ReplaceSinks
+type sinkSet struct {
+	entries []*sinkEntry
+
+	mu      sync.Mutex
+	writes  int           // Writes in progress
+	retired bool          // Set once replaced: no new writes
+	idle    chan struct{} // Closed once retired with no writes in progress
+}
+
+// currentSinks returns the producer's sinks
+func (p *Producer) currentSinks() []*sinkEntry {
+	if sinks := p.sinks.Load(); sinks != nil {
+		return sinks.entries
+	}
+	return nil
+}
+
+// acquireSinks returns the producer's sinks with a write in progress registered, or nil
+// if none were ever configured; release it once written
+// It returns ErrProducerClosed once Close has retired the sinks
+func (p *Producer) acquireSinks() (*sinkSet, error) {
+	for {
+		sinks := p.sinks.Load()
+		if sinks == nil || sinks.acquire() {
+			return sinks, nil
+		}
+		if p.sinks.Load() == sinks {
+			// Retired without being replaced: the producer is closing
+			return nil, ErrProducerClosed
+		}
+		// Replaced since it was loaded: load the new sinks
+	}
+}
+
+// acquire registers a write in progress, unless the sinks were replaced
+func (s *sinkSet) acquire() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.retired {
+		return false
+	}
+	s.writes++
+	return true
+}
+
+// release ends a write registered by acquire
+func (s *sinkSet) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writes--
+	if s.retired && s.writes == 0 {
+		close(s.idle)
+	}
+}
+
+// retire stops new writes to replaced sinks and waits for those in progress to finish,
+// or for ctx to be done
+func (s *sinkSet) retire(ctx context.Context) error {
+	s.mu.Lock()
+	s.retired = true
+	s.idle = make(chan struct{})
+	if s.writes == 0 {
+		close(s.idle)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-s.idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// accepts reports whether the sink should receive event
+func (e *sinkEntry) accepts(event Event) bool {
+	if dropped, ok := event.(*EventsDroppedEvent); ok && e.async != nil && dropped.source == e.async {
+		return false
+	}
+	if e.minLevel != nil && EventLevel(event) < *e.minLevel {
+		return false
+	}
+	if e.filter != nil && !e.filter(event) {
+		return false
+	}
+	switch {
+	case e.sampleRate >= 1:
+		return true
+	case e.sampleRate <= 0:
+		return false
+	}
+	return samplingFraction(event.GetCorrelationID()) < e.sampleRate
+}
+
+// writeSinks writes event to every sink that accepts it; direct writes bypass the sinks'
+// buffers, as when closing
+func (p *Producer) writeSinks(sinks []*sinkEntry, event Event, direct bool) error {
+	var errs []error
+	for _, entry := range sinks {
+		if !entry.accepts(event) {
+			continue
+		}
+		written := event
+		if entry.projection != nil {
+			written = entry.projection.Apply(event)
+		} else if p.chain != nil && entry.ownChain() {
+			written = unsignedCopy(event)
+		}
+		if err := entry.sign(p.chain, written); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		var err error
+		if entry.async != nil && !direct {
+			err = entry.async.WriteEvent(written)
+		} else {
+			err = entry.sink.WriteEvent(written)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ownChain reports whether the sink gets its own signing chain: it does not receive every
+// event as written, because of a filter, level, sample rate, or projection
+func (e *sinkEntry) ownChain() bool {
+	return e.projection != nil || e.filter != nil || e.minLevel != nil || e.sampleRate < 1
+}
+
+// sign signs an event in the sink's own chain when the producer signs events and the sink
+// has one (see ownChain)
+// Called with the producer's chain locked, so events are chained in the order they are
+// written
+func (e *sinkEntry) sign(producerChain *eventChain, event Event) error {
+	if producerChain == nil || !e.ownChain() {
+		return nil
+	}
+	if e.chain == nil {
+		e.chain = &eventChain{signer: producerChain.signer}
+	}
+	return e.chain.sign(event)
+}
+
+// writerSink writes encoded events to an io.Writer, one per line
+type writerSink struct {
+	mu      sync.Mutex
+	w       io.Writer
+	encoder Encoder
+}
+
+// NewWriterSink creates a sink that writes events to w, one per line, encoded with
+// encoder (nil: NewJSONEncoder)
+// Writes are serialized, so events are never interleaved
+func NewWriterSink(w io.Writer, encoder Encoder) Sink {
+	if encoder == nil {
+		encoder = NewJSONEncoder()
+	}
+	return &writerSink{w: w, encoder: encoder}
+}
+
+func (s *writerSink) WriteEvent(event Event) error {
+	data, err := s.encoder.Encode(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.w.Write(data); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+	return nil
+}
+
+// EventLevel returns the severity of an event, used for sink levels and styled output
+// log.message events carry their own level; other events get the level registered for
+// their type (see RegisterEventLevel)
+func EventLevel(event Event) slog.Level {
+	if logEvent, ok := event.(*LogMessageEvent); ok {
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(logEvent.Level)); err == nil {
+			return level
+		}
+	}
+	return eventTypeLevel(event.GetEventType())
+}
@@ -0,0 +1,67 @@
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingSink blocks every write until release is closed
+type blockingSink struct {
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func (s *blockingSink) WriteEvent(Event) error {
+	s.once.Do(func() { close(s.started) })
+	<-s.release
+	return nil
+}
+
+// TestReplaceSinksWedgedSink checks that a sink that stops returning, with writers
+// blocked on its full buffer, neither stalls ReplaceSinks past its ctx nor keeps events
+// from the new sinks
+func TestReplaceSinksWedgedSink(t *testing.T) {
+	wedged := &blockingSink{started: make(chan struct{}), release: make(chan struct{})}
+	defer close(wedged.release)
+	p := NewProducer("replace", "localhost", WithSink(wedged, SinkAsync(1, BackpressureBlock)))
+
+	ctx := context.Background()
+	_ = p.EmitServiceStarted(ctx, "1.0.0", 1) // Taken by the worker, which blocks
+	<-wedged.started
+	_ = p.EmitServiceStarted(ctx, "1.0.0", 2) // Fills the buffer
+	blocked := make(chan struct{})
+	go func() {
+		defer close(blocked)
+		_ = p.EmitServiceStarted(ctx, "1.0.0", 3) // Waits for space
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	var buf bytes.Buffer
+	replaceCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	replaced := make(chan error, 1)
+	go func() {
+		replaced <- p.ReplaceSinks(replaceCtx, NewWriterSink(&buf, nil))
+	}()
+	select {
+	case <-replaced:
+	case <-time.After(5 * time.Second):
+		t.Fatal("ReplaceSinks did not return after its ctx was done")
+	}
+
+	select {
+	case <-blocked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("writer waiting on the replaced sink's buffer was not released")
+	}
+	if err := p.EmitServiceStarted(ctx, "1.0.0", 4); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("service.started")) {
+		t.Errorf("new sink did not receive the event: %q", buf.String())
+	}
+}
@@ -0,0 +1,199 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// slowQueryPlanTimeout bounds how long the plan fetcher may run for one slow query
+const slowQueryPlanTimeout = 2 * time.Second
+
+// maxPendingSlowQueries bounds the started queries remembered for slow-query reporting
+const maxPendingSlowQueries = 10000
+
+// QuerySlowEvent represents a db.query.slow event, emitted after db.query.completed when a
+// query took at least the slow-query threshold
+type QuerySlowEvent struct {
+	Base        *BaseEvent `json:"base"`
+	QueryID     string     `json:"query_id"`
+	Query       string     `json:"query"`
+	Fingerprint string     `json:"fingerprint"` // Stable across literal values (see QueryFingerprint)
+	Status      Status     `json:"status"`
+	DurationMs  int64      `json:"duration_ms"`
+	ThresholdMs int64      `json:"threshold_ms"`
+	Plan        string     `json:"plan,omitempty"`
+	PlanError   string     `json:"plan_error,omitempty"`
+}
+
+func (e *QuerySlowEvent) GetEventType() string     { return e.Base.GetEventType() }
+func (e *QuerySlowEvent) GetTimestamp() time.Time  { return e.Base.GetTimestamp() }
+func (e *QuerySlowEvent) GetService() string       { return e.Base.GetService() }
+func (e *QuerySlowEvent) GetAPI() string           { return e.Base.GetAPI() }
+func (e *QuerySlowEvent) GetHost() string          { return e.Base.GetHost() }
+func (e *QuerySlowEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *QuerySlowEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *QuerySlowEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *QuerySlowEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
+
+// PlanFetcher returns the execution plan of a query, typically by running EXPLAIN on the
+// same database
+// params are the original, unredacted parameters; they are never emitted
+type PlanFetcher func(ctx context.Context, query string, params []interface{}) (string, error)
+
+// slowQueryTracker remembers started queries so slow completions can be reported
+type slowQueryTracker struct {
+	threshold time.Duration
+	fetch     PlanFetcher
+
+	mu      sync.Mutex
+	pending map[string]pendingQuery // By query ID
+}
+
+// pendingQuery is a started query awaiting completion
+type pendingQuery struct {
+	query  string
+	params []interface{}
+}
+
+// WithSlowQueryThreshold reports queries that take at least threshold: after
+// db.query.completed, a db.query.slow event carries the query's fingerprint and, if fetch
+// is set, its execution plan
+// Plans are fetched on the goroutine calling EmitQueryCompleted, bounded by a short timeout
+func WithSlowQueryThreshold(threshold time.Duration, fetch PlanFetcher) ProducerOption {
+	return func(p *Producer) {
+		p.slowQueries = &slowQueryTracker{
+			threshold: threshold,
+			fetch:     fetch,
+			pending:   make(map[string]pendingQuery),
+		}
+	}
+}
+
+// start remembers a started query
+func (t *slowQueryTracker) start(queryID, query string, params []interface{}) {
+	if queryID == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.pending) < maxPendingSlowQueries {
+		t.pending[queryID] = pendingQuery{query: query, params: params}
+	}
+}
+
+// finish forgets a query, returning it if it was remembered
+func (t *slowQueryTracker) finish(queryID string) (pendingQuery, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	query, ok := t.pending[queryID]
+	delete(t.pending, queryID)
+	return query, ok
+}
+
+// emitQuerySlow emits db.query.slow if a completed query reached the threshold
+// It shares the API identifier and metadata of the completed event's builder
+func (p *Producer) emitQuerySlow(ctx context.Context, b builderBase, queryID string, duration time.Duration) error {
+	query, ok := p.slowQueries.finish(queryID)
+	if !ok || duration < p.slowQueries.threshold {
+		return nil
+	}
+
+	event := &QuerySlowEvent{
+		Base:        b.createBase("db.query.slow", nil),
+		QueryID:     queryID,
+		Query:       query.query,
+		Fingerprint: QueryFingerprint(query.query),
+		Status:      StatusWarning,
+		DurationMs:  duration.Milliseconds(),
+		ThresholdMs: p.slowQueries.threshold.Milliseconds(),
+	}
+	if p.slowQueries.fetch != nil {
+		planCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), slowQueryPlanTimeout)
+		plan, err := p.slowQueries.fetch(planCtx, query.query, query.params)
+		cancel()
+		if err != nil {
+			event.PlanError = err.Error()
+		} else {
+			event.Plan = plan
+		}
+	}
+	return b.emit(ctx, event.Base, event, duration)
+}
+
+// QueryFingerprint identifies a query's shape: literals are replaced with ?, lists of
+// placeholders collapse to one, and case and whitespace are normalized, so the same
+// statement with different values shares a fingerprint
+// It returns a short hex hash of the normalized query
+func QueryFingerprint(query string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(NormalizeQuery(query)))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// NormalizeQuery returns the normalized query text that QueryFingerprint hashes: tokens
+// are lowercased and separated by single spaces, and literals become ?
+func NormalizeQuery(query string) string {
+	var tokens []string
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+		case c == '\'':
+			// String literal, with '' escapes
+			for i++; i < len(query); i++ {
+				if query[i] == '\'' {
+					if i+1 < len(query) && query[i+1] == '\'' {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			tokens = append(tokens, "?")
+		case isDigit(c) || (c == '$' && i+1 < len(query) && isDigit(query[i+1])):
+			// Number or positional placeholder ($1)
+			for i+1 < len(query) && (isDigit(query[i+1]) || query[i+1] == '.') {
+				i++
+			}
+			tokens = append(tokens, "?")
+		case isWordChar(c):
+			start := i
+			for i+1 < len(query) && (isWordChar(query[i+1]) || isDigit(query[i+1])) {
+				i++
+			}
+			tokens = append(tokens, strings.ToLower(query[start:i+1]))
+		default:
+			tokens = append(tokens, string(c))
+		}
+	}
+
+	// Collapse placeholder lists: IN (?, ?, ?) -> IN (?)
+	collapsed := tokens[:0]
+	for _, token := range tokens {
+		n := len(collapsed)
+		if token == "?" && n >= 2 && collapsed[n-1] == "," && collapsed[n-2] == "?" {
+			collapsed = collapsed[:n-1]
+			continue
+		}
+		collapsed = append(collapsed, token)
+	}
+	return strings.Join(collapsed, " ")
+}
+
+// isWordChar reports whether c can start an identifier or keyword
+func isWordChar(c byte) bool {
+	return c == '_' || c == '.' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isDigit reports whether c is an ASCII digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
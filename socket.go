@@ -0,0 +1,278 @@
+package lifecycle
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultSocketWriteTimeout bounds one write to a socket whose reader has stalled
+const DefaultSocketWriteTimeout = time.Second
+
+// ErrSocketDisconnected is returned by SocketSink while it waits to reconnect
+var ErrSocketDisconnected = errors.New("lifecycle: socket disconnected")
+
+// SocketSink streams events as NDJSON over a Unix domain socket, for sidecar agents that
+// consume events locally with less overhead than files or TCP (see ListenSocket). Unix
+// sockets are also available on Windows 10 and later
+// When the agent is not listening, or goes away, events fail with ErrSocketDisconnected
+// and the sink reconnects with backoff. A write that the agent does not read within the
+// write timeout closes the connection, so a stalled agent cannot block the producer; wrap
+// the sink with SinkAsync to buffer events through reconnects and choose what happens when
+// the buffer fills:
+//
+//	lifecycle.WithSink(lifecycle.NewSocketSink("/run/agent/events.sock"),
+//		lifecycle.SinkAsync(10000, lifecycle.BackpressureDropOldest))
+type SocketSink struct {
+	path         string
+	encoder      Encoder
+	writeTimeout time.Duration
+	retry        RetryPolicy
+	framing      []FrameOption // Non-nil: events are written as frames
+
+	mu        sync.Mutex
+	conn      net.Conn
+	frames    *FrameWriter // On conn, when framing
+	failures  int          // Consecutive failed connection attempts
+	nextRetry time.Time    // No connection attempts before this time
+}
+
+// SocketOption configures a SocketSink
+type SocketOption func(*SocketSink)
+
+// WithSocketEncoder sets how events are encoded (default: NewJSONEncoder())
+func WithSocketEncoder(encoder Encoder) SocketOption {
+	return func(s *SocketSink) {
+		s.encoder = encoder
+	}
+}
+
+// WithSocketFraming writes events as length-prefixed frames instead of lines (see
+// FramePreamble), which SocketListener also accepts; opts can add checksums
+func WithSocketFraming(opts ...FrameOption) SocketOption {
+	return func(s *SocketSink) {
+		s.framing = append([]FrameOption{}, opts...)
+	}
+}
+
+// WithSocketWriteTimeout sets how long a write may wait for the reader (default:
+// DefaultSocketWriteTimeout)
+func WithSocketWriteTimeout(timeout time.Duration) SocketOption {
+	return func(s *SocketSink) {
+		s.writeTimeout = timeout
+	}
+}
+
+// WithSocketReconnect sets the backoff between connection attempts; only its delays are
+// used, since the sink keeps reconnecting (default: DefaultRetryPolicy)
+func WithSocketReconnect(policy RetryPolicy) SocketOption {
+	return func(s *SocketSink) {
+		s.retry = policy
+	}
+}
+
+// NewSocketSink creates a sink writing to the Unix domain socket at path, connecting on
+// the first event
+func NewSocketSink(path string, opts ...SocketOption) *SocketSink {
+	s := &SocketSink{
+		path:         path,
+		encoder:      NewJSONEncoder(),
+		writeTimeout: DefaultSocketWriteTimeout,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.retry = s.retry.withDefaults()
+	return s
+}
+
+// WriteEvent writes event to the socket, connecting first if needed
+func (s *SocketSink) WriteEvent(event Event) error {
+	data, err := s.encoder.Encode(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	if s.framing == nil {
+		data = append(data, '\n')
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.connect(); err != nil {
+		return err
+	}
+	if s.writeTimeout > 0 {
+		_ = s.conn.SetWriteDeadline(time.Now().Add(s.writeTimeout))
+	}
+	if s.frames != nil {
+		err = s.frames.WriteFrame(data)
+	} else {
+		_, err = s.conn.Write(data)
+	}
+	if err != nil {
+		// A partial line would corrupt the stream, so start over on a new connection
+		s.conn.Close()
+		s.conn = nil
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+	return nil
+}
+
+// connect dials the socket unless connected or waiting out the backoff
+func (s *SocketSink) connect() error {
+	if s.conn != nil {
+		return nil
+	}
+	if time.Now().Before(s.nextRetry) {
+		return ErrSocketDisconnected
+	}
+	conn, err := net.DialTimeout("unix", s.path, s.writeTimeout)
+	if err != nil {
+		s.failures++
+		s.nextRetry = time.Now().Add(s.retry.delay(s.failures))
+		return fmt.Errorf("%w: %v", ErrSocketDisconnected, err)
+	}
+	s.conn = conn
+	if s.framing != nil {
+		s.frames = NewFrameWriter(conn, s.framing...)
+	}
+	s.failures = 0
+	return nil
+}
+
+// Close closes the connection; the next event reconnects
+func (s *SocketSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// SocketListener accepts NDJSON or framed event streams from SocketSinks on a Unix domain
+// socket
+type SocketListener struct {
+	listener net.Listener
+
+	mu     sync.Mutex
+	conns  map[net.Conn]bool
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// ListenSocket listens on the Unix domain socket at path, replacing a stale socket file
+// left by a previous listener
+func ListenSocket(path string) (*SocketListener, error) {
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("socket %s is in use", path)
+		}
+		_ = os.Remove(path)
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	return &SocketListener{listener: listener, conns: make(map[net.Conn]bool)}, nil
+}
+
+// Serve accepts connections until Close, calling fn for each event received
+// fn is called from one goroutine at a time, and while it runs no more events are read,
+// so a slow consumer pushes back on the writers. Lines that are not lifecycle events are
+// skipped; an error from fn closes the connection it came from
+func (l *SocketListener) Serve(fn func(*Record) error) error {
+	var fnMu sync.Mutex
+	for {
+		conn, err := l.listener.Accept()
+		if err != nil {
+			l.mu.Lock()
+			closed := l.closed
+			l.mu.Unlock()
+			if closed {
+				l.wg.Wait()
+				return nil
+			}
+			return err
+		}
+
+		l.mu.Lock()
+		if l.closed {
+			l.mu.Unlock()
+			conn.Close()
+			continue
+		}
+		l.conns[conn] = true
+		l.wg.Add(1)
+		l.mu.Unlock()
+
+		go func() {
+			defer l.wg.Done()
+			defer func() {
+				l.mu.Lock()
+				delete(l.conns, conn)
+				l.mu.Unlock()
+				conn.Close()
+			}()
+
+			handle := func(data []byte) bool {
+				record, err := DecodeRecord(data)
+				if err != nil {
+					return true
+				}
+				fnMu.Lock()
+				err = fn(record)
+				fnMu.Unlock()
+				return err == nil
+			}
+
+			br := bufio.NewReader(conn)
+			if isFramed(br) {
+				fr := NewFrameReader(br)
+				for {
+					payload, err := fr.ReadFrame()
+					if err != nil || !handle(payload) {
+						return
+					}
+				}
+			}
+
+			scanner := bufio.NewScanner(br)
+			scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+			for scanner.Scan() {
+				data := bytes.TrimSpace(scanner.Bytes())
+				if len(data) == 0 {
+					continue
+				}
+				if !handle(data) {
+					return
+				}
+			}
+		}()
+	}
+}
+
+// Addr returns the listener's address
+func (l *SocketListener) Addr() net.Addr {
+	return l.listener.Addr()
+}
+
+// Close stops accepting connections and closes those open; Serve returns once their
+// events are handled
+func (l *SocketListener) Close() error {
+	l.mu.Lock()
+	l.closed = true
+	for conn := range l.conns {
+		conn.Close()
+	}
+	l.mu.Unlock()
+	return l.listener.Close()
+}
@@ -0,0 +1,156 @@
+package lifecycle
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// StackFrame is one frame of a stack trace
+type StackFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// DefaultMaxStackFrames is the default limit on the number of frames in a captured stack
+const DefaultMaxStackFrames = 64
+
+// WithMaxStackFrames limits the frames recorded in structured stacks (default: DefaultMaxStackFrames)
+func WithMaxStackFrames(n int) ProducerOption {
+	return func(p *Producer) {
+		p.maxStackFrames = n
+	}
+}
+
+// CaptureStack returns the calling goroutine's stack as structured frames, innermost first
+// skip is the number of frames to skip; 0 starts at the caller of CaptureStack
+// At most DefaultMaxStackFrames frames are returned
+func CaptureStack(skip int) []StackFrame {
+	return captureStack(skip+1, DefaultMaxStackFrames)
+}
+
+// captureStack captures up to limit frames, skipping skip frames above its caller
+func captureStack(skip, limit int) []StackFrame {
+	if limit <= 0 {
+		return nil
+	}
+
+	// Inlined calls expand to several frames per PC, so collect at least limit PCs
+	pcs := make([]uintptr, limit)
+	n := runtime.Callers(skip+2, pcs) // Skip runtime.Callers and captureStack
+	frames := runtime.CallersFrames(pcs[:n])
+
+	stack := make([]StackFrame, 0, n)
+	for len(stack) < limit {
+		frame, more := frames.Next()
+		if frame.Function != "" || frame.File != "" {
+			stack = append(stack, StackFrame{Function: frame.Function, File: frame.File, Line: frame.Line})
+		}
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+// trimPanicFrames drops the frames between a deferred recover and the code that panicked
+// (the deferred function, runtime.gopanic, and runtime panic helpers such as sigpanic)
+func trimPanicFrames(stack []StackFrame) []StackFrame {
+	for i, frame := range stack {
+		if frame.Function != "runtime.gopanic" {
+			continue
+		}
+		rest := stack[i+1:]
+		for len(rest) > 0 && strings.HasPrefix(rest[0].Function, "runtime.") {
+			rest = rest[1:]
+		}
+		return rest
+	}
+	return stack
+}
+
+// limitFrames returns at most limit frames of stack
+func limitFrames(stack []StackFrame, limit int) []StackFrame {
+	if limit >= 0 && len(stack) > limit {
+		return stack[:limit]
+	}
+	return stack
+}
+
+// FormatStack renders frames in the layout of a Go traceback
+func FormatStack(stack []StackFrame) string {
+	var b strings.Builder
+	for _, frame := range stack {
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+	}
+	return b.String()
+}
+
+// ParseStack parses a Go traceback, such as the output of runtime/debug.Stack or a
+// panic message, into structured frames
+// Only the first goroutine is parsed; argument lists and PC offsets are discarded
+func ParseStack(trace string) []StackFrame {
+	var stack []StackFrame
+	var function string
+	seenGoroutine := false
+
+	for _, line := range strings.Split(trace, "\n") {
+		switch {
+		case strings.HasPrefix(line, "goroutine "):
+			if seenGoroutine {
+				return stack
+			}
+			seenGoroutine = true
+			function = ""
+		case strings.HasPrefix(line, "\t"):
+			if function == "" {
+				continue
+			}
+			file, lineNo := parseFileLine(strings.TrimSpace(line))
+			stack = append(stack, StackFrame{Function: function, File: file, Line: lineNo})
+			function = ""
+		case strings.HasPrefix(line, "created by "):
+			function, _, _ = strings.Cut(strings.TrimPrefix(line, "created by "), " in goroutine ")
+		case line != "":
+			function = trimArgs(line)
+		}
+	}
+	return stack
+}
+
+// trimArgs removes the argument list from a traceback function line
+// e.g., "main.(*T).run(0xc000010000, {0x4b2f40, 0x3})" -> "main.(*T).run"
+func trimArgs(line string) string {
+	if !strings.HasSuffix(line, ")") {
+		return line
+	}
+	depth := 0
+	for i := len(line) - 1; i >= 0; i-- {
+		switch line[i] {
+		case ')':
+			depth++
+		case '(':
+			depth--
+			if depth == 0 {
+				return line[:i]
+			}
+		}
+	}
+	return line
+}
+
+// parseFileLine splits "file.go:12 +0x1d" into its file and line
+func parseFileLine(s string) (string, int) {
+	s, _, _ = strings.Cut(s, " +0x")
+	i := strings.LastIndexByte(s, ':')
+	if i < 0 {
+		return s, 0
+	}
+	line, err := strconv.Atoi(s[i+1:])
+	if err != nil {
+		return s, 0
+	}
+	return s[:i], line
+}
@@ -0,0 +1,89 @@
+package lifecycle
+
+import "strconv"
+
+// StatusCodeColorer picks the color of HTTP status codes in styled output, for mappings
+// that status code classes cannot express; set it with WithStatusCodeColorer
+type StatusCodeColorer interface {
+	// StatusCodeColor returns the color of code, or "" for none
+	StatusCodeColor(code int32) string
+}
+
+// StatusCodeColorerFunc adapts a function to StatusCodeColorer
+type StatusCodeColorerFunc func(code int32) string
+
+// StatusCodeColor calls f
+func (f StatusCodeColorerFunc) StatusCodeColor(code int32) string {
+	return f(code)
+}
+
+// WithStatusCodeColorer colors status codes with colorer instead of the color registry's
+// status code mappings (see ColorRegistry.RegisterStatusCode)
+func WithStatusCodeColorer(colorer StatusCodeColorer) StyledOutputOption {
+	return func(s *StyledOutput) {
+		s.codeColorer = colorer
+	}
+}
+
+// defaultStatusCodes returns the status each status code class is colored as
+// 499 is the de facto code for requests the client gave up on, which are not errors
+func defaultStatusCodes() map[string]string {
+	return map[string]string{
+		"2xx": "success",
+		"3xx": "info",
+		"4xx": "warning",
+		"5xx": "error",
+		"499": "cancelled",
+	}
+}
+
+// RegisterStatusCode colors a status code ("404") or class ("4xx") as status, whose
+// color is registered with RegisterStatusColor (e.g., "info" to stop 404s standing out)
+// Codes take precedence over their class; an empty status removes the mapping
+func (r *ColorRegistry) RegisterStatusCode(code, status string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if status == "" {
+		delete(r.statusCodes, code)
+	} else {
+		r.statusCodes[code] = status
+	}
+	r.snapshot.Store(nil)
+}
+
+// GetStatusCodeColor returns the color for an HTTP status code, or empty string if its
+// code and class are not mapped
+func (r *ColorRegistry) GetStatusCodeColor(code int32) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if status := lookupStatusCode(r.statusCodes, code); status != "" {
+		if color, ok := r.statusColors[status]; ok {
+			return color
+		}
+		return "#808080" // Gray, as for unknown statuses
+	}
+	return ""
+}
+
+// GetStatusCodeColor returns the color for an HTTP status code, or empty string if its
+// code and class are not mapped
+func (s *ColorSnapshot) GetStatusCodeColor(code int32) string {
+	if s == nil {
+		return ""
+	}
+	if status := lookupStatusCode(s.statusCodes, code); status != "" {
+		return s.GetStatusColor(status)
+	}
+	return ""
+}
+
+// lookupStatusCode returns the status code is colored as, by its exact code or class
+func lookupStatusCode(statusCodes map[string]string, code int32) string {
+	if status, ok := statusCodes[strconv.Itoa(int(code))]; ok {
+		return status
+	}
+	if code >= 100 && code < 600 {
+		return statusCodes[strconv.Itoa(int(code/100))+"xx"]
+	}
+	return ""
+}
@@ -4,18 +4,45 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/log"
+	"github.com/muesli/termenv"
 )
 
 // StyledOutput provides beautiful terminal styling for lifecycle events
 // while maintaining structured JSON output for log aggregation
+// It is safe for concurrent use; each event is written atomically
 type StyledOutput struct {
+	mu            sync.Mutex // Serializes event writes
 	logger        *log.Logger
 	jsonOutput    io.Writer      // Separate JSON output for log aggregation
 	jsonOnly      bool           // If true, only output JSON (no styling)
 	colorRegistry *ColorRegistry // Color registry for services, APIs, events, statuses
+	themeName     string         // Theme applied to the color registry (empty = registry defaults)
+	colorMode     ColorMode      // Whether to emit ANSI colors
+	colorDepth    ColorDepth     // Colors to render with when colors are enabled
+	layout        Layout         // How each event is arranged
+	writer        io.Writer      // Terminal writer for the logger and multi-line blocks
+	renderer      *lipgloss.Renderer
+
+	timeFormat   string                       // Layout of the timestamp field
+	hideLevels   bool                         // Omit the level label
+	levelStyles  map[log.Level]lipgloss.Style // Optional: replace the logger's level styles
+	loggerConfig []func(*log.Logger)          // Applied to the logger after the defaults
+	rawDurations bool                         // Show *_ms fields as numbers
+	lastSeen     map[string]time.Time         // Optional: last event time by correlation ID
+	grouping     bool                         // Group events by correlation ID
+	groupID      string                       // Correlation ID of the current group
+	fieldWidths  map[string]int               // Display widths by field (nil: defaultFieldWidths)
+	maxWidth     int                          // Display width of other fields (0: unlimited)
+	stackLines   int                          // Stack trace lines shown (0: all)
+	verbose      bool                         // Show values in full
+	icons        bool                         // Start lines with event icons
+	codeColorer  StatusCodeColorer            // Optional: replaces the registry's status code colors
 }
 
 // StyledOutputOption configures the styled output
@@ -43,6 +70,64 @@ func WithStyledLogger(logger *log.Logger) StyledOutputOption {
 	}
 }
 
+// WithStyledWriter sets where styled output is written, replacing the writer passed to
+// NewStyledOutput, which can then be nil (e.g., for JSON-only output with WithJSONOutput)
+func WithStyledWriter(w io.Writer) StyledOutputOption {
+	return func(s *StyledOutput) {
+		s.writer = w
+	}
+}
+
+// WithStyledTimeFormat sets the time.Format layout of the timestamp field (default:
+// time.RFC3339), e.g. time.Kitchen or "15:04:05.000" for terminal tailing
+func WithStyledTimeFormat(layout string) StyledOutputOption {
+	return func(s *StyledOutput) {
+		s.timeFormat = layout
+	}
+}
+
+// WithStyledLevels sets whether each line starts with its level label (default: true)
+func WithStyledLevels(show bool) StyledOutputOption {
+	return func(s *StyledOutput) {
+		s.hideLevels = !show
+	}
+}
+
+// WithStyledLevelStyles replaces the styles of level labels, keeping the defaults of
+// levels not in styles:
+//
+//	lifecycle.WithStyledLevelStyles(map[log.Level]lipgloss.Style{
+//		log.ErrorLevel: lipgloss.NewStyle().SetString("ERROR").Bold(true).Foreground(lipgloss.Color("9")),
+//	})
+func WithStyledLevelStyles(styles map[log.Level]lipgloss.Style) StyledOutputOption {
+	return func(s *StyledOutput) {
+		if s.levelStyles == nil {
+			s.levelStyles = make(map[log.Level]lipgloss.Style, len(styles))
+		}
+		for level, style := range styles {
+			s.levelStyles[level] = style
+		}
+	}
+}
+
+// WithStyledLoggerConfig adjusts the charmbracelet/log logger once it is configured,
+// without replacing it as WithStyledLogger does (e.g., to set a prefix, report the time
+// of writing, or change the formatter):
+//
+//	lifecycle.WithStyledLoggerConfig(func(l *log.Logger) {
+//		l.SetPrefix("api")
+//		l.SetReportTimestamp(true)
+//		l.SetTimeFormat(time.Kitchen)
+//	})
+//
+// Callers reported with SetReportCaller are inside this package rather than the code that
+// emitted the event; the source of events that record one is shown as a field instead
+func WithStyledLoggerConfig(configure func(*log.Logger)) StyledOutputOption {
+	return func(s *StyledOutput) {
+		s.loggerConfig = append(s.loggerConfig, configure)
+	}
+}
+
 // WithStyledColorRegistry sets a color registry for styled output
 func WithStyledColorRegistry(registry *ColorRegistry) StyledOutputOption {
 	return func(s *StyledOutput) {
@@ -50,22 +135,75 @@ func WithStyledColorRegistry(registry *ColorRegistry) StyledOutputOption {
 	}
 }
 
-// NewStyledOutput creates a new styled output handler
-func NewStyledOutput(w io.Writer, opts ...StyledOutputOption) *StyledOutput {
-	// Create logger - charmbracelet/log will auto-detect color support
-	logger := log.New(w)
-	logger.SetReportCaller(false) // Don't report caller
+// WithTheme applies a named theme (see ThemeDark, ThemeLight, ThemeHighContrast,
+// ThemeColorblind, or ThemeAuto) to the styled output's color registry
+// Unknown theme names leave the registry's colors unchanged
+func WithTheme(name string) StyledOutputOption {
+	return func(s *StyledOutput) {
+		s.themeName = name
+	}
+}
+
+// WithColorMode sets when styled output uses colors (default: ColorModeAuto)
+// In auto mode, output redirected to a file or pipe degrades to plain text
+func WithColorMode(mode ColorMode) StyledOutputOption {
+	return func(s *StyledOutput) {
+		s.colorMode = mode
+	}
+}
 
+// NewStyledOutput creates a new styled output handler writing to w
+func NewStyledOutput(w io.Writer, opts ...StyledOutputOption) *StyledOutput {
 	s := &StyledOutput{
-		logger:        logger,
+		writer:        w,
 		jsonOutput:    nil, // No separate JSON output by default
 		jsonOnly:      false,
 		colorRegistry: NewColorRegistry(), // Default color registry
+		timeFormat:    time.RFC3339,
+		stackLines:    defaultStackLines,
 	}
 
 	for _, opt := range opts {
 		opt(s)
 	}
+	if s.writer == nil {
+		s.writer = io.Discard
+	}
+
+	if s.logger == nil {
+		// Create logger - charmbracelet/log will auto-detect color support
+		s.logger = log.New(s.writer)
+		s.logger.SetReportCaller(false)   // Don't report caller
+		s.logger.SetLevel(log.DebugLevel) // Filtering is the producer's job (see Producer.SetLogLevel)
+	}
+
+	// Bind colors to the actual writer rather than lipgloss's global stdout renderer
+	profile := applyColorDepth(resolveColorProfile(s.writer, s.colorMode), s.colorDepth)
+	profile = enableTerminalColors(s.writer, profile)
+	s.renderer = lipgloss.NewRenderer(s.writer)
+	s.renderer.SetColorProfile(profile)
+	s.logger.SetColorProfile(profile)
+
+	if s.hideLevels || s.levelStyles != nil {
+		styles := log.DefaultStyles()
+		for level, style := range s.levelStyles {
+			styles.Levels[level] = style
+		}
+		if s.hideLevels {
+			styles.Levels = map[log.Level]lipgloss.Style{}
+		}
+		s.logger.SetStyles(styles)
+	}
+	for _, configure := range s.loggerConfig {
+		configure(s.logger)
+	}
+
+	// Apply the theme after all options so it targets the final color registry
+	if s.themeName != "" && s.colorRegistry != nil {
+		if theme, err := LookupTheme(s.themeName); err == nil {
+			s.colorRegistry.ApplyTheme(theme)
+		}
+	}
 
 	return s
 }
@@ -73,6 +211,9 @@ func NewStyledOutput(w io.Writer, opts ...StyledOutputOption) *StyledOutput {
 // WriteEvent writes a lifecycle event with beautiful styling
 // Also writes JSON to jsonOutput if configured
 func (s *StyledOutput) WriteEvent(event Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	// Always write JSON if jsonOutput is configured (for log aggregation)
 	if s.jsonOutput != nil {
 		jsonData, err := json.Marshal(event)
@@ -97,24 +238,47 @@ func (s *StyledOutput) WriteEvent(event Event) error {
 func (s *StyledOutput) writeStyledEvent(event Event) error {
 	eventType := event.GetEventType()
 
-	// Determine log level from event type
+	// Determine log level from event type (intercepted log lines carry their own level)
 	level := s.eventTypeToLevel(eventType)
+	if logEvent, ok := event.(*LogMessageEvent); ok {
+		level = styledLogLevel(logEvent.Level, level)
+	}
+
+	// Take a snapshot so color lookups for this event are lock-free and consistent
+	colors := s.colorSnapshot()
 
 	// Get event color from registry
-	eventColor := ""
-	if s.colorRegistry != nil {
-		eventColor = s.colorRegistry.GetEventColor(eventType)
-	}
+	eventColor := colors.GetEventColor(eventType)
 
 	// Build key-value pairs for structured logging
-	fields := s.buildFields(event, eventColor)
+	fields := s.buildFields(event, colors)
+
+	// Arrange message and fields for the configured layout
+	message, fields := s.applyLayout(eventType, fields)
 
 	// Format event type with color if available
-	styledEventType := eventType
+	styledEventType := message
 	if eventColor != "" {
-		styledEventType = FormatWithColor(eventType, eventColor)
+		styledEventType = s.formatWithColor(message, eventColor)
 	}
 
+	if icon := s.eventIcon(eventType); icon != "" {
+		styledEventType = icon + " " + styledEventType
+	}
+
+	// Draw grouped events as branches of their correlation's first event
+	if connector := s.groupConnector(event); connector != "" {
+		styledEventType = connector + styledEventType
+		fields = omitFields(fields, "correlation_id")
+	}
+
+	// Stack traces are shown below the event line, and long values are cut short
+	var stack string
+	if s.layout != LayoutWide {
+		fields, stack = takeStackTrace(fields)
+	}
+	s.truncateFields(fields)
+
 	// Use charmbracelet/log's structured logging
 	switch level {
 	case log.DebugLevel:
@@ -131,49 +295,69 @@ func (s *StyledOutput) writeStyledEvent(event Event) error {
 		s.logger.Info(styledEventType, fields...)
 	}
 
+	if stack != "" {
+		s.writeStackTrace(stack)
+	}
+	if s.layout == LayoutWide {
+		s.writeWideDetails(event, colors)
+	}
+
 	return nil
 }
 
 // eventTypeToLevel maps event types to log levels
+// charmbracelet/log levels share slog's values
 func (s *StyledOutput) eventTypeToLevel(eventType string) log.Level {
-	switch {
-	case contains(eventType, "error", "errored", "failed", "crashed"):
-		return log.ErrorLevel
-	case contains(eventType, "warn", "warning"):
-		return log.WarnLevel
-	case contains(eventType, "debug", "trace"):
-		return log.DebugLevel
-	case contains(eventType, "started", "completed", "handled", "created", "updated", "deleted"):
-		return log.InfoLevel
-	default:
-		return log.InfoLevel
+	return log.Level(eventTypeLevel(eventType))
+}
+
+// colorSnapshot returns a snapshot of the color registry, or nil if none is configured
+// or colors are disabled
+func (s *StyledOutput) colorSnapshot() *ColorSnapshot {
+	if s.colorRegistry == nil || s.renderer.ColorProfile() == termenv.Ascii {
+		return nil
+	}
+	return s.colorRegistry.Snapshot()
+}
+
+// formatWithColor formats text with the given color using the output's renderer
+func (s *StyledOutput) formatWithColor(text, color string) string {
+	if color == "" {
+		return text
+	}
+	return s.renderer.NewStyle().Foreground(s.terminalColor(color)).Render(text)
+}
+
+// styledLogLevel parses a slog level name (e.g., "WARN" or "INFO+2"), returning fallback if unknown
+func styledLogLevel(name string, fallback log.Level) log.Level {
+	if i := strings.IndexAny(name, "+-"); i > 0 {
+		name = name[:i]
+	}
+	level, err := log.ParseLevel(strings.ToLower(name))
+	if err != nil {
+		return fallback
 	}
+	return level
 }
 
 // buildFields extracts key-value pairs from the event for structured logging
 // Colors are applied to service, API, and status fields
-func (s *StyledOutput) buildFields(event Event, eventColor string) []interface{} {
+func (s *StyledOutput) buildFields(event Event, colors *ColorSnapshot) []interface{} {
 	fields := []interface{}{}
 
 	// Add base event fields from Event interface with colors
 	if service := event.GetService(); service != "" {
-		serviceColor := ""
-		if s.colorRegistry != nil {
-			serviceColor = s.colorRegistry.GetServiceColor(service)
-		}
+		serviceColor := colors.GetServiceColor(service)
 		if serviceColor != "" {
-			fields = append(fields, "service", FormatWithColor(service, serviceColor))
+			fields = append(fields, "service", s.formatWithColor(service, serviceColor))
 		} else {
 			fields = append(fields, "service", service)
 		}
 	}
 	if api := event.GetAPI(); api != "" {
-		apiColor := ""
-		if s.colorRegistry != nil {
-			apiColor = s.colorRegistry.GetAPIColor(api)
-		}
+		apiColor := colors.GetAPIColor(api)
 		if apiColor != "" {
-			fields = append(fields, "api", FormatWithColor(api, apiColor))
+			fields = append(fields, "api", s.formatWithColor(api, apiColor))
 		} else {
 			fields = append(fields, "api", api)
 		}
@@ -185,17 +369,30 @@ func (s *StyledOutput) buildFields(event Event, eventColor string) []interface{}
 		fields = append(fields, "correlation_id", correlationID)
 	}
 	if !event.GetTimestamp().IsZero() {
-		fields = append(fields, "timestamp", event.GetTimestamp().Format(time.RFC3339))
+		fields = append(fields, "timestamp", event.GetTimestamp().Format(s.timeFormat))
+	}
+	if delta := s.relativeTime(event.GetCorrelationID(), event.GetTimestamp()); delta != "" {
+		fields = append(fields, "delta", delta)
 	}
 
 	// Add event-specific fields based on event type (with status colors)
-	s.addEventSpecificFields(event, &fields)
+	s.addEventSpecificFields(event, colors, &fields)
+	if !s.rawDurations {
+		humanizeDurations(fields)
+	}
 
 	return fields
 }
 
 // addEventSpecificFields extracts fields from specific event types
-func (s *StyledOutput) addEventSpecificFields(event Event, fields *[]interface{}) {
+// Events implementing StyledFielder supply their own fields; event types without a
+// dedicated case below are rendered generically via reflection
+func (s *StyledOutput) addEventSpecificFields(event Event, colors *ColorSnapshot, fields *[]interface{}) {
+	if fielder, ok := event.(StyledFielder); ok {
+		*fields = append(*fields, fielder.StyledFields()...)
+		return
+	}
+
 	switch e := event.(type) {
 	case *ServiceStartedEvent:
 		if e != nil && e.Base != nil {
@@ -222,14 +419,30 @@ func (s *StyledOutput) addEventSpecificFields(event Event, fields *[]interface{}
 			if e.Reason != "" {
 				*fields = append(*fields, "reason", e.Reason)
 			}
-			if e.StackTrace != "" {
-				*fields = append(*fields, "stack_trace", e.StackTrace)
+			if stackTrace := stackText(e.StackTrace, e.Stack); stackTrace != "" {
+				*fields = append(*fields, "stack_trace", stackTrace)
 			}
 			if e.ExitCode != 0 {
 				*fields = append(*fields, "exit_code", e.ExitCode)
 			}
 		}
 
+	case *ErrorOccurredEvent:
+		if e != nil && e.Base != nil {
+			if e.Kind != "" {
+				*fields = append(*fields, "kind", e.Kind)
+			}
+			if e.ErrorMessage != "" {
+				*fields = append(*fields, "error", e.ErrorMessage)
+			}
+			if e.Source != nil {
+				*fields = append(*fields, "source", fmt.Sprintf("%s:%d", e.Source.File, e.Source.Line))
+			}
+			if stackTrace := stackText(e.StackTrace, e.Stack); stackTrace != "" {
+				*fields = append(*fields, "stack_trace", stackTrace)
+			}
+		}
+
 	case *RequestReceivedEvent:
 		if e != nil && e.Base != nil {
 			if e.Method != "" {
@@ -238,9 +451,15 @@ func (s *StyledOutput) addEventSpecificFields(event Event, fields *[]interface{}
 			if e.Path != "" {
 				*fields = append(*fields, "path", e.Path)
 			}
+			if e.Route != "" && e.Route != e.Path {
+				*fields = append(*fields, "route", e.Route)
+			}
 			if e.UserAgent != "" {
 				*fields = append(*fields, "user_agent", e.UserAgent)
 			}
+			if e.Client != nil {
+				*fields = append(*fields, "client", e.Client.String())
+			}
 			if e.RemoteAddr != "" {
 				*fields = append(*fields, "remote_addr", e.RemoteAddr)
 			}
@@ -251,9 +470,9 @@ func (s *StyledOutput) addEventSpecificFields(event Event, fields *[]interface{}
 			if e.StatusCode > 0 {
 				statusStr := fmt.Sprintf("%d", e.StatusCode)
 				// Color status code based on HTTP status
-				statusColor := s.getStatusCodeColor(e.StatusCode)
+				statusColor := s.getStatusCodeColor(colors, e.StatusCode)
 				if statusColor != "" {
-					*fields = append(*fields, "status_code", FormatWithColor(statusStr, statusColor))
+					*fields = append(*fields, "status_code", s.formatWithColor(statusStr, statusColor))
 				} else {
 					*fields = append(*fields, "status_code", e.StatusCode)
 				}
@@ -272,12 +491,9 @@ func (s *StyledOutput) addEventSpecificFields(event Event, fields *[]interface{}
 			}
 			// Add status with color
 			if e.Status != "" {
-				statusColor := ""
-				if s.colorRegistry != nil {
-					statusColor = s.colorRegistry.GetStatusColor(string(e.Status))
-				}
+				statusColor := colors.GetStatusColor(string(e.Status))
 				if statusColor != "" {
-					*fields = append(*fields, "status", FormatWithColor(string(e.Status), statusColor))
+					*fields = append(*fields, "status", s.formatWithColor(string(e.Status), statusColor))
 				} else {
 					*fields = append(*fields, "status", string(e.Status))
 				}
@@ -288,9 +504,9 @@ func (s *StyledOutput) addEventSpecificFields(event Event, fields *[]interface{}
 		if e != nil && e.Base != nil {
 			if e.StatusCode > 0 {
 				statusStr := fmt.Sprintf("%d", e.StatusCode)
-				statusColor := s.getStatusCodeColor(e.StatusCode)
+				statusColor := s.getStatusCodeColor(colors, e.StatusCode)
 				if statusColor != "" {
-					*fields = append(*fields, "status_code", FormatWithColor(statusStr, statusColor))
+					*fields = append(*fields, "status_code", s.formatWithColor(statusStr, statusColor))
 				} else {
 					*fields = append(*fields, "status_code", e.StatusCode)
 				}
@@ -306,12 +522,9 @@ func (s *StyledOutput) addEventSpecificFields(event Event, fields *[]interface{}
 			}
 			// Add status with color (error status)
 			if e.Status != "" {
-				statusColor := ""
-				if s.colorRegistry != nil {
-					statusColor = s.colorRegistry.GetStatusColor(string(e.Status))
-				}
+				statusColor := colors.GetStatusColor(string(e.Status))
 				if statusColor != "" {
-					*fields = append(*fields, "status", FormatWithColor(string(e.Status), statusColor))
+					*fields = append(*fields, "status", s.formatWithColor(string(e.Status), statusColor))
 				} else {
 					*fields = append(*fields, "status", string(e.Status))
 				}
@@ -363,12 +576,9 @@ func (s *StyledOutput) addEventSpecificFields(event Event, fields *[]interface{}
 				*fields = append(*fields, "resource", e.Resource.ID)
 			}
 			// Status is "created"
-			statusColor := ""
-			if s.colorRegistry != nil {
-				statusColor = s.colorRegistry.GetStatusColor("created")
-			}
+			statusColor := colors.GetStatusColor("created")
 			if statusColor != "" {
-				*fields = append(*fields, "status", FormatWithColor("created", statusColor))
+				*fields = append(*fields, "status", s.formatWithColor("created", statusColor))
 			} else {
 				*fields = append(*fields, "status", "created")
 			}
@@ -379,12 +589,9 @@ func (s *StyledOutput) addEventSpecificFields(event Event, fields *[]interface{}
 				*fields = append(*fields, "resource", e.Resource.ID)
 			}
 			// Status is "updated"
-			statusColor := ""
-			if s.colorRegistry != nil {
-				statusColor = s.colorRegistry.GetStatusColor("updated")
-			}
+			statusColor := colors.GetStatusColor("updated")
 			if statusColor != "" {
-				*fields = append(*fields, "status", FormatWithColor("updated", statusColor))
+				*fields = append(*fields, "status", s.formatWithColor("updated", statusColor))
 			} else {
 				*fields = append(*fields, "status", "updated")
 			}
@@ -395,37 +602,28 @@ func (s *StyledOutput) addEventSpecificFields(event Event, fields *[]interface{}
 				*fields = append(*fields, "resource", e.Resource.ID)
 			}
 			// Status is "deleted"
-			statusColor := ""
-			if s.colorRegistry != nil {
-				statusColor = s.colorRegistry.GetStatusColor("deleted")
-			}
+			statusColor := colors.GetStatusColor("deleted")
 			if statusColor != "" {
-				*fields = append(*fields, "status", FormatWithColor("deleted", statusColor))
+				*fields = append(*fields, "status", s.formatWithColor("deleted", statusColor))
 			} else {
 				*fields = append(*fields, "status", "deleted")
 			}
 		}
+
+	default:
+		*fields = append(*fields, s.reflectFields(event, colors)...)
 	}
 }
 
 // getStatusCodeColor returns a color for HTTP status codes
-func (s *StyledOutput) getStatusCodeColor(statusCode int32) string {
-	if s.colorRegistry == nil {
+func (s *StyledOutput) getStatusCodeColor(colors *ColorSnapshot, statusCode int32) string {
+	if colors == nil {
 		return ""
 	}
-
-	switch {
-	case statusCode >= 200 && statusCode < 300:
-		return s.colorRegistry.GetStatusColor("success")
-	case statusCode >= 300 && statusCode < 400:
-		return s.colorRegistry.GetStatusColor("info")
-	case statusCode >= 400 && statusCode < 500:
-		return s.colorRegistry.GetStatusColor("warning")
-	case statusCode >= 500:
-		return s.colorRegistry.GetStatusColor("error")
-	default:
-		return ""
+	if s.codeColorer != nil {
+		return s.codeColorer.StatusCodeColor(statusCode)
 	}
+	return colors.GetStatusCodeColor(statusCode)
 }
 
 // contains checks if any of the substrings are contained in the string
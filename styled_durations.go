@@ -0,0 +1,96 @@
+package lifecycle
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRelativeCorrelations bounds the correlation IDs tracked for relative times; the
+// oldest are forgotten all at once when it is reached
+const maxRelativeCorrelations = 4096
+
+// WithStyledRawDurations shows *_ms fields as raw millisecond counts (e.g.,
+// "duration_ms=1234") instead of durations ("duration=1.2s")
+func WithStyledRawDurations() StyledOutputOption {
+	return func(s *StyledOutput) {
+		s.rawDurations = true
+	}
+}
+
+// WithStyledRelativeTimes adds a "delta" field with the time since the previous event
+// of the same correlation (e.g., "delta=+12ms"), making request flows easy to follow
+// when tailing
+func WithStyledRelativeTimes() StyledOutputOption {
+	return func(s *StyledOutput) {
+		s.lastSeen = make(map[string]time.Time)
+	}
+}
+
+// relativeTime returns the time since the previous event of a correlation and records
+// this event's time, or "" for the first event
+func (s *StyledOutput) relativeTime(correlationID string, t time.Time) string {
+	if s.lastSeen == nil || correlationID == "" || t.IsZero() {
+		return ""
+	}
+	previous, ok := s.lastSeen[correlationID]
+	if !ok && len(s.lastSeen) >= maxRelativeCorrelations {
+		clear(s.lastSeen)
+	}
+	s.lastSeen[correlationID] = t
+	if !ok {
+		return ""
+	}
+	d := t.Sub(previous)
+	if d < 0 {
+		return humanDuration(d)
+	}
+	return "+" + humanDuration(d)
+}
+
+// humanizeDurations rewrites *_ms fields holding millisecond counts as durations
+func humanizeDurations(fields []interface{}) {
+	for i := 0; i+1 < len(fields); i += 2 {
+		key, ok := fields[i].(string)
+		if !ok || !strings.HasSuffix(key, "_ms") {
+			continue
+		}
+		var ms float64
+		switch v := fields[i+1].(type) {
+		case int64:
+			ms = float64(v)
+		case int32:
+			ms = float64(v)
+		case int:
+			ms = float64(v)
+		case float64:
+			ms = v
+		default:
+			continue
+		}
+		fields[i] = strings.TrimSuffix(key, "_ms")
+		fields[i+1] = humanDuration(time.Duration(ms * float64(time.Millisecond)))
+	}
+}
+
+// humanDuration formats d for people at a precision that suits its size: "850µs",
+// "35ms", "1.2s", "2m5s", "1h30m"
+func humanDuration(d time.Duration) string {
+	switch {
+	case d < 0:
+		return "-" + humanDuration(-d)
+	case d < time.Millisecond:
+		return d.Round(time.Microsecond).String()
+	case d < time.Second:
+		return fmt.Sprintf("%dms", d.Round(time.Millisecond).Milliseconds())
+	case d < time.Minute:
+		return strings.TrimSuffix(strconv.FormatFloat(d.Seconds(), 'f', 1, 64), ".0") + "s"
+	}
+	s := d.Round(time.Second).String()
+	s = strings.TrimSuffix(s, "0s")
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
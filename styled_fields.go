@@ -0,0 +1,99 @@
+package lifecycle
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// StyledFielder is implemented by events that choose their own styled output fields
+// StyledFields returns alternating key/value pairs, like the arguments to a charmbracelet/log call
+// Base event fields (service, api, host, correlation_id, timestamp) are added automatically
+type StyledFielder interface {
+	StyledFields() []interface{}
+}
+
+var (
+	baseEventType    = reflect.TypeOf(BaseEvent{})
+	baseEventPtrType = reflect.TypeOf(&BaseEvent{})
+	timeType         = reflect.TypeOf(time.Time{})
+)
+
+// reflectFields extracts styled fields from an arbitrary event struct via reflection
+// Field names come from json tags, zero values are skipped, the embedded base event is
+// skipped, and nested structs are flattened with dotted keys (e.g., "actor.user_id")
+func (s *StyledOutput) reflectFields(event Event, colors *ColorSnapshot) []interface{} {
+	v := reflect.ValueOf(event)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields []interface{}
+	s.appendStructFields(&fields, v, "", colors)
+	return fields
+}
+
+// appendStructFields appends the non-zero exported fields of v, prefixing keys with prefix
+func (s *StyledOutput) appendStructFields(fields *[]interface{}, v reflect.Value, prefix string, colors *ColorSnapshot) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || field.Type == baseEventType || field.Type == baseEventPtrType {
+			continue
+		}
+
+		name, skip := styledFieldName(field)
+		if skip {
+			continue
+		}
+		key := prefix + name
+
+		value := v.Field(i)
+		if value.IsZero() {
+			continue
+		}
+		for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+			if value.IsNil() {
+				break
+			}
+			value = value.Elem()
+		}
+
+		switch {
+		case value.Kind() == reflect.Struct && value.Type() != timeType && field.Anonymous && field.Tag.Get("json") == "":
+			// Embedded structs are flattened like encoding/json does
+			s.appendStructFields(fields, value, prefix, colors)
+		case value.Kind() == reflect.Struct && value.Type() != timeType:
+			s.appendStructFields(fields, value, key+".", colors)
+		case value.Type() == timeType:
+			*fields = append(*fields, key, value.Interface().(time.Time).Format(time.RFC3339))
+		case name == "status" && value.Kind() == reflect.String:
+			status := value.String()
+			*fields = append(*fields, key, s.formatWithColor(status, colors.GetStatusColor(status)))
+		case name == "status_code" && value.CanInt():
+			code := int32(value.Int())
+			*fields = append(*fields, key, s.formatWithColor(fmt.Sprintf("%d", code), s.getStatusCodeColor(colors, code)))
+		default:
+			*fields = append(*fields, key, value.Interface())
+		}
+	}
+}
+
+// styledFieldName returns the display name for a struct field, taken from its json tag
+func styledFieldName(field reflect.StructField) (name string, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	if name, _, _ = strings.Cut(tag, ","); name != "" {
+		return name, false
+	}
+	return field.Name, false
+}
@@ -0,0 +1,44 @@
+package lifecycle
+
+// Tree connectors drawn before grouped events
+const (
+	groupBranch = "├─ "
+	groupLast   = "└─ "
+)
+
+// WithStyledGrouping groups consecutive events sharing a correlation ID under the first
+// of them (typically api.request.received) with tree connectors, so a request's queries,
+// calls, and outcome read as one block:
+//
+//	INFO api.request.received correlation_id=4f2a method=GET path=/users/42
+//	INFO ├─ db.query.completed duration=3ms
+//	INFO └─ api.request.handled status_code=200 duration=5ms
+//
+// A request outcome closes its group. Grouped events omit the correlation ID
+// Concurrent requests interleave, so grouping reads best when requests are sequential,
+// such as in development or when replaying one correlation
+func WithStyledGrouping() StyledOutputOption {
+	return func(s *StyledOutput) {
+		s.grouping = true
+	}
+}
+
+// groupConnector returns the tree connector of event, or "" if it starts a group or is
+// not grouped, and tracks the current group
+func (s *StyledOutput) groupConnector(event Event) string {
+	if !s.grouping {
+		return ""
+	}
+	correlationID := event.GetCorrelationID()
+	if correlationID == "" || correlationID != s.groupID {
+		s.groupID = correlationID
+		return ""
+	}
+
+	switch event.GetEventType() {
+	case "api.request.handled", "api.request.errored":
+		s.groupID = ""
+		return groupLast
+	}
+	return groupBranch
+}
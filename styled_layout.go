@@ -0,0 +1,181 @@
+package lifecycle
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Layout controls how styled output arranges each event
+type Layout int
+
+const (
+	// LayoutDefault renders one line per event with all fields
+	LayoutDefault Layout = iota
+	// LayoutCompact renders a dense single line, omitting service, host, and timestamp
+	LayoutCompact
+	// LayoutWide aligns event types into a column and renders errors, stack traces,
+	// and nested resource data as indented blocks below the event line
+	LayoutWide
+)
+
+// wideEventTypeWidth is the column width event types are padded to in LayoutWide
+const wideEventTypeWidth = 28
+
+// compactOmittedFields are the base fields dropped in LayoutCompact
+var compactOmittedFields = []string{"service", "host", "timestamp"}
+
+// WithLayout sets the rendering layout for styled output (default: LayoutDefault)
+func WithLayout(layout Layout) StyledOutputOption {
+	return func(s *StyledOutput) {
+		s.layout = layout
+	}
+}
+
+// applyLayout adjusts the event message and fields for the configured layout
+func (s *StyledOutput) applyLayout(eventType string, fields []interface{}) (string, []interface{}) {
+	switch s.layout {
+	case LayoutCompact:
+		return eventType, omitFields(fields, compactOmittedFields...)
+	case LayoutWide:
+		// Stack traces are rendered in a box below the event line
+		return fmt.Sprintf("%-*s", wideEventTypeWidth, eventType), omitFields(fields, "stack_trace")
+	default:
+		return eventType, fields
+	}
+}
+
+// writeWideDetails writes the multi-line blocks shown below an event in LayoutWide
+func (s *StyledOutput) writeWideDetails(event Event, colors *ColorSnapshot) {
+	var blocks []string
+
+	if details := s.limitLines(errorDetails(event)); details != "" {
+		box := s.renderer.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			Padding(0, 1).
+			MarginLeft(2)
+		if color := colors.GetStatusColor("error"); color != "" {
+			box = box.BorderForeground(s.terminalColor(color))
+		}
+		blocks = append(blocks, box.Render(details))
+	}
+
+	if data := nestedData(event); len(data) > 0 {
+		var b strings.Builder
+		writeIndentedData(&b, data, 1)
+		blocks = append(blocks, strings.TrimRight(b.String(), "\n"))
+	}
+
+	for _, block := range blocks {
+		fmt.Fprintln(s.writer, block)
+	}
+}
+
+// errorDetails returns the error message, code, and stack trace of error-class events
+func errorDetails(event Event) string {
+	var lines []string
+	switch e := event.(type) {
+	case *RequestErroredEvent:
+		lines = appendDetail(lines, "error", e.ErrorMessage)
+		lines = appendDetail(lines, "code", e.ErrorCode)
+	case *QueryErroredEvent:
+		lines = appendDetail(lines, "error", e.ErrorMessage)
+		lines = appendDetail(lines, "code", e.ErrorCode)
+	case *ServiceCrashedEvent:
+		lines = appendDetail(lines, "reason", e.Reason)
+		if stackTrace := stackText(e.StackTrace, e.Stack); stackTrace != "" {
+			lines = append(lines, "", stackTrace)
+		}
+	case *ErrorOccurredEvent:
+		lines = appendDetail(lines, "error", e.ErrorMessage)
+		lines = appendDetail(lines, "kind", e.Kind)
+		if stackTrace := stackText(e.StackTrace, e.Stack); stackTrace != "" {
+			lines = append(lines, "", stackTrace)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// stackText returns an event's stack trace text, rendering structured frames when
+// no text was recorded
+func stackText(stackTrace string, stack []StackFrame) string {
+	if stackTrace == "" {
+		stackTrace = FormatStack(stack)
+	}
+	return strings.TrimRight(stackTrace, "\n")
+}
+
+// appendDetail appends "label: value" when value is non-empty
+func appendDetail(lines []string, label, value string) []string {
+	if value == "" {
+		return lines
+	}
+	return append(lines, label+": "+value)
+}
+
+// nestedData returns the resource payload carried by resource events
+func nestedData(event Event) map[string]interface{} {
+	switch e := event.(type) {
+	case *ResourceCreatedEvent:
+		return e.ResourceData
+	case *ResourceUpdatedEvent:
+		return e.NewData
+	case *ResourceDeletedEvent:
+		return e.FinalData
+	}
+	return nil
+}
+
+// writeIndentedData writes data as an indented key/value tree with sorted keys
+func writeIndentedData(b *strings.Builder, data map[string]interface{}, depth int) {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	indent := strings.Repeat("  ", depth)
+	for _, key := range keys {
+		switch value := data[key].(type) {
+		case map[string]interface{}:
+			fmt.Fprintf(b, "%s%s:\n", indent, key)
+			writeIndentedData(b, value, depth+1)
+		case []interface{}:
+			fmt.Fprintf(b, "%s%s:\n", indent, key)
+			for _, item := range value {
+				if nested, ok := item.(map[string]interface{}); ok {
+					fmt.Fprintf(b, "%s  -\n", indent)
+					writeIndentedData(b, nested, depth+2)
+				} else {
+					fmt.Fprintf(b, "%s  - %v\n", indent, item)
+				}
+			}
+		default:
+			fmt.Fprintf(b, "%s%s: %v\n", indent, key, value)
+		}
+	}
+}
+
+// omitFields returns fields without the given keys
+func omitFields(fields []interface{}, keys ...string) []interface{} {
+	kept := make([]interface{}, 0, len(fields))
+	for i := 0; i+1 < len(fields); i += 2 {
+		if key, ok := fields[i].(string); ok && containsString(keys, key) {
+			continue
+		}
+		kept = append(kept, fields[i], fields[i+1])
+	}
+	return kept
+}
+
+// containsString reports whether values contains s
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
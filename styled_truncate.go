@@ -0,0 +1,141 @@
+package lifecycle
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultFieldWidths are the display widths of fields known to run long
+var defaultFieldWidths = map[string]int{
+	"query":      120,
+	"user_agent": 80,
+}
+
+// defaultStackLines is how many lines of a stack trace are shown unless verbose
+const defaultStackLines = 20
+
+// WithStyledFieldWidth limits how many characters of field are shown, ending truncated
+// values with an ellipsis; 0 shows the field in full
+// By default queries are limited to 120 characters and User-Agents to 80
+func WithStyledFieldWidth(field string, width int) StyledOutputOption {
+	return func(s *StyledOutput) {
+		if s.fieldWidths == nil {
+			s.fieldWidths = make(map[string]int, len(defaultFieldWidths)+1)
+			for name, w := range defaultFieldWidths {
+				s.fieldWidths[name] = w
+			}
+		}
+		s.fieldWidths[field] = width
+	}
+}
+
+// WithStyledMaxWidth limits the characters shown of every field without its own width
+// (see WithStyledFieldWidth); 0, the default, shows them in full
+func WithStyledMaxWidth(width int) StyledOutputOption {
+	return func(s *StyledOutput) {
+		s.maxWidth = width
+	}
+}
+
+// WithStyledStackLines sets how many lines of a stack trace are shown below the event
+// line before the rest is elided (default: 20); 0 shows them all
+func WithStyledStackLines(lines int) StyledOutputOption {
+	return func(s *StyledOutput) {
+		s.stackLines = lines
+	}
+}
+
+// WithStyledVerbose shows every field and stack trace in full, ignoring widths and
+// stack line limits
+func WithStyledVerbose(verbose bool) StyledOutputOption {
+	return func(s *StyledOutput) {
+		s.verbose = verbose
+	}
+}
+
+// SetVerbose switches between full and truncated values at runtime (e.g., from a
+// --verbose flag or a signal handler)
+func (s *StyledOutput) SetVerbose(verbose bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.verbose = verbose
+}
+
+// fieldWidth returns the display width of field, or 0 for no limit
+func (s *StyledOutput) fieldWidth(field string) int {
+	widths := s.fieldWidths
+	if widths == nil {
+		widths = defaultFieldWidths
+	}
+	if width, ok := widths[field]; ok {
+		return width
+	}
+	return s.maxWidth
+}
+
+// truncateFields shortens long string values to their field's width
+// Values already styled with ANSI escapes are left alone, as cutting them would break the
+// escape sequences
+func (s *StyledOutput) truncateFields(fields []interface{}) {
+	if s.verbose {
+		return
+	}
+	for i := 0; i+1 < len(fields); i += 2 {
+		key, _ := fields[i].(string)
+		value, ok := fields[i+1].(string)
+		if !ok || strings.Contains(value, "\x1b") {
+			continue
+		}
+		if width := s.fieldWidth(key); width > 0 {
+			fields[i+1] = truncate(value, width)
+		}
+	}
+}
+
+// truncate shortens s to width characters, ending it with an ellipsis
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	if width <= 1 {
+		return "…"
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+// takeStackTrace removes the stack_trace field, returning it to be shown below the event
+// line, where its lines stay readable
+func takeStackTrace(fields []interface{}) ([]interface{}, string) {
+	for i := 0; i+1 < len(fields); i += 2 {
+		if fields[i] == "stack_trace" {
+			stack, _ := fields[i+1].(string)
+			return omitFields(fields, "stack_trace"), stack
+		}
+	}
+	return fields, ""
+}
+
+// limitLines keeps the first lines of text, noting how many were elided, unless verbose
+func (s *StyledOutput) limitLines(text string) string {
+	if s.verbose || s.stackLines <= 0 {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	if len(lines) <= s.stackLines {
+		return text
+	}
+	return strings.Join(lines[:s.stackLines], "\n") + fmt.Sprintf("\n… %d more lines", len(lines)-s.stackLines)
+}
+
+// writeStackTrace writes a stack trace indented below the event line
+func (s *StyledOutput) writeStackTrace(stack string) {
+	var b strings.Builder
+	for _, line := range strings.Split(s.limitLines(stack), "\n") {
+		b.WriteString("    ")
+		b.WriteString(s.renderer.NewStyle().Faint(true).Render(line))
+		b.WriteByte('\n')
+	}
+	fmt.Fprint(s.writer, b.String())
+}
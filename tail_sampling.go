@@ -0,0 +1,241 @@
+package lifecycle
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// TailSampler buffers events by correlation ID and writes a group only if it turns out to
+// be interesting: one of its events is an error (or has a 5xx status) or took at least the
+// latency threshold. Groups that stay uninteresting for the whole window are dropped, or
+// kept at the base rate, so complete traces are preserved for the requests worth reading
+//
+// Once a group is kept, its buffered events are written and later events in the window
+// pass straight through, after the buffered ones. Events without a correlation ID are
+// never buffered
+// It is safe for concurrent use
+type TailSampler struct {
+	window    time.Duration
+	threshold time.Duration
+	baseRate  float64
+	maxGroups int
+	write     func(Event) error
+
+	mu     sync.Mutex
+	groups map[string]*tailGroup
+	order  []string // Correlation IDs in arrival order, for evicting the oldest group; may include decided groups
+}
+
+// tailGroup is the buffered state of one correlation ID
+type tailGroup struct {
+	events   []Event
+	keep     bool
+	flushing bool // Kept events are being written; later events queue behind them
+	timer    *time.Timer
+}
+
+// TailSamplerOption configures a TailSampler
+type TailSamplerOption func(*TailSampler)
+
+// WithTailWindow sets how long a group is buffered after its first event (default: 10s)
+func WithTailWindow(window time.Duration) TailSamplerOption {
+	return func(t *TailSampler) {
+		t.window = window
+	}
+}
+
+// WithLatencyThreshold keeps groups with an event that took at least threshold
+// (default: 1s)
+func WithLatencyThreshold(threshold time.Duration) TailSamplerOption {
+	return func(t *TailSampler) {
+		t.threshold = threshold
+	}
+}
+
+// WithTailBaseRate keeps a fraction of uninteresting groups, so normal traffic stays
+// visible (default: 0)
+func WithTailBaseRate(rate float64) TailSamplerOption {
+	return func(t *TailSampler) {
+		t.baseRate = rate
+	}
+}
+
+// WithMaxTailGroups bounds memory by deciding the oldest group early once more than max
+// groups are buffered (default: 10000)
+func WithMaxTailGroups(max int) TailSamplerOption {
+	return func(t *TailSampler) {
+		t.maxGroups = max
+	}
+}
+
+// NewTailSampler creates a tail sampler
+func NewTailSampler(opts ...TailSamplerOption) *TailSampler {
+	t := &TailSampler{
+		window:    10 * time.Second,
+		threshold: time.Second,
+		maxGroups: 10000,
+		groups:    make(map[string]*tailGroup),
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// WithTailSampler buffers the producer's output through a tail sampler
+// It runs after any head Sampler; like it, it only affects written output
+func WithTailSampler(sampler *TailSampler) ProducerOption {
+	return func(p *Producer) {
+		p.tailSampler = sampler
+		sampler.write = p.writeEvent
+	}
+}
+
+// hold buffers event and reports whether the caller must not write it now
+func (t *TailSampler) hold(event Event, duration time.Duration) bool {
+	id := event.GetCorrelationID()
+	if id == "" {
+		return false
+	}
+	interesting := t.interesting(event, duration)
+
+	t.mu.Lock()
+	group, ok := t.groups[id]
+	if !ok {
+		created := &tailGroup{}
+		group = created
+		t.groups[id] = group
+		t.order = append(t.order, id)
+		if len(t.order) > 2*len(t.groups)+64 {
+			t.compactOrderLocked()
+		}
+		group.timer = time.AfterFunc(t.window, func() { t.decide(id, created) })
+	}
+	if group.keep && !group.flushing {
+		t.mu.Unlock()
+		return false
+	}
+	group.events = append(group.events, event)
+	if group.keep {
+		// The flushing caller writes it after the events buffered before it
+		t.mu.Unlock()
+		return true
+	}
+	if !interesting {
+		evicted := t.evictLocked()
+		t.mu.Unlock()
+		for _, id := range evicted {
+			t.decide(id, nil)
+		}
+		return true
+	}
+
+	group.keep = true
+	group.flushing = true
+	t.mu.Unlock()
+
+	t.flush(group)
+	return true
+}
+
+// flush writes a kept group's buffered events, including those queued while it writes,
+// then lets later events pass straight through
+func (t *TailSampler) flush(group *tailGroup) {
+	for {
+		t.mu.Lock()
+		pending := group.events
+		group.events = nil
+		if len(pending) == 0 {
+			group.flushing = false
+			t.mu.Unlock()
+			return
+		}
+		t.mu.Unlock()
+
+		t.writeAll(pending)
+	}
+}
+
+// interesting reports whether event makes its group worth keeping
+func (t *TailSampler) interesting(event Event, duration time.Duration) bool {
+	if isErrorEvent(event.GetEventType()) {
+		return true
+	}
+	if code, ok := eventStatusCode(event); ok && code >= 500 {
+		return true
+	}
+	return t.threshold > 0 && duration >= t.threshold
+}
+
+// evictLocked returns the oldest groups beyond maxGroups, to be decided early
+func (t *TailSampler) evictLocked() []string {
+	var evicted []string
+	for t.maxGroups > 0 && len(t.groups)-len(evicted) > t.maxGroups && len(t.order) > 0 {
+		id := t.order[0]
+		t.order = t.order[1:]
+		if _, ok := t.groups[id]; ok {
+			evicted = append(evicted, id)
+		}
+	}
+	return evicted
+}
+
+// compactOrderLocked drops decided groups from the arrival order
+func (t *TailSampler) compactOrderLocked() {
+	order := t.order[:0]
+	for _, id := range t.order {
+		if _, ok := t.groups[id]; ok {
+			order = append(order, id)
+		}
+	}
+	t.order = order
+}
+
+// decide ends a group's window, writing its events if it is kept by the base rate
+// If only is set, the group is decided only if it is still the current one for id
+func (t *TailSampler) decide(id string, only *tailGroup) {
+	t.mu.Lock()
+	group, ok := t.groups[id]
+	if !ok || (only != nil && group != only) {
+		t.mu.Unlock()
+		return
+	}
+	delete(t.groups, id)
+	group.timer.Stop()
+	if group.keep {
+		// Kept events are written by the caller flushing them
+		t.mu.Unlock()
+		return
+	}
+	pending := group.events
+	t.mu.Unlock()
+
+	if len(pending) > 0 && t.baseRate > 0 && rand.Float64() < t.baseRate {
+		t.writeAll(pending)
+	}
+}
+
+// Flush ends every group's window now, as on shutdown
+func (t *TailSampler) Flush() {
+	t.mu.Lock()
+	ids := make([]string, 0, len(t.groups))
+	for id := range t.groups {
+		ids = append(ids, id)
+	}
+	t.mu.Unlock()
+
+	for _, id := range ids {
+		t.decide(id, nil)
+	}
+}
+
+// writeAll writes buffered events in order
+func (t *TailSampler) writeAll(events []Event) {
+	if t.write == nil {
+		return
+	}
+	for _, event := range events {
+		_ = t.write(event)
+	}
+}
@@ -0,0 +1,56 @@
+package lifecycle
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// TestTailSamplerKeptGroupOrder checks that events arriving while a kept group is being
+// written queue behind its buffered events instead of passing them
+func TestTailSamplerKeptGroupOrder(t *testing.T) {
+	sampler := NewTailSampler()
+
+	var mu sync.Mutex
+	var written []string
+	started, release := make(chan struct{}), make(chan struct{})
+	sampler.write = func(event Event) error {
+		mu.Lock()
+		written = append(written, event.GetEventType())
+		first := len(written) == 1
+		mu.Unlock()
+		if first {
+			close(started)
+			<-release
+		}
+		return nil
+	}
+	event := func(eventType string) Event {
+		return &BaseEvent{EventType: eventType, CorrelationID: "trace-1"}
+	}
+
+	if !sampler.hold(event("api.request.received"), 0) {
+		t.Fatal("uninteresting event was not buffered")
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		sampler.hold(event("error.occurred"), 0)
+	}()
+
+	// The kept group is being written: a later event must wait for it
+	<-started
+	if !sampler.hold(event("api.request.handled"), 0) {
+		t.Error("event passed through while the kept group was being written")
+	}
+	close(release)
+	<-done
+
+	want := []string{"api.request.received", "error.occurred", "api.request.handled"}
+	if !reflect.DeepEqual(written, want) {
+		t.Errorf("written = %v, want %v", written, want)
+	}
+	if sampler.hold(event("api.request.completed"), 0) {
+		t.Error("event in a flushed kept group was buffered")
+	}
+}
@@ -0,0 +1,203 @@
+package lifecycle
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// ApacheCombinedTemplate formats request events as Apache Combined Log Format lines, with
+// "-" for the parts an event does not carry (request line, address, referer, and user
+// agent are on events from ClassifyAccessLogs and in request metadata)
+const ApacheCombinedTemplate = `{{field . "remote_addr" | default "-"}} - {{field . "actor.user_id" | default "-"}} ` +
+	`[{{clf .Timestamp}}] "{{field . "method" | default "-"}} {{field . "path" | default "-"}} HTTP/1.1" ` +
+	`{{field . "status_code" | default "-"}} {{field . "response_size_bytes" | default "-"}} ` +
+	`{{field . "referer" | default "-" | quote}} {{field . "user_agent" | default "-" | quote}}`
+
+// TemplateEncoder formats events with text/template, one template per event type, for
+// sinks that need legacy log formats:
+//
+//	encoder, err := lifecycle.NewTemplateEncoder(map[string]string{
+//		"api.request.*": lifecycle.ApacheCombinedTemplate,
+//		"service.*":     `{{clf .Timestamp}} {{.Service}} {{.EventType}}`,
+//	})
+//	producer := lifecycle.NewProducer(service, host, lifecycle.WithEncoder(encoder))
+//
+// Templates execute on the event's Record, so base fields are .EventType, .Timestamp,
+// .Service, and so on. Besides the text/template built-ins, templates can use:
+//
+//	field . "name"    a field as Record.Get finds it, or a dotted path into an event field
+//	                  (e.g., "actor.user_id"); nil when absent
+//	default "-" x     x, or "-" when x is nil or empty
+//	clf t             t in Common Log Format, e.g., 10/Oct/2000:13:55:36 -0700
+//	rfc3339 t         t in RFC 3339 with nanoseconds
+//	quote x           x as a double-quoted string with Go escapes
+//	json x            x as JSON
+//	upper x, lower x  x in upper or lower case
+//
+// Events of types without a template are encoded by the fallback encoder
+type TemplateEncoder struct {
+	exact    map[string]*template.Template
+	patterns []patternTemplate // Most specific first
+	fallback Encoder
+}
+
+// patternTemplate is the template of a path.Match pattern of event types
+type patternTemplate struct {
+	pattern  string
+	template *template.Template
+}
+
+// TemplateEncoderOption configures a TemplateEncoder
+type TemplateEncoderOption func(*templateEncoderConfig)
+
+type templateEncoderConfig struct {
+	fallback Encoder
+	funcs    template.FuncMap
+}
+
+// WithTemplateFallback sets the encoder of events without a template (default: NewJSONEncoder)
+func WithTemplateFallback(encoder Encoder) TemplateEncoderOption {
+	return func(c *templateEncoderConfig) {
+		c.fallback = encoder
+	}
+}
+
+// WithTemplateFuncs adds functions the templates can call, replacing built-in ones of
+// the same name
+func WithTemplateFuncs(funcs template.FuncMap) TemplateEncoderOption {
+	return func(c *templateEncoderConfig) {
+		for name, fn := range funcs {
+			c.funcs[name] = fn
+		}
+	}
+}
+
+// NewTemplateEncoder returns an encoder formatting events with templates keyed by event
+// type, exactly or as path.Match patterns; when several patterns match, the longest wins
+func NewTemplateEncoder(templates map[string]string, opts ...TemplateEncoderOption) (*TemplateEncoder, error) {
+	config := templateEncoderConfig{fallback: NewJSONEncoder(), funcs: templateFuncs()}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	e := &TemplateEncoder{exact: make(map[string]*template.Template), fallback: config.fallback}
+	for eventType, text := range templates {
+		tmpl, err := template.New(eventType).Funcs(config.funcs).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("template for %s: %w", eventType, err)
+		}
+		if strings.ContainsAny(eventType, "*?[") {
+			e.patterns = append(e.patterns, patternTemplate{pattern: eventType, template: tmpl})
+		} else {
+			e.exact[eventType] = tmpl
+		}
+	}
+	sort.Slice(e.patterns, func(i, j int) bool {
+		a, b := e.patterns[i].pattern, e.patterns[j].pattern
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		return a < b
+	})
+	return e, nil
+}
+
+// Encode formats the event with its type's template, or the fallback encoder
+func (e *TemplateEncoder) Encode(event Event) ([]byte, error) {
+	tmpl := e.templateFor(event.GetEventType())
+	if tmpl == nil {
+		return e.fallback.Encode(event)
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	record, err := DecodeRecord(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, record); err != nil {
+		return nil, err
+	}
+	line := bytes.TrimRight(buf.Bytes(), "\r\n")
+	if bytes.ContainsAny(line, "\r\n") {
+		return nil, fmt.Errorf("template for %s produced more than one line", event.GetEventType())
+	}
+	return line, nil
+}
+
+// templateFor returns the template of an event type, or nil
+func (e *TemplateEncoder) templateFor(eventType string) *template.Template {
+	if tmpl, ok := e.exact[eventType]; ok {
+		return tmpl
+	}
+	for _, p := range e.patterns {
+		if matchPattern(p.pattern, eventType) {
+			return p.template
+		}
+	}
+	return nil
+}
+
+// templateFuncs returns the built-in template functions of TemplateEncoder
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"field": templateField,
+		"default": func(def string, value interface{}) interface{} {
+			if value == nil || value == "" {
+				return def
+			}
+			return value
+		},
+		"clf": func(t time.Time) string {
+			return t.Format("02/Jan/2006:15:04:05 -0700")
+		},
+		"rfc3339": func(t time.Time) string {
+			return t.Format(time.RFC3339Nano)
+		},
+		"quote": func(value interface{}) string {
+			return strconv.Quote(filterString(value))
+		},
+		"json": func(value interface{}) (string, error) {
+			data, err := json.Marshal(value)
+			return string(data), err
+		},
+		"upper": func(value interface{}) string {
+			return strings.ToUpper(filterString(value))
+		},
+		"lower": func(value interface{}) string {
+			return strings.ToLower(filterString(value))
+		},
+	}
+}
+
+// templateField returns a field of r by name or dotted path, or nil
+func templateField(r *Record, name string) interface{} {
+	if value, ok := r.Get(name); ok {
+		return value
+	}
+	parts := strings.Split(name, ".")
+	if len(parts) < 2 {
+		return nil
+	}
+	var value interface{} = r.Fields
+	for _, part := range parts {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		if value, ok = object[part]; !ok {
+			return nil
+		}
+	}
+	return value
+}
@@ -0,0 +1,150 @@
+package lifecycle
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme is a named set of palette overrides for styled output
+// Status colors replace the registry's status colors, and the auto palette
+// replaces the palette used by WithAutoColors
+type Theme struct {
+	Name         string
+	StatusColors map[string]string // status -> color
+	AutoPalette  []string          // Palette for auto-assigned colors (nil = keep current)
+	EventIcons   map[string]string // Event type or pattern -> icon, merged into the registry's
+}
+
+// Built-in theme names
+const (
+	ThemeAuto         = "auto" // Picks dark or light based on the terminal background
+	ThemeDark         = "dark"
+	ThemeLight        = "light"
+	ThemeHighContrast = "high-contrast"
+	ThemeColorblind   = "colorblind"
+)
+
+var (
+	themesMu sync.RWMutex
+	themes   = map[string]*Theme{
+		ThemeDark: {
+			Name:         ThemeDark,
+			StatusColors: defaultStatusColors(),
+			AutoPalette:  DefaultAutoColorPalette,
+		},
+		ThemeLight: {
+			Name: ThemeLight,
+			StatusColors: map[string]string{
+				"success":     "#15803D", // Dark green
+				"error":       "#B91C1C", // Dark red
+				"warning":     "#B45309", // Dark amber
+				"info":        "#0369A1", // Dark blue
+				"pending":     "#A16207", // Dark yellow
+				"in_progress": "#6D28D9", // Dark purple
+				"completed":   "#15803D", // Dark green
+				"failed":      "#B91C1C", // Dark red
+				"cancelled":   "#4B5563", // Dark gray
+				"created":     "#0369A1", // Dark blue
+				"updated":     "#B45309", // Dark amber
+				"deleted":     "#B91C1C", // Dark red
+			},
+			AutoPalette: []string{
+				"#1D4ED8", "#047857", "#B45309", "#6D28D9", "#BE185D", "#0F766E",
+				"#C2410C", "#4338CA", "#4D7C0F", "#0E7490", "#7E22CE", "#A16207",
+			},
+		},
+		ThemeHighContrast: {
+			Name: ThemeHighContrast,
+			StatusColors: map[string]string{
+				"success":     "#00FF00",
+				"error":       "#FF0000",
+				"warning":     "#FFFF00",
+				"info":        "#00FFFF",
+				"pending":     "#FFFF00",
+				"in_progress": "#FF00FF",
+				"completed":   "#00FF00",
+				"failed":      "#FF0000",
+				"cancelled":   "#FFFFFF",
+				"created":     "#00FFFF",
+				"updated":     "#FFFF00",
+				"deleted":     "#FF0000",
+			},
+			AutoPalette: []string{"#00FFFF", "#FF00FF", "#FFFF00", "#00FF00", "#FFFFFF", "#5FAFFF"},
+		},
+		// Okabe-Ito palette, distinguishable under the common forms of color vision deficiency
+		ThemeColorblind: {
+			Name: ThemeColorblind,
+			StatusColors: map[string]string{
+				"success":     "#0072B2", // Blue
+				"error":       "#D55E00", // Vermillion
+				"warning":     "#E69F00", // Orange
+				"info":        "#56B4E9", // Sky blue
+				"pending":     "#F0E442", // Yellow
+				"in_progress": "#CC79A7", // Reddish purple
+				"completed":   "#0072B2", // Blue
+				"failed":      "#D55E00", // Vermillion
+				"cancelled":   "#999999", // Gray
+				"created":     "#56B4E9", // Sky blue
+				"updated":     "#E69F00", // Orange
+				"deleted":     "#D55E00", // Vermillion
+			},
+			AutoPalette: []string{"#E69F00", "#56B4E9", "#009E73", "#F0E442", "#0072B2", "#CC79A7"},
+		},
+	}
+)
+
+// RegisterTheme registers (or replaces) a named theme
+func RegisterTheme(theme *Theme) {
+	themesMu.Lock()
+	defer themesMu.Unlock()
+	themes[theme.Name] = theme
+}
+
+// LookupTheme returns the theme with the given name
+// ThemeAuto resolves to ThemeDark or ThemeLight based on the terminal background
+func LookupTheme(name string) (*Theme, error) {
+	if name == ThemeAuto {
+		name = DetectTheme()
+	}
+
+	themesMu.RLock()
+	defer themesMu.RUnlock()
+	theme, ok := themes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown theme %q", name)
+	}
+	return theme, nil
+}
+
+// DetectTheme returns ThemeDark or ThemeLight based on the terminal background
+// Terminals that cannot be queried are assumed to be dark
+func DetectTheme() string {
+	if lipgloss.HasDarkBackground() {
+		return ThemeDark
+	}
+	return ThemeLight
+}
+
+// ApplyTheme overrides the registry's status colors, event icons, and auto palette with
+// the theme's
+// The auto palette is only replaced when auto colors are enabled
+func (r *ColorRegistry) ApplyTheme(theme *Theme) {
+	if theme == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for status, color := range theme.StatusColors {
+		r.statusColors[status] = color
+	}
+	for eventType, icon := range theme.EventIcons {
+		r.eventIcons[eventType] = icon
+	}
+	if r.autoPalette != nil && len(theme.AutoPalette) > 0 {
+		r.autoPalette = append([]string(nil), theme.AutoPalette...)
+	}
+	r.snapshot.Store(nil)
+}
@@ -0,0 +1,86 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// OperationTimedOutEvent represents an operation.timed_out event
+type OperationTimedOutEvent struct {
+	Base      *BaseEvent `json:"base"`
+	Operation string     `json:"operation"`
+	Status    Status     `json:"status"`
+	TimeoutMs int64      `json:"timeout_ms"` // Configured timeout
+	Deadline  time.Time  `json:"deadline"`   // Deadline that fired, which may come from a parent context
+	ElapsedMs int64      `json:"elapsed_ms"`
+}
+
+func (e *OperationTimedOutEvent) GetEventType() string     { return e.Base.GetEventType() }
+func (e *OperationTimedOutEvent) GetTimestamp() time.Time  { return e.Base.GetTimestamp() }
+func (e *OperationTimedOutEvent) GetService() string       { return e.Base.GetService() }
+func (e *OperationTimedOutEvent) GetAPI() string           { return e.Base.GetAPI() }
+func (e *OperationTimedOutEvent) GetHost() string          { return e.Base.GetHost() }
+func (e *OperationTimedOutEvent) GetCorrelationID() string { return e.Base.GetCorrelationID() }
+func (e *OperationTimedOutEvent) LogValue() slog.Value     { return eventLogValue(e) }
+
+func (e *OperationTimedOutEvent) GetMetadata() map[string]interface{} { return e.Base.GetMetadata() }
+func (e *OperationTimedOutEvent) SetMetadata(key string, value interface{}) {
+	e.Base.SetMetadata(key, value)
+}
+
+// EmitOperationTimedOut emits an operation.timed_out event
+func (p *Producer) EmitOperationTimedOut(ctx context.Context, operation string, timeout time.Duration, deadline time.Time, elapsed time.Duration) error {
+	event := &OperationTimedOutEvent{
+		Base:      p.createBaseEvent("operation.timed_out", extractCorrelationID(ctx), nil),
+		Operation: operation,
+		Status:    StatusError,
+		TimeoutMs: timeout.Milliseconds(),
+		Deadline:  deadline,
+		ElapsedMs: elapsed.Milliseconds(),
+	}
+	// ctx is usually past its deadline here, so emit with a context that is not
+	return p.emitEvent(context.WithoutCancel(ctx), event, elapsed)
+}
+
+// WithTimeout calls fn with a context that times out after timeout and emits an
+// operation.timed_out event if the deadline fires before fn returns
+// fn's error is returned unchanged; fn must respect its context for the timeout to
+// take effect
+func (p *Producer) WithTimeout(ctx context.Context, timeout time.Duration, operation string, fn func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := fn(ctx)
+	p.reportDeadline(ctx, operation, timeout, start)
+	return err
+}
+
+// TimeoutMiddleware gives each request a deadline of timeout and emits an
+// operation.timed_out event, named "METHOD route", for requests still running when it
+// fires. The route is taken from the request context (see ContextWithRoute), a template
+// registered with WithRoutes, or the templated path (see TemplatePath), never the raw path
+// Unlike http.TimeoutHandler it does not write a response; handlers must respect the
+// request context
+func (p *Producer) TimeoutMiddleware(timeout time.Duration, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		start := time.Now()
+		next.ServeHTTP(w, r.WithContext(ctx))
+		p.reportDeadline(ctx, r.Method+" "+p.route(ctx, r.URL.Path, ""), timeout, start)
+	})
+}
+
+// reportDeadline emits operation.timed_out if ctx's deadline has fired
+func (p *Producer) reportDeadline(ctx context.Context, operation string, timeout time.Duration, start time.Time) {
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return
+	}
+	deadline, _ := ctx.Deadline()
+	_ = p.EmitOperationTimedOut(ctx, operation, timeout, deadline, time.Since(start))
+}
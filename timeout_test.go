@@ -0,0 +1,25 @@
+package lifecycle
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestTimeoutMiddlewareRoute checks that timed-out requests are named by their route
+// rather than the raw path, which may carry IDs
+func TestTimeoutMiddlewareRoute(t *testing.T) {
+	var output bytes.Buffer
+	p := NewProducer("timeout", "localhost", WithOutput(&output), WithRoutes("/api/users/{id}"))
+	handler := p.TimeoutMiddleware(time.Millisecond, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/users/8231", nil))
+
+	if got := output.String(); !strings.Contains(got, `"operation":"GET /api/users/{id}"`) {
+		t.Errorf("operation.timed_out = %s, want operation GET /api/users/{id}", got)
+	}
+}
@@ -0,0 +1,171 @@
+package lifecycle
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultTrackerCorrelations is the number of correlations a Tracker keeps by default
+const defaultTrackerCorrelations = 1000
+
+// maxTrackedEvents limits the events kept per correlation, so a runaway loop cannot
+// grow a trace without bound
+const maxTrackedEvents = 1000
+
+// Tracker assembles the events a Producer emits into traces by correlation ID, keeping
+// the most recent correlations, for in-process debug endpoints
+// Events are tracked after PII redaction and before sampling, so sampled-out events
+// still appear. Each is kept as a copy with its own base, taken before the event is
+// written, so tracked events are not signed (see WithEventSigning). Events without a
+// correlation ID are ignored
+//
+// It is safe for concurrent use
+type Tracker struct {
+	mu       sync.Mutex
+	capacity int
+	traces   map[string]*Trace
+	order    []string // Correlation IDs, oldest first
+}
+
+// Trace is the events of one correlation with a breakdown of where its time went
+type Trace struct {
+	CorrelationID string      `json:"correlation_id"`
+	Events        []Event     `json:"events"` // In emission order
+	Start         time.Time   `json:"start"`  // Timestamp of the first event
+	End           time.Time   `json:"end"`    // Timestamp of the last event
+	DurationMs    int64       `json:"duration_ms"`
+	Totals        TraceTotals `json:"totals"`
+	Truncated     bool        `json:"truncated,omitempty"` // Events were dropped past the limit
+}
+
+// TraceTotals sums the durations of a trace's outcome events by kind
+type TraceTotals struct {
+	HandlerMs  int64 `json:"handler_ms"`  // api.request outcomes
+	DBMs       int64 `json:"db_ms"`       // db.query outcomes
+	RedisMs    int64 `json:"redis_ms"`    // redis.command outcomes
+	ExternalMs int64 `json:"external_ms"` // api.call outcomes (outgoing HTTP requests)
+	OtherMs    int64 `json:"other_ms"`    // Handler time not spent in the above
+	Errors     int   `json:"errors"`      // Error outcome events
+}
+
+// NewTracker creates a tracker keeping the last capacity correlations (default: 1000)
+func NewTracker(capacity int) *Tracker {
+	if capacity <= 0 {
+		capacity = defaultTrackerCorrelations
+	}
+	return &Tracker{
+		capacity: capacity,
+		traces:   make(map[string]*Trace),
+	}
+}
+
+// WithTracker enables in-process trace assembly of emitted events
+func WithTracker(tracker *Tracker) ProducerOption {
+	return func(p *Producer) {
+		p.tracker = tracker
+	}
+}
+
+// Observe records an event and its duration in its correlation's trace
+func (t *Tracker) Observe(event Event, duration time.Duration) {
+	id := event.GetCorrelationID()
+	if id == "" {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	trace, ok := t.traces[id]
+	if !ok {
+		if len(t.order) >= t.capacity {
+			delete(t.traces, t.order[0])
+			t.order = t.order[1:]
+		}
+		trace = &Trace{CorrelationID: id, Start: event.GetTimestamp()}
+		t.traces[id] = trace
+		t.order = append(t.order, id)
+	}
+
+	if len(trace.Events) >= maxTrackedEvents {
+		trace.Truncated = true
+		return
+	}
+	// The emitted event's base is still changed as it is written, when it is signed
+	trace.Events = append(trace.Events, unsignedCopy(event))
+	if ts := event.GetTimestamp(); ts.After(trace.End) {
+		trace.End = ts
+	}
+	if ts := event.GetTimestamp(); ts.Before(trace.Start) {
+		trace.Start = ts
+	}
+	trace.DurationMs = trace.End.Sub(trace.Start).Milliseconds()
+
+	eventType := event.GetEventType()
+	if !isOutcomeEvent(eventType) {
+		return
+	}
+	if isErrorEvent(eventType) {
+		trace.Totals.Errors++
+	}
+	ms := duration.Milliseconds()
+	if ms == 0 {
+		ms = eventIntField(event, "DurationMs")
+	}
+	switch {
+	case strings.HasPrefix(eventType, "api.request."):
+		trace.Totals.HandlerMs += ms
+	case strings.HasPrefix(eventType, "db.query."):
+		trace.Totals.DBMs += ms
+	case strings.HasPrefix(eventType, "redis.command."):
+		trace.Totals.RedisMs += ms
+	case strings.HasPrefix(eventType, "api.call."):
+		trace.Totals.ExternalMs += ms
+	}
+	trace.Totals.OtherMs = max(0, trace.Totals.HandlerMs-trace.Totals.DBMs-trace.Totals.RedisMs-trace.Totals.ExternalMs)
+}
+
+// Get returns a copy of the trace of a correlation, and whether it is still tracked
+func (t *Tracker) Get(correlationID string) (*Trace, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	trace, ok := t.traces[correlationID]
+	if !ok {
+		return nil, false
+	}
+	copied := *trace
+	copied.Events = append([]Event(nil), trace.Events...)
+	return &copied, true
+}
+
+// CorrelationIDs returns the tracked correlation IDs, most recent first
+func (t *Tracker) CorrelationIDs() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ids := make([]string, len(t.order))
+	for i, id := range t.order {
+		ids[len(ids)-1-i] = id
+	}
+	return ids
+}
+
+// eventIntField returns the integer field of an event with the given Go name, or 0 if it
+// has none (e.g., eventIntField(event, "DurationMs"))
+func eventIntField(event Event, name string) int64 {
+	v := reflect.ValueOf(event)
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return 0
+	}
+	field := v.FieldByName(name)
+	if !field.IsValid() || !field.CanInt() {
+		return 0
+	}
+	return field.Int()
+}
@@ -0,0 +1,50 @@
+package lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"testing"
+)
+
+// TestTrackerSignedEmit checks that reading traces while signed events are emitted does
+// not share the events being signed, for "go test -race"
+func TestTrackerSignedEmit(t *testing.T) {
+	tracker := NewTracker(16)
+	p := NewProducer("tracker", "localhost",
+		WithOutput(io.Discard),
+		WithEventSigning(NewHMACSigner([]byte("tracker"))),
+		WithTracker(tracker),
+	)
+	ctx := ContextWithCorrelationID(context.Background(), "trace-1")
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			event, _ := p.NewBaseEvent(ctx, "tracker.step")
+			_ = p.Emit(ctx, event)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			if trace, ok := tracker.Get("trace-1"); ok {
+				if _, err := json.Marshal(trace); err != nil {
+					t.Error(err)
+				}
+			}
+		}
+	}()
+	wg.Wait()
+
+	trace, ok := tracker.Get("trace-1")
+	if !ok || len(trace.Events) != 200 {
+		t.Fatalf("tracked trace = %v, %v", trace, ok)
+	}
+	if base := eventBase(trace.Events[0]); base == nil || base.Signature != "" {
+		t.Errorf("tracked event base = %+v, want an unsigned copy", base)
+	}
+}
@@ -0,0 +1,128 @@
+package lifecycle
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TypeDefinition is a type or event definition file of the API generator:
+//
+//	kind: Event
+//	spec:
+//	  type: examples.OrderPlaced
+//	  description: A customer placed an order
+//	  annotations:
+//	    - color: "#10B981"
+//	  fields:
+//	    - name: order_id
+//	      type: string
+//	      required: true
+//	    - name: email
+//	      type: string
+//	      flags: {pii: true}
+//
+// Field flags are those of ConvertFromSchemaFieldFlags
+type TypeDefinition struct {
+	Kind string   `yaml:"kind" json:"kind"` // "Type" for API resources or "Event"
+	Spec TypeSpec `yaml:"spec" json:"spec"`
+	Path string   `yaml:"-" json:"-"` // File the definition was loaded from
+}
+
+// TypeSpec is the body of a TypeDefinition
+type TypeSpec struct {
+	Type        string           `yaml:"type" json:"type"` // e.g., "examples.User"
+	Description string           `yaml:"description,omitempty" json:"description,omitempty"`
+	Color       string           `yaml:"color,omitempty" json:"color,omitempty"` // Deprecated: use a color annotation
+	Annotations []TypeAnnotation `yaml:"annotations,omitempty" json:"annotations,omitempty"`
+	Fields      []TypeField      `yaml:"fields,omitempty" json:"fields,omitempty"`
+}
+
+// TypeAnnotation is an annotation of a type; Color is "#RRGGBB" or a map with a "value"
+// or "color" key
+type TypeAnnotation struct {
+	Color interface{} `yaml:"color,omitempty" json:"color,omitempty"`
+}
+
+// TypeField is a field of a type
+type TypeField struct {
+	Name        string                 `yaml:"name" json:"name"`
+	Type        string                 `yaml:"type" json:"type"`                       // string, integer, number, boolean, timestamp, object, array, or any
+	Items       string                 `yaml:"items,omitempty" json:"items,omitempty"` // Element type of an array
+	Required    bool                   `yaml:"required,omitempty" json:"required,omitempty"`
+	Description string                 `yaml:"description,omitempty" json:"description,omitempty"`
+	Flags       map[string]interface{} `yaml:"flags,omitempty" json:"flags,omitempty"` // e.g., {pii: true, bucket: 10}
+}
+
+// IsEvent reports whether the definition is of an event rather than an API resource
+func (d *TypeDefinition) IsEvent() bool {
+	return d.Kind == "Event"
+}
+
+// Color returns the definition's color annotation, or its deprecated color field
+func (d *TypeDefinition) Color() string {
+	if color := ExtractColorFromAnnotations(d.Spec.Annotations); color != "" {
+		return color
+	}
+	return d.Spec.Color
+}
+
+// FieldAnnotations returns the annotations of the definition's flagged fields, keyed by
+// field name
+func (d *TypeDefinition) FieldAnnotations() map[string]FieldAnnotations {
+	flags := make(map[string]interface{})
+	for _, field := range d.Spec.Fields {
+		if len(field.Flags) == 0 {
+			continue
+		}
+		// YAML decodes whole numbers as ints, which ConvertFromSchemaFieldFlags ignores
+		normalized := make(map[string]interface{}, len(field.Flags))
+		for key, value := range field.Flags {
+			if n, ok := value.(int); ok {
+				value = float64(n)
+			}
+			normalized[key] = value
+		}
+		flags[field.Name] = normalized
+	}
+	return ConvertFromSchemaFieldFlags(flags)
+}
+
+// LoadTypeDefinitions loads the type and event definitions from the YAML (or JSON) files
+// under dir, sorted by type name; files of other kinds are skipped
+func LoadTypeDefinitions(dir string) ([]TypeDefinition, error) {
+	var defs []TypeDefinition
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		switch filepath.Ext(path) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var def TypeDefinition
+		if err := yaml.Unmarshal(data, &def); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if (def.Kind != "Type" && def.Kind != "Event") || def.Spec.Type == "" {
+			return nil
+		}
+		def.Path = path
+		defs = append(defs, def)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Spec.Type < defs[j].Spec.Type })
+	return defs, nil
+}
@@ -0,0 +1,196 @@
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Typed resource events take domain models directly instead of map[string]interface{}
+// payloads. Fields are annotated with a lifecycle struct tag listing FieldAnnotations
+// flags, and named by their json tags:
+//
+//	type User struct {
+//		ID    string `json:"id"`
+//		Email string `json:"email" lifecycle:"pii,encrypted"`
+//		Notes string `json:"notes" lifecycle:"sensitive"`
+//		Age   int    `json:"age" lifecycle:"bucket=10"`
+//	}
+//
+//	err := lifecycle.EmitResourceCreatedT(ctx, producer, correlationID, actor,
+//		lifecycle.NewResource("examples.User", user.ID), user)
+//
+// Annotated fields are always redacted, except numbers in noise or bucket fields, which
+// are reported approximately; other fields still pass through PII detection
+
+// annotationCache holds the annotations derived from each type's struct tags
+var annotationCache sync.Map // reflect.Type -> map[string]FieldAnnotations
+
+// AnnotationsFor returns the field annotations declared by T's lifecycle struct tags,
+// keyed by json field name and including nested structs
+func AnnotationsFor[T any]() map[string]FieldAnnotations {
+	return annotationsForType(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// annotationsForType returns the cached annotations for t
+func annotationsForType(t reflect.Type) map[string]FieldAnnotations {
+	if cached, ok := annotationCache.Load(t); ok {
+		return cached.(map[string]FieldAnnotations)
+	}
+	annotations := make(map[string]FieldAnnotations)
+	collectAnnotations(t, annotations, make(map[reflect.Type]bool))
+	annotationCache.Store(t, annotations)
+	return annotations
+}
+
+// collectAnnotations adds the annotations of t's fields, and those of nested struct types
+func collectAnnotations(t reflect.Type, annotations map[string]FieldAnnotations, seen map[reflect.Type]bool) {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || seen[t] {
+		return
+	}
+	seen[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, skip := styledFieldName(field)
+		if skip {
+			continue
+		}
+		if tag, ok := field.Tag.Lookup("lifecycle"); ok {
+			annotations[name] = parseAnnotationTag(tag)
+		}
+		collectAnnotations(field.Type, annotations, seen)
+	}
+}
+
+// parseAnnotationTag parses a lifecycle struct tag such as "pii,encrypted" or "bucket=10"
+func parseAnnotationTag(tag string) FieldAnnotations {
+	var annotations FieldAnnotations
+	for _, flag := range strings.Split(tag, ",") {
+		flag, arg, _ := strings.Cut(strings.TrimSpace(flag), "=")
+		switch flag {
+		case "pii":
+			annotations.PII = true
+		case "encrypted":
+			annotations.Encrypted = true
+		case "redactable":
+			annotations.Redactable = true
+		case "sensitive":
+			annotations.Sensitive = true
+		case "immutable":
+			annotations.Immutable = true
+		case "noise":
+			annotations.Noise, _ = strconv.ParseFloat(arg, 64)
+		case "bucket":
+			annotations.Bucket, _ = strconv.ParseFloat(arg, 64)
+		case "anonymize_ip":
+			annotations.AnonymizeIP = true
+		}
+	}
+	return annotations
+}
+
+// typedData marshals a domain model into an event payload and returns the annotations
+// from its struct tags
+func typedData[T any](value T) (map[string]interface{}, map[string]FieldAnnotations, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal %T: %w", value, err)
+	}
+	if bytes.Equal(encoded, []byte("null")) {
+		return nil, AnnotationsFor[T](), nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var data map[string]interface{}
+	if err := decoder.Decode(&data); err != nil {
+		return nil, nil, fmt.Errorf("%T does not marshal to a JSON object: %w", value, err)
+	}
+	return data, AnnotationsFor[T](), nil
+}
+
+// EmitResourceCreatedT emits a resource.created event whose data is the typed model value
+func EmitResourceCreatedT[T any](ctx context.Context, p *Producer, correlationID string, actor *Actor,
+	resource *Resource, value T, opts ...EmitOption) error {
+	data, annotations, err := typedData(value)
+	if err != nil {
+		return err
+	}
+	return p.EmitResourceCreatedWithOptions(ctx, ResourceCreatedOptions{
+		CorrelationID: correlationID,
+		Actor:         actor,
+		Resource:      resource,
+		Data:          data,
+		Annotations:   annotations,
+	}, opts...)
+}
+
+// EmitResourceUpdatedT emits a resource.updated event from the model's state before and
+// after the update; the updated fields are the top-level fields whose values differ
+func EmitResourceUpdatedT[T any](ctx context.Context, p *Producer, correlationID string, actor *Actor,
+	resource *Resource, previous, updated T, opts ...EmitOption) error {
+	previousData, annotations, err := typedData(previous)
+	if err != nil {
+		return err
+	}
+	newData, _, err := typedData(updated)
+	if err != nil {
+		return err
+	}
+	return p.EmitResourceUpdatedWithOptions(ctx, ResourceUpdatedOptions{
+		CorrelationID: correlationID,
+		Actor:         actor,
+		Resource:      resource,
+		PreviousData:  previousData,
+		NewData:       newData,
+		UpdatedFields: changedFields(previousData, newData),
+		Annotations:   annotations,
+	}, opts...)
+}
+
+// EmitResourceDeletedT emits a resource.deleted event whose final data is the typed model value
+func EmitResourceDeletedT[T any](ctx context.Context, p *Producer, correlationID string, actor *Actor,
+	resource *Resource, softDelete bool, value T, opts ...EmitOption) error {
+	data, annotations, err := typedData(value)
+	if err != nil {
+		return err
+	}
+	return p.EmitResourceDeletedWithOptions(ctx, ResourceDeletedOptions{
+		CorrelationID: correlationID,
+		Actor:         actor,
+		Resource:      resource,
+		SoftDelete:    softDelete,
+		FinalData:     data,
+		Annotations:   annotations,
+	}, opts...)
+}
+
+// changedFields returns the sorted top-level keys whose values differ between two payloads
+func changedFields(previous, updated map[string]interface{}) []string {
+	var fields []string
+	for key, value := range updated {
+		if old, ok := previous[key]; !ok || !reflect.DeepEqual(old, value) {
+			fields = append(fields, key)
+		}
+	}
+	for key := range previous {
+		if _, ok := updated[key]; !ok {
+			fields = append(fields, key)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
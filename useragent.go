@@ -0,0 +1,222 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// ClientInfo describes the client that sent a request, parsed from its User-Agent and
+// located by the producer's GeoResolver
+type ClientInfo struct {
+	Browser        string `json:"browser,omitempty"`         // Browser or client library family (e.g., "Chrome", "curl")
+	BrowserVersion string `json:"browser_version,omitempty"` // Major version
+	OS             string `json:"os,omitempty"`              // OS family (e.g., "Windows", "macOS", "iOS", "Android", "Linux")
+	Device         string `json:"device,omitempty"`          // "desktop", "mobile", "tablet", "bot", or "other"
+	Bot            bool   `json:"bot,omitempty"`             // Crawler, monitor, or other automated client
+	Country        string `json:"country,omitempty"`         // From the GeoResolver, if any
+	Region         string `json:"region,omitempty"`          // From the GeoResolver, if any
+}
+
+// String summarizes the client (e.g., "Chrome 120, macOS, desktop, DE-BE")
+func (c *ClientInfo) String() string {
+	parts := make([]string, 0, 3)
+	if c.Browser != "" {
+		parts = append(parts, strings.TrimSpace(c.Browser+" "+c.BrowserVersion))
+	}
+	if c.OS != "" {
+		parts = append(parts, c.OS)
+	}
+	if c.Device != "" {
+		parts = append(parts, c.Device)
+	}
+	if c.Country != "" {
+		parts = append(parts, strings.TrimSuffix(c.Country+"-"+c.Region, "-"))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// UserAgentHandling is what the producer records of a request's raw User-Agent, next to
+// the parsed client
+type UserAgentHandling int
+
+const (
+	UserAgentKeep UserAgentHandling = iota // Record it as received (default)
+	UserAgentDrop                          // Record only the parsed client
+	UserAgentHash                          // Record a short hash, which still tells clients apart
+)
+
+// WithUserAgentHandling sets what the producer records of raw User-Agent strings, which
+// are high-cardinality and can help fingerprint users (default: UserAgentKeep)
+func WithUserAgentHandling(handling UserAgentHandling) ProducerOption {
+	return func(p *Producer) {
+		p.userAgents = handling
+	}
+}
+
+// userAgent returns the User-Agent from ctx as the producer records it, and the client
+// parsed from it
+func (p *Producer) userAgent(ctx context.Context) (string, *ClientInfo) {
+	ua := extractUserAgent(ctx)
+	return p.rawUserAgent(ua), ParseUserAgent(ua)
+}
+
+// rawUserAgent returns a User-Agent as the producer records it
+func (p *Producer) rawUserAgent(ua string) string {
+	switch {
+	case ua == "":
+		return ""
+	case p.userAgents == UserAgentDrop:
+		return ""
+	case p.userAgents == UserAgentHash:
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(ua))
+		return fmt.Sprintf("%016x", h.Sum64())
+	}
+	return ua
+}
+
+// uaFamily is a browser or client library recognized by a product token in the
+// User-Agent, followed by its version
+type uaFamily struct {
+	name     string
+	token    string
+	requires string // Optional: another token the User-Agent must contain
+}
+
+// uaBrowsers are checked in order: browsers built on Chrome mention Chrome and Safari, and
+// Chrome mentions Safari
+var uaBrowsers = []uaFamily{
+	{"Edge", "Edg/", ""}, {"Edge", "Edge/", ""}, {"Edge", "EdgA/", ""}, {"Edge", "EdgiOS/", ""},
+	{"Opera", "OPR/", ""}, {"Opera", "Opera/", ""},
+	{"Samsung Internet", "SamsungBrowser/", ""},
+	{"Firefox", "Firefox/", ""}, {"Firefox", "FxiOS/", ""},
+	{"Chrome", "CriOS/", ""}, {"Chrome", "Chrome/", ""},
+	{"Internet Explorer", "MSIE ", ""}, {"Internet Explorer", "rv:", "Trident/"},
+	{"Safari", "Version/", "Safari/"},
+}
+
+// uaLibraries are HTTP clients and tools, matched case-insensitively at the start of the
+// User-Agent
+var uaLibraries = []uaFamily{
+	{"curl", "curl/", ""}, {"Wget", "wget/", ""}, {"Go", "go-http-client/", ""},
+	{"Python Requests", "python-requests/", ""}, {"Python", "python-urllib/", ""}, {"Python", "python/", ""},
+	{"OkHttp", "okhttp/", ""}, {"Postman", "postmanruntime/", ""}, {"axios", "axios/", ""},
+	{"node-fetch", "node-fetch/", ""}, {"Java", "java/", ""}, {"Apache HttpClient", "apache-httpclient/", ""},
+	{"grpc-go", "grpc-go/", ""}, {"Dart", "dart/", ""},
+}
+
+// uaBotMarkers identify automated clients, matched case-insensitively
+var uaBotMarkers = []string{
+	"bot", "crawler", "spider", "slurp", "facebookexternalhit", "headlesschrome",
+	"lighthouse", "pingdom", "uptime", "monitor", "preview", "probe", "healthcheck",
+}
+
+// ParseUserAgent parses a User-Agent header into the client's browser, OS, and device
+// families, or returns nil for an empty one
+// Parsing is heuristic and covers common browsers, HTTP libraries, and crawlers; unknown
+// clients get an empty browser and the "other" device
+func ParseUserAgent(ua string) *ClientInfo {
+	ua = strings.TrimSpace(ua)
+	if ua == "" {
+		return nil
+	}
+	lower := strings.ToLower(ua)
+	info := &ClientInfo{OS: uaOS(ua)}
+
+	for _, marker := range uaBotMarkers {
+		if strings.Contains(lower, marker) {
+			info.Bot = true
+			break
+		}
+	}
+
+	for _, library := range uaLibraries {
+		if strings.HasPrefix(lower, library.token) {
+			info.Browser, info.BrowserVersion = library.name, majorVersion(ua[len(library.token):])
+			break
+		}
+	}
+	if info.Browser == "" && strings.HasPrefix(ua, "Mozilla/") {
+		for _, browser := range uaBrowsers {
+			i := strings.Index(ua, browser.token)
+			if i < 0 || !strings.Contains(ua, browser.requires) {
+				continue
+			}
+			info.Browser, info.BrowserVersion = browser.name, majorVersion(ua[i+len(browser.token):])
+			break
+		}
+	}
+	if info.Bot {
+		// Crawlers often pose as a browser; name the crawler instead
+		if name, version := botName(ua); name != "" {
+			info.Browser, info.BrowserVersion = name, version
+		}
+	}
+
+	info.Device = uaDevice(ua, info)
+	return info
+}
+
+// uaOS returns the OS family named in a User-Agent
+func uaOS(ua string) string {
+	switch {
+	case strings.Contains(ua, "iPhone") || strings.Contains(ua, "iPad") || strings.Contains(ua, "iPod"):
+		return "iOS"
+	case strings.Contains(ua, "Android"):
+		return "Android"
+	case strings.Contains(ua, "Windows"):
+		return "Windows"
+	case strings.Contains(ua, "CrOS"):
+		return "ChromeOS"
+	case strings.Contains(ua, "Mac OS X") || strings.Contains(ua, "Macintosh"):
+		return "macOS"
+	case strings.Contains(ua, "Linux") || strings.Contains(ua, "X11"):
+		return "Linux"
+	}
+	return ""
+}
+
+// uaDevice returns the device family of a parsed client
+func uaDevice(ua string, info *ClientInfo) string {
+	switch {
+	case info.Bot:
+		return "bot"
+	case strings.Contains(ua, "iPad") || strings.Contains(ua, "Tablet") ||
+		(info.OS == "Android" && !strings.Contains(ua, "Mobile")):
+		return "tablet"
+	case strings.Contains(ua, "Mobi") || info.OS == "iOS" || info.OS == "Android":
+		return "mobile"
+	case info.OS != "":
+		return "desktop"
+	}
+	return "other"
+}
+
+// majorVersion returns the leading digits of a version (e.g., "120" for "120.0.6099.71")
+func majorVersion(version string) string {
+	end := 0
+	for end < len(version) && version[end] >= '0' && version[end] <= '9' {
+		end++
+	}
+	return version[:end]
+}
+
+// botName returns the product name and major version of a crawler (e.g., "Googlebot" and
+// "2" for "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)")
+func botName(ua string) (name, version string) {
+	for _, field := range strings.FieldsFunc(ua, func(r rune) bool { return r == ' ' || r == ';' || r == '(' || r == ')' }) {
+		name, version, _ := strings.Cut(field, "/")
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "http") || strings.HasPrefix(lower, "+") {
+			continue
+		}
+		for _, marker := range uaBotMarkers {
+			if strings.Contains(lower, marker) {
+				return name, majorVersion(version)
+			}
+		}
+	}
+	return "", ""
+}